@@ -71,6 +71,8 @@ func (h *ACLPolicyHandler) handleList(rw http.ResponseWriter, req *http.Request)
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.ACLPolicyListStub, 0)
 	}
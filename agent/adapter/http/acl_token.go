@@ -98,6 +98,8 @@ func (h *ACLTokenHandler) handleList(rw http.ResponseWriter, req *http.Request)
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.ACLTokenListStub, 0)
 	}
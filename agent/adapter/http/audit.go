@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// AuditHandler :
+type AuditHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewAuditHandler :
+func NewAuditHandler(conn conn.RPCConnection) *AuditHandler {
+	return &AuditHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *AuditHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 || params[0] != "" {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	switch req.Method {
+	case "GET":
+		return h.handleList(rw, req)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+func (h *AuditHandler) handleList(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	query := req.URL.Query()
+
+	args := &structs.AuditListRequest{
+		QueryOptions: parseQueryOptions(req),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+		TokenID:      query.Get("token"),
+		Operation:    query.Get("operation"),
+	}
+
+	if s := query.Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, NewCodedError(400, ErrBadRequest, err)
+		}
+		args.Since = t
+	}
+
+	var out structs.AuditListResponse
+	if err := h.rpcConn.Call("Audit.ListEntries", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	setNextToken(rw, out.NextToken)
+
+	if out.Items == nil {
+		out.Items = make([]*structs.AuditEntry, 0)
+	}
+
+	return out.Items, nil
+}
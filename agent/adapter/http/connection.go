@@ -23,10 +23,22 @@ func NewConnectionHandler(conn conn.RPCConnection) *ConnectionHandler {
 func (h *ConnectionHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	params := parsePathParams(req)
-	if len(params) > 1 {
+	if len(params) > 2 {
 		return nil, NewCodedError(404, ErrNotFound)
 	}
 
+	if len(params) == 2 {
+		if req.Method != "PUT" && req.Method != "POST" {
+			return nil, NewCodedError(405, ErrMethodNotAllowed)
+		}
+		switch params[1] {
+		case "rotate-psk":
+			return h.handleRotatePresharedKey(rw, req, params[0])
+		default:
+			return nil, NewCodedError(404, ErrNotFound)
+		}
+	}
+
 	connID := params[0]
 
 	switch req.Method {
@@ -74,6 +86,8 @@ func (h *ConnectionHandler) handleList(rw http.ResponseWriter, req *http.Request
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.ConnectionListStub, 0)
 	}
@@ -107,6 +121,21 @@ func (h *ConnectionHandler) handlePost(rw http.ResponseWriter, req *http.Request
 	return nil, nil
 }
 
+func (h *ConnectionHandler) handleRotatePresharedKey(rw http.ResponseWriter, req *http.Request, connID string) (interface{}, error) {
+
+	args := &structs.ConnectionRotatePresharedKeyRequest{
+		ConnectionID: connID,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Connection.RotatePresharedKey", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
 func (h *ConnectionHandler) handleDelete(rw http.ResponseWriter, req *http.Request, connID string) (interface{}, error) {
 
 	args := structs.ConnectionDeleteRequest{
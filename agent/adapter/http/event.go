@@ -0,0 +1,124 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+const (
+	// eventStreamQueryTime is the maximum amount of time each of
+	// the blocking queries issued by the event stream waits for events.
+	eventStreamQueryTime = 30 * time.Second
+)
+
+// EventHandler :
+type EventHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewEventHandler :
+func NewEventHandler(conn conn.RPCConnection) *EventHandler {
+	return &EventHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *EventHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 || params[0] != "" {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	switch req.Method {
+	case "GET":
+		return h.handleStream(rw, req)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+// handleStream streams events as newline-delimited JSON objects, until the
+// client disconnects. Events can be filtered by topic and key (resource ID)
+// through the query parameters of the same name, which can be repeated or
+// contain comma-separated values. The index parameter allows resuming a stream
+// from the index of the last event received.
+func (h *EventHandler) handleStream(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	query := req.URL.Query()
+
+	args := structs.EventListRequest{
+		Topics:       splitQueryValues(query["topic"]),
+		Keys:         splitQueryValues(query["key"]),
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	args.MaxQueryTime = eventStreamQueryTime
+
+	if s := query.Get("index"); s != "" {
+		index, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, NewCodedError(400, "Invalid index")
+		}
+		args.MinQueryIndex = index
+	}
+
+	// Issue a first query without blocking, so that errors such as
+	// unauthorized access can be reported with a proper status code.
+	var out structs.EventListResponse
+	first := args
+	first.MaxQueryTime = time.Millisecond
+	if err := h.rpcConn.Call("Event.ListEvents", &first, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+
+	flusher, _ := rw.(http.Flusher)
+	enc := json.NewEncoder(rw)
+
+	for {
+		for _, e := range out.Items {
+			if err := enc.Encode(e); err != nil {
+				return nil, nil
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		args.MinQueryIndex = out.Index
+
+		select {
+		case <-req.Context().Done():
+			return nil, nil
+		default:
+		}
+
+		out = structs.EventListResponse{}
+		if err := h.rpcConn.Call("Event.ListEvents", &args, &out); err != nil {
+			return nil, nil
+		}
+	}
+}
+
+func splitQueryValues(values []string) []string {
+	out := []string{}
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
@@ -73,6 +73,8 @@ func (h *InterfaceHandler) handleList(rw http.ResponseWriter, req *http.Request)
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.InterfaceListStub, 0)
 	}
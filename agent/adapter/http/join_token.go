@@ -0,0 +1,126 @@
+package http
+
+import (
+	"net/http"
+
+	conn "github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// JoinTokenHandler :
+type JoinTokenHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewJoinTokenHandler :
+func NewJoinTokenHandler(conn conn.RPCConnection) *JoinTokenHandler {
+	return &JoinTokenHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *JoinTokenHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	tokenID := params[0]
+
+	switch req.Method {
+	case "GET":
+		return h.handleGet(rw, req, tokenID)
+	case "POST":
+		return h.handlePost(rw, req, tokenID)
+	case "DELETE":
+		return h.handleDelete(rw, req, tokenID)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+func (h *JoinTokenHandler) handleGet(rw http.ResponseWriter, req *http.Request, tokenID string) (interface{}, error) {
+
+	if tokenID == "" {
+		return h.handleList(rw, req)
+	}
+
+	args := structs.JoinTokenSpecificRequest{
+		QueryOptions: parseQueryOptions(req),
+		JoinTokenID:  tokenID,
+	}
+
+	var out structs.SingleJoinTokenResponse
+	if err := h.rpcConn.Call("JoinToken.GetJoinToken", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	if out.JoinToken == nil {
+		return nil, NewCodedError(404, "Join token not found")
+	}
+
+	return out.JoinToken, nil
+}
+
+func (h *JoinTokenHandler) handleList(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	args := &structs.JoinTokenListRequest{
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	var out structs.JoinTokenListResponse
+	if err := h.rpcConn.Call("JoinToken.ListJoinTokens", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	setNextToken(rw, out.NextToken)
+
+	if out.Items == nil {
+		out.Items = make([]*structs.JoinTokenListStub, 0)
+	}
+
+	return out.Items, nil
+}
+
+func (h *JoinTokenHandler) handlePost(rw http.ResponseWriter, req *http.Request, tokenID string) (interface{}, error) {
+
+	// Join tokens can't be updated once created
+	if tokenID != "" {
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+
+	var token structs.JoinToken
+	err := parseBody(req.Body, &token)
+	if err != nil {
+		return nil, NewCodedError(400, ErrBadRequest, err)
+	}
+
+	args := &structs.JoinTokenCreateRequest{
+		JoinToken:    &token,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.JoinTokenCreateResponse
+	if err := h.rpcConn.Call("JoinToken.CreateJoinToken", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out.JoinToken, nil
+}
+
+func (h *JoinTokenHandler) handleDelete(rw http.ResponseWriter, req *http.Request, tokenID string) (interface{}, error) {
+
+	args := structs.JoinTokenDeleteRequest{
+		WriteRequest: parseWriteRequestOptions(req),
+		JoinTokenIDs: []string{tokenID},
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("JoinToken.DeleteJoinToken", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// MetricsHandler serves the metrics of the agent in the Prometheus format
+type MetricsHandler struct {
+	rpcConn conn.RPCConnection
+	handler http.Handler
+}
+
+// NewMetricsHandler :
+func NewMetricsHandler(conn conn.RPCConnection) *MetricsHandler {
+	return &MetricsHandler{
+		rpcConn: conn,
+		handler: promhttp.Handler(),
+	}
+}
+
+// Handle :
+func (h *MetricsHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return h.handleGet(rw, req)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+func (h *MetricsHandler) handleGet(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	args := structs.MetricsAuthorizeRequest{
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	// Prometheus only supports passing tokens through the Authorization header
+	if args.AuthToken == "" {
+		args.AuthToken = strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Operator.AuthorizeMetrics", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	h.handler.ServeHTTP(rw, req)
+
+	return nil, nil
+}
@@ -11,6 +11,7 @@ func CORS() func(http.HandlerFunc) http.HandlerFunc {
 			rw.Header().Set("Access-Control-Allow-Origin", "*")
 			rw.Header().Set("Access-Control-Allow-Methods", "*")
 			rw.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Referer, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			rw.Header().Set("Access-Control-Expose-Headers", "X-Drago-Next-Token")
 			if req.Method == "OPTIONS" {
 				rw.WriteHeader(http.StatusOK)
 				return
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"net/http"
+
+	conn "github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// NamespaceHandler :
+type NamespaceHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewNamespaceHandler :
+func NewNamespaceHandler(conn conn.RPCConnection) *NamespaceHandler {
+	return &NamespaceHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *NamespaceHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	name := params[0]
+
+	switch req.Method {
+	case "GET":
+		return h.handleGet(rw, req, name)
+	case "POST":
+		return h.handlePost(rw, req, name)
+	case "DELETE":
+		return h.handleDelete(rw, req, name)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+func (h *NamespaceHandler) handleGet(rw http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+
+	if name == "" {
+		return h.handleList(rw, req)
+	}
+
+	args := structs.NamespaceSpecificRequest{
+		QueryOptions: parseQueryOptions(req),
+		Name:         name,
+	}
+
+	var out structs.SingleNamespaceResponse
+	if err := h.rpcConn.Call("Namespace.GetNamespace", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out.Namespace, nil
+}
+
+func (h *NamespaceHandler) handleList(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	args := &structs.NamespaceListRequest{
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	var out structs.NamespaceListResponse
+	if err := h.rpcConn.Call("Namespace.ListNamespaces", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	setNextToken(rw, out.NextToken)
+
+	if out.Items == nil {
+		out.Items = make([]*structs.NamespaceListStub, 0)
+	}
+
+	return out.Items, nil
+}
+
+func (h *NamespaceHandler) handlePost(rw http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+
+	var ns structs.Namespace
+	err := parseBody(req.Body, &ns)
+	if err != nil {
+		return nil, NewCodedError(500, ErrInternal, err)
+	}
+
+	// Make sure the namespace name matches
+	if ns.Name != name {
+		return nil, NewCodedError(400, "Namespace name does not match request path")
+	}
+
+	args := &structs.NamespaceUpsertRequest{
+		Namespace:    &ns,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Namespace.UpsertNamespace", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
+func (h *NamespaceHandler) handleDelete(rw http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+
+	args := structs.NamespaceDeleteRequest{
+		WriteRequest: parseWriteRequestOptions(req),
+		Names:        []string{name},
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Namespace.DeleteNamespaces", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
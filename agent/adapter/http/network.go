@@ -23,12 +23,34 @@ func NewNetworkHandler(conn conn.RPCConnection) *NetworkHandler {
 func (h *NetworkHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	params := parsePathParams(req)
-	if len(params) > 1 {
+	if len(params) > 2 {
 		return nil, NewCodedError(404, ErrNotFound)
 	}
 
 	networkID := params[0]
 
+	if len(params) == 2 {
+		switch params[1] {
+		case "validate":
+			if req.Method != "GET" {
+				return nil, NewCodedError(405, ErrMethodNotAllowed)
+			}
+			return h.handleValidate(rw, req, networkID)
+		case "rotate-keys":
+			if req.Method != "PUT" && req.Method != "POST" {
+				return nil, NewCodedError(405, ErrMethodNotAllowed)
+			}
+			return h.handleRotateKeys(rw, req, networkID)
+		case "import":
+			if req.Method != "PUT" && req.Method != "POST" {
+				return nil, NewCodedError(405, ErrMethodNotAllowed)
+			}
+			return h.handleImport(rw, req, networkID)
+		default:
+			return nil, NewCodedError(404, ErrNotFound)
+		}
+	}
+
 	switch req.Method {
 	case "GET":
 		return h.handleGet(rw, req, networkID)
@@ -60,6 +82,21 @@ func (h *NetworkHandler) handleGet(rw http.ResponseWriter, req *http.Request, ne
 	return out.Network, nil
 }
 
+func (h *NetworkHandler) handleValidate(rw http.ResponseWriter, req *http.Request, networkID string) (interface{}, error) {
+
+	args := structs.NetworkSpecificRequest{
+		QueryOptions: parseQueryOptions(req),
+		NetworkID:    networkID,
+	}
+
+	var out structs.NetworkValidateResponse
+	if err := h.rpcConn.Call("Network.ValidateNetwork", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out.Conflicts, nil
+}
+
 func (h *NetworkHandler) handleList(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	args := &structs.NetworkListRequest{
@@ -71,6 +108,8 @@ func (h *NetworkHandler) handleList(rw http.ResponseWriter, req *http.Request) (
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.NetworkListStub, 0)
 	}
@@ -118,3 +157,36 @@ func (h *NetworkHandler) handleDelete(rw http.ResponseWriter, req *http.Request,
 
 	return nil, nil
 }
+
+func (h *NetworkHandler) handleRotateKeys(rw http.ResponseWriter, req *http.Request, networkID string) (interface{}, error) {
+
+	args := &structs.InterfaceRotateKeysRequest{
+		NetworkID:    networkID,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Interface.RotateKeys", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
+func (h *NetworkHandler) handleImport(rw http.ResponseWriter, req *http.Request, networkID string) (interface{}, error) {
+
+	args := &structs.NetworkImportRequest{}
+	if err := parseBody(req.Body, args); err != nil {
+		return nil, NewCodedError(400, err.Error())
+	}
+
+	args.NetworkID = networkID
+	args.WriteRequest = parseWriteRequestOptions(req)
+
+	var out structs.NetworkImportResponse
+	if err := h.rpcConn.Call("Network.ImportNetwork", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out.Plan, nil
+}
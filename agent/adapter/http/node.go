@@ -23,10 +23,26 @@ func NewNodeHandler(conn conn.RPCConnection) *NodeHandler {
 func (h *NodeHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	pathParams := parsePathParams(req)
-	if len(pathParams) > 1 {
+	if len(pathParams) > 2 {
 		return nil, NewCodedError(404, ErrNotFound)
 	}
 
+	if len(pathParams) == 2 {
+		if req.Method != "PUT" && req.Method != "POST" {
+			return nil, NewCodedError(405, ErrMethodNotAllowed)
+		}
+		switch pathParams[1] {
+		case "eligibility":
+			return h.handleUpdateEligibility(rw, req, pathParams[0])
+		case "drain":
+			return h.handleUpdateDrain(rw, req, pathParams[0])
+		case "rotate-keys":
+			return h.handleRotateKeys(rw, req, pathParams[0])
+		default:
+			return nil, NewCodedError(404, ErrNotFound)
+		}
+	}
+
 	switch req.Method {
 	case "GET":
 		return h.handleGet(rw, req, pathParams)
@@ -69,6 +85,8 @@ func (h *NodeHandler) handleList(rw http.ResponseWriter, req *http.Request) (int
 		return nil, parseError(err)
 	}
 
+	setNextToken(rw, out.NextToken)
+
 	if out.Items == nil {
 		out.Items = make([]*structs.NodeListStub, 0)
 	}
@@ -103,3 +121,54 @@ func (h *NodeHandler) handlePost(rw http.ResponseWriter, req *http.Request, path
 
 	return nil, nil
 }
+
+func (h *NodeHandler) handleUpdateEligibility(rw http.ResponseWriter, req *http.Request, nodeID string) (interface{}, error) {
+
+	var args structs.NodeUpdateEligibilityRequest
+	if err := parseBody(req.Body, &args); err != nil {
+		return nil, NewCodedError(400, err.Error())
+	}
+
+	args.NodeID = nodeID
+	args.WriteRequest = parseWriteRequestOptions(req)
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Node.UpdateEligibility", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
+func (h *NodeHandler) handleUpdateDrain(rw http.ResponseWriter, req *http.Request, nodeID string) (interface{}, error) {
+
+	var args structs.NodeUpdateDrainRequest
+	if err := parseBody(req.Body, &args); err != nil {
+		return nil, NewCodedError(400, err.Error())
+	}
+
+	args.NodeID = nodeID
+	args.WriteRequest = parseWriteRequestOptions(req)
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Node.UpdateDrain", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
+func (h *NodeHandler) handleRotateKeys(rw http.ResponseWriter, req *http.Request, nodeID string) (interface{}, error) {
+
+	args := &structs.InterfaceRotateKeysRequest{
+		NodeID:       nodeID,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Interface.RotateKeys", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// OperatorHandler :
+type OperatorHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewOperatorHandler :
+func NewOperatorHandler(conn conn.RPCConnection) *OperatorHandler {
+	return &OperatorHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *OperatorHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	switch params[0] {
+	case "snapshot":
+		switch req.Method {
+		case "GET":
+			return h.handleSnapshotSave(rw, req)
+		case "PUT", "POST":
+			return h.handleSnapshotRestore(rw, req)
+		default:
+			return nil, NewCodedError(405, ErrMethodNotAllowed)
+		}
+	default:
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+}
+
+func (h *OperatorHandler) handleSnapshotSave(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	args := structs.SnapshotSaveRequest{
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	var out structs.SnapshotSaveResponse
+	if err := h.rpcConn.Call("Operator.SnapshotSave", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	// The snapshot is served as is, rather than encoded as JSON
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.Header().Set("Content-Length", strconv.Itoa(len(out.Snapshot)))
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(out.Snapshot)
+
+	return nil, nil
+}
+
+func (h *OperatorHandler) handleSnapshotRestore(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, NewCodedError(400, err.Error())
+	}
+
+	args := structs.SnapshotRestoreRequest{
+		Snapshot:     b,
+		WriteRequest: parseWriteRequestOptions(req),
+	}
+
+	var out structs.SnapshotRestoreResponse
+	if err := h.rpcConn.Call("Operator.SnapshotRestore", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	// peerConfigQRCodeSize is the size, in pixels, of the QR codes
+	// containing the configurations of external peers.
+	peerConfigQRCodeSize = 512
+)
+
+// PeerHandler :
+type PeerHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewPeerHandler :
+func NewPeerHandler(conn conn.RPCConnection) *PeerHandler {
+	return &PeerHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *PeerHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 2 {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	if len(params) == 2 {
+		if req.Method != "GET" {
+			return nil, NewCodedError(405, ErrMethodNotAllowed)
+		}
+		switch params[1] {
+		case "config":
+			return h.handleConfig(rw, req, params[0])
+		default:
+			return nil, NewCodedError(404, ErrNotFound)
+		}
+	}
+
+	peerID := params[0]
+
+	switch req.Method {
+	case "GET":
+		return h.handleGet(rw, req, peerID)
+	case "PUT", "POST":
+		return h.handlePost(rw, req, peerID)
+	case "DELETE":
+		return h.handleDelete(rw, req, peerID)
+	default:
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+}
+
+func (h *PeerHandler) handleGet(rw http.ResponseWriter, req *http.Request, peerID string) (interface{}, error) {
+
+	if peerID == "" {
+		return h.handleList(rw, req)
+	}
+
+	args := structs.InterfaceSpecificRequest{
+		QueryOptions: parseQueryOptions(req),
+		InterfaceID:  peerID,
+	}
+
+	var out structs.SingleInterfaceResponse
+	if err := h.rpcConn.Call("Interface.GetInterface", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	if !out.Interface.External {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	return out.Interface, nil
+}
+
+func (h *PeerHandler) handleList(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	args := &structs.InterfaceListRequest{
+		QueryOptions: parseQueryOptions(req),
+		NetworkID:    req.URL.Query().Get("network"),
+	}
+
+	var out structs.InterfaceListResponse
+	if err := h.rpcConn.Call("Peer.ListPeers", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	setNextToken(rw, out.NextToken)
+
+	if out.Items == nil {
+		out.Items = make([]*structs.InterfaceListStub, 0)
+	}
+
+	return out.Items, nil
+}
+
+func (h *PeerHandler) handlePost(rw http.ResponseWriter, req *http.Request, peerID string) (interface{}, error) {
+
+	// External peers are updated through the interfaces endpoint
+	if peerID != "" {
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+
+	args := &structs.ExternalPeerCreateRequest{}
+	if err := parseBody(req.Body, args); err != nil {
+		return nil, NewCodedError(400, err.Error())
+	}
+
+	args.WriteRequest = parseWriteRequestOptions(req)
+
+	var out structs.ExternalPeerCreateResponse
+	if err := h.rpcConn.Call("Peer.CreatePeer", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out, nil
+}
+
+func (h *PeerHandler) handleDelete(rw http.ResponseWriter, req *http.Request, peerID string) (interface{}, error) {
+
+	args := structs.InterfaceDeleteRequest{
+		WriteRequest: parseWriteRequestOptions(req),
+		InterfaceIDs: []string{peerID},
+	}
+
+	var out structs.GenericResponse
+	if err := h.rpcConn.Call("Peer.DeletePeer", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return nil, nil
+}
+
+// handleConfig serves the configuration of an external peer in the wg-quick format,
+// either as is, or encoded in a PNG QR code, which can be scanned by the mobile apps.
+func (h *PeerHandler) handleConfig(rw http.ResponseWriter, req *http.Request, peerID string) (interface{}, error) {
+
+	format := req.URL.Query().Get("format")
+	if format != "" && format != "conf" && format != "png" {
+		return nil, NewCodedError(400, "Invalid format, expected 'conf' or 'png'")
+	}
+
+	args := structs.ExternalPeerConfigRequest{
+		QueryOptions: parseQueryOptions(req),
+		InterfaceID:  peerID,
+	}
+
+	var out structs.ExternalPeerConfigResponse
+	if err := h.rpcConn.Call("Peer.GetConfig", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	body, contentType := []byte(out.Config), "text/plain; charset=utf-8"
+
+	if format == "png" {
+		png, err := qrcode.Encode(out.Config, qrcode.Medium, peerConfigQRCodeSize)
+		if err != nil {
+			return nil, NewCodedError(500, err.Error())
+		}
+		body, contentType = png, "image/png"
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(body)
+
+	return nil, nil
+}
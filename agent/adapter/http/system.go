@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/seashell/drago/agent/conn"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// SystemHandler :
+type SystemHandler struct {
+	rpcConn conn.RPCConnection
+}
+
+// NewSystemHandler :
+func NewSystemHandler(conn conn.RPCConnection) *SystemHandler {
+	return &SystemHandler{
+		rpcConn: conn,
+	}
+}
+
+// Handle :
+func (h *SystemHandler) Handle(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	params := parsePathParams(req)
+	if len(params) > 1 {
+		return nil, NewCodedError(404, ErrNotFound)
+	}
+
+	switch params[0] {
+	case "gc":
+		return h.handleGarbageCollect(rw, req)
+	default:
+		return nil, NewCodedError(404, "Not found")
+	}
+}
+
+func (h *SystemHandler) handleGarbageCollect(rw http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, NewCodedError(405, ErrMethodNotAllowed)
+	}
+
+	args := structs.SystemGCRequest{
+		QueryOptions: parseQueryOptions(req),
+	}
+
+	var out structs.SystemGCResponse
+	if err := h.rpcConn.Call("System.GarbageCollect", &args, &out); err != nil {
+		return nil, parseError(err)
+	}
+
+	return out, nil
+}
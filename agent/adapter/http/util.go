@@ -14,26 +14,40 @@ import (
 )
 
 const (
-	paginationPageQueryKey    = "page"
-	paginationPerPageQueryKey = "per_page"
-	defaultPaginationPage     = 1
-	defaultPaginationPerPage  = 10
-)
+	paginationPerPageQueryKey   = "per_page"
+	paginationNextTokenQueryKey = "next_token"
+	paginationSortQueryKey      = "sort"
+	paginationOrderQueryKey     = "order"
 
-func parsePaginationQueryParams(query url.Values) (int, int) {
+	filterQueryKey = "filter"
 
-	var err error
-	var page, perPage int
+	// nextTokenHeader is the header in which list endpoints return
+	// the token for retrieving the next page of items, if any.
+	nextTokenHeader = "X-Drago-Next-Token"
 
-	if page, err = strconv.Atoi(query.Get(paginationPageQueryKey)); err != nil {
-		page = defaultPaginationPage
-	}
+	// namespaceHeader is the header in which requests specify
+	// the namespace they target, which defaults to "default".
+	namespaceHeader = "X-Drago-Namespace"
+)
+
+// parsePaginationQueryParams parses the pagination and sorting options of list
+// requests into opts. Invalid page sizes are ignored, returning all items.
+func parsePaginationQueryParams(query url.Values, opts *structs.QueryOptions) {
 
-	if perPage, err = strconv.Atoi(query.Get(paginationPerPageQueryKey)); err != nil {
-		perPage = defaultPaginationPerPage
+	if perPage, err := strconv.Atoi(query.Get(paginationPerPageQueryKey)); err == nil && perPage > 0 {
+		opts.PerPage = perPage
 	}
 
-	return page, perPage
+	opts.NextToken = query.Get(paginationNextTokenQueryKey)
+	opts.Sort = query.Get(paginationSortQueryKey)
+	opts.Order = query.Get(paginationOrderQueryKey)
+}
+
+// setNextToken sets the token for retrieving the next page of items, if any.
+func setNextToken(rw http.ResponseWriter, token string) {
+	if token != "" {
+		rw.Header().Set(nextTokenHeader, token)
+	}
 }
 
 func parseBody(body io.ReadCloser, out interface{}) error {
@@ -63,6 +77,10 @@ func parseAuthToken(req *http.Request) string {
 	return req.Header.Get("X-Drago-Token")
 }
 
+func parseNamespace(req *http.Request) string {
+	return req.Header.Get(namespaceHeader)
+}
+
 func trimPathPrefix(req *http.Request, prefix string) *http.Request {
 	s := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/"), "/")
 	s = strings.TrimPrefix(s, prefix)
@@ -71,10 +89,18 @@ func trimPathPrefix(req *http.Request, prefix string) *http.Request {
 }
 
 func parseQueryOptions(req *http.Request) structs.QueryOptions {
-	return structs.QueryOptions{
+
+	opts := structs.QueryOptions{
 		AuthToken: parseAuthToken(req),
 		Filters:   parseFilters(req),
+		Namespace: parseNamespace(req),
 	}
+
+	parsePaginationQueryParams(req.URL.Query(), &opts)
+
+	opts.Filter = req.URL.Query().Get(filterQueryKey)
+
+	return opts
 }
 
 func parseFilters(req *http.Request) structs.Filters {
@@ -84,15 +110,20 @@ func parseFilters(req *http.Request) structs.Filters {
 func parseWriteRequestOptions(req *http.Request) structs.WriteRequest {
 	return structs.WriteRequest{
 		AuthToken: parseAuthToken(req),
+		Namespace: parseNamespace(req),
 	}
 }
 
 func parseError(err error) error {
-	switch err.Error() {
-	case structs.ErrPermissionDenied.Error():
+	switch {
+	case err.Error() == structs.ErrPermissionDenied.Error():
 		return NewCodedError(403, err.Error())
-	case structs.ErrNotFound.Error():
+	case err.Error() == structs.ErrNotFound.Error():
 		return NewCodedError(404, err.Error())
+	case strings.HasPrefix(err.Error(), structs.ErrInvalidInput.Error()):
+		return NewCodedError(400, err.Error())
+	case strings.HasPrefix(err.Error(), structs.ErrConflict.Error()):
+		return NewCodedError(409, err.Error())
 	default:
 		return NewCodedError(500, err.Error())
 	}
@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	stdhttp "net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	handler "github.com/seashell/drago/agent/adapter/http"
 	middleware "github.com/seashell/drago/agent/adapter/http/middleware"
@@ -14,6 +18,7 @@ import (
 	config "github.com/seashell/drago/drago/structs/config"
 	http "github.com/seashell/drago/pkg/http"
 	log "github.com/seashell/drago/pkg/log"
+	tlsutil "github.com/seashell/drago/pkg/tlsutil"
 )
 
 // Agent :
@@ -28,6 +33,9 @@ type Agent struct {
 
 	httpServer *http.Server
 
+	// httpCertReloader serves the HTTPS certificate, if enabled
+	httpCertReloader *tlsutil.KeyPairReloader
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -107,6 +115,21 @@ func (a *Agent) Config() map[string]interface{} {
 	return config
 }
 
+// Reload reloads the parts of the agent configuration
+// which can be changed without restarting it, namely the
+// HTTPS certificate.
+func (a *Agent) Reload() error {
+
+	if a.httpCertReloader != nil {
+		if err := a.httpCertReloader.Reload(); err != nil {
+			return fmt.Errorf("could not reload https certificate: %v", err)
+		}
+		a.logger.Infof("reloaded https certificate")
+	}
+
+	return nil
+}
+
 // Shutdown is used to terminate the agent.
 func (a *Agent) Shutdown() error {
 
@@ -129,6 +152,11 @@ func (a *Agent) Shutdown() error {
 			a.logger.Errorf("server shutdown failed: %s", err.Error())
 		}
 	}
+	if a.httpServer != nil {
+		if err := a.httpServer.Shutdown(); err != nil {
+			a.logger.Errorf("http server shutdown failed: %s", err.Error())
+		}
+	}
 
 	a.logger.Infof("agent shutdown complete")
 
@@ -140,15 +168,23 @@ func (a *Agent) Shutdown() error {
 
 func (a *Agent) setupRPCConnection() error {
 
-	address := ""
+	servers := []string{}
 
 	if a.config.Server.Enabled {
-		address = fmt.Sprintf("%s:%d", a.config.BindAddr, a.config.Ports.RPC)
+		servers = append(servers, fmt.Sprintf("%s:%d", a.config.BindAddr, a.config.Ports.RPC))
 	} else {
-		address = a.config.Client.Servers[0]
+		servers = append(servers, a.config.Client.Servers...)
 	}
 
-	a.rpcConn = conn.NewRPCConnection(address, a.logger)
+	var tlsConfig *tls.Config
+	if c := a.config.TLS.RPCConfig(); c != nil {
+		var err error
+		if tlsConfig, err = c.OutgoingTLSConfig(); err != nil {
+			return fmt.Errorf("invalid tls config: %v", err)
+		}
+	}
+
+	a.rpcConn = conn.NewRPCConnectionWithServers(servers, tlsConfig, a.logger)
 
 	return nil
 }
@@ -192,16 +228,96 @@ func (a *Agent) serverConfig() (*drago.Config, error) {
 		RPC:  a.config.Ports.RPC,
 	}
 
+	c.RPCAdvertiseAddr = a.rpcAdvertiseAddr()
+
 	c.ACL = &config.ACLConfig{
 		Enabled: a.config.ACL.Enabled,
 	}
 
+	c.TLSConfig = a.config.TLS.RPCConfig()
+
+	c.RequireJoinToken = !c.DevMode
+	if a.config.Server.RequireJoinToken != nil {
+		c.RequireJoinToken = *a.config.Server.RequireJoinToken
+	}
+
+	if s := a.config.Server.HeartbeatTTL; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heartbeat_ttl: %v", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid heartbeat_ttl: must be positive")
+		}
+		c.HeartbeatTTL = d
+	}
+
+	if s := a.config.Server.HeartbeatGrace; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heartbeat_grace: %v", err)
+		}
+		c.HeartbeatGrace = d
+	}
+
+	if s := a.config.Server.NodeGCInterval; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node_gc_interval: %v", err)
+		}
+		c.HostGCInterval = d
+	}
+
+	if s := a.config.Server.NodeGCThreshold; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node_gc_threshold: %v", err)
+		}
+		c.HostGCThreshold = d
+	}
+
+	if s := a.config.Server.AuditRetention; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit_retention: %v", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid audit_retention: must be positive")
+		}
+		c.AuditRetention = d
+	}
+
+	c.AuditLogFile = a.config.Server.AuditLogFile
+
 	c.LogLevel = a.config.LogLevel
 	c.Logger = a.logger
 
 	return c, nil
 }
 
+// rpcAdvertiseAddr returns the RPC address the server advertises to client nodes,
+// which is the server advertise address, or the bind address if not set, along
+// with the RPC port unless the advertise address has one. Unspecified addresses,
+// such as 0.0.0.0, can't be reached by nodes, so nothing is advertised for them.
+func (a *Agent) rpcAdvertiseAddr() string {
+
+	host := a.config.BindAddr
+
+	if a.config.AdvertiseAddrs != nil && a.config.AdvertiseAddrs.Server != "" {
+		addr := a.config.AdvertiseAddrs.Server
+		if _, _, err := net.SplitHostPort(addr); err == nil {
+			return addr
+		}
+		host = addr
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		return ""
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(a.config.Ports.RPC))
+}
+
 // Setup Drago client, if enabled
 func (a *Agent) setupClient() error {
 
@@ -234,6 +350,8 @@ func (a *Agent) clientConfig() (*client.Config, error) {
 
 	c.Servers = a.config.Client.Servers
 	c.StateDir = a.config.Client.StateDir
+	c.JoinToken = a.config.Client.JoinToken
+	c.Namespace = a.config.Client.Namespace
 
 	c.AdvertiseAddress = a.config.AdvertiseAddrs.Peer
 
@@ -242,6 +360,17 @@ func (a *Agent) clientConfig() (*client.Config, error) {
 
 	c.Meta = a.config.Client.Meta
 
+	if s := a.config.Client.KeyLifetime; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key_lifetime: %v", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid key_lifetime: must be positive")
+		}
+		c.KeyLifetime = d
+	}
+
 	c.LogLevel = a.config.LogLevel
 	c.Logger = a.logger
 
@@ -257,12 +386,20 @@ func (a *Agent) setupHTTPServer() error {
 			"/api/agent/":        handler.NewAgentHandler(a.rpcConn, a),
 			"/api/nodes/":        handler.NewNodeHandler(a.rpcConn),
 			"/api/interfaces/":   handler.NewInterfaceHandler(a.rpcConn),
+			"/api/peers/":        handler.NewPeerHandler(a.rpcConn),
 			"/api/connections/":  handler.NewConnectionHandler(a.rpcConn),
 			"/api/networks/":     handler.NewNetworkHandler(a.rpcConn),
 			"/api/acl/":          handler.NewACLHandler(a.rpcConn),
 			"/api/acl/tokens/":   handler.NewACLTokenHandler(a.rpcConn),
 			"/api/acl/policies/": handler.NewACLPolicyHandler(a.rpcConn),
+			"/api/events/":       handler.NewEventHandler(a.rpcConn),
+			"/api/system/":       handler.NewSystemHandler(a.rpcConn),
+			"/api/operator/":     handler.NewOperatorHandler(a.rpcConn),
+			"/api/join-tokens/":  handler.NewJoinTokenHandler(a.rpcConn),
+			"/api/audit/":        handler.NewAuditHandler(a.rpcConn),
+			"/api/namespaces/":   handler.NewNamespaceHandler(a.rpcConn),
 			"/status":            handler.NewStatusHandler(a.rpcConn),
+			"/metrics":           handler.NewMetricsHandler(a.rpcConn),
 		},
 		Middleware: []http.Middleware{
 			middleware.CORS(),
@@ -281,6 +418,19 @@ func (a *Agent) setupHTTPServer() error {
 		config.Handlers["/"] = handler.NewFallthroughHandler("/ui/")
 	}
 
+	if c := a.config.TLS.HTTPConfig(); c != nil {
+		tlsConfig, reloader, err := c.ReloadableIncomingTLSConfig()
+		if err != nil {
+			return fmt.Errorf("invalid tls config: %v", err)
+		}
+		config.TLSConfig = tlsConfig
+		a.httpCertReloader = reloader
+
+		if port := a.config.TLS.HTTPRedirectPort; port != 0 {
+			config.RedirectBindAddress = fmt.Sprintf("%s:%d", a.config.BindAddr, port)
+		}
+	}
+
 	httpServer, err := http.NewServer(config)
 	if err != nil {
 		return err
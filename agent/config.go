@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/seashell/drago/pkg/tlsutil"
 	"github.com/seashell/drago/pkg/util"
 	"github.com/seashell/drago/version"
 )
@@ -56,6 +57,9 @@ type Config struct {
 	// ACL contains all ACL configurations
 	ACL *ACLConfig `hcl:"acl,block"`
 
+	// TLS contains all TLS configurations
+	TLS *TLSConfig `hcl:"tls,block"`
+
 	// DevMode is set by the --dev CLI flag.
 	DevMode *bool
 
@@ -129,6 +133,14 @@ func (c *Config) Merge(b *Config) *Config {
 		result.ACL = result.ACL.Merge(b.ACL)
 	}
 
+	// Apply the TLS config
+	if result.TLS == nil && b.TLS != nil {
+		tls := *b.TLS
+		result.TLS = &tls
+	} else if b.TLS != nil {
+		result.TLS = result.TLS.Merge(b.TLS)
+	}
+
 	// Apply the advertise addrs config
 	if result.AdvertiseAddrs == nil && b.AdvertiseAddrs != nil {
 		advertise := *b.AdvertiseAddrs
@@ -144,6 +156,35 @@ func (c *Config) Merge(b *Config) *Config {
 type ServerConfig struct {
 	// Enabled controls if the agent is a server
 	Enabled bool `hcl:"enabled,optional"`
+
+	// RequireJoinToken controls whether nodes unknown to the server must
+	// present a valid join token in order to register. Defaults to true,
+	// except in dev mode.
+	RequireJoinToken *bool `hcl:"require_join_token,optional"`
+
+	// HeartbeatTTL controls the interval at which client nodes are
+	// expected to send heartbeats (e.g. "5s"). Defaults to 5 seconds.
+	HeartbeatTTL string `hcl:"heartbeat_ttl,optional"`
+
+	// HeartbeatGrace controls the additional time the server waits for
+	// a heartbeat before marking a node as down. Defaults to 5 seconds.
+	HeartbeatGrace string `hcl:"heartbeat_grace,optional"`
+
+	// NodeGCInterval controls how often the server garbage collects
+	// nodes which are down (e.g. "5m"). Defaults to 5 minutes.
+	NodeGCInterval string `hcl:"node_gc_interval,optional"`
+
+	// NodeGCThreshold controls how long a node must be down before
+	// being garbage collected (e.g. "24h"). Defaults to 24 hours.
+	NodeGCThreshold string `hcl:"node_gc_threshold,optional"`
+
+	// AuditRetention controls how long entries are kept in the
+	// audit log (e.g. "720h"). Defaults to 30 days.
+	AuditRetention string `hcl:"audit_retention,optional"`
+
+	// AuditLogFile is the path of a file to which audit entries
+	// are mirrored in the JSON lines format, if set.
+	AuditLogFile string `hcl:"audit_log_file,optional"`
 }
 
 // Merge merges two ServerConfig structs, returning the result
@@ -153,6 +194,27 @@ func (c *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.Enabled {
 		result.Enabled = true
 	}
+	if b.RequireJoinToken != nil {
+		result.RequireJoinToken = b.RequireJoinToken
+	}
+	if b.HeartbeatTTL != "" {
+		result.HeartbeatTTL = b.HeartbeatTTL
+	}
+	if b.HeartbeatGrace != "" {
+		result.HeartbeatGrace = b.HeartbeatGrace
+	}
+	if b.NodeGCInterval != "" {
+		result.NodeGCInterval = b.NodeGCInterval
+	}
+	if b.NodeGCThreshold != "" {
+		result.NodeGCThreshold = b.NodeGCThreshold
+	}
+	if b.AuditRetention != "" {
+		result.AuditRetention = b.AuditRetention
+	}
+	if b.AuditLogFile != "" {
+		result.AuditLogFile = b.AuditLogFile
+	}
 	return &result
 }
 
@@ -164,6 +226,14 @@ type ClientConfig struct {
 	// Server is the address of a known Drago server in "host:port" format
 	Servers []string `hcl:"servers,optional"`
 
+	// JoinToken is the secret of the join token used for
+	// registering the client node with the servers
+	JoinToken string `hcl:"join_token,optional"`
+
+	// Namespace is the namespace in which the client node is registered,
+	// which must be the namespace of the join token, if any
+	Namespace string `hcl:"namespace,optional"`
+
 	// StateDir is the directory where the client state will be kept
 	StateDir string `hcl:"state_dir,optional"`
 
@@ -177,6 +247,10 @@ type ClientConfig struct {
 	// Meta contains metadata about the client node
 	Meta map[string]string `hcl:"meta,optional"`
 
+	// KeyLifetime controls how long the key pairs of WireGuard interfaces are
+	// used before being rotated. If not set, keys are only rotated on request.
+	KeyLifetime string `hcl:"key_lifetime,optional"`
+
 	// SyncInterval controls how frequently the client synchronizes its state
 	SyncInterval time.Duration `hcl:"sync_interval,optional"`
 }
@@ -191,6 +265,12 @@ func (c *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.Servers != nil {
 		result.Servers = b.Servers
 	}
+	if b.JoinToken != "" {
+		result.JoinToken = b.JoinToken
+	}
+	if b.Namespace != "" {
+		result.Namespace = b.Namespace
+	}
 	if b.StateDir != "" {
 		result.StateDir = b.StateDir
 	}
@@ -200,6 +280,9 @@ func (c *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.InterfacesPrefix != "" {
 		result.InterfacesPrefix = b.InterfacesPrefix
 	}
+	if b.KeyLifetime != "" {
+		result.KeyLifetime = b.KeyLifetime
+	}
 	if b.SyncInterval != 0 {
 		result.SyncInterval = b.SyncInterval
 	}
@@ -225,6 +308,104 @@ func (c *ACLConfig) Merge(b *ACLConfig) *ACLConfig {
 	return &result
 }
 
+// TLSConfig contains configuration for securing Drago's transports with TLS
+type TLSConfig struct {
+	// RPC controls if RPCs between agents are secured with TLS
+	RPC bool `hcl:"rpc,optional"`
+
+	// HTTP controls if the HTTP API and UI are served over HTTPS
+	HTTP bool `hcl:"http,optional"`
+
+	// CAFile is the path to the CA certificate used for verifying peers
+	CAFile string `hcl:"ca_file,optional"`
+
+	// CertFile is the path to the certificate presented by this agent
+	CertFile string `hcl:"cert_file,optional"`
+
+	// KeyFile is the path to the private key of the certificate
+	KeyFile string `hcl:"key_file,optional"`
+
+	// VerifyIncoming controls if servers require clients to present
+	// a certificate signed by the CA (i.e., mutual TLS)
+	VerifyIncoming bool `hcl:"verify_incoming,optional"`
+
+	// VerifyServerHostname controls if clients verify that the server
+	// certificate is valid for the address being dialed
+	VerifyServerHostname bool `hcl:"verify_server_hostname,optional"`
+
+	// VerifyHTTPSClient controls if HTTPS clients must present
+	// a certificate signed by the CA
+	VerifyHTTPSClient bool `hcl:"verify_https_client,optional"`
+
+	// HTTPRedirectPort, if set, is the port on which plain HTTP
+	// requests are redirected to HTTPS
+	HTTPRedirectPort int `hcl:"http_redirect_port,optional"`
+}
+
+// Merge merges two TLSConfig structs, returning the result
+func (c *TLSConfig) Merge(b *TLSConfig) *TLSConfig {
+	result := *c
+
+	if b.RPC {
+		result.RPC = true
+	}
+	if b.HTTP {
+		result.HTTP = true
+	}
+	if b.CAFile != "" {
+		result.CAFile = b.CAFile
+	}
+	if b.CertFile != "" {
+		result.CertFile = b.CertFile
+	}
+	if b.KeyFile != "" {
+		result.KeyFile = b.KeyFile
+	}
+	if b.VerifyIncoming {
+		result.VerifyIncoming = true
+	}
+	if b.VerifyServerHostname {
+		result.VerifyServerHostname = true
+	}
+	if b.VerifyHTTPSClient {
+		result.VerifyHTTPSClient = true
+	}
+	if b.HTTPRedirectPort != 0 {
+		result.HTTPRedirectPort = b.HTTPRedirectPort
+	}
+
+	return &result
+}
+
+// RPCConfig returns the configuration used for securing RPCs,
+// or nil if RPCs are not secured with TLS.
+func (c *TLSConfig) RPCConfig() *tlsutil.Config {
+	if c == nil || !c.RPC {
+		return nil
+	}
+	return &tlsutil.Config{
+		CAFile:               c.CAFile,
+		CertFile:             c.CertFile,
+		KeyFile:              c.KeyFile,
+		VerifyIncoming:       c.VerifyIncoming,
+		VerifyServerHostname: c.VerifyServerHostname,
+	}
+}
+
+// HTTPConfig returns the configuration used for serving
+// HTTPS, or nil if the HTTP API is served in plain text.
+func (c *TLSConfig) HTTPConfig() *tlsutil.Config {
+	if c == nil || !c.HTTP {
+		return nil
+	}
+	return &tlsutil.Config{
+		CAFile:         c.CAFile,
+		CertFile:       c.CertFile,
+		KeyFile:        c.KeyFile,
+		VerifyIncoming: c.VerifyHTTPSClient,
+	}
+}
+
 // Ports encapsulates the various ports we bind to for network services. If any
 // are not specified then the defaults are used instead.
 type Ports struct {
@@ -302,6 +483,7 @@ func DefaultConfig() *Config {
 		ACL: &ACLConfig{
 			Enabled: false,
 		},
+		TLS:     &TLSConfig{},
 		Version: version.GetVersion(),
 	}
 }
@@ -315,6 +497,7 @@ func EmptyConfig() *Config {
 		Server:         &ServerConfig{},
 		Client:         &ClientConfig{},
 		ACL:            &ACLConfig{},
+		TLS:            &TLSConfig{},
 	}
 }
 
@@ -1,6 +1,7 @@
 package conn
 
 import (
+	"crypto/tls"
 	"errors"
 	"sync"
 	"time"
@@ -13,66 +14,234 @@ const (
 	errNoServers = "no servers"
 )
 
+const (
+	// serverBackoffBase is the time a server is skipped after its first failure.
+	serverBackoffBase = 1 * time.Second
+	// serverBackoffMax caps the time a failing server is skipped.
+	serverBackoffMax = 30 * time.Second
+)
+
 var (
 	ErrNoServers = errors.New(errNoServers)
 )
 
 type RPCConnection interface {
 	Call(method string, args interface{}, reply interface{}) error
+
+	// Servers returns the addresses of all servers known to the connection.
+	Servers() []string
+
+	// UpdateServers merges the given addresses into the list of known servers.
+	UpdateServers(servers []string)
+}
+
+// server tracks the health of a single server in the pool.
+type server struct {
+	address  string
+	failures int
+	retryAt  time.Time
 }
 
 type rpcConnection struct {
-	logger  log.Logger
-	client  *rpc.Client
-	address string
+	logger    log.Logger
+	tlsConfig *tls.Config
+	client    *rpc.Client
+	current   *server
+	servers   []*server
+	next      int
 	sync.Mutex
 }
 
 func NewRPCConnection(address string, logger log.Logger) RPCConnection {
-	return &rpcConnection{
-		address: address,
-		logger:  logger,
+	return NewRPCConnectionWithServers([]string{address}, nil, logger)
+}
+
+// NewRPCConnectionWithServers returns a connection which fails over
+// across the given servers, skipping unhealthy ones with a backoff.
+// If tlsConfig is not nil, servers are dialed over TLS.
+func NewRPCConnectionWithServers(servers []string, tlsConfig *tls.Config, logger log.Logger) RPCConnection {
+	c := &rpcConnection{
+		logger:    logger,
+		tlsConfig: tlsConfig,
 	}
+	c.UpdateServers(servers)
+	return c
 }
 
 // Call calls a RPC method on a remote server using the clients RPC client, establishing
 // the connection if it's being used for the first time, of if it has been disconnected.
+// Servers which cannot be reached are marked as failed and the call is retried on the
+// next server in the pool, until every server has been tried once.
 func (c *rpcConnection) Call(method string, args interface{}, reply interface{}) error {
 
-	// Get a cached client or create a new one
-	client, err := c.getRPCClient()
-	if err != nil {
+	attempts := len(c.Servers())
+	if attempts == 0 {
 		return ErrNoServers
 	}
 
-	if err := client.Call(method, args, reply); err != nil {
-		c.client = nil
-		return err
+	var lastErr error = ErrNoServers
+
+	for i := 0; i < attempts; i++ {
+
+		// Get a cached client or create a new one
+		client, srv, err := c.getRPCClient()
+		if err != nil {
+			c.markFailed(srv, nil)
+			continue
+		}
+
+		err = client.Call(method, args, reply)
+		if err == nil || rpc.IsServerError(err) {
+			c.markHealthy(srv)
+			return err
+		}
+
+		c.markFailed(srv, client)
+		lastErr = err
 	}
 
+	return lastErr
+}
+
+// Servers returns the addresses of all servers known to the connection.
+func (c *rpcConnection) Servers() []string {
+	c.Lock()
+	defer c.Unlock()
+
+	out := make([]string, 0, len(c.servers))
+	for _, s := range c.servers {
+		out = append(out, s.address)
+	}
+	return out
+}
+
+// UpdateServers merges the given addresses into the list of known servers.
+// Servers are never removed, so that a stale list cannot leave us without any.
+func (c *rpcConnection) UpdateServers(servers []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, addr := range servers {
+		if addr == "" || c.serverByAddress(addr) != nil {
+			continue
+		}
+		c.servers = append(c.servers, &server{address: addr})
+	}
+}
+
+func (c *rpcConnection) serverByAddress(addr string) *server {
+	for _, s := range c.servers {
+		if s.address == addr {
+			return s
+		}
+	}
 	return nil
 }
 
-func (c *rpcConnection) getRPCClient() (*rpc.Client, error) {
+func (c *rpcConnection) getRPCClient() (*rpc.Client, *server, error) {
 
 	c.Lock()
 	defer c.Unlock()
 
 	if c.client != nil {
-		return c.client, nil
+		return c.client, c.current, nil
+	}
+
+	srv := c.pickServer()
+	if srv == nil {
+		return nil, nil, ErrNoServers
 	}
 
 	client, err := rpc.NewClient(&rpc.ClientConfig{
 		Logger:      c.logger,
-		Address:     c.address,
+		Address:     srv.address,
 		DialTimeout: 3 * time.Second,
+		TLSConfig:   c.tlsConfig,
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, srv, err
 	}
 
 	c.client = client
+	c.current = srv
+
+	return c.client, c.current, nil
+}
+
+// pickServer returns the next server in the pool which is not backing off.
+// If all servers are backing off, the one which will recover first is used.
+func (c *rpcConnection) pickServer() *server {
+
+	if len(c.servers) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	var earliest *server
+	for i := 0; i < len(c.servers); i++ {
+		s := c.servers[(c.next+i)%len(c.servers)]
+		if !now.Before(s.retryAt) {
+			c.next = (c.next + i) % len(c.servers)
+			return s
+		}
+		if earliest == nil || s.retryAt.Before(earliest.retryAt) {
+			earliest = s
+		}
+	}
 
-	return c.client, nil
+	return earliest
+}
+
+// markHealthy resets the failure count of a server.
+func (c *rpcConnection) markHealthy(s *server) {
+	c.Lock()
+	defer c.Unlock()
+
+	s.failures = 0
+	s.retryAt = time.Time{}
+}
+
+// markFailed puts a server on backoff and rotates to the next one. Failures
+// of a client which was already replaced by a concurrent call are ignored.
+func (c *rpcConnection) markFailed(s *server, client *rpc.Client) {
+	c.Lock()
+	defer c.Unlock()
+
+	if s == nil {
+		return
+	}
+
+	if client != nil {
+		if c.client != client {
+			return
+		}
+		c.client.Close()
+		c.client = nil
+		c.current = nil
+	}
+
+	s.failures++
+	s.retryAt = time.Now().Add(backoff(s.failures))
+
+	if len(c.servers) > 0 {
+		c.next = (c.next + 1) % len(c.servers)
+	}
+
+	if c.logger != nil {
+		c.logger.Debugf("server %s failed %d time(s), retrying in %s", s.address, s.failures, backoff(s.failures))
+	}
+}
+
+// backoff returns the time a server is skipped after n consecutive failures.
+func backoff(n int) time.Duration {
+	d := serverBackoffBase
+	for i := 1; i < n && d < serverBackoffMax; i++ {
+		d *= 2
+	}
+	if d > serverBackoffMax {
+		d = serverBackoffMax
+	}
+	return d
 }
@@ -16,3 +16,37 @@ func TestCreateConn(t *testing.T) {
 	t.Log("success!")
 
 }
+
+func TestFailover(t *testing.T) {
+
+	conn := NewRPCConnectionWithServers([]string{"127.0.0.1:1", "127.0.0.1:2"}, nil, nil).(*rpcConnection)
+
+	if err := conn.Call("TestMethod", struct{}{}, nil); err == nil {
+		t.Fatal("expected call to unreachable servers to fail")
+	}
+
+	for _, s := range conn.servers {
+		if s.failures != 1 {
+			t.Fatalf("expected server %s to have failed once, got %d", s.address, s.failures)
+		}
+	}
+
+	conn.UpdateServers([]string{"", "127.0.0.1:2", "127.0.0.1:3"})
+	if got := conn.Servers(); len(got) != 3 || got[2] != "127.0.0.1:3" {
+		t.Fatalf("unexpected servers after update: %v", got)
+	}
+
+	// The new server is the only one not backing off.
+	if s := conn.pickServer(); s.address != "127.0.0.1:3" {
+		t.Fatalf("expected healthy server to be picked, got %s", s.address)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if d := backoff(1); d != serverBackoffBase {
+		t.Fatalf("expected %s, got %s", serverBackoffBase, d)
+	}
+	if d := backoff(100); d != serverBackoffMax {
+		t.Fatalf("expected %s, got %s", serverBackoffMax, d)
+	}
+}
@@ -56,13 +56,13 @@ func (p *ACLPolicies) Get(name string) (*structs.ACLPolicy, error) {
 }
 
 // List :
-func (p *ACLPolicies) List() ([]*structs.ACLPolicyListStub, error) {
+func (p *ACLPolicies) List(q *QueryOptions) ([]*structs.ACLPolicyListStub, *QueryMeta, error) {
 
 	var items []*structs.ACLPolicyListStub
-	err := p.client.listResources(path.Join(aclPoliciesPath, "/"), nil, &items)
+	meta, err := p.client.listResources(path.Join(aclPoliciesPath, "/"), nil, q, &items)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return items, nil
+	return items, meta, nil
 }
@@ -70,15 +70,15 @@ func (t *ACLTokens) Get(id string) (*structs.ACLToken, error) {
 }
 
 // List :
-func (t *ACLTokens) List() ([]*structs.ACLTokenListStub, error) {
+func (t *ACLTokens) List(q *QueryOptions) ([]*structs.ACLTokenListStub, *QueryMeta, error) {
 
 	var items []*structs.ACLTokenListStub
-	err := t.client.listResources(path.Join(aclTokensPath, "/"), nil, &items)
+	meta, err := t.client.listResources(path.Join(aclTokensPath, "/"), nil, q, &items)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return items, nil
+	return items, meta, nil
 }
 
 // Self :
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 
 	"github.com/hashicorp/go-cleanhttp"
 )
@@ -32,6 +33,14 @@ func NewClient(config *Config) (*Client, error) {
 		httpClient: cleanhttp.DefaultClient(),
 	}
 
+	tlsConfig, err := config.TLSConfig.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		client.httpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	}
+
 	return client, nil
 }
 
@@ -116,29 +125,64 @@ func (c *Client) deleteResource(id, p string, receiver interface{}) error {
 	return c.doRequest(req, receiver)
 }
 
-func (c *Client) listResources(p string, filters map[string][]string, receiver interface{}) error {
+// QueryOptions are used for paginating and sorting the items returned by list requests.
+type QueryOptions struct {
+	// PerPage is the maximum number of items returned.
+	// If zero, all items are returned.
+	PerPage int
+
+	// NextToken is the token returned along with the previous page, if any.
+	NextToken string
+
+	// Sort is the name of the field by which items are
+	// sorted, and Order is either "asc" or "desc".
+	Sort  string
+	Order string
+
+	// Filter is an expression, such as `Status == "ready"`,
+	// which the items returned must match.
+	Filter string
+}
+
+// QueryMeta contains information returned along with the items of list requests.
+type QueryMeta struct {
+	// NextToken is the token for retrieving the next
+	// page of items, or empty if there are no more items.
+	NextToken string
+}
+
+func (c *Client) listResources(p string, filters map[string][]string, q *QueryOptions, receiver interface{}) (*QueryMeta, error) {
 
 	u, err := url.Parse(c.config.Address)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	u.Path += p
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	c.addQuery(filters, req)
+	c.addQueryOptions(q, req)
 	c.addHeaders(req)
 
-	return c.doRequest(req, receiver)
+	res, err := c.do(req, receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryMeta{NextToken: res.Header.Get("X-Drago-Next-Token")}, nil
 }
 
 func (c *Client) addHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Add("X-Drago-Token", c.config.Token)
+	if c.config.Namespace != "" {
+		req.Header.Set("X-Drago-Namespace", c.config.Namespace)
+	}
 }
 
 func (c *Client) addQuery(filters map[string][]string, req *http.Request) {
@@ -154,32 +198,76 @@ func (c *Client) addQuery(filters map[string][]string, req *http.Request) {
 	req.URL.RawQuery = q.Encode()
 }
 
+func (c *Client) addQueryOptions(q *QueryOptions, req *http.Request) {
+
+	if q == nil {
+		return
+	}
+
+	query := req.URL.Query()
+
+	if q.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(q.PerPage))
+	}
+	if q.NextToken != "" {
+		query.Set("next_token", q.NextToken)
+	}
+	if q.Sort != "" {
+		query.Set("sort", q.Sort)
+	}
+	if q.Order != "" {
+		query.Set("order", q.Order)
+	}
+	if q.Filter != "" {
+		query.Set("filter", q.Filter)
+	}
+
+	req.URL.RawQuery = query.Encode()
+}
+
 func (c *Client) doRequest(req *http.Request, receiver interface{}) error {
+	_, err := c.do(req, receiver)
+	return err
+}
+
+func (c *Client) do(req *http.Request, receiver interface{}) (*http.Response, error) {
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer res.Body.Close()
 
-	if ok := res.StatusCode >= 200 && res.StatusCode < 300; !ok {
+	if err := decodeError(res); err != nil {
+		return nil, err
+	}
 
-		err := CodedError{
-			Code: res.StatusCode,
+	if receiver != nil {
+		if err := json.NewDecoder(res.Body).Decode(receiver); err != nil {
+			return nil, err
 		}
+	}
 
-		if err := json.NewDecoder(res.Body).Decode(&err); err != nil {
-			resBody, _ := ioutil.ReadAll(res.Body)
-			return fmt.Errorf("%v (%v)", res.Status, string(resBody))
-		}
+	return res, nil
+}
 
-		return err
+// decodeError returns the error contained in a response,
+// or nil if the response does not indicate an error.
+func decodeError(res *http.Response) error {
+
+	if ok := res.StatusCode >= 200 && res.StatusCode < 300; ok {
+		return nil
 	}
 
-	if receiver != nil {
-		return json.NewDecoder(res.Body).Decode(receiver)
+	err := CodedError{
+		Code: res.StatusCode,
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&err); err != nil {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("%v (%v)", res.Status, string(resBody))
 	}
 
-	return nil
+	return err
 }
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"path"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	auditPath = "/api/audit"
+)
+
+// Audit is a handle to the audit API
+type Audit struct {
+	client *Client
+}
+
+// Audit returns a handle on the audit endpoints.
+func (c *Client) Audit() *Audit {
+	return &Audit{client: c}
+}
+
+// List :
+func (a *Audit) List(filters map[string][]string, q *QueryOptions) ([]*structs.AuditEntry, *QueryMeta, error) {
+
+	var items []*structs.AuditEntry
+	meta, err := a.client.listResources(path.Join(auditPath, "/"), filters, q, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, meta, nil
+}
@@ -1,7 +1,13 @@
 package api
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"strconv"
 	"time"
 )
 
@@ -21,14 +27,48 @@ type Config struct {
 	// Token to be used for authentication.
 	Token string
 
+	// Namespace targeted by requests. If empty, requests
+	// target the default namespace.
+	Namespace string
+
 	// Request timeout.
 	Timeout time.Duration
+
+	// TLSConfig contains configurations for talking to servers over HTTPS.
+	TLSConfig *TLSConfig
+}
+
+// TLSConfig contains configurations for talking to Drago servers over HTTPS.
+type TLSConfig struct {
+	// CACert is the path to a PEM-encoded CA certificate used for
+	// verifying the server certificate. Defaults to the system roots.
+	CACert string
+
+	// ClientCert is the path to a PEM-encoded client certificate,
+	// presented to servers which verify HTTPS clients.
+	ClientCert string
+
+	// ClientKey is the path to the PEM-encoded private key of ClientCert.
+	ClientKey string
+
+	// Insecure disables the verification of the server certificate.
+	Insecure bool
 }
 
-// DefaultConfig returns a default configuration for Drago's API client.
+// DefaultConfig returns a default configuration for Drago's API client,
+// with the namespace and TLS settings read from the environment.
 func DefaultConfig() *Config {
 	config := &Config{
-		Address: DefaultAddress,
+		Address:   DefaultAddress,
+		Namespace: os.Getenv("DRAGO_NAMESPACE"),
+		TLSConfig: &TLSConfig{
+			CACert:     os.Getenv("DRAGO_CACERT"),
+			ClientCert: os.Getenv("DRAGO_CLIENT_CERT"),
+			ClientKey:  os.Getenv("DRAGO_CLIENT_KEY"),
+		},
+	}
+	if v := os.Getenv("DRAGO_SKIP_VERIFY"); v != "" {
+		config.TLSConfig.Insecure, _ = strconv.ParseBool(v)
 	}
 	return config
 }
@@ -56,9 +96,73 @@ func (c *Config) Merge(b *Config) *Config {
 	if b.Token != "" {
 		result.Token = b.Token
 	}
+	if b.Namespace != "" {
+		result.Namespace = b.Namespace
+	}
 	if b.Timeout != 0 {
 		result.Timeout = b.Timeout
 	}
+	if result.TLSConfig == nil && b.TLSConfig != nil {
+		tls := *b.TLSConfig
+		result.TLSConfig = &tls
+	} else if b.TLSConfig != nil {
+		result.TLSConfig = result.TLSConfig.Merge(b.TLSConfig)
+	}
 
 	return &result
 }
+
+// Merge merges two TLS configurations.
+func (c *TLSConfig) Merge(b *TLSConfig) *TLSConfig {
+	result := *c
+
+	if b.CACert != "" {
+		result.CACert = b.CACert
+	}
+	if b.ClientCert != "" {
+		result.ClientCert = b.ClientCert
+	}
+	if b.ClientKey != "" {
+		result.ClientKey = b.ClientKey
+	}
+	if b.Insecure {
+		result.Insecure = true
+	}
+
+	return &result
+}
+
+// tlsConfig returns the configuration used by the HTTP client, or
+// nil if the default one should be used.
+func (c *TLSConfig) tlsConfig() (*tls.Config, error) {
+
+	if c == nil || (c.CACert == "" && c.ClientCert == "" && !c.Insecure) {
+		return nil, nil
+	}
+
+	out := &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+	}
+
+	if c.CACert != "" {
+		b, err := ioutil.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to parse CA certificate %s", c.CACert)
+		}
+		out.RootCAs = pool
+	}
+
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		out.Certificates = []tls.Certificate{cert}
+	}
+
+	return out, nil
+}
@@ -33,15 +33,15 @@ func (n *Connections) Get(id string) (*structs.Connection, error) {
 }
 
 // List :
-func (n *Connections) List() ([]*structs.ConnectionListStub, error) {
+func (n *Connections) List(q *QueryOptions) ([]*structs.ConnectionListStub, *QueryMeta, error) {
 
 	var items []*structs.ConnectionListStub
-	err := n.client.listResources(path.Join(connectionsPath, "/"), nil, &items)
+	meta, err := n.client.listResources(path.Join(connectionsPath, "/"), nil, q, &items)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return items, nil
+	return items, meta, nil
 }
 
 func (n *Connections) Create(connection *structs.Connection) error {
@@ -65,6 +65,11 @@ func (n *Connections) Update(conn *structs.Connection) (*structs.Connection, err
 	return out, nil
 }
 
+// RotatePresharedKey :
+func (n *Connections) RotatePresharedKey(id string) error {
+	return n.client.createResource(path.Join(connectionsPath, id, "rotate-psk"), nil, nil)
+}
+
 func (n *Connections) Delete(id string) error {
 
 	err := n.client.deleteResource(id, connectionsPath, nil)
@@ -33,15 +33,15 @@ func (n *Interfaces) Get(id string) (*structs.Interface, error) {
 }
 
 // List :
-func (n *Interfaces) List(filters map[string][]string) ([]*structs.InterfaceListStub, error) {
+func (n *Interfaces) List(filters map[string][]string, q *QueryOptions) ([]*structs.InterfaceListStub, *QueryMeta, error) {
 
 	var items []*structs.InterfaceListStub
-	err := n.client.listResources(path.Join(interfacesPath, "/"), filters, &items)
+	meta, err := n.client.listResources(path.Join(interfacesPath, "/"), filters, q, &items)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return items, nil
+	return items, meta, nil
 }
 
 // Create :
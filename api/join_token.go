@@ -0,0 +1,69 @@
+package api
+
+import (
+	"path"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	joinTokensPath = "/api/join-tokens"
+)
+
+// JoinTokens is a handle to the join tokens API
+type JoinTokens struct {
+	client *Client
+}
+
+// JoinTokens returns a handle on the join tokens endpoints.
+func (c *Client) JoinTokens() *JoinTokens {
+	return &JoinTokens{client: c}
+}
+
+// Create :
+func (t *JoinTokens) Create(token *structs.JoinToken) (*structs.JoinToken, error) {
+
+	out := &structs.JoinToken{}
+
+	err := t.client.createResource(joinTokensPath, token, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Delete :
+func (t *JoinTokens) Delete(id string) error {
+
+	err := t.client.deleteResource(id, joinTokensPath, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get :
+func (t *JoinTokens) Get(id string) (*structs.JoinToken, error) {
+
+	var token *structs.JoinToken
+	err := t.client.getResource(joinTokensPath, id, &token)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// List :
+func (t *JoinTokens) List(q *QueryOptions) ([]*structs.JoinTokenListStub, *QueryMeta, error) {
+
+	var items []*structs.JoinTokenListStub
+	meta, err := t.client.listResources(path.Join(joinTokensPath, "/"), nil, q, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, meta, nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"path"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	namespacesPath = "/api/namespaces"
+)
+
+// Namespaces is a handle to the namespaces API
+type Namespaces struct {
+	client *Client
+}
+
+// Namespaces returns a handle on the namespaces endpoints.
+func (c *Client) Namespaces() *Namespaces {
+	return &Namespaces{client: c}
+}
+
+// Upsert :
+func (n *Namespaces) Upsert(ns *structs.Namespace) error {
+
+	err := n.client.createResource(path.Join(namespacesPath, ns.Name), ns, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete :
+func (n *Namespaces) Delete(name string) error {
+
+	err := n.client.deleteResource(name, namespacesPath, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get :
+func (n *Namespaces) Get(name string) (*structs.Namespace, error) {
+
+	out := &structs.Namespace{}
+	err := n.client.getResource(namespacesPath, name, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// List :
+func (n *Namespaces) List(q *QueryOptions) ([]*structs.NamespaceListStub, *QueryMeta, error) {
+
+	var items []*structs.NamespaceListStub
+	meta, err := n.client.listResources(path.Join(namespacesPath, "/"), nil, q, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, meta, nil
+}
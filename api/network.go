@@ -31,6 +31,17 @@ func (n *Networks) Create(network *structs.Network) error {
 	return err
 }
 
+// Update :
+func (n *Networks) Update(network *structs.Network) error {
+
+	err := n.client.createResource(path.Join(networksPath, network.ID), network, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Delete :
 func (n *Networks) Delete(id string) error {
 
@@ -55,13 +66,48 @@ func (n *Networks) Get(id string) (*structs.Network, error) {
 }
 
 // List :
-func (n *Networks) List() ([]*structs.NetworkListStub, error) {
+func (n *Networks) List(q *QueryOptions) ([]*structs.NetworkListStub, *QueryMeta, error) {
 
 	var items []*structs.NetworkListStub
-	err := n.client.listResources(path.Join(networksPath, "/"), nil, &items)
+	meta, err := n.client.listResources(path.Join(networksPath, "/"), nil, q, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, meta, nil
+}
+
+// Validate :
+func (n *Networks) Validate(id string) ([]*structs.AllowedIPsConflict, error) {
+
+	var conflicts []*structs.AllowedIPsConflict
+	err := n.client.getResource(path.Join(networksPath, id), "validate", &conflicts)
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// RotateKeys :
+func (n *Networks) RotateKeys(id string) error {
+	return n.client.createResource(path.Join(networksPath, id, "rotate-keys"), nil, nil)
+}
+
+// Import imports wg-quick configuration files into a network. If dryRun
+// is set, the import is only planned, and nothing is changed.
+func (n *Networks) Import(id string, files []*structs.NetworkImportFile, dryRun bool) (*structs.NetworkImportPlan, error) {
+
+	req := &structs.NetworkImportRequest{
+		Files:  files,
+		DryRun: dryRun,
+	}
+
+	var plan structs.NetworkImportPlan
+	err := n.client.createResource(path.Join(networksPath, id, "import"), req, &plan)
 	if err != nil {
 		return nil, err
 	}
 
-	return items, nil
+	return &plan, nil
 }
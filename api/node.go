@@ -33,13 +33,38 @@ func (t *Nodes) Get(id string) (*structs.Node, error) {
 }
 
 // List :
-func (t *Nodes) List(filters map[string][]string) ([]*structs.NodeListStub, error) {
+func (t *Nodes) List(filters map[string][]string, q *QueryOptions) ([]*structs.NodeListStub, *QueryMeta, error) {
 
 	var items []*structs.NodeListStub
-	err := t.client.listResources(path.Join(nodesPath, "/"), filters, &items)
+	meta, err := t.client.listResources(path.Join(nodesPath, "/"), filters, q, &items)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return items, nil
+	return items, meta, nil
+}
+
+// UpdateEligibility :
+func (t *Nodes) UpdateEligibility(id string, eligibility string) error {
+
+	req := &structs.NodeUpdateEligibilityRequest{
+		Eligibility: eligibility,
+	}
+
+	return t.client.createResource(path.Join(nodesPath, id, "eligibility"), req, nil)
+}
+
+// UpdateDrain :
+func (t *Nodes) UpdateDrain(id string, drain bool) error {
+
+	req := &structs.NodeUpdateDrainRequest{
+		Drain: drain,
+	}
+
+	return t.client.createResource(path.Join(nodesPath, id, "drain"), req, nil)
+}
+
+// RotateKeys :
+func (t *Nodes) RotateKeys(id string) error {
+	return t.client.createResource(path.Join(nodesPath, id, "rotate-keys"), nil, nil)
 }
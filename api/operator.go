@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	operatorPath = "/api/operator"
+)
+
+// Operator is a handle to the operator API
+type Operator struct {
+	client *Client
+}
+
+// Operator returns a handle on the operator endpoints.
+func (c *Client) Operator() *Operator {
+	return &Operator{client: c}
+}
+
+// SnapshotSave retrieves a snapshot of the server state, writing it to w.
+func (o *Operator) SnapshotSave(w io.Writer) error {
+
+	req, err := o.newSnapshotRequest("GET", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := o.client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if err := decodeError(res); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, res.Body)
+
+	return err
+}
+
+// SnapshotRestore restores the server state from the snapshot read from r,
+// returning the time at which the snapshot was saved.
+func (o *Operator) SnapshotRestore(r io.Reader) (time.Time, error) {
+
+	req, err := o.newSnapshotRequest("PUT", r)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	res, err := o.client.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	defer res.Body.Close()
+
+	if err := decodeError(res); err != nil {
+		return time.Time{}, err
+	}
+
+	var resp structs.SnapshotRestoreResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return time.Time{}, err
+	}
+
+	return resp.CreatedAt, nil
+}
+
+func (o *Operator) newSnapshotRequest(method string, body io.Reader) (*http.Request, error) {
+
+	u, err := url.Parse(o.client.config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path += operatorPath + "/snapshot"
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	o.client.addHeaders(req)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	return req, nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	peersPath = "/api/peers"
+)
+
+// Peers is a handle to the external peers API
+type Peers struct {
+	client *Client
+}
+
+// Peers returns a handle on the external peers endpoints.
+func (c *Client) Peers() *Peers {
+	return &Peers{client: c}
+}
+
+// Get :
+func (p *Peers) Get(id string) (*structs.Interface, error) {
+
+	var iface *structs.Interface
+	err := p.client.getResource(peersPath, id, &iface)
+	if err != nil {
+		return nil, err
+	}
+
+	return iface, nil
+}
+
+// List :
+func (p *Peers) List(filters map[string][]string, q *QueryOptions) ([]*structs.InterfaceListStub, *QueryMeta, error) {
+
+	var items []*structs.InterfaceListStub
+	meta, err := p.client.listResources(path.Join(peersPath, "/"), filters, q, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, meta, nil
+}
+
+// Create creates an external peer, returning the ID of its interface.
+func (p *Peers) Create(req *structs.ExternalPeerCreateRequest) (string, error) {
+
+	var out structs.ExternalPeerCreateResponse
+	err := p.client.createResource(peersPath, req, &out)
+	if err != nil {
+		return "", err
+	}
+
+	return out.InterfaceID, nil
+}
+
+// Delete :
+func (p *Peers) Delete(id string) error {
+
+	err := p.client.deleteResource(id, peersPath, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Config returns the configuration of an external peer in the wg-quick format.
+func (p *Peers) Config(id string) (string, error) {
+
+	u, err := url.Parse(p.client.config.Address)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path += path.Join(peersPath, id, "config")
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	p.client.addHeaders(req)
+
+	res, err := p.client.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	if err := decodeError(res); err != nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
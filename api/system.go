@@ -0,0 +1,34 @@
+package api
+
+import (
+	"path"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	systemPath = "/api/system"
+)
+
+// System is a handle to the system API
+type System struct {
+	client *Client
+}
+
+// System returns a handle on the system endpoints.
+func (c *Client) System() *System {
+	return &System{client: c}
+}
+
+// GarbageCollect triggers a garbage collection of nodes which
+// have been down for longer than the configured threshold.
+func (s *System) GarbageCollect() ([]string, error) {
+
+	var resp structs.SystemGCResponse
+	err := s.client.createResource(path.Join(systemPath, "gc"), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.NodeIDs, nil
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	prometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/seashell/drago/agent/conn"
 	nic "github.com/seashell/drago/client/nic"
 	state "github.com/seashell/drago/client/state"
@@ -25,6 +26,7 @@ var (
 	defaultReconciliationInterval      = 2 * time.Second
 	defaultFirstHeartbeatDelay         = 1 * time.Second
 	defaultHeartbeatInterval           = 5 * time.Second
+	defaultInterfacesQueryTime         = 5 * time.Minute
 )
 
 // Client is the Drago client
@@ -43,6 +45,10 @@ type Client struct {
 	node     *structs.Node
 	nodeLock sync.Mutex
 
+	// heartbeatTTL is the heartbeat interval requested by the servers
+	heartbeatTTL     time.Duration
+	heartbeatTTLLock sync.Mutex
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -79,6 +85,10 @@ func New(conn conn.RPCConnection, config *Config) (*Client, error) {
 		return nil, fmt.Errorf("error setting up interfaces: %v", err)
 	}
 
+	if err := prometheus.Register(newInterfaceCollector(c)); err != nil {
+		c.logger.Warnf("could not register interface metrics: %v", err)
+	}
+
 	go c.registerAndHeartbeat()
 
 	go c.run()
@@ -99,7 +109,7 @@ func (c *Client) NodeID() string {
 
 // NodeSecretID returns the node secret ID for the given client
 func (c *Client) NodeSecretID() string {
-	return c.node.ID
+	return c.node.SecretID
 }
 
 // Stats is used to return statistics for the server
@@ -178,6 +188,13 @@ func (c *Client) setupState() error {
 
 	c.state = repo
 
+	// Merge the servers learned before a restart into the configured ones
+	servers, err := c.state.Servers()
+	if err != nil {
+		return fmt.Errorf("failed to read known servers: %v", err)
+	}
+	c.rpc.UpdateServers(servers)
+
 	return nil
 }
 
@@ -187,6 +204,7 @@ func (c *Client) setupNetworkController() error {
 		InterfacesPrefix: c.config.InterfacesPrefix,
 		WireguardPath:    c.config.WireguardPath,
 		KeyStore:         c.state, // TODO: improve how we store private keys (do we really need to store them?)
+		KeyLifetime:      c.config.KeyLifetime,
 	})
 	if err != nil {
 		return err
@@ -232,11 +250,10 @@ func (c *Client) registerAndHeartbeat() {
 		if err := c.updateNodeStatus(); err != nil {
 			c.logger.Debugf("error updating node status: %v", err)
 			c.tryToRegisterUntilSuccessful()
-			heartbeatCh = time.After(defaultHeartbeatInterval)
-		} else {
-			heartbeatCh = time.After(defaultHeartbeatInterval)
 		}
 
+		heartbeatCh = time.After(c.heartbeatInterval())
+
 	}
 }
 
@@ -258,13 +275,18 @@ func (c *Client) run() {
 				return
 			}
 
+			start := time.Now()
+
 			current, err := c.state.Interfaces()
 			if err != nil {
 				c.logger.Errorf("could not read interfaces from state repository: %v", err)
+				reconcileErrors.Inc()
 			}
 
 			c.reconcileInterfaces(current, desired)
 
+			reconcileDuration.Observe(time.Since(start).Seconds())
+
 			c.shutdownLock.Unlock()
 		case <-c.shutdownCh:
 			return
@@ -296,9 +318,11 @@ func (c *Client) reconcileInterfaces(current, desired []*structs.Interface) {
 	for _, id := range diff.deleted {
 		if err := c.state.DeleteInterfaces([]string{id}); err != nil {
 			c.logger.Warnf("could not persist interface deletion to the state: %v", err)
+			reconcileErrors.Inc()
 		}
 		if err := c.niController.DeleteInterfaceByAlias(id); err != nil {
 			c.logger.Warnf("could not delete interface: %v", err)
+			reconcileErrors.Inc()
 		}
 	}
 
@@ -310,12 +334,14 @@ func (c *Client) reconcileInterfaces(current, desired []*structs.Interface) {
 		err := c.state.UpsertInterface(iface)
 		if err != nil {
 			c.logger.Warnf("could not persist interface: %v", err)
+			reconcileErrors.Inc()
 			continue
 		}
 
 		err = c.niController.CreateInterface(iface)
 		if err != nil {
 			c.logger.Warnf("could not create wireguard interface: %v", err)
+			reconcileErrors.Inc()
 		}
 	}
 
@@ -327,11 +353,13 @@ func (c *Client) reconcileInterfaces(current, desired []*structs.Interface) {
 		err := c.state.UpsertInterface(iface)
 		if err != nil {
 			c.logger.Warnf("could not persist interface: %v", err)
+			reconcileErrors.Inc()
 			continue
 		}
 
 		if err := c.niController.UpdateInterface(iface); err != nil {
 			c.logger.Warnf("could not update wireguard interface: %v", err)
+			reconcileErrors.Inc()
 		}
 	}
 
@@ -340,32 +368,53 @@ func (c *Client) reconcileInterfaces(current, desired []*structs.Interface) {
 func (c *Client) watchInterfaces(ch chan []*structs.Interface) {
 
 	req := &structs.NodeSpecificRequest{
-		NodeID: c.NodeID(),
+		NodeID:   c.NodeID(),
+		SecretID: c.NodeSecretID(),
+		QueryOptions: structs.QueryOptions{
+			Namespace:    c.config.Namespace,
+			MaxQueryTime: defaultInterfacesQueryTime,
+		},
 	}
 
 	for {
 
 		c.logger.Debugf("updating interface configuration (server -> client)")
 
+		// Block until the interface configuration changes on the server,
+		// or until the query times out, in which case it is re-issued.
 		var resp structs.NodeInterfacesResponse
 		err := c.RPC("Node.GetInterfaces", req, &resp)
 		if err != nil {
 			c.logger.Debugf("error fetching interfaces: %v", err)
+			// The server might have been restarted, so fetch the
+			// whole configuration again once it becomes available.
+			req.MinQueryIndex = 0
 			retryCh := time.After(defaultReconciliationRetryInterval)
 			select {
 			case <-retryCh:
 			case <-c.shutdownCh:
 				return
 			}
-		} else {
-			ch <- resp.Items
+			continue
 		}
 
-		retryCh := time.After(c.config.ReconcileInterval)
 		select {
+		case ch <- resp.Items:
 		case <-c.shutdownCh:
 			return
-		case <-retryCh:
+		}
+
+		req.MinQueryIndex = resp.Index
+
+		// Servers not supporting blocking queries always return a zero index,
+		// in which case the configuration is polled every reconcile interval.
+		if resp.Index == 0 {
+			retryCh := time.After(c.config.ReconcileInterval)
+			select {
+			case <-c.shutdownCh:
+				return
+			case <-retryCh:
+			}
 		}
 	}
 }
@@ -385,9 +434,16 @@ func (c *Client) synchronizeInterfaces() {
 			interfaces = []*structs.Interface{}
 		}
 
+		for _, iface := range interfaces {
+			c.setPeerConnectionIDs(iface.ID, iface.PeerStats)
+		}
+
 		req := &structs.NodeInterfaceUpdateRequest{
 			NodeID:     c.NodeID(),
 			Interfaces: interfaces,
+			WriteRequest: structs.WriteRequest{
+				Namespace: c.config.Namespace,
+			},
 		}
 
 		var resp structs.GenericResponse
@@ -410,6 +466,32 @@ func (c *Client) synchronizeInterfaces() {
 	}
 }
 
+// setPeerConnectionIDs sets the IDs of the connections from which the peers
+// of an interface were configured, matching them by their public keys.
+func (c *Client) setPeerConnectionIDs(ifaceID string, stats []*structs.PeerStats) {
+
+	ifaces, err := c.state.Interfaces()
+	if err != nil {
+		return
+	}
+
+	connections := map[string]string{}
+	for _, iface := range ifaces {
+		if iface.ID != ifaceID {
+			continue
+		}
+		for _, p := range iface.Peers {
+			if p.PublicKey != nil && p.ConnectionID != nil {
+				connections[*p.PublicKey] = *p.ConnectionID
+			}
+		}
+	}
+
+	for _, s := range stats {
+		s.ConnectionID = connections[s.PublicKey]
+	}
+}
+
 func (c *Client) tryToRegisterUntilSuccessful() {
 
 	for {
@@ -417,7 +499,11 @@ func (c *Client) tryToRegisterUntilSuccessful() {
 		c.logger.Debugf("registering node (client -> server)")
 
 		req := &structs.NodeRegisterRequest{
-			Node: c.Node(),
+			Node:      c.Node(),
+			JoinToken: c.config.JoinToken,
+			WriteRequest: structs.WriteRequest{
+				Namespace: c.config.Namespace,
+			},
 		}
 
 		var err error
@@ -428,6 +514,9 @@ func (c *Client) tryToRegisterUntilSuccessful() {
 			c.node.Status = structs.NodeStatusReady
 			c.nodeLock.Unlock()
 
+			c.setHeartbeatTTL(resp.HeartbeatTTL)
+			c.updateServers(resp.Servers)
+
 			return
 		}
 
@@ -452,6 +541,9 @@ func (c *Client) updateNodeStatus() error {
 		Status:           structs.NodeStatusReady,
 		AdvertiseAddress: c.Node().AdvertiseAddress,
 		Meta:             c.node.Meta,
+		WriteRequest: structs.WriteRequest{
+			Namespace: c.config.Namespace,
+		},
 	}
 
 	var err error
@@ -460,9 +552,63 @@ func (c *Client) updateNodeStatus() error {
 		return err
 	}
 
+	c.setHeartbeatTTL(resp.HeartbeatTTL)
+	c.updateServers(resp.Servers)
+
 	return nil
 }
 
+// updateServers merges the servers returned by a server into the
+// pool used for RPCs, persisting the resulting list if it changed.
+func (c *Client) updateServers(servers []string) {
+
+	before := c.rpc.Servers()
+	c.rpc.UpdateServers(servers)
+	after := c.rpc.Servers()
+
+	if len(after) == len(before) {
+		return
+	}
+
+	c.logger.Debugf("known servers updated: %v", after)
+
+	if err := c.state.SetServers(after); err != nil {
+		c.logger.Warnf("could not persist known servers: %v", err)
+	}
+}
+
+// setHeartbeatTTL updates the heartbeat interval with the one requested by
+// the servers. Servers which do not specify a TTL leave it unchanged.
+func (c *Client) setHeartbeatTTL(ttl time.Duration) {
+
+	if ttl <= 0 {
+		return
+	}
+
+	c.heartbeatTTLLock.Lock()
+	defer c.heartbeatTTLLock.Unlock()
+
+	if ttl != c.heartbeatTTL {
+		c.logger.Debugf("heartbeat interval set to %s", ttl)
+	}
+
+	c.heartbeatTTL = ttl
+}
+
+// heartbeatInterval returns the interval at which heartbeats should be sent,
+// falling back to a default until the servers request a specific one.
+func (c *Client) heartbeatInterval() time.Duration {
+
+	c.heartbeatTTLLock.Lock()
+	defer c.heartbeatTTLLock.Unlock()
+
+	if c.heartbeatTTL == 0 {
+		return defaultHeartbeatInterval
+	}
+
+	return c.heartbeatTTL
+}
+
 func (c *Client) RPC(method string, args interface{}, reply interface{}) error {
 	return c.rpc.Call(method, args, reply)
 }
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	drago "github.com/seashell/drago/drago"
+	events "github.com/seashell/drago/drago/events"
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+	config "github.com/seashell/drago/drago/structs/config"
+	simple "github.com/seashell/drago/pkg/log/simple"
+)
+
+// testConnection is an RPC connection which serves the first request
+// for the interfaces of a node from a node service, and fails afterwards.
+type testConnection struct {
+	nodes  *drago.NodeService
+	served bool
+}
+
+func (c *testConnection) Call(method string, args interface{}, reply interface{}) error {
+	if method != "Node.GetInterfaces" || c.served {
+		return errors.New("unavailable")
+	}
+	c.served = true
+	return c.nodes.GetInterfaces(args.(*structs.NodeSpecificRequest), reply.(*structs.NodeInterfacesResponse))
+}
+
+func (c *testConnection) Servers() []string {
+	return nil
+}
+
+func (c *testConnection) UpdateServers(servers []string) {}
+
+func TestWatchInterfacesPresharedKey(t *testing.T) {
+
+	ctx := context.Background()
+	repo := inmem.NewStateRepository(nil)
+
+	logger, err := simple.NewLoggerAdapter(simple.Config{})
+	if err != nil {
+		t.Fatalf("simple.NewLoggerAdapter() failed: %v", err)
+	}
+
+	psk := "preshared-key"
+
+	repo.UpsertNetwork(ctx, &structs.Network{ID: "net", Namespace: structs.DefaultNamespace, Name: "net", AddressRange: "10.0.0.0/24"})
+	for _, id := range []string{"a", "b"} {
+		repo.UpsertNode(ctx, &structs.Node{ID: id, SecretID: id + "-secret", Namespace: structs.DefaultNamespace, Name: id, AdvertiseAddress: "1.2.3.4"})
+		repo.UpsertInterface(ctx, &structs.Interface{ID: id + "-iface", Namespace: structs.DefaultNamespace, NodeID: id, NetworkID: "net"})
+	}
+	repo.UpsertConnection(ctx, &structs.Connection{
+		ID:           "conn",
+		Namespace:    structs.DefaultNamespace,
+		NetworkID:    "net",
+		PresharedKey: &psk,
+		PeerSettings: []*structs.PeerSettings{
+			{NodeID: "a", InterfaceID: "a-iface", RoutingRules: &structs.RoutingRules{}},
+			{NodeID: "b", InterfaceID: "b-iface", RoutingRules: &structs.RoutingRules{}},
+		},
+	})
+
+	nodes, err := drago.NewNodeService(&drago.Config{ACL: &config.ACLConfig{}}, logger, repo, nil, events.NewBroker(events.DefaultBufferSize), nil)
+	if err != nil {
+		t.Fatalf("drago.NewNodeService() failed: %v", err)
+	}
+
+	c := &Client{
+		config:     DefaultConfig(),
+		logger:     logger,
+		rpc:        &testConnection{nodes: nodes},
+		node:       &structs.Node{ID: "a", SecretID: "a-secret"},
+		shutdownCh: make(chan struct{}),
+	}
+	defer c.Shutdown()
+
+	ch := make(chan []*structs.Interface)
+	go c.watchInterfaces(ch)
+
+	interfaces := <-ch
+	if len(interfaces) != 1 || len(interfaces[0].Peers) != 1 {
+		t.Fatalf("expected 1 interface with 1 peer, got %v", interfaces)
+	}
+	if key := interfaces[0].Peers[0].PresharedKey; key == nil || *key != psk {
+		t.Fatalf("expected the node to receive the preshared key of its connection")
+	}
+}
@@ -38,6 +38,13 @@ type Config struct {
 	// Token contains the auth token used by the client.
 	Token string
 
+	// JoinToken is the secret of the join token presented
+	// by the client when registering with the servers.
+	JoinToken string
+
+	// Namespace is the namespace in which the client node is registered.
+	Namespace string
+
 	// StateDir is the directory to store our state in.
 	StateDir string
 
@@ -48,12 +55,17 @@ type Config struct {
 	// interfaces created by Drago.
 	InterfacesPrefix string
 
-	// ReconcileInterval is the interval between two reconciliation cycles.
+	// ReconcileInterval is the interval between two reconciliation cycles, used
+	// only when the server does not support blocking queries.
 	ReconcileInterval time.Duration
 
 	// WireguardPath is path to the WireGuard binary.
 	WireguardPath string
 
+	// KeyLifetime is the time after which the key pairs of WireGuard interfaces
+	// are rotated. If zero, key pairs are only rotated when requested by the servers.
+	KeyLifetime time.Duration
+
 	// Meta contains client metadata
 	Meta map[string]string
 }
@@ -89,6 +101,12 @@ func (c *Config) Merge(b *Config) *Config {
 	if b.Servers != nil {
 		result.Servers = b.Servers
 	}
+	if b.JoinToken != "" {
+		result.JoinToken = b.JoinToken
+	}
+	if b.Namespace != "" {
+		result.Namespace = b.Namespace
+	}
 	if b.StateDir != "" {
 		result.StateDir = b.StateDir
 	}
@@ -104,6 +122,9 @@ func (c *Config) Merge(b *Config) *Config {
 	if b.WireguardPath != "" {
 		result.WireguardPath = b.WireguardPath
 	}
+	if b.KeyLifetime != 0 {
+		result.KeyLifetime = b.KeyLifetime
+	}
 	if b.Meta != nil {
 		result.Meta = b.Meta
 	}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "drago",
+		Subsystem: "client",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile the interfaces of the node with the configuration from the servers.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	reconcileErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "drago",
+		Subsystem: "client",
+		Name:      "reconcile_errors_total",
+		Help:      "Number of errors which occurred while reconciling interfaces.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(reconcileDuration, reconcileErrors)
+}
+
+var (
+	interfaceLabels = []string{"node_id", "interface_id"}
+	peerLabels      = []string{"node_id", "interface_id", "connection_id", "public_key"}
+)
+
+// interfaceCollector exports per-interface and per-peer
+// statistics read from WireGuard whenever metrics are collected.
+type interfaceCollector struct {
+	client *Client
+
+	interfaceRx   *prometheus.Desc
+	interfaceTx   *prometheus.Desc
+	peerRx        *prometheus.Desc
+	peerTx        *prometheus.Desc
+	peerHandshake *prometheus.Desc
+}
+
+func newInterfaceCollector(c *Client) *interfaceCollector {
+	return &interfaceCollector{
+		client: c,
+		interfaceRx: prometheus.NewDesc("drago_client_interface_receive_bytes_total",
+			"Bytes received by an interface from all of its peers.", interfaceLabels, nil),
+		interfaceTx: prometheus.NewDesc("drago_client_interface_transmit_bytes_total",
+			"Bytes transmitted by an interface to all of its peers.", interfaceLabels, nil),
+		peerRx: prometheus.NewDesc("drago_client_peer_receive_bytes_total",
+			"Bytes received from a peer.", peerLabels, nil),
+		peerTx: prometheus.NewDesc("drago_client_peer_transmit_bytes_total",
+			"Bytes transmitted to a peer.", peerLabels, nil),
+		peerHandshake: prometheus.NewDesc("drago_client_peer_last_handshake_age_seconds",
+			"Time since the last handshake with a peer. Not reported for peers which never completed a handshake.", peerLabels, nil),
+	}
+}
+
+func (ic *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ic.interfaceRx
+	ch <- ic.interfaceTx
+	ch <- ic.peerRx
+	ch <- ic.peerTx
+	ch <- ic.peerHandshake
+}
+
+func (ic *interfaceCollector) Collect(ch chan<- prometheus.Metric) {
+
+	c := ic.client
+
+	c.niControllerLock.Lock()
+	stats, err := c.niController.Statistics()
+	c.niControllerLock.Unlock()
+	if err != nil {
+		c.logger.Warnf("could not collect interface metrics: %v", err)
+		return
+	}
+
+	for _, iface := range stats {
+		c.setPeerConnectionIDs(iface.ID, iface.Peers)
+	}
+
+	nodeID := c.NodeID()
+	now := time.Now()
+
+	for _, iface := range stats {
+
+		var rx, tx int64
+
+		for _, p := range iface.Peers {
+
+			rx += p.ReceiveBytes
+			tx += p.TransmitBytes
+
+			labels := []string{nodeID, iface.ID, p.ConnectionID, p.PublicKey}
+
+			ch <- prometheus.MustNewConstMetric(ic.peerRx, prometheus.CounterValue, float64(p.ReceiveBytes), labels...)
+			ch <- prometheus.MustNewConstMetric(ic.peerTx, prometheus.CounterValue, float64(p.TransmitBytes), labels...)
+
+			if !p.LastHandshake.IsZero() {
+				ch <- prometheus.MustNewConstMetric(ic.peerHandshake, prometheus.GaugeValue, now.Sub(p.LastHandshake).Seconds(), labels...)
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(ic.interfaceRx, prometheus.CounterValue, float64(rx), nodeID, iface.ID)
+		ch <- prometheus.MustNewConstMetric(ic.interfaceTx, prometheus.CounterValue, float64(tx), nodeID, iface.ID)
+	}
+}
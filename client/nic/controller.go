@@ -2,12 +2,14 @@ package nic
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"net"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	structs "github.com/seashell/drago/drago/structs"
@@ -19,6 +21,10 @@ import (
 
 const (
 	linkTypeWireguard = "wireguard"
+
+	// endpointResolveTimeout is the maximum time spent
+	// resolving the hostname in the endpoint of a peer.
+	endpointResolveTimeout = 5 * time.Second
 )
 
 // Config contains configurations for a network controller.
@@ -35,12 +41,18 @@ type Config struct {
 	// KeyStore is an implementation of the KeyStore interface, used by the
 	// Controller to cache private keys for each interface.
 	KeyStore PrivateKeyStore
+
+	// KeyLifetime is the time after which the key pair of an interface
+	// is rotated. If zero, key pairs are only rotated when requested.
+	KeyLifetime time.Duration
 }
 
 // Controller : network interface controller.
 type Controller struct {
 	config *Config
 	wg     *wgctrl.Client
+
+	keysLock sync.Mutex
 }
 
 // NewController :
@@ -81,18 +93,79 @@ func (c *Controller) Interfaces() ([]*structs.Interface, error) {
 			return nil, err
 		}
 
+		pending, err := c.pendingPublicKey(l.Attrs().Alias)
+		if err != nil {
+			return nil, err
+		}
+
 		out = append(out, &structs.Interface{
-			ID:         l.Attrs().Alias,
-			Name:       util.StrToPtr(l.Attrs().Name),
-			ListenPort: &dev.ListenPort,
-			PublicKey:  util.StrToPtr(dev.PrivateKey.PublicKey().String()),
-			// TODO: capture other information that might be useful e.g. for diagnosis (see l.Attrs().Statistics)
+			ID:               l.Attrs().Alias,
+			Name:             util.StrToPtr(l.Attrs().Name),
+			ListenPort:       &dev.ListenPort,
+			PublicKey:        util.StrToPtr(dev.PrivateKey.PublicKey().String()),
+			PendingPublicKey: pending,
+			PeerStats:        peerStats(dev),
 		})
 	}
 
 	return out, nil
 }
 
+// Statistics returns live statistics of all network interfaces
+// managed by the controller and their peers.
+func (c *Controller) Statistics() ([]*InterfaceStatistics, error) {
+
+	out := []*InterfaceStatistics{}
+
+	links, err := linksByPrefix(c.config.InterfacesPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range links {
+
+		dev, err := c.wg.Device(l.Attrs().Name)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, &InterfaceStatistics{
+			ID:    l.Attrs().Alias,
+			Name:  l.Attrs().Name,
+			Peers: peerStats(dev),
+		})
+	}
+
+	return out, nil
+}
+
+// peerStats returns the statistics of the peers of a WireGuard device. Since
+// peers are identified by their public keys, connection IDs are left empty.
+func peerStats(dev *wgtypes.Device) []*structs.PeerStats {
+
+	out := []*structs.PeerStats{}
+
+	for _, p := range dev.Peers {
+		ps := &structs.PeerStats{
+			PublicKey:     p.PublicKey.String(),
+			LastHandshake: p.LastHandshakeTime,
+			ReceiveBytes:  p.ReceiveBytes,
+			TransmitBytes: p.TransmitBytes,
+		}
+		if p.Endpoint != nil {
+			ps.Endpoint = p.Endpoint.String()
+		}
+		// The kernel reports peers which never completed a handshake
+		// at the epoch, rather than with an unset time.
+		if ps.LastHandshake.Unix() <= 0 {
+			ps.LastHandshake = time.Time{}
+		}
+		out = append(out, ps)
+	}
+
+	return out
+}
+
 // DeleteInterfaceByName deletes a network interface and all associated routes by name.
 func (c *Controller) DeleteInterfaceByName(s string) error {
 	err := deleteLinkAndRoutesByName(s)
@@ -197,24 +270,9 @@ func (c *Controller) configureLink(iface *structs.Interface) error {
 		return err
 	}
 
-	// Create a new private key for this interface.
-	// If a private key has already been created, use it.
-	// TODO: implement an expiration/rotation strategy.
-	key, err := c.config.KeyStore.KeyByID(iface.ID)
+	key, err := c.interfaceKey(iface)
 	if err != nil {
-		wgKey, err := wgtypes.GeneratePrivateKey()
-		if err != nil {
-			return fmt.Errorf("could not generate private key: %v", err)
-		}
-		key = &PrivateKey{
-			ID:        iface.ID,
-			Key:       wgKey.String(),
-			CreatedAt: time.Now().Unix(),
-		}
-		err = c.config.KeyStore.UpsertKey(key)
-		if err != nil {
-			return fmt.Errorf("could not persist private key: %v", err)
-		}
+		return err
 	}
 
 	wgKey, err := wgtypes.ParseKey(key.Key)
@@ -287,6 +345,14 @@ func (c *Controller) newPeerConfig(peer *structs.Peer) (*wgtypes.PeerConfig, err
 		config.PublicKey = key
 	}
 
+	if peer.PresharedKey != nil {
+		var key wgtypes.Key
+		if key, err = wgtypes.ParseKey(*peer.PresharedKey); err != nil {
+			return nil, err
+		}
+		config.PresharedKey = &key
+	}
+
 	for _, ip := range peer.AllowedIPs {
 		_, parsed, err := net.ParseCIDR(ip)
 		if err != nil {
@@ -305,15 +371,37 @@ func (c *Controller) newPeerConfig(peer *structs.Peer) (*wgtypes.PeerConfig, err
 		if peer.Port != nil {
 			port = *peer.Port
 		}
-		config.Endpoint = &net.UDPAddr{
-			IP:   net.ParseIP(*peer.Address),
-			Port: port,
+		if config.Endpoint, err = resolveEndpoint(*peer.Address, port); err != nil {
+			return nil, err
 		}
 	}
 
 	return config, nil
 }
 
+// resolveEndpoint returns the UDP address of a peer reachable at the given address,
+// which can be either an IP address or a hostname. Hostnames are resolved every time
+// the interface is configured, so that peers with a dynamic DNS name can be tracked.
+func resolveEndpoint(address string, port int) (*net.UDPAddr, error) {
+
+	if ip := net.ParseIP(address); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), endpointResolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve peer endpoint %s: %v", address, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("could not resolve peer endpoint %s: no addresses found", address)
+	}
+
+	return &net.UDPAddr{IP: addrs[0].IP, Port: port}, nil
+}
+
 func (c *Controller) randomInterfaceName() string {
 	buf := make([]byte, 3)
 	if _, err := rand.Read(buf); err != nil {
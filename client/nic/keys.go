@@ -0,0 +1,134 @@
+package nic
+
+import (
+	"fmt"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+	wgtypes "golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// Key pairs are rotated in two steps, so that the public key of a new key pair reaches
+// the server before the interface switches to it. First, the next private key is generated
+// and stored along with the current one, and its public key is reported to the server as
+// the interface's pending public key. Then, once the server acknowledges the pending public
+// key, the interface switches to the new key pair, and the server distributes its public
+// key to the peers. Rotations are started either when the current key outlives its
+// lifetime, or when requested by the server.
+
+// interfaceKey returns the private key to be used by an interface, generating one
+// if the interface has none, and advancing the rotation of its key pair according
+// to the interface configuration received from the server.
+func (c *Controller) interfaceKey(iface *structs.Interface) (*PrivateKey, error) {
+
+	c.keysLock.Lock()
+	defer c.keysLock.Unlock()
+
+	key, err := c.config.KeyStore.KeyByID(iface.ID)
+	if err != nil || key == nil {
+		wgKey, err := wgtypes.GeneratePrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate private key: %v", err)
+		}
+		key = &PrivateKey{
+			ID:        iface.ID,
+			Key:       wgKey.String(),
+			CreatedAt: time.Now().Unix(),
+		}
+		if err := c.config.KeyStore.UpsertKey(key); err != nil {
+			return nil, fmt.Errorf("could not persist private key: %v", err)
+		}
+		return key, nil
+	}
+
+	if key.NextKey != "" {
+		next, err := publicKey(key.NextKey)
+		if err != nil {
+			return nil, err
+		}
+		// Switch to the next key pair once its public key was acknowledged by the server
+		if iface.PendingPublicKey != nil && *iface.PendingPublicKey == next {
+			key.Key, key.NextKey, key.CreatedAt = key.NextKey, "", time.Now().Unix()
+			if err := c.config.KeyStore.UpsertKey(key); err != nil {
+				return nil, fmt.Errorf("could not persist private key: %v", err)
+			}
+		}
+		return key, nil
+	}
+
+	if iface.RotateKey {
+		current, err := publicKey(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		// Only start a rotation if the server still knows the current public key.
+		// Otherwise, the requested rotation was already completed, and the server
+		// has not received the new public key yet.
+		if iface.PublicKey != nil && *iface.PublicKey == current {
+			if err := c.startKeyRotation(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return key, nil
+}
+
+// pendingPublicKey returns the public key of the next key pair of an interface, if
+// a rotation is in progress. If the current key of the interface outlived the key
+// lifetime, a new rotation is started.
+func (c *Controller) pendingPublicKey(id string) (*string, error) {
+
+	c.keysLock.Lock()
+	defer c.keysLock.Unlock()
+
+	key, err := c.config.KeyStore.KeyByID(id)
+	if err != nil || key == nil {
+		return nil, nil // the interface has not been configured yet
+	}
+
+	if key.NextKey == "" && c.config.KeyLifetime > 0 {
+		if time.Since(time.Unix(key.CreatedAt, 0)) > c.config.KeyLifetime {
+			if err := c.startKeyRotation(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if key.NextKey == "" {
+		return nil, nil
+	}
+
+	next, err := publicKey(key.NextKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &next, nil
+}
+
+// startKeyRotation generates the next private key of an interface and persists it.
+func (c *Controller) startKeyRotation(key *PrivateKey) error {
+
+	wgKey, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("could not generate private key: %v", err)
+	}
+
+	key.NextKey = wgKey.String()
+
+	if err := c.config.KeyStore.UpsertKey(key); err != nil {
+		return fmt.Errorf("could not persist private key: %v", err)
+	}
+
+	return nil
+}
+
+// publicKey returns the public key corresponding to a base64-encoded private key.
+func publicKey(s string) (string, error) {
+	key, err := wgtypes.ParseKey(s)
+	if err != nil {
+		return "", fmt.Errorf("could not parse private key: %v", err)
+	}
+	return key.PublicKey().String(), nil
+}
@@ -12,6 +12,7 @@ type NetworkInterfaceController interface {
 	DeleteInterfaceByAlias(s string) error
 	DeleteInterfaceByName(s string) error
 	DeleteAllInterfaces() error
+	Statistics() ([]*InterfaceStatistics, error)
 }
 
 type PrivateKeyStore interface {
@@ -21,7 +22,20 @@ type PrivateKeyStore interface {
 }
 
 type PrivateKey struct {
-	ID        string
-	Key       string
+	ID  string
+	Key string
+
+	// NextKey is the private key the interface switches to once its
+	// public key is acknowledged by the server, if a rotation is in progress.
+	NextKey string
+
 	CreatedAt int64
 }
+
+// InterfaceStatistics contains live statistics of an interface and its peers.
+type InterfaceStatistics struct {
+	// ID is the ID of the interface, stored as the link alias.
+	ID    string
+	Name  string
+	Peers []*structs.PeerStats
+}
@@ -13,6 +13,11 @@ import (
 var (
 	interfacesBucketName  = []byte("interfaces")
 	privateKeysBucketName = []byte("keys")
+	metaBucketName        = []byte("meta")
+)
+
+var (
+	serversKey = []byte("servers")
 )
 
 // StateRepository ...
@@ -37,6 +42,10 @@ func NewStateRepository(path string) (*StateRepository, error) {
 			return err
 		}
 
+		if _, err := tx.CreateBucketIfNotExists(metaBucketName); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -141,6 +150,31 @@ func (r *StateRepository) DeleteKey(id string) error {
 	return err
 }
 
+// Servers returns the last known list of server addresses.
+func (r *StateRepository) Servers() ([]string, error) {
+
+	servers := []string{}
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(metaBucketName).Get(serversKey)
+		if v == nil {
+			return nil
+		}
+		return decode(v, &servers)
+	})
+
+	return servers, err
+}
+
+// SetServers persists the list of known server addresses.
+func (r *StateRepository) SetServers(servers []string) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(metaBucketName)
+		return b.Put(serversKey, encode(servers))
+	})
+	return err
+}
+
 func encode(in interface{}) []byte {
 	out, err := json.Marshal(in)
 	if err != nil {
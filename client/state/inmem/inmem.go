@@ -13,6 +13,8 @@ type Repository struct {
 	// interface_id -> value
 	interfaces map[string]*structs.Interface
 
+	servers []string
+
 	mu sync.RWMutex
 }
 
@@ -56,3 +58,16 @@ func (r *Repository) DeleteInterfaces(ids []string) error {
 
 	return nil
 }
+
+func (r *Repository) Servers() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string{}, r.servers...), nil
+}
+
+func (r *Repository) SetServers(servers []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers = append([]string{}, servers...)
+	return nil
+}
@@ -18,4 +18,8 @@ type Repository interface {
 	KeyByID(id string) (*nic.PrivateKey, error)
 	UpsertKey(key *nic.PrivateKey) error
 	DeleteKey(id string) error
+
+	// Server list
+	Servers() ([]string, error)
+	SetServers(servers []string) error
 }
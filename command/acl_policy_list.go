@@ -19,7 +19,8 @@ type ACLPolicyListCommand struct {
 	Command
 
 	// Parsed flags
-	json bool
+	json    bool
+	listing listFlags
 }
 
 func (c *ACLPolicyListCommand) FlagSet() *pflag.FlagSet {
@@ -30,6 +31,8 @@ func (c *ACLPolicyListCommand) FlagSet() *pflag.FlagSet {
 	// General options
 	flags.BoolVar(&c.json, "json", false, "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
@@ -66,10 +69,19 @@ func (c *ACLPolicyListCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	policies, err := api.ACLPolicies().List()
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving ACL policies: %s", err))
-		return 1
+	var policies []*structs.ACLPolicyListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.ACLPolicies().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving ACL policies: %s", err))
+			return 1
+		}
+		policies = append(policies, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(policies) == 0 {
@@ -78,6 +90,10 @@ func (c *ACLPolicyListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatPolicyList(policies))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 }
 
@@ -96,6 +112,8 @@ ACL Policy List Options:
   --json
     Enable JSON output.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
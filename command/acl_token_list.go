@@ -19,7 +19,8 @@ type ACLTokenListCommand struct {
 	Command
 
 	// Parsed flags
-	json bool
+	json    bool
+	listing listFlags
 }
 
 func (c *ACLTokenListCommand) FlagSet() *pflag.FlagSet {
@@ -30,6 +31,8 @@ func (c *ACLTokenListCommand) FlagSet() *pflag.FlagSet {
 	// General options
 	flags.BoolVar(&c.json, "json", false, "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
@@ -66,10 +69,19 @@ func (c *ACLTokenListCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	tokens, err := api.ACLTokens().List()
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving ACL tokens: %s", err))
-		return 1
+	var tokens []*structs.ACLTokenListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.ACLTokens().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving ACL tokens: %s", err))
+			return 1
+		}
+		tokens = append(tokens, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(tokens) == 0 {
@@ -78,6 +90,10 @@ func (c *ACLTokenListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatTokenList(tokens))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 }
 
@@ -96,6 +112,8 @@ ACL Policy List Options:
   --json
     Enable JSON output.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
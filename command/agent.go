@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/caarlos0/env"
 	"github.com/dimiro1/banner"
@@ -130,11 +132,22 @@ func (c *AgentCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	<-ctx.Done()
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	defer signal.Stop(reloadCh)
 
-	c.agent.Shutdown()
-
-	return 0
+	for {
+		select {
+		case <-reloadCh:
+			c.UI.Output("==> Caught SIGHUP, reloading configuration...")
+			if err := c.agent.Reload(); err != nil {
+				c.UI.Error(fmt.Sprintf("Error reloading configuration: %s", err.Error()))
+			}
+		case <-ctx.Done():
+			c.agent.Shutdown()
+			return 0
+		}
+	}
 }
 
 // Help :
@@ -357,6 +370,8 @@ func (c *AgentCommand) printConfig() {
 		"server":          strconv.FormatBool(config.Server.Enabled),
 		"version":         config.Version.VersionNumber(),
 		"acl enabled":     strconv.FormatBool(config.ACL.Enabled),
+		"rpc tls":         strconv.FormatBool(config.TLS.RPC),
+		"http tls":        strconv.FormatBool(config.TLS.HTTP),
 	}
 
 	padding := 18
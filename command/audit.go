@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// AuditCommand :
+type AuditCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *AuditCommand) Name() string {
+	return "audit"
+}
+
+// Synopsis :
+func (c *AuditCommand) Synopsis() string {
+	return "Interact with the audit log"
+}
+
+// Run :
+func (c *AuditCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *AuditCommand) Help() string {
+	h := `
+Usage: drago audit <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with the audit log, which
+  records the write operations performed through the API.
+    
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// AuditListCommand :
+type AuditListCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json         bool
+	resourceType string
+	resourceID   string
+	token        string
+	operation    string
+	since        time.Duration
+	listing      listFlags
+}
+
+func (c *AuditListCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+	flags.StringVar(&c.resourceType, "resource-type", "", "")
+	flags.StringVar(&c.resourceID, "resource-id", "", "")
+	flags.StringVar(&c.token, "token-id", "", "")
+	flags.StringVar(&c.operation, "operation", "", "")
+	flags.DurationVar(&c.since, "since", 0, "")
+
+	c.listing.addFlags(flags)
+
+	return flags
+}
+
+// Name :
+func (c *AuditListCommand) Name() string {
+	return "audit list"
+}
+
+// Synopsis :
+func (c *AuditListCommand) Synopsis() string {
+	return "List audit log entries"
+}
+
+// Run :
+func (c *AuditListCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago audit list --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	filters := map[string][]string{}
+	if c.resourceType != "" {
+		filters["resource_type"] = []string{c.resourceType}
+	}
+	if c.resourceID != "" {
+		filters["resource_id"] = []string{c.resourceID}
+	}
+	if c.token != "" {
+		filters["token"] = []string{c.token}
+	}
+	if c.operation != "" {
+		filters["operation"] = []string{c.operation}
+	}
+	if c.since > 0 {
+		filters["since"] = []string{time.Now().Add(-c.since).UTC().Format(time.RFC3339)}
+	}
+
+	var entries []*structs.AuditEntry
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Audit().List(filters, q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving audit log: %s", err))
+			return 1
+		}
+		entries = append(entries, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
+	}
+
+	if len(entries) == 0 {
+		return 0
+	}
+
+	c.UI.Output(c.formatEntries(entries))
+
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
+	return 0
+}
+
+// Help :
+func (c *AuditListCommand) Help() string {
+	h := `
+Usage: drago audit list [options]
+
+  List the entries of the audit log, newest first. Each entry records a write
+  operation performed through the API, the token used for performing it, and
+  the fields of the target resource it changed.
+
+  If ACLs are enabled, this option requires a token with the 'audit:read' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Audit List Options:
+
+  --json
+    Enable JSON output, including the values of the changed fields.
+
+  --resource-type=<type>
+    Filter entries by resource type, e.g. network or connection.
+
+  --resource-id=<id>
+    Filter entries by resource ID.
+
+  --token-id=<id>
+    Filter entries by the ID of the token used.
+
+  --operation=<operation>
+    Filter entries by operation, e.g. Network.UpsertNetwork.
+
+  --since=<duration>
+    Only list entries newer than the duration passed, e.g. 24h.
+
+Filtering and Pagination Options:
+` + ListingOptions() + `
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *AuditListCommand) formatEntries(entries []*structs.AuditEntry) string {
+
+	var b bytes.Buffer
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(entries); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("TIME", "TOKEN", "OPERATION", "RESOURCE TYPE", "RESOURCE ID", "CHANGES", "ERROR").WithWriter(&b)
+		for _, e := range entries {
+			fields := []string{}
+			for k := range e.Changes {
+				fields = append(fields, k)
+			}
+			sort.Strings(fields)
+			tbl.AddRow(e.Timestamp.Format(time.RFC3339), e.TokenName, e.Operation, e.ResourceType, e.ResourceID, strings.Join(fields, ","), e.Error)
+		}
+		tbl.Print()
+	}
+
+	return b.String()
+}
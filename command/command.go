@@ -12,8 +12,10 @@ import (
 
 // Command is the base command
 type Command struct {
-	address string
-	token   string
+	address       string
+	token         string
+	namespace     string
+	tlsSkipVerify bool
 }
 
 // FlagSet declares flags that are common to all commands,
@@ -27,6 +29,8 @@ func (c *Command) FlagSet(name string) *pflag.FlagSet {
 
 	flags.StringVar(&c.address, "address", "", "")
 	flags.StringVar(&c.token, "token", "", "")
+	flags.StringVar(&c.namespace, "namespace", "", "")
+	flags.BoolVar(&c.tlsSkipVerify, "tls-skip-verify", false, "")
 
 	// TODO: direct output to UI
 	flags.SetOutput(nil)
@@ -38,8 +42,12 @@ func (c *Command) FlagSet(name string) *pflag.FlagSet {
 // which can be used to interact with the Drago HTTP API.
 func (c *Command) APIClient() (*api.Client, error) {
 	return api.NewClient(&api.Config{
-		Address: c.address,
-		Token:   c.token,
+		Address:   c.address,
+		Token:     c.token,
+		Namespace: c.namespace,
+		TLSConfig: &api.TLSConfig{
+			Insecure: c.tlsSkipVerify,
+		},
 	})
 }
 
@@ -55,6 +63,21 @@ func GlobalOptions() string {
     The token used to authenticate with the Drago server.
     Overrides the DRAGO_TOKEN environment variable if set.
     Default = ""
+
+  --namespace=<namespace>
+    The namespace targeted by the command. Networks, nodes, interfaces,
+    connections and join tokens are only visible within their namespace.
+    Overrides the DRAGO_NAMESPACE environment variable if set.
+    Default = "default"
+
+  --tls-skip-verify
+    Do not verify the TLS certificate of the Drago server. This is
+    highly discouraged. Overrides the DRAGO_SKIP_VERIFY environment
+    variable if set.
+
+  The CA certificate used for verifying the server, and the client
+  certificate presented to it, are read from the DRAGO_CACERT,
+  DRAGO_CLIENT_CERT and DRAGO_CLIENT_KEY environment variables.
 `
 	return text
 }
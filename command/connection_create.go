@@ -21,6 +21,7 @@ type ConnectionCreateCommand struct {
 	json                bool
 	persistentKeepalive int
 	allowAll            bool
+	noPSK               bool
 }
 
 func (c *ConnectionCreateCommand) FlagSet() *pflag.FlagSet {
@@ -32,6 +33,7 @@ func (c *ConnectionCreateCommand) FlagSet() *pflag.FlagSet {
 	flags.BoolVar(&c.json, "json", false, "")
 	flags.IntVar(&c.persistentKeepalive, "keepalive", 0, "")
 	flags.BoolVar(&c.allowAll, "allow-all", false, "")
+	flags.BoolVar(&c.noPSK, "no-psk", false, "")
 
 	return flags
 }
@@ -76,7 +78,7 @@ func (c *ConnectionCreateCommand) Run(ctx context.Context, args []string) int {
 	}
 
 	// Resolve network name
-	networks, err := api.Networks().List()
+	networks, _, err := api.Networks().List(nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
 		return 1
@@ -99,6 +101,7 @@ func (c *ConnectionCreateCommand) Run(ctx context.Context, args []string) int {
 		NetworkID:           networkID,
 		PersistentKeepalive: &c.persistentKeepalive,
 		PeerSettings:        []*structs.PeerSettings{},
+		DisablePresharedKey: c.noPSK,
 	}
 
 	for idx, nodeID := range nodeIDs {
@@ -107,7 +110,7 @@ func (c *ConnectionCreateCommand) Run(ctx context.Context, args []string) int {
 			"node": {nodeID},
 		}
 
-		interfaces, err := api.Interfaces().List(filters)
+		interfaces, _, err := api.Interfaces().List(filters, nil)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error getting node interfaces: %s", err))
 			return 1
@@ -174,6 +177,9 @@ ACL Token Create Options:
   --keepalive=<seconds>
     Time interval between persistent keepalive packets. Defaults to 0, which disables the feature.
 
+  --no-psk
+    Do not generate a preshared key for this connection.
+
 `
 	return strings.TrimSpace(h)
 }
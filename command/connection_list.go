@@ -19,7 +19,8 @@ type ConnectionListCommand struct {
 	Command
 
 	// Parsed flags
-	json bool
+	json    bool
+	listing listFlags
 }
 
 func (c *ConnectionListCommand) FlagSet() *pflag.FlagSet {
@@ -30,6 +31,8 @@ func (c *ConnectionListCommand) FlagSet() *pflag.FlagSet {
 	// General options
 	flags.BoolVar(&c.json, "json", false, "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
@@ -66,10 +69,19 @@ func (c *ConnectionListCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	connections, err := api.Connections().List()
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving connections: %s", err))
-		return 1
+	var connections []*structs.ConnectionListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Connections().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving connections: %s", err))
+			return 1
+		}
+		connections = append(connections, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(connections) == 0 {
@@ -78,6 +90,10 @@ func (c *ConnectionListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatConnectionList(connections))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 }
 
@@ -86,7 +102,10 @@ func (c *ConnectionListCommand) Help() string {
 	h := `
 Usage: drago connection list [options]
 
-  Lists connections managed by Drago.
+  Lists connections managed by Drago, along with their state, derived from the
+  last handshake reported by the connected nodes. Connections are 'established'
+  if a handshake completed in the last 3 minutes, 'stale' if not, and 'never'
+  if no handshake ever completed.
 
   If ACLs are enabled, this option requires a token with the 'connection:read' capability.
 
@@ -98,6 +117,8 @@ Connection List Options:
   --json
     Enable JSON output.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
@@ -111,17 +132,24 @@ func (c *ConnectionListCommand) formatConnectionList(connections []*structs.Conn
 		enc := json.NewEncoder(&b)
 		enc.SetIndent("", "    ")
 		for _, conn := range connections {
-			fconnections = append(fconnections, map[string]string{
-				"id": conn.ID,
-			})
+			fconn := map[string]interface{}{
+				"id":                conn.ID,
+				"state":             conn.State,
+				"bytes_transferred": conn.BytesTransferred,
+				"last_handshake":    nil,
+			}
+			if !conn.LastHandshake.IsZero() {
+				fconn["last_handshake"] = conn.LastHandshake
+			}
+			fconnections = append(fconnections, fconn)
 		}
 		if err := enc.Encode(fconnections); err != nil {
 			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
 		}
 	} else {
-		tbl := table.New("CONNECTION ID").WithWriter(&b)
+		tbl := table.New("CONNECTION ID", "STATE", "LAST HANDSHAKE", "TRANSFERRED").WithWriter(&b)
 		for _, conn := range connections {
-			tbl.AddRow(conn.ID)
+			tbl.AddRow(conn.ID, conn.State, formatTimeAgo(conn.LastHandshake), formatBytes(conn.BytesTransferred))
 		}
 		tbl.Print()
 	}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// ConnectionRotatePresharedKeyCommand :
+type ConnectionRotatePresharedKeyCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *ConnectionRotatePresharedKeyCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *ConnectionRotatePresharedKeyCommand) Name() string {
+	return "connection rotate-psk"
+}
+
+// Synopsis :
+func (c *ConnectionRotatePresharedKeyCommand) Synopsis() string {
+	return "Rotate the preshared key of a connection"
+}
+
+// Run :
+func (c *ConnectionRotatePresharedKeyCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <connection_id>")
+		c.UI.Error(`For additional help, try 'drago connection rotate-psk --help'`)
+		return 1
+	}
+
+	id := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	err = api.Connections().RotatePresharedKey(id)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error rotating preshared key: %s", err))
+		return 1
+	}
+
+	c.UI.Output("Rotated!")
+
+	return 0
+}
+
+// Help :
+func (c *ConnectionRotatePresharedKeyCommand) Help() string {
+	h := `
+Usage: drago connection rotate-psk <connection_id> [options]
+
+  Replace the preshared key of a connection with a newly generated one. The new
+  key is applied by both connected nodes the next time they fetch their
+  configuration, so the tunnel may be briefly interrupted in the meantime.
+
+  If ACLs are enabled, this option requires a token with the 'connection:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+`
+	return strings.TrimSpace(h)
+}
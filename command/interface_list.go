@@ -23,6 +23,7 @@ type InterfaceListCommand struct {
 	self    bool
 	node    string
 	network string
+	listing listFlags
 }
 
 func (c *InterfaceListCommand) FlagSet() *pflag.FlagSet {
@@ -36,6 +37,8 @@ func (c *InterfaceListCommand) FlagSet() *pflag.FlagSet {
 	flags.StringVar(&c.node, "node", "", "")
 	flags.StringVar(&c.network, "network", "", "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
@@ -76,7 +79,7 @@ func (c *InterfaceListCommand) Run(ctx context.Context, args []string) int {
 	networkID := ""
 
 	if len(c.network) > 0 {
-		networks, err := api.Networks().List()
+		networks, _, err := api.Networks().List(nil)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error retrieving networks: %s", err))
 			return 1
@@ -115,10 +118,19 @@ func (c *InterfaceListCommand) Run(ctx context.Context, args []string) int {
 		filters["node"] = []string{nodeID}
 	}
 
-	ifaces, err := api.Interfaces().List(filters)
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving interfaces: %s", err))
-		return 1
+	var ifaces []*structs.InterfaceListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Interfaces().List(filters, q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving interfaces: %s", err))
+			return 1
+		}
+		ifaces = append(ifaces, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(ifaces) == 0 {
@@ -127,6 +139,10 @@ func (c *InterfaceListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatInterfaceList(ifaces))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 }
 
@@ -156,6 +172,8 @@ Network List Options:
   --network=<network>
     Filter results by network.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"fmt"
+
+	api "github.com/seashell/drago/api"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// defaultPageSize is the number of items listed by
+	// list commands, unless a page size or --all is passed.
+	defaultPageSize = 100
+)
+
+// listFlags holds the filtering, pagination and sorting options of list commands.
+type listFlags struct {
+	filter    string
+	pageSize  int
+	nextToken string
+	all       bool
+	sort      string
+	order     string
+}
+
+// addFlags declares the filtering, pagination and sorting flags in flags.
+func (p *listFlags) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&p.filter, "filter", "", "")
+	flags.IntVar(&p.pageSize, "page-size", defaultPageSize, "")
+	flags.StringVar(&p.nextToken, "next-token", "", "")
+	flags.BoolVar(&p.all, "all", false, "")
+	flags.StringVar(&p.sort, "sort", "", "")
+	flags.StringVar(&p.order, "order", "", "")
+}
+
+// queryOptions returns the query options of the first page to be listed.
+func (p *listFlags) queryOptions() *api.QueryOptions {
+	return &api.QueryOptions{
+		Filter:    p.filter,
+		PerPage:   p.pageSize,
+		NextToken: p.nextToken,
+		Sort:      p.sort,
+		Order:     p.order,
+	}
+}
+
+// nextPage updates q to request the page following the one returned along with
+// meta. It returns whether that page should be listed, which is only if --all is
+// set, and there are more results. Otherwise, q holds the token of the next page.
+func (p *listFlags) nextPage(q *api.QueryOptions, meta *api.QueryMeta) bool {
+	q.NextToken = meta.NextToken
+	return p.all && q.NextToken != ""
+}
+
+// warnNextToken tells the user how to list the next page, if any.
+func (p *listFlags) warnNextToken(ui cli.UI, token string) {
+	if token != "" {
+		ui.Warn(fmt.Sprintf("\nThere are more results. Run the command again with --next-token=%s to list the next page, or with --all to list all results.", token))
+	}
+}
+
+// PaginationOptions returns the usage string of the pagination options of list commands.
+func ListingOptions() string {
+	text := `
+  --page-size=<n>
+    Maximum number of results listed. Defaults to 100.
+
+  --next-token=<token>
+    Token returned along with the previous page of results,
+    used for listing the next one.
+
+  --all
+    List all results, in pages of the size passed in --page-size.
+
+  --sort=<field>
+    Name of the field by which results are sorted, e.g. 'name'
+    or 'created_at'. Defaults to the ID, except for audit
+    entries, which are listed newest first.
+
+  --order=<asc|desc>
+    Order in which results are sorted. Defaults to 'asc'.
+`
+	return text
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// NamespaceCommand :
+type NamespaceCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *NamespaceCommand) Name() string {
+	return "namespace"
+}
+
+// Synopsis :
+func (c *NamespaceCommand) Synopsis() string {
+	return "Interact with namespaces"
+}
+
+// Run :
+func (c *NamespaceCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *NamespaceCommand) Help() string {
+	h := `
+Usage: drago namespace <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with namespaces. Namespaces
+  isolate networks, nodes, interfaces, connections and join tokens from those
+  in other namespaces. Other commands target the namespace set with the
+  --namespace flag, or the default namespace if none is set.
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NamespaceApplyCommand :
+type NamespaceApplyCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	description string
+}
+
+func (c *NamespaceApplyCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	flags.StringVar(&c.description, "description", "", "")
+
+	return flags
+}
+
+// Name :
+func (c *NamespaceApplyCommand) Name() string {
+	return "namespace apply"
+}
+
+// Synopsis :
+func (c *NamespaceApplyCommand) Synopsis() string {
+	return "Create or update a namespace"
+}
+
+// Run :
+func (c *NamespaceApplyCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <name>")
+		c.UI.Error(`For additional help, try 'drago namespace apply --help'`)
+		return 1
+	}
+
+	name := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	ns := &structs.Namespace{
+		Name:        name,
+		Description: c.description,
+	}
+	if err := api.Namespaces().Upsert(ns); err != nil {
+		c.UI.Error(fmt.Sprintf("Error applying namespace: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NamespaceApplyCommand) Help() string {
+	h := `
+Usage: drago namespace apply <name> [options]
+
+  Create or update a namespace.
+
+General Options:
+` + GlobalOptions() + `
+
+Namespace Apply Options:
+
+  --description=<description>
+    Sets the description for the namespace.
+
+`
+	return strings.TrimSpace(h)
+}
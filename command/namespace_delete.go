@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// NamespaceDeleteCommand :
+type NamespaceDeleteCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *NamespaceDeleteCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *NamespaceDeleteCommand) Name() string {
+	return "namespace delete"
+}
+
+// Synopsis :
+func (c *NamespaceDeleteCommand) Synopsis() string {
+	return "Delete namespace"
+}
+
+// Run :
+func (c *NamespaceDeleteCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <name>")
+		c.UI.Error(`For additional help, try 'drago namespace delete --help'`)
+		return 1
+	}
+
+	name := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	if err := api.Namespaces().Delete(name); err != nil {
+		c.UI.Error(fmt.Sprintf("Error deleting namespace: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NamespaceDeleteCommand) Help() string {
+	h := `
+Usage: drago namespace delete <name> [options]
+
+  Delete an existing namespace. Namespaces still containing networks,
+  nodes or join tokens can't be deleted, nor can the default namespace.
+
+General Options:
+` + GlobalOptions()
+
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,124 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NamespaceInfoCommand :
+type NamespaceInfoCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json bool
+}
+
+func (c *NamespaceInfoCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NamespaceInfoCommand) Name() string {
+	return "namespace info"
+}
+
+// Synopsis :
+func (c *NamespaceInfoCommand) Synopsis() string {
+	return "Display details about an existing namespace"
+}
+
+// Run :
+func (c *NamespaceInfoCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <name>")
+		c.UI.Error(`For additional help, try 'drago namespace info --help'`)
+		return 1
+	}
+
+	name := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	ns, err := api.Namespaces().Get(name)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error retrieving namespace: %s", err))
+		return 1
+	}
+
+	c.UI.Output(c.formatNamespace(ns))
+
+	return 0
+}
+
+// Help :
+func (c *NamespaceInfoCommand) Help() string {
+	h := `
+Usage: drago namespace info <name> [options]
+
+  Display information on an existing namespace.
+
+General Options:
+` + GlobalOptions() + `
+
+Namespace Info Options:
+
+  --json
+    Enable JSON output.
+
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NamespaceInfoCommand) formatNamespace(ns *structs.Namespace) string {
+
+	var b bytes.Buffer
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		fns := map[string]interface{}{
+			"name":        ns.Name,
+			"description": ns.Description,
+			"createdAt":   ns.CreatedAt,
+			"updatedAt":   ns.UpdatedAt,
+		}
+		if err := enc.Encode(fns); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("NAMESPACE", "DESCRIPTION").WithWriter(&b)
+		tbl.AddRow(ns.Name, ns.Description)
+		tbl.Print()
+	}
+
+	return b.String()
+}
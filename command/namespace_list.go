@@ -0,0 +1,147 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NamespaceListCommand :
+type NamespaceListCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json    bool
+	listing listFlags
+}
+
+func (c *NamespaceListCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+
+	c.listing.addFlags(flags)
+
+	return flags
+}
+
+// Name :
+func (c *NamespaceListCommand) Name() string {
+	return "namespace list"
+}
+
+// Synopsis :
+func (c *NamespaceListCommand) Synopsis() string {
+	return "List namespaces"
+}
+
+// Run :
+func (c *NamespaceListCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago namespace list --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	var namespaces []*structs.NamespaceListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Namespaces().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving namespaces: %s", err))
+			return 1
+		}
+		namespaces = append(namespaces, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return 0
+	}
+
+	c.UI.Output(c.formatNamespaceList(namespaces))
+
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NamespaceListCommand) Help() string {
+	h := `
+Usage: drago namespace list [options]
+
+  List the namespaces which the token can read.
+
+General Options:
+` + GlobalOptions() + `
+
+Namespace List Options:
+
+  --json
+    Enable JSON output.
+
+Filtering and Pagination Options:
+` + ListingOptions() + `
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NamespaceListCommand) formatNamespaceList(namespaces []*structs.NamespaceListStub) string {
+
+	var b bytes.Buffer
+	fnamespaces := []interface{}{}
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		for _, ns := range namespaces {
+			fnamespaces = append(fnamespaces, map[string]string{
+				"name":        ns.Name,
+				"description": ns.Description,
+			})
+		}
+		if err := enc.Encode(fnamespaces); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("NAMESPACE", "DESCRIPTION").WithWriter(&b)
+		for _, ns := range namespaces {
+			tbl.AddRow(ns.Name, ns.Description)
+		}
+		tbl.Print()
+	}
+
+	return b.String()
+}
@@ -17,6 +17,7 @@ type NetworkCreateCommand struct {
 
 	// Parsed flags
 	addressRange string
+	topology     string
 }
 
 func (c *NetworkCreateCommand) FlagSet() *pflag.FlagSet {
@@ -26,6 +27,7 @@ func (c *NetworkCreateCommand) FlagSet() *pflag.FlagSet {
 
 	// General options
 	flags.StringVar(&c.addressRange, "range", "", "")
+	flags.StringVar(&c.topology, "topology", "", "")
 
 	return flags
 }
@@ -68,6 +70,7 @@ func (c *NetworkCreateCommand) Run(ctx context.Context, args []string) int {
 	err = api.Networks().Create(&structs.Network{
 		Name:         name,
 		AddressRange: c.addressRange,
+		Topology:     c.topology,
 	})
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error creating network: %s", err))
@@ -96,6 +99,14 @@ Network Create Options:
   --range=<range>
     Sets the address range of the network, in CIDR notation.
 
+  --topology=<topology>
+    Sets the topology of the network. Valid values are "manual", in which
+    connections are created by users, and "mesh", in which every interface
+    in the network is automatically connected to every other interface, and
+    "hub-and-spoke", in which interfaces of nodes marked as hubs (with the
+    node metadata hub=true) are automatically connected to every other
+    interface. Defaults to "manual".
+
 `
 	return strings.TrimSpace(h)
 }
@@ -65,7 +65,7 @@ func (c *NetworkDeleteCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	networks, err := api.Networks().List()
+	networks, _, err := api.Networks().List(nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
 		return 1
@@ -0,0 +1,198 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NetworkImportCommand :
+type NetworkImportCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	files []string
+	apply bool
+	json  bool
+}
+
+func (c *NetworkImportCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.StringArrayVarP(&c.files, "file", "f", nil, "")
+	flags.BoolVar(&c.apply, "apply", false, "")
+	flags.BoolVar(&c.json, "json", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NetworkImportCommand) Name() string {
+	return "network import"
+}
+
+// Synopsis :
+func (c *NetworkImportCommand) Synopsis() string {
+	return "Import wg-quick configuration files into a network"
+}
+
+// Run :
+func (c *NetworkImportCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <network>")
+		c.UI.Error(`For additional help, try 'drago network import --help'`)
+		return 1
+	}
+
+	if len(c.files) == 0 {
+		c.UI.Error("At least one configuration file must be provided with --file")
+		return 1
+	}
+
+	name := args[0]
+	id := ""
+
+	files := []*structs.NetworkImportFile{}
+	for _, path := range c.files {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading configuration file: %s", err))
+			return 1
+		}
+		files = append(files, &structs.NetworkImportFile{
+			Name:    filepath.Base(path),
+			Content: string(b),
+		})
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	networks, _, err := api.Networks().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
+		return 1
+	}
+
+	for _, n := range networks {
+		if n.Name == name {
+			id = n.ID
+			break
+		}
+	}
+
+	if id == "" {
+		c.UI.Error("Error: network not found")
+		return 1
+	}
+
+	plan, err := api.Networks().Import(id, files, !c.apply)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error importing configuration files: %s", err))
+		return 1
+	}
+
+	c.UI.Output(c.formatPlan(plan))
+
+	if !c.json && !c.apply {
+		c.UI.Output("This was a dry run, nothing was changed. Run with --apply to import the configuration files.")
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NetworkImportCommand) Help() string {
+	h := `
+  Usage: drago network import <network> --file <path> [--file <path> ...] [options]
+
+  Import existing wg-quick configuration files into a network. Interfaces are
+  matched by public key against the interfaces in the network, and the ones
+  not found are created as external peers. A connection is created for each
+  pair of interfaces which are peers of each other, with the AllowedIPs and
+  PersistentKeepalive found in the files.
+
+  By default, the command only reports the changes the import would make.
+  Run it again with --apply to make them. Preshared keys are not imported,
+  and new ones are generated for the connections created.
+
+  If ACLs are enabled, this option requires a token with the 'network:write',
+  'interface:write' and 'connection:write' capabilities.
+
+General Options:
+` + GlobalOptions() + `
+
+Network Import Options:
+
+  --file, -f=<path>
+    Path to a wg-quick configuration file. Can be specified multiple times.
+
+  --apply
+    Apply the import, instead of only reporting the changes it would make.
+
+  --json
+    Enable JSON output.
+
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NetworkImportCommand) formatPlan(plan *structs.NetworkImportPlan) string {
+
+	var b bytes.Buffer
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(plan); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+		return b.String()
+	}
+
+	tbl := table.New("ACTION", "INTERFACE ID", "NODE ID", "PUBLIC KEY", "ADDRESS", "FILE").WithWriter(&b)
+	for _, i := range plan.Interfaces {
+		tbl.AddRow(i.Action, i.InterfaceID, i.NodeID, i.PublicKey, i.Address, i.File)
+	}
+	tbl.Print()
+
+	b.WriteString("\n")
+
+	tbl = table.New("ACTION", "CONNECTION ID", "PEERS", "ALLOWED IPS", "KEEPALIVE").WithWriter(&b)
+	for _, conn := range plan.Connections {
+		allowedIPs := []string{}
+		for _, ips := range conn.AllowedIPs {
+			allowedIPs = append(allowedIPs, "["+strings.Join(ips, ",")+"]")
+		}
+		tbl.AddRow(conn.Action, conn.ConnectionID, strings.Join(conn.PublicKeys, " <-> "), strings.Join(allowedIPs, " <-> "), strconv.Itoa(conn.PersistentKeepalive))
+	}
+	tbl.Print()
+
+	return b.String()
+}
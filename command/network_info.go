@@ -69,7 +69,7 @@ func (c *NetworkInfoCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	networks, err := api.Networks().List()
+	networks, _, err := api.Networks().List(nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
 		return 1
@@ -132,6 +132,7 @@ func (c *NetworkInfoCommand) formatNetwork(network *structs.Network) string {
 			"id":           network.ID,
 			"name":         network.Name,
 			"addressRange": network.AddressRange,
+			"topology":     network.Topology,
 		}
 
 		if err := enc.Encode(fnetwork); err != nil {
@@ -139,8 +140,8 @@ func (c *NetworkInfoCommand) formatNetwork(network *structs.Network) string {
 		}
 
 	} else {
-		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE").WithWriter(&b)
-		tbl.AddRow(network.ID, network.Name, network.AddressRange)
+		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE", "TOPOLOGY").WithWriter(&b)
+		tbl.AddRow(network.ID, network.Name, network.AddressRange, network.Topology)
 		tbl.Print()
 	}
 
@@ -19,7 +19,8 @@ type NetworkListCommand struct {
 	Command
 
 	// Parsed flags
-	json bool
+	json    bool
+	listing listFlags
 }
 
 func (c *NetworkListCommand) FlagSet() *pflag.FlagSet {
@@ -30,6 +31,8 @@ func (c *NetworkListCommand) FlagSet() *pflag.FlagSet {
 	// General options
 	flags.BoolVar(&c.json, "json", false, "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
@@ -66,10 +69,19 @@ func (c *NetworkListCommand) Run(ctx context.Context, args []string) int {
 		return 1
 	}
 
-	networks, err := api.Networks().List()
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving networks: %s", err))
-		return 1
+	var networks []*structs.NetworkListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Networks().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving networks: %s", err))
+			return 1
+		}
+		networks = append(networks, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(networks) == 0 {
@@ -78,6 +90,10 @@ func (c *NetworkListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatNetworkList(networks))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 }
 
@@ -98,6 +114,8 @@ Network List Options:
   --json
     Enable JSON output.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
@@ -115,15 +133,16 @@ func (c *NetworkListCommand) formatNetworkList(networks []*structs.NetworkListSt
 				"id":           network.ID,
 				"name":         network.Name,
 				"addressRange": network.AddressRange,
+				"topology":     network.Topology,
 			})
 		}
 		if err := enc.Encode(fnetworks); err != nil {
 			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
 		}
 	} else {
-		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE").WithWriter(&b)
+		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE", "TOPOLOGY").WithWriter(&b)
 		for _, network := range networks {
-			tbl.AddRow(network.ID, network.Name, network.AddressRange)
+			tbl.AddRow(network.ID, network.Name, network.AddressRange, network.Topology)
 		}
 		tbl.Print()
 	}
@@ -143,6 +162,7 @@ func (c *NetworkListCommand) formatNetwork(network *structs.Network) string {
 			"id":           network.ID,
 			"name":         network.Name,
 			"addressRange": network.AddressRange,
+			"topology":     network.Topology,
 		}
 
 		if err := enc.Encode(fnetwork); err != nil {
@@ -150,8 +170,8 @@ func (c *NetworkListCommand) formatNetwork(network *structs.Network) string {
 		}
 
 	} else {
-		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE").WithWriter(&b)
-		tbl.AddRow(network.ID, network.Name, network.AddressRange)
+		tbl := table.New("NETWORK ID", "NAME", "ADDRESS RANGE", "TOPOLOGY").WithWriter(&b)
+		tbl.AddRow(network.ID, network.Name, network.AddressRange, network.Topology)
 		tbl.Print()
 	}
 
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NetworkRotateKeysCommand :
+type NetworkRotateKeysCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *NetworkRotateKeysCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *NetworkRotateKeysCommand) Name() string {
+	return "network rotate-keys"
+}
+
+// Synopsis :
+func (c *NetworkRotateKeysCommand) Synopsis() string {
+	return "Rotate the WireGuard keys of all interfaces in a network"
+}
+
+// Run :
+func (c *NetworkRotateKeysCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <network>")
+		c.UI.Error(`For additional help, try 'drago network rotate-keys --help'`)
+		return 1
+	}
+
+	name := args[0]
+	id := ""
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	networks, _, err := api.Networks().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
+		return 1
+	}
+
+	for _, n := range networks {
+		if n.Name == name {
+			id = n.ID
+
+			break
+		}
+	}
+
+	if id == "" {
+		c.UI.Error("Error: network not found")
+		return 1
+	}
+
+	if err := api.Networks().RotateKeys(id); err != nil {
+		c.UI.Error(fmt.Sprintf("Error rotating keys: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Requested rotation of the keys of network %s", name))
+
+	return 0
+}
+
+// Help :
+func (c *NetworkRotateKeysCommand) Help() string {
+	h := `
+  Usage: drago network rotate-keys <network> [options]
+
+  Request the nodes in a network to rotate the key pairs of their interfaces
+  in that network, regardless of the configured key lifetime. Each node
+  publishes its new public key before switching to it, after which it is
+  distributed to the node's peers.
+
+  If ACLs are enabled, this option requires a token with the 'interface:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+`
+	return strings.TrimSpace(h)
+}
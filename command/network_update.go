@@ -0,0 +1,130 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NetworkUpdateCommand :
+type NetworkUpdateCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	topology string
+}
+
+func (c *NetworkUpdateCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.StringVar(&c.topology, "topology", "", "")
+
+	return flags
+}
+
+// Name :
+func (c *NetworkUpdateCommand) Name() string {
+	return "network update"
+}
+
+// Synopsis :
+func (c *NetworkUpdateCommand) Synopsis() string {
+	return "Update an existing network"
+}
+
+// Run :
+func (c *NetworkUpdateCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <network>")
+		c.UI.Error(`For additional help, try 'drago network update --help'`)
+		return 1
+	}
+
+	name := args[0]
+	id := ""
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	networks, _, err := api.Networks().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
+		return 1
+	}
+
+	for _, n := range networks {
+		if n.Name == name {
+			id = n.ID
+
+			break
+		}
+	}
+
+	if id == "" {
+		c.UI.Error("Error: network not found")
+		return 1
+	}
+
+	network, err := api.Networks().Get(id)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error retrieving network: %s", err))
+		return 1
+	}
+
+	if c.topology != "" {
+		network.Topology = c.topology
+	}
+
+	err = api.Networks().Update(network)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating network: %s", err))
+		return 1
+	}
+
+	c.UI.Output("Network updated!")
+
+	return 0
+}
+
+// Help :
+func (c *NetworkUpdateCommand) Help() string {
+	h := `
+Usage: drago network update <network> [options]
+
+  Update an existing Drago network.
+
+  If ACLs are enabled, this option requires a token with the 'network:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Network Update Options:
+
+  --topology=<topology>
+    Sets the topology of the network. Valid values are "manual", "mesh" and
+    "hub-and-spoke".
+    When switching to a topology other than "manual", connections are
+    automatically created between the interfaces in the network.
+
+`
+	return strings.TrimSpace(h)
+}
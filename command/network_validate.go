@@ -0,0 +1,164 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NetworkValidateCommand :
+type NetworkValidateCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json bool
+}
+
+func (c *NetworkValidateCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NetworkValidateCommand) Name() string {
+	return "network validate"
+}
+
+// Synopsis :
+func (c *NetworkValidateCommand) Synopsis() string {
+	return "Check a network for overlapping AllowedIPs"
+}
+
+// Run :
+func (c *NetworkValidateCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <network>")
+		c.UI.Error(`For additional help, try 'drago network validate --help'`)
+		return 1
+	}
+
+	name := args[0]
+	id := ""
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	networks, _, err := api.Networks().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
+		return 1
+	}
+
+	for _, n := range networks {
+		if n.Name == name {
+			id = n.ID
+
+			break
+		}
+	}
+
+	if id == "" {
+		c.UI.Error("Error: network not found")
+		return 1
+	}
+
+	conflicts, err := api.Networks().Validate(id)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error validating network: %s", err))
+		return 1
+	}
+
+	if c.json || len(conflicts) > 0 {
+		c.UI.Output(c.formatConflicts(conflicts))
+	}
+
+	// Fail only on identical prefixes, since the most
+	// specific prefix takes precedence on partial overlaps.
+	for _, conflict := range conflicts {
+		if conflict.Identical {
+			return 1
+		}
+	}
+
+	if !c.json {
+		c.UI.Output("No conflicting AllowedIPs found")
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NetworkValidateCommand) Help() string {
+	h := `
+  Usage: drago network validate <network> [options]
+
+  Check the connections of a network for AllowedIPs which overlap on the same
+  interface. Identical prefixes routed through different peers are reported as
+  errors, since WireGuard silently routes them through only one of the peers.
+  Partial overlaps are reported as warnings, since the most specific prefix
+  takes precedence, which may be intended.
+
+  If ACLs are enabled, this option requires a token with the 'network:read' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Network Validate Options:
+
+  --json
+    Enable JSON output.
+
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NetworkValidateCommand) formatConflicts(conflicts []*structs.AllowedIPsConflict) string {
+
+	var b bytes.Buffer
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(conflicts); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("LEVEL", "INTERFACE ID", "CONNECTION ID", "ALLOWED IP", "CONFLICTING CONNECTION ID", "CONFLICTING ALLOWED IP").WithWriter(&b)
+		for _, conflict := range conflicts {
+			level := "warning"
+			if conflict.Identical {
+				level = "error"
+			}
+			tbl.AddRow(level, conflict.InterfaceID, conflict.ConnectionID, conflict.AllowedIP, conflict.ConflictingConnectionID, conflict.ConflictingAllowedIP)
+		}
+		tbl.Print()
+	}
+
+	return b.String()
+}
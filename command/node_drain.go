@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeDrainCommand :
+type NodeDrainCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	self    bool
+	enable  bool
+	disable bool
+}
+
+func (c *NodeDrainCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.self, "self", false, "")
+	flags.BoolVar(&c.enable, "enable", false, "")
+	flags.BoolVar(&c.disable, "disable", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NodeDrainCommand) Name() string {
+	return "node drain"
+}
+
+// Synopsis :
+func (c *NodeDrainCommand) Synopsis() string {
+	return "Toggle the drain of a node"
+}
+
+// Run :
+func (c *NodeDrainCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.enable == c.disable {
+		c.UI.Error("Either the --enable or the --disable flag must be set")
+		c.UI.Error(`For additional help, try 'drago node drain --help'`)
+		return 1
+	}
+
+	args = flags.Args()
+	if (c.self && len(args) != 0) || (!c.self && len(args) != 1) {
+		c.UI.Error("This command takes either one argument or the --self flag")
+		c.UI.Error(`For additional help, try 'drago node drain --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	var nodeID string
+	if !c.self {
+		nodeID = args[0]
+	} else {
+		if nodeID, err = localAgentNodeID(api); err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining local node ID: %s", err))
+			return 1
+		}
+	}
+
+	if err := api.Nodes().UpdateDrain(nodeID, c.enable); err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating drain: %s", err))
+		return 1
+	}
+
+	if c.enable {
+		c.UI.Output(fmt.Sprintf("Node %s drain enabled", nodeID))
+	} else {
+		c.UI.Output(fmt.Sprintf("Node %s drain disabled", nodeID))
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NodeDrainCommand) Help() string {
+	h := `
+Usage: drago node drain [options] <node_id>
+
+  Toggle the drain of a node, in order to take it out of service for
+  maintenance without deleting it. Draining nodes are ineligible, and
+  are removed from the configuration of their peers. Disabling the drain
+  makes the node eligible again, and restores it on its peers.
+
+  If ACLs are enabled, this option requires a token with the 'node:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Node Drain Options:
+
+  --enable
+    Enable the drain.
+
+  --disable
+    Disable the drain.
+
+  --self
+    Drain the local node.
+
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeEligibilityCommand :
+type NodeEligibilityCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	self    bool
+	enable  bool
+	disable bool
+}
+
+func (c *NodeEligibilityCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.self, "self", false, "")
+	flags.BoolVar(&c.enable, "enable", false, "")
+	flags.BoolVar(&c.disable, "disable", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NodeEligibilityCommand) Name() string {
+	return "node eligibility"
+}
+
+// Synopsis :
+func (c *NodeEligibilityCommand) Synopsis() string {
+	return "Toggle the scheduling eligibility of a node"
+}
+
+// Run :
+func (c *NodeEligibilityCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.enable == c.disable {
+		c.UI.Error("Either the --enable or the --disable flag must be set")
+		c.UI.Error(`For additional help, try 'drago node eligibility --help'`)
+		return 1
+	}
+
+	args = flags.Args()
+	if (c.self && len(args) != 0) || (!c.self && len(args) != 1) {
+		c.UI.Error("This command takes either one argument or the --self flag")
+		c.UI.Error(`For additional help, try 'drago node eligibility --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	var nodeID string
+	if !c.self {
+		nodeID = args[0]
+	} else {
+		if nodeID, err = localAgentNodeID(api); err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining local node ID: %s", err))
+			return 1
+		}
+	}
+
+	eligibility := structs.NodeSchedulingEligible
+	if c.disable {
+		eligibility = structs.NodeSchedulingIneligible
+	}
+
+	if err := api.Nodes().UpdateEligibility(nodeID, eligibility); err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating scheduling eligibility: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Node %s scheduling eligibility set to %s", nodeID, eligibility))
+
+	return 0
+}
+
+// Help :
+func (c *NodeEligibilityCommand) Help() string {
+	h := `
+Usage: drago node eligibility [options] <node_id>
+
+  Toggle the scheduling eligibility of a node. Ineligible nodes keep their
+  existing connections, but are not automatically connected to other nodes
+  by the server.
+
+  If ACLs are enabled, this option requires a token with the 'node:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Node Eligibility Options:
+
+  --enable
+    Mark the node as eligible.
+
+  --disable
+    Mark the node as ineligible.
+
+  --self
+    Update the eligibility of the local node.
+
+`
+	return strings.TrimSpace(h)
+}
@@ -19,9 +19,10 @@ type NodeListCommand struct {
 	Command
 
 	// Parsed flags
-	json   bool
-	status string
-	meta   []string
+	json    bool
+	status  string
+	meta    []string
+	listing listFlags
 }
 
 func (c *NodeListCommand) FlagSet() *pflag.FlagSet {
@@ -34,12 +35,14 @@ func (c *NodeListCommand) FlagSet() *pflag.FlagSet {
 	flags.StringVar(&c.status, "status", "*", "")
 	flags.StringSliceVar(&c.meta, "meta", []string{}, "")
 
+	c.listing.addFlags(flags)
+
 	return flags
 }
 
 // Name :
 func (c *NodeListCommand) Name() string {
-	return "node status"
+	return "node list"
 }
 
 // Synopsis :
@@ -74,10 +77,19 @@ func (c *NodeListCommand) Run(ctx context.Context, args []string) int {
 	filters["meta"] = c.meta
 	filters["status"] = []string{c.status}
 
-	nodes, err := api.Nodes().List(filters)
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error retrieving node status: %s", err))
-		return 1
+	var nodes []*structs.NodeListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Nodes().List(filters, q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving node status: %s", err))
+			return 1
+		}
+		nodes = append(nodes, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
 	}
 
 	if len(nodes) == 0 {
@@ -86,6 +98,10 @@ func (c *NodeListCommand) Run(ctx context.Context, args []string) int {
 
 	c.UI.Output(c.formatNodeList(nodes))
 
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
 	return 0
 
 }
@@ -113,6 +129,8 @@ Node List Options:
   --status=<initializing|ready|down>
     Filter nodes by status.
 
+Filtering and Pagination Options:
+` + ListingOptions() + `
 `
 	return strings.TrimSpace(h)
 }
@@ -126,19 +144,21 @@ func (c *NodeListCommand) formatNodeList(nodes []*structs.NodeListStub) string {
 		enc := json.NewEncoder(&b)
 		enc.SetIndent("", "    ")
 		for _, node := range nodes {
-			fnodes = append(fnodes, map[string]string{
-				"id":     node.ID,
-				"name":   node.Name,
-				"status": node.Status,
+			fnodes = append(fnodes, map[string]interface{}{
+				"id":          node.ID,
+				"name":        node.Name,
+				"status":      node.Status,
+				"eligibility": nodeEligibility(node.Eligibility),
+				"drain":       node.Drain,
 			})
 		}
 		if err := enc.Encode(fnodes); err != nil {
 			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
 		}
 	} else {
-		tbl := table.New("NODE ID", "NAME", "STATUS").WithWriter(&b)
+		tbl := table.New("NODE ID", "NAME", "STATUS", "ELIGIBILITY", "DRAIN").WithWriter(&b)
 		for _, node := range nodes {
-			tbl.AddRow(node.ID, node.Name, node.Status)
+			tbl.AddRow(node.ID, node.Name, node.Status, nodeEligibility(node.Eligibility), node.Drain)
 		}
 		tbl.Print()
 	}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeRotateKeysCommand :
+type NodeRotateKeysCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	self bool
+}
+
+func (c *NodeRotateKeysCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.self, "self", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NodeRotateKeysCommand) Name() string {
+	return "node rotate-keys"
+}
+
+// Synopsis :
+func (c *NodeRotateKeysCommand) Synopsis() string {
+	return "Rotate the WireGuard keys of a node"
+}
+
+// Run :
+func (c *NodeRotateKeysCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if (c.self && len(args) != 0) || (!c.self && len(args) != 1) {
+		c.UI.Error("This command takes either one argument or the --self flag")
+		c.UI.Error(`For additional help, try 'drago node rotate-keys --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	var nodeID string
+	if !c.self {
+		nodeID = args[0]
+	} else {
+		if nodeID, err = localAgentNodeID(api); err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining local node ID: %s", err))
+			return 1
+		}
+	}
+
+	if err := api.Nodes().RotateKeys(nodeID); err != nil {
+		c.UI.Error(fmt.Sprintf("Error rotating keys: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Requested rotation of the keys of node %s", nodeID))
+
+	return 0
+}
+
+// Help :
+func (c *NodeRotateKeysCommand) Help() string {
+	h := `
+Usage: drago node rotate-keys [options] <node_id>
+
+  Request a node to rotate the key pairs of all its WireGuard interfaces,
+  regardless of the configured key lifetime. The node publishes each new
+  public key before switching to it, after which it is distributed to the
+  node's peers.
+
+  If ACLs are enabled, this option requires a token with the 'interface:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Node Rotate Keys Options:
+
+  --self
+    Rotate the keys of the local node.
+
+`
+	return strings.TrimSpace(h)
+}
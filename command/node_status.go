@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	table "github.com/rodaine/table"
 	structs "github.com/seashell/drago/drago/structs"
@@ -79,7 +80,7 @@ func (c *NodeStatusCommand) Run(ctx context.Context, args []string) int {
 		filters["status"] = []string{c.status}
 
 		// Print status of multiple nodes
-		nodes, err := api.Nodes().List(filters)
+		nodes, _, err := api.Nodes().List(filters, nil)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error retrieving node status: %s", err))
 			return 1
@@ -188,11 +189,22 @@ func (c *NodeStatusCommand) formatNode(node *structs.Node) string {
 		enc := json.NewEncoder(&b)
 		enc.SetIndent("", "    ")
 
-		fnode := map[string]string{
+		history := []map[string]string{}
+		for _, t := range node.StatusHistory {
+			history = append(history, map[string]string{
+				"status":    t.Status,
+				"timestamp": t.Timestamp.Format(time.RFC3339),
+			})
+		}
+
+		fnode := map[string]interface{}{
 			"id":               node.ID,
 			"name":             node.Name,
 			"advertiseAddress": node.AdvertiseAddress,
 			"status":           node.Status,
+			"eligibility":      nodeEligibility(node.SchedulingEligibility),
+			"drain":            node.Drain,
+			"statusHistory":    history,
 		}
 
 		if err := enc.Encode(fnode); err != nil {
@@ -200,9 +212,20 @@ func (c *NodeStatusCommand) formatNode(node *structs.Node) string {
 		}
 
 	} else {
-		tbl := table.New("NODE ID", "NAME", "ADVERTISE ADDRESS", "STATUS").WithWriter(&b)
-		tbl.AddRow(node.ID, node.Name, node.AdvertiseAddress, node.Status)
+		tbl := table.New("NODE ID", "NAME", "ADVERTISE ADDRESS", "STATUS", "ELIGIBILITY", "DRAIN").WithWriter(&b)
+		tbl.AddRow(node.ID, node.Name, node.AdvertiseAddress, node.Status, nodeEligibility(node.SchedulingEligibility), node.Drain)
 		tbl.Print()
+
+		if len(node.StatusHistory) > 0 {
+			b.WriteString("\nStatus History\n")
+			tbl = table.New("TIME", "STATUS").WithWriter(&b)
+			// Most recent transitions first
+			for i := len(node.StatusHistory) - 1; i >= 0; i-- {
+				t := node.StatusHistory[i]
+				tbl.AddRow(t.Timestamp.Format(time.RFC3339), t.Status)
+			}
+			tbl.Print()
+		}
 	}
 
 	return b.String()
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// NodeTokenCommand :
+type NodeTokenCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *NodeTokenCommand) Name() string {
+	return "node token"
+}
+
+// Synopsis :
+func (c *NodeTokenCommand) Synopsis() string {
+	return "Interact with join tokens"
+}
+
+// Run :
+func (c *NodeTokenCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *NodeTokenCommand) Help() string {
+	h := `
+Usage: drago node token <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with join tokens, which
+  authorize the registration of new nodes.
+  
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
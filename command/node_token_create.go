@@ -0,0 +1,180 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeTokenCreateCommand :
+type NodeTokenCreateCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json     bool
+	name     string
+	maxUses  int
+	ttl      time.Duration
+	networks []string
+	meta     []string
+}
+
+func (c *NodeTokenCreateCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.StringVar(&c.name, "name", "", "")
+	flags.IntVar(&c.maxUses, "max-uses", 1, "")
+	flags.DurationVar(&c.ttl, "ttl", 0, "")
+	flags.StringSliceVar(&c.networks, "network", []string{}, "")
+	flags.StringSliceVar(&c.meta, "meta", []string{}, "")
+	flags.BoolVar(&c.json, "json", false, "")
+
+	return flags
+}
+
+// Name :
+func (c *NodeTokenCreateCommand) Name() string {
+	return "node token create"
+}
+
+// Synopsis :
+func (c *NodeTokenCreateCommand) Synopsis() string {
+	return "Create a new join token"
+}
+
+// Run :
+func (c *NodeTokenCreateCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago node token create --help'`)
+		return 1
+	}
+
+	meta := map[string]string{}
+	for _, kv := range c.meta {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			c.UI.Error(fmt.Sprintf("Error parsing meta value: %v", kv))
+			return 1
+		}
+		meta[parts[0]] = parts[1]
+	}
+
+	token := &structs.JoinToken{
+		Name:     c.name,
+		MaxUses:  c.maxUses,
+		Networks: c.networks,
+		Meta:     meta,
+	}
+
+	if c.ttl > 0 {
+		token.ExpiresAt = time.Now().Add(c.ttl)
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	token, err = api.JoinTokens().Create(token)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating join token: %s", err))
+		return 1
+	}
+
+	c.UI.Output(c.formatToken(token))
+
+	return 0
+}
+
+// Help :
+func (c *NodeTokenCreateCommand) Help() string {
+	h := `
+Usage: drago node token create [options]
+
+  Create a join token, which authorizes the registration of new nodes.
+  Client nodes present the token secret through the 'join_token' option
+  of their configuration.
+
+  If ACLs are enabled, this option requires a token with the 'node:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Node Token Create Options:
+
+  --name=<name>
+    Sets the human readable name for the join token.
+
+  --max-uses=<n>
+    Sets how many nodes can register with the token. Defaults to 1.
+    A value of 0 allows the token to be used indefinitely.
+
+  --ttl=<duration>
+    Sets how long the token can be used for (e.g. "24h").
+    By default, tokens do not expire.
+
+  --network=<network>
+    Specifies a network, by name or ID, which nodes registering with
+    the token automatically join. Can be repeated.
+
+  --meta=<key=value>
+    Specifies metadata added to nodes registering with the token.
+    Can be repeated.
+
+  --json
+    Enable JSON output.
+
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NodeTokenCreateCommand) formatToken(token *structs.JoinToken) string {
+
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "    ")
+	formatted := map[string]interface{}{
+		"id":        token.ID,
+		"name":      token.Name,
+		"secret":    token.Secret,
+		"maxUses":   token.MaxUses,
+		"networks":  token.Networks,
+		"meta":      token.Meta,
+		"expiresAt": token.ExpiresAt,
+		"createdAt": token.CreatedAt,
+	}
+	if err := enc.Encode(formatted); err != nil {
+		c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+	}
+
+	s := b.String()
+
+	if c.json {
+		return s
+	}
+
+	return cleanJSONString(s)
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeTokenDeleteCommand :
+type NodeTokenDeleteCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *NodeTokenDeleteCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *NodeTokenDeleteCommand) Name() string {
+	return "node token delete"
+}
+
+// Synopsis :
+func (c *NodeTokenDeleteCommand) Synopsis() string {
+	return "Delete an existing join token"
+}
+
+// Run :
+func (c *NodeTokenDeleteCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <token_id>")
+		c.UI.Error(`For additional help, try 'drago node token delete --help'`)
+		return 1
+	}
+
+	id := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	if err := api.JoinTokens().Delete(id); err != nil {
+		c.UI.Error(fmt.Sprintf("Error deleting join token: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NodeTokenDeleteCommand) Help() string {
+	h := `
+Usage: drago node token delete <token_id> [options]
+
+  Delete an existing join token. Nodes already registered with the token
+  are not affected.
+
+  If ACLs are enabled, this option requires a token with the 'node:write' capability.
+
+General Options:
+` + GlobalOptions()
+
+	return strings.TrimSpace(h)
+}
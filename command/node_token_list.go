@@ -0,0 +1,162 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// NodeTokenListCommand :
+type NodeTokenListCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json    bool
+	listing listFlags
+}
+
+func (c *NodeTokenListCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+
+	c.listing.addFlags(flags)
+
+	return flags
+}
+
+// Name :
+func (c *NodeTokenListCommand) Name() string {
+	return "node token list"
+}
+
+// Synopsis :
+func (c *NodeTokenListCommand) Synopsis() string {
+	return "List join tokens"
+}
+
+// Run :
+func (c *NodeTokenListCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago node token list --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	var tokens []*structs.JoinTokenListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.JoinTokens().List(q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving join tokens: %s", err))
+			return 1
+		}
+		tokens = append(tokens, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
+	}
+
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	c.UI.Output(c.formatTokenList(tokens))
+
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
+	return 0
+}
+
+// Help :
+func (c *NodeTokenListCommand) Help() string {
+	h := `
+Usage: drago node token list [options]
+
+  List existing join tokens.
+
+  If ACLs are enabled, this option requires a token with the 'node:list' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Node Token List Options:
+
+  --json
+    Enable JSON output.
+
+Filtering and Pagination Options:
+` + ListingOptions() + `
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *NodeTokenListCommand) formatTokenList(tokens []*structs.JoinTokenListStub) string {
+
+	var b bytes.Buffer
+	ftokens := []interface{}{}
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		for _, token := range tokens {
+			ftokens = append(ftokens, map[string]interface{}{
+				"id":        token.ID,
+				"name":      token.Name,
+				"uses":      token.Uses,
+				"maxUses":   token.MaxUses,
+				"networks":  token.Networks,
+				"expiresAt": token.ExpiresAt,
+			})
+		}
+		if err := enc.Encode(ftokens); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("TOKEN ID", "NAME", "USES", "NETWORKS", "EXPIRES AT").WithWriter(&b)
+		for _, token := range tokens {
+			uses := fmt.Sprintf("%d/%d", token.Uses, token.MaxUses)
+			if token.MaxUses == 0 {
+				uses = fmt.Sprintf("%d/unlimited", token.Uses)
+			}
+			expires := "never"
+			if !token.ExpiresAt.IsZero() {
+				expires = token.ExpiresAt.Format(time.RFC3339)
+			}
+			tbl.AddRow(token.ID, token.Name, uses, strings.Join(token.Networks, ","), expires)
+		}
+		tbl.Print()
+	}
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// OperatorCommand :
+type OperatorCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *OperatorCommand) Name() string {
+	return "operator"
+}
+
+// Synopsis :
+func (c *OperatorCommand) Synopsis() string {
+	return "Interact with the operator API"
+}
+
+// Run :
+func (c *OperatorCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *OperatorCommand) Help() string {
+	h := `
+Usage: drago operator <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with the operator API, which
+  allows performing maintenance tasks on the servers, such as saving and
+  restoring snapshots of their state.
+    
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
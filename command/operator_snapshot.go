@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// OperatorSnapshotCommand :
+type OperatorSnapshotCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *OperatorSnapshotCommand) Name() string {
+	return "operator snapshot"
+}
+
+// Synopsis :
+func (c *OperatorSnapshotCommand) Synopsis() string {
+	return "Save and restore snapshots of the server state"
+}
+
+// Run :
+func (c *OperatorSnapshotCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *OperatorSnapshotCommand) Help() string {
+	h := `
+Usage: drago operator snapshot <subcommand> [options] [args]
+
+  This command groups subcommands for saving and restoring snapshots of the
+  server state. Snapshots contain all ACL tokens, policies, nodes, networks,
+  interfaces and connections, including their secrets, and should therefore
+  be stored securely.
+    
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
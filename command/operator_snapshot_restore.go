@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// OperatorSnapshotRestoreCommand :
+type OperatorSnapshotRestoreCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *OperatorSnapshotRestoreCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *OperatorSnapshotRestoreCommand) Name() string {
+	return "operator snapshot restore"
+}
+
+// Synopsis :
+func (c *OperatorSnapshotRestoreCommand) Synopsis() string {
+	return "Restore the server state from a snapshot"
+}
+
+// Run :
+func (c *OperatorSnapshotRestoreCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <file>")
+		c.UI.Error(`For additional help, try 'drago operator snapshot restore --help'`)
+		return 1
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error opening snapshot: %s", err))
+		return 1
+	}
+	defer f.Close()
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	createdAt, err := api.Operator().SnapshotRestore(f)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error restoring snapshot: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Restored snapshot created at %s", createdAt.Format(time.RFC3339)))
+
+	return 0
+}
+
+// Help :
+func (c *OperatorSnapshotRestoreCommand) Help() string {
+	h := `
+Usage: drago operator snapshot restore [options] <file>
+
+  Restore the server state from a snapshot previously saved with 'drago operator
+  snapshot save'. All ACL tokens, policies, nodes, join tokens, networks,
+  interfaces and connections in the server are replaced by those in the
+  snapshot. The snapshot is verified before any changes are made.
+
+  If ACLs are enabled, this option requires a token with the 'operator:write' capability.
+
+General Options:
+` + GlobalOptions()
+
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	snapshot "github.com/seashell/drago/drago/state/snapshot"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// OperatorSnapshotSaveCommand :
+type OperatorSnapshotSaveCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *OperatorSnapshotSaveCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *OperatorSnapshotSaveCommand) Name() string {
+	return "operator snapshot save"
+}
+
+// Synopsis :
+func (c *OperatorSnapshotSaveCommand) Synopsis() string {
+	return "Save a snapshot of the server state"
+}
+
+// Run :
+func (c *OperatorSnapshotSaveCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <file>")
+		c.UI.Error(`For additional help, try 'drago operator snapshot save --help'`)
+		return 1
+	}
+
+	file := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	buf := &bytes.Buffer{}
+	if err := api.Operator().SnapshotSave(buf); err != nil {
+		c.UI.Error(fmt.Sprintf("Error saving snapshot: %s", err))
+		return 1
+	}
+
+	// Verify the snapshot before writing it, so that a corrupted
+	// one never replaces a previously saved snapshot.
+	meta, _, err := snapshot.Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error verifying snapshot: %s", err))
+		return 1
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing snapshot: %s", err))
+		return 1
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		c.UI.Error(fmt.Sprintf("Error writing snapshot: %s", err))
+		return 1
+	}
+	if err := tmp.Close(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing snapshot: %s", err))
+		return 1
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing snapshot: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Saved snapshot to %s (created at %s)", file, meta.CreatedAt.Format(time.RFC3339)))
+
+	return 0
+}
+
+// Help :
+func (c *OperatorSnapshotSaveCommand) Help() string {
+	h := `
+Usage: drago operator snapshot save [options] <file>
+
+  Save a snapshot of the server state to a file. The snapshot is a versioned
+  and checksummed archive containing all ACL tokens, policies, nodes, join
+  tokens, networks, interfaces and connections. Audit entries are not included.
+
+  Snapshots contain secrets, such as ACL token secrets and node private keys,
+  and should therefore be stored securely.
+
+  If ACLs are enabled, this option requires a token with the 'operator:write' capability.
+
+General Options:
+` + GlobalOptions()
+
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// PeerCommand :
+type PeerCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *PeerCommand) Name() string {
+	return "peer"
+}
+
+// Synopsis :
+func (c *PeerCommand) Synopsis() string {
+	return "Interact with external peers"
+}
+
+// Run :
+func (c *PeerCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *PeerCommand) Help() string {
+	h := `
+Usage: drago peer <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with external peers, i.e.
+  devices which do not run the Drago agent, such as phones, laptops or routers.
+  The configuration of external peers is generated by the server, and can be
+  imported into any WireGuard client.
+    
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
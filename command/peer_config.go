@@ -0,0 +1,156 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// peerConfigQRCodeSize is the size, in pixels, of the PNG QR codes written.
+	peerConfigQRCodeSize = 512
+)
+
+// PeerConfigCommand :
+type PeerConfigCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	qr  bool
+	png string
+	out string
+}
+
+func (c *PeerConfigCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.qr, "qr", false, "")
+	flags.StringVar(&c.png, "png", "", "")
+	flags.StringVar(&c.out, "out", "", "")
+
+	return flags
+}
+
+// Name :
+func (c *PeerConfigCommand) Name() string {
+	return "peer config"
+}
+
+// Synopsis :
+func (c *PeerConfigCommand) Synopsis() string {
+	return "Export the configuration of an external peer"
+}
+
+// Run :
+func (c *PeerConfigCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <peer_id>")
+		c.UI.Error(`For additional help, try 'drago peer config --help'`)
+		return 1
+	}
+
+	id := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	config, err := api.Peers().Config(id)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error retrieving peer configuration: %s", err))
+		return 1
+	}
+
+	// The configuration contains the private key of the peer,
+	// so files are only made readable by the current user.
+	if c.out != "" {
+		if err := ioutil.WriteFile(c.out, []byte(config), 0600); err != nil {
+			c.UI.Error(fmt.Sprintf("Error writing configuration: %s", err))
+			return 1
+		}
+	}
+
+	if c.png != "" {
+		qr, err := qrcode.New(config, qrcode.Medium)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error generating QR code: %s", err))
+			return 1
+		}
+		png, err := qr.PNG(peerConfigQRCodeSize)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error generating QR code: %s", err))
+			return 1
+		}
+		if err := ioutil.WriteFile(c.png, png, 0600); err != nil {
+			c.UI.Error(fmt.Sprintf("Error writing QR code: %s", err))
+			return 1
+		}
+	}
+
+	if c.qr {
+		qr, err := qrcode.New(config, qrcode.Low)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error generating QR code: %s", err))
+			return 1
+		}
+		c.UI.Output(qr.ToSmallString(false))
+	} else if c.out == "" && c.png == "" {
+		c.UI.Output(strings.TrimSpace(config))
+	}
+
+	return 0
+}
+
+// Help :
+func (c *PeerConfigCommand) Help() string {
+	h := `
+Usage: drago peer config <peer_id> [options]
+
+  Export the configuration of an external peer in the wg-quick format, which
+  can be imported into any WireGuard client. The configuration reflects the
+  current connections of the peer, so it must be exported and imported again
+  whenever they change.
+
+  The configuration contains the private key of the peer, and should therefore
+  be handled with care.
+
+  If ACLs are enabled, this option requires a token with the 'interface:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Peer Config Options:
+
+  --qr
+    Print the configuration as a QR code in the terminal, which can be
+    scanned by the WireGuard mobile apps.
+
+  --png=<file>
+    Write the configuration as a QR code to a PNG file.
+
+  --out=<file>
+    Write the configuration to a file, e.g. wg0.conf, instead of printing it.
+
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,190 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// PeerCreateCommand :
+type PeerCreateCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	name      string
+	address   string
+	endpoint  string
+	dns       []string
+	connect   []string
+	keepalive int
+}
+
+func (c *PeerCreateCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.StringVar(&c.name, "name", "", "")
+	flags.StringVar(&c.address, "address", "", "")
+	flags.StringVar(&c.endpoint, "endpoint", "", "")
+	flags.StringSliceVar(&c.dns, "dns", nil, "")
+	flags.StringArrayVar(&c.connect, "connect", nil, "")
+	flags.IntVar(&c.keepalive, "keepalive", -1, "")
+
+	return flags
+}
+
+// Name :
+func (c *PeerCreateCommand) Name() string {
+	return "peer create"
+}
+
+// Synopsis :
+func (c *PeerCreateCommand) Synopsis() string {
+	return "Create a new external peer"
+}
+
+// Run :
+func (c *PeerCreateCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <network>")
+		c.UI.Error(`For additional help, try 'drago peer create --help'`)
+		return 1
+	}
+
+	networkName := args[0]
+	networkID := ""
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	// Resolve network name
+	networks, _, err := api.Networks().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error getting networks: %s", err))
+		return 1
+	}
+
+	for _, n := range networks {
+		if n.Name == networkName {
+			networkID = n.ID
+			break
+		}
+	}
+
+	if networkID == "" {
+		c.UI.Error("Error: network not found")
+		return 1
+	}
+
+	req := &structs.ExternalPeerCreateRequest{
+		NetworkID: networkID,
+		Name:      c.name,
+		DNS:       c.dns,
+		ConnectTo: []string{},
+	}
+
+	if c.address != "" {
+		req.Address = &c.address
+	}
+	if c.endpoint != "" {
+		req.Endpoint = &c.endpoint
+	}
+	if c.keepalive >= 0 {
+		req.PersistentKeepalive = &c.keepalive
+	}
+
+	// Find the interfaces of the nodes to connect the peer to
+	for _, nodeID := range c.connect {
+
+		filters := map[string][]string{
+			"node":    {nodeID},
+			"network": {networkID},
+		}
+
+		interfaces, _, err := api.Interfaces().List(filters, nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error getting node interfaces: %s", err))
+			return 1
+		}
+
+		if len(interfaces) == 0 {
+			c.UI.Error(fmt.Sprintf("Error: node %s does not have any interface in network %s", nodeID, networkName))
+			return 1
+		}
+
+		req.ConnectTo = append(req.ConnectTo, interfaces[0].ID)
+	}
+
+	id, err := api.Peers().Create(req)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating peer: %s", err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Created peer %s", id))
+	c.UI.Output(fmt.Sprintf("Export its configuration with 'drago peer config %s'", id))
+
+	return 0
+}
+
+// Help :
+func (c *PeerCreateCommand) Help() string {
+	h := `
+Usage: drago peer create <network> [options]
+
+  Create a new external peer in a network. The server generates the key pair of
+  the peer and allocates its address, after which its configuration can be
+  exported with 'drago peer config'.
+
+  If ACLs are enabled, this option requires a token with the 'interface:write'
+  capability, as well as 'connection:write' if the --connect flag is used.
+
+General Options:
+` + GlobalOptions() + `
+
+Peer Create Options:
+
+  --name=<name>
+    Name of the peer, used for identifying it.
+
+  --address=<address>
+    Address of the peer, in CIDR notation. Defaults to an address
+    allocated from the network address range.
+
+  --endpoint=<host:port>
+    Static endpoint of the peer, set on the peers it is connected to.
+    Only needed if the peer is reachable at a fixed address.
+
+  --dns=<address>
+    DNS server set in the configuration of the peer. Can be repeated,
+    or contain comma-separated values.
+
+  --connect=<node_id>
+    Connect the peer to the interface of a node in the network. Can be repeated.
+
+  --keepalive=<seconds>
+    Time interval between persistent keepalive packets on the connections
+    created. Defaults to 25, as external peers are usually behind a NAT.
+
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// PeerDeleteCommand :
+type PeerDeleteCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *PeerDeleteCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *PeerDeleteCommand) Name() string {
+	return "peer delete"
+}
+
+// Synopsis :
+func (c *PeerDeleteCommand) Synopsis() string {
+	return "Delete an external peer"
+}
+
+// Run :
+func (c *PeerDeleteCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("This command takes one argument: <peer_id>")
+		c.UI.Error(`For additional help, try 'drago peer delete --help'`)
+		return 1
+	}
+
+	id := args[0]
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	err = api.Peers().Delete(id)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error deleting peer: %s", err))
+		return 1
+	}
+
+	c.UI.Output("Deleted!")
+
+	return 0
+}
+
+// Help :
+func (c *PeerDeleteCommand) Help() string {
+	h := `
+Usage: drago peer delete <peer_id> [options]
+
+  Delete an external peer, along with its connections. Its address is released.
+
+  If ACLs are enabled, this option requires a token with the 'interface:write' capability.
+
+General Options:
+` + GlobalOptions() + `
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,182 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	table "github.com/rodaine/table"
+	structs "github.com/seashell/drago/drago/structs"
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// PeerListCommand :
+type PeerListCommand struct {
+	UI cli.UI
+	Command
+
+	// Parsed flags
+	json    bool
+	network string
+	listing listFlags
+}
+
+func (c *PeerListCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	// General options
+	flags.BoolVar(&c.json, "json", false, "")
+	flags.StringVar(&c.network, "network", "", "")
+
+	c.listing.addFlags(flags)
+
+	return flags
+}
+
+// Name :
+func (c *PeerListCommand) Name() string {
+	return "peer list"
+}
+
+// Synopsis :
+func (c *PeerListCommand) Synopsis() string {
+	return "Display a list of external peers"
+}
+
+// Run :
+func (c *PeerListCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago peer list --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	filters := map[string][]string{}
+
+	if len(c.network) > 0 {
+		networks, _, err := api.Networks().List(nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving networks: %s", err))
+			return 1
+		}
+
+		networkID := ""
+		for _, network := range networks {
+			if c.network == network.Name {
+				networkID = network.ID
+				break
+			}
+		}
+
+		if networkID == "" {
+			c.UI.Error("Error: network not found")
+			return 1
+		}
+
+		filters["network"] = []string{networkID}
+	}
+
+	var peers []*structs.InterfaceListStub
+
+	q := c.listing.queryOptions()
+	for {
+		items, meta, err := api.Peers().List(filters, q)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error retrieving peers: %s", err))
+			return 1
+		}
+		peers = append(peers, items...)
+		if !c.listing.nextPage(q, meta) {
+			break
+		}
+	}
+
+	if len(peers) == 0 {
+		return 0
+	}
+
+	c.UI.Output(c.formatPeerList(peers))
+
+	if !c.json {
+		c.listing.warnNextToken(c.UI, q.NextToken)
+	}
+
+	return 0
+}
+
+// Help :
+func (c *PeerListCommand) Help() string {
+	h := `
+Usage: drago peer list [options]
+
+  List external peers.
+
+  If ACLs are enabled, this option requires a token with the 'interface:read' capability.
+
+General Options:
+` + GlobalOptions() + `
+
+Peer List Options:
+
+  --json
+    Enable JSON output.
+
+  --network=<network>
+    Filter results by network.
+
+Filtering and Pagination Options:
+` + ListingOptions() + `
+`
+	return strings.TrimSpace(h)
+}
+
+func (c *PeerListCommand) formatPeerList(peers []*structs.InterfaceListStub) string {
+
+	var b bytes.Buffer
+	fpeers := []interface{}{}
+
+	if c.json {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "    ")
+		for _, peer := range peers {
+			fpeers = append(fpeers, map[string]interface{}{
+				"id":          peer.ID,
+				"name":        valueOrPlaceholder(peer.Name, ""),
+				"address":     valueOrPlaceholder(peer.Address, "N/A"),
+				"network":     peer.NetworkID,
+				"connections": peer.ConnectionsCount,
+			})
+		}
+		if err := enc.Encode(fpeers); err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting JSON output: %s", err))
+		}
+	} else {
+		tbl := table.New("PEER ID", "NAME", "ADDRESS", "NETWORK ID", "CONNECTIONS").WithWriter(&b)
+		for _, peer := range peers {
+			tbl.AddRow(peer.ID, valueOrPlaceholder(peer.Name, "N/A"), valueOrPlaceholder(peer.Address, "N/A"), peer.NetworkID, peer.ConnectionsCount)
+		}
+		tbl.Print()
+	}
+
+	return b.String()
+}
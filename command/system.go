@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+)
+
+// SystemCommand :
+type SystemCommand struct {
+	UI cli.UI
+}
+
+// Name :
+func (c *SystemCommand) Name() string {
+	return "system"
+}
+
+// Synopsis :
+func (c *SystemCommand) Synopsis() string {
+	return "Interact with the system API"
+}
+
+// Run :
+func (c *SystemCommand) Run(ctx context.Context, args []string) int {
+	return cli.CommandReturnCodeHelp
+}
+
+// Help :
+func (c *SystemCommand) Help() string {
+	h := `
+Usage: drago system <subcommand> [options] [args]
+
+  This command groups subcommands for interacting with the system API, which
+  allows performing system-wide maintenance operations.
+    
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cli "github.com/seashell/drago/pkg/cli"
+	"github.com/spf13/pflag"
+)
+
+// SystemGCCommand :
+type SystemGCCommand struct {
+	UI cli.UI
+	Command
+}
+
+func (c *SystemGCCommand) FlagSet() *pflag.FlagSet {
+
+	flags := c.Command.FlagSet(c.Name())
+	flags.Usage = func() { c.UI.Output("\n" + c.Help() + "\n") }
+
+	return flags
+}
+
+// Name :
+func (c *SystemGCCommand) Name() string {
+	return "system gc"
+}
+
+// Synopsis :
+func (c *SystemGCCommand) Synopsis() string {
+	return "Run the system garbage collection process"
+}
+
+// Run :
+func (c *SystemGCCommand) Run(ctx context.Context, args []string) int {
+
+	flags := c.FlagSet()
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 0 {
+		c.UI.Error("This command takes no arguments")
+		c.UI.Error(`For additional help, try 'drago system gc --help'`)
+		return 1
+	}
+
+	// Get the HTTP client
+	api, err := c.Command.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting up API client: %s", err))
+		return 1
+	}
+
+	ids, err := api.System().GarbageCollect()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error running garbage collection: %s", err))
+		return 1
+	}
+
+	for _, id := range ids {
+		c.UI.Output(fmt.Sprintf("Removed node %s", id))
+	}
+
+	c.UI.Output(fmt.Sprintf("Garbage collection finished, %d nodes removed", len(ids)))
+
+	return 0
+}
+
+// Help :
+func (c *SystemGCCommand) Help() string {
+	h := `
+Usage: drago system gc [options]
+
+  Run the system garbage collection process, removing nodes which have been
+  down for longer than the threshold configured in the server, along with
+  their interfaces and connections. Garbage collection is also performed
+  periodically by the server.
+
+  If ACLs are enabled, this option requires a token with the 'node:write' capability.
+
+General Options:
+` + GlobalOptions()
+
+	return strings.TrimSpace(h)
+}
@@ -3,8 +3,10 @@ package command
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	api "github.com/seashell/drago/api"
+	structs "github.com/seashell/drago/drago/structs"
 )
 
 // Returns the node ID of the local agent, in case it is a client.
@@ -36,6 +38,37 @@ func valueOrPlaceholder(s *string, p string) string {
 	return p
 }
 
+// Returns the scheduling eligibility of a node, treating nodes
+// registered before eligibility was tracked as eligible.
+func nodeEligibility(s string) string {
+	if s == "" {
+		return structs.NodeSchedulingEligible
+	}
+	return s
+}
+
+// Returns a human-readable amount of bytes, e.g. "1.5 MiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Returns the time elapsed since t, e.g. "2m5s ago", or "never" if t is zero.
+func formatTimeAgo(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s ago", time.Since(t).Round(time.Second))
+}
+
 // TODO: improve how we clean JSON strings
 func cleanJSONString(s string) string {
 
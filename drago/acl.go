@@ -21,21 +21,23 @@ type ACLService struct {
 	config      *Config
 	logger      log.Logger
 	state       state.Repository
+	auditor     *Auditor
 	authHandler auth.AuthorizationHandler
 }
 
 // NewACLService :
-func NewACLService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) *ACLService {
+func NewACLService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *ACLService {
 	return &ACLService{
 		config:      config,
 		logger:      logger,
 		state:       state,
+		auditor:     auditor,
 		authHandler: authHandler,
 	}
 }
 
 // BootstrapACL :
-func (s *ACLService) BootstrapACL(args *structs.ACLBootstrapRequest, out *structs.ACLTokenUpsertResponse) error {
+func (s *ACLService) BootstrapACL(args *structs.ACLBootstrapRequest, out *structs.ACLTokenUpsertResponse) (err error) {
 
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
@@ -43,6 +45,13 @@ func (s *ACLService) BootstrapACL(args *structs.ACLBootstrapRequest, out *struct
 
 	ctx := context.TODO()
 
+	defer s.auditor.Begin(ctx, "ACL.BootstrapACL", args.AuthToken, "", "token", func() []string {
+		if out.ACLToken == nil {
+			return nil
+		}
+		return []string{out.ACLToken.ID}
+	})(&err)
+
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
 	}
@@ -60,7 +69,7 @@ func (s *ACLService) BootstrapACL(args *structs.ACLBootstrapRequest, out *struct
 		UpdatedAt: time.Now(),
 	}
 
-	err := s.state.UpsertACLToken(ctx, t)
+	err = s.state.UpsertACLToken(ctx, t)
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 )
 
@@ -38,7 +39,7 @@ func (s *ACLService) GetPolicy(args *structs.ACLPolicySpecificRequest, out *stru
 	return nil
 }
 
-func (s *ACLService) UpsertPolicy(args *structs.ACLPolicyUpsertRequest, out *structs.GenericResponse) error {
+func (s *ACLService) UpsertPolicy(args *structs.ACLPolicyUpsertRequest, out *structs.GenericResponse) (err error) {
 
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
@@ -46,6 +47,8 @@ func (s *ACLService) UpsertPolicy(args *structs.ACLPolicyUpsertRequest, out *str
 
 	ctx := context.TODO()
 
+	defer s.auditor.Begin(ctx, "ACL.UpsertPolicy", args.AuthToken, "", "policy", auditID(&args.ACLPolicy.Name))(&err)
+
 	// Check if authorized
 	if err := s.authHandler.Authorize(ctx, args.AuthToken, "policy", "", ACLPolicyWrite); err != nil {
 		return structs.ErrPermissionDenied
@@ -53,7 +56,7 @@ func (s *ACLService) UpsertPolicy(args *structs.ACLPolicyUpsertRequest, out *str
 
 	p := args.ACLPolicy
 
-	err := p.Validate()
+	err = p.Validate()
 	if err != nil {
 		return structs.NewError(structs.ErrInvalidInput, err)
 	}
@@ -75,7 +78,7 @@ func (s *ACLService) UpsertPolicy(args *structs.ACLPolicyUpsertRequest, out *str
 	return nil
 }
 
-func (s *ACLService) DeletePolicies(args *structs.ACLPolicyDeleteRequest, out *structs.GenericResponse) error {
+func (s *ACLService) DeletePolicies(args *structs.ACLPolicyDeleteRequest, out *structs.GenericResponse) (err error) {
 
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
@@ -83,12 +86,14 @@ func (s *ACLService) DeletePolicies(args *structs.ACLPolicyDeleteRequest, out *s
 
 	ctx := context.TODO()
 
+	defer s.auditor.Begin(ctx, "ACL.DeletePolicies", args.AuthToken, "", "policy", func() []string { return args.Names })(&err)
+
 	// Check if authorized
 	if err := s.authHandler.Authorize(ctx, args.AuthToken, "policy", "", ACLPolicyWrite); err != nil {
 		return structs.ErrPermissionDenied
 	}
 
-	err := s.state.DeleteACLPolicies(ctx, args.Names)
+	err = s.state.DeleteACLPolicies(ctx, args.Names)
 	if err != nil {
 		return structs.ErrInternal
 	}
@@ -109,15 +114,27 @@ func (s *ACLService) ListPolicies(args *structs.ACLPolicyListRequest, out *struc
 		return structs.ErrPermissionDenied
 	}
 
-	policies, err := s.state.ACLPolicies(ctx)
+	out.Items = nil
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		policies, next, err := s.state.ACLPoliciesPage(ctx, opts)
+		if err != nil {
+			return "", structs.ErrInternal
+		}
+
+		for _, p := range policies {
+			out.Items = append(out.Items, p.Stub())
+		}
+
+		return next, nil
+	})
 	if err != nil {
-		return structs.ErrInternal
+		return err
 	}
 
-	out.Items = nil
-
-	for _, p := range policies {
-		out.Items = append(out.Items, p.Stub())
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
 	}
 
 	return nil
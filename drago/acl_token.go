@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 	uuid "github.com/seashell/drago/pkg/uuid"
 )
@@ -39,7 +40,7 @@ func (s *ACLService) GetToken(args *structs.ACLTokenSpecificRequest, out *struct
 }
 
 // UpsertToken creates or updates a new Token entity
-func (s *ACLService) UpsertToken(args *structs.ACLTokenUpsertRequest, out *structs.ACLTokenUpsertResponse) error {
+func (s *ACLService) UpsertToken(args *structs.ACLTokenUpsertRequest, out *structs.ACLTokenUpsertResponse) (err error) {
 
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
@@ -47,6 +48,8 @@ func (s *ACLService) UpsertToken(args *structs.ACLTokenUpsertRequest, out *struc
 
 	ctx := context.TODO()
 
+	defer s.auditor.Begin(ctx, "ACL.UpsertToken", args.AuthToken, "", "token", auditID(&args.ACLToken.ID))(&err)
+
 	// Check if authorized
 	if err := s.authHandler.Authorize(ctx, args.AuthToken, "token", "", ACLTokenWrite); err != nil {
 		return structs.ErrPermissionDenied
@@ -54,7 +57,7 @@ func (s *ACLService) UpsertToken(args *structs.ACLTokenUpsertRequest, out *struc
 
 	t := args.ACLToken
 
-	err := t.Validate()
+	err = t.Validate()
 	if err != nil {
 		return structs.ErrInvalidInput
 	}
@@ -85,7 +88,7 @@ func (s *ACLService) UpsertToken(args *structs.ACLTokenUpsertRequest, out *struc
 }
 
 // DeleteToken deletes a token entity from the repository
-func (s *ACLService) DeleteToken(args *structs.ACLTokenDeleteRequest, out *structs.GenericResponse) error {
+func (s *ACLService) DeleteToken(args *structs.ACLTokenDeleteRequest, out *structs.GenericResponse) (err error) {
 
 	if !s.config.ACL.Enabled {
 		return structs.ErrACLDisabled
@@ -93,12 +96,14 @@ func (s *ACLService) DeleteToken(args *structs.ACLTokenDeleteRequest, out *struc
 
 	ctx := context.TODO()
 
+	defer s.auditor.Begin(ctx, "ACL.DeleteToken", args.AuthToken, "", "token", func() []string { return args.ACLTokenIDs })(&err)
+
 	// Check if authorized
 	if err := s.authHandler.Authorize(ctx, args.AuthToken, "token", "", ACLTokenWrite); err != nil {
 		return structs.ErrPermissionDenied
 	}
 
-	err := s.state.DeleteACLTokens(ctx, args.ACLTokenIDs)
+	err = s.state.DeleteACLTokens(ctx, args.ACLTokenIDs)
 	if err != nil {
 		return structs.ErrInternal
 	}
@@ -120,15 +125,27 @@ func (s *ACLService) ListTokens(args *structs.ACLTokenListRequest, out *structs.
 		return structs.ErrPermissionDenied
 	}
 
-	tokens, err := s.state.ACLTokens(ctx)
+	out.Items = nil
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		tokens, next, err := s.state.ACLTokensPage(ctx, opts)
+		if err != nil {
+			return "", structs.ErrInternal
+		}
+
+		for _, t := range tokens {
+			out.Items = append(out.Items, t.Stub())
+		}
+
+		return next, nil
+	})
 	if err != nil {
-		return structs.ErrInternal
+		return err
 	}
 
-	out.Items = nil
-
-	for _, t := range tokens {
-		out.Items = append(out.Items, t.Stub())
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,137 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	cidr "github.com/seashell/drago/pkg/cidr"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+// allowedIP is the value stored in the trie of AllowedIPs of an interface.
+type allowedIP struct {
+	connectionID string
+	prefix       string
+	normalized   string
+	managed      bool
+}
+
+// allowedIPsConflicts returns the conflicts between the AllowedIPs of the connections of an
+// interface, i.e. prefixes routed through different peers which overlap. Connections are
+// processed in order of ID, and each conflict is reported once, on the connection processed
+// last. Overlaps between the AllowedIPs of a same connection are not conflicts, as traffic
+// is routed through the same peer anyway, and neither are partial overlaps between managed
+// connections, which the network topology creates on purpose (e.g. spokes routing the whole
+// address range through a hub, and the address of another hub through that hub). Invalid
+// prefixes are ignored.
+func allowedIPsConflicts(interfaceID string, connections []*structs.Connection) []*structs.AllowedIPsConflict {
+
+	sorted := append([]*structs.Connection{}, connections...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	trie := cidr.NewTrie()
+	out := []*structs.AllowedIPsConflict{}
+
+	for _, c := range sorted {
+
+		settings := c.PeerSettingsByInterfaceID(interfaceID)
+		if settings == nil || settings.RoutingRules == nil {
+			continue
+		}
+
+		entries := []*cidr.Entry{}
+
+		for _, s := range settings.RoutingRules.AllowedIPs {
+
+			prefix, err := cidr.Parse(s)
+			if err != nil {
+				continue
+			}
+
+			for _, e := range trie.Overlapping(prefix) {
+				other := e.Value.(*allowedIP)
+				identical := other.normalized == prefix.String()
+				if !identical && c.Managed && other.managed {
+					continue
+				}
+				out = append(out, &structs.AllowedIPsConflict{
+					InterfaceID:             interfaceID,
+					ConnectionID:            c.ID,
+					AllowedIP:               s,
+					ConflictingConnectionID: other.connectionID,
+					ConflictingAllowedIP:    other.prefix,
+					Identical:               identical,
+				})
+			}
+
+			entries = append(entries, &cidr.Entry{Prefix: prefix, Value: &allowedIP{connectionID: c.ID, prefix: s, normalized: prefix.String(), managed: c.Managed}})
+		}
+
+		// Insert the prefixes of the connection only after checking all
+		// of them, so that they are not compared against each other.
+		for _, e := range entries {
+			trie.Insert(e.Prefix, e.Value)
+		}
+	}
+
+	return out
+}
+
+// checkAllowedIPs validates the AllowedIPs of a connection, and makes sure none of them is
+// identical to an AllowedIP of the other connections of the interfaces it connects. Partial
+// overlaps are only logged, since the most specific prefix takes precedence in that case.
+func checkAllowedIPs(ctx context.Context, repo state.Repository, logger log.Logger, c *structs.Connection) error {
+
+	for _, settings := range c.PeerSettings {
+
+		if settings.RoutingRules == nil {
+			continue
+		}
+
+		for _, s := range settings.RoutingRules.AllowedIPs {
+			if _, err := cidr.Parse(s); err != nil {
+				return structs.NewInvalidInputError(fmt.Sprintf("Invalid AllowedIP %s", s))
+			}
+		}
+
+		existing, err := repo.ConnectionsByInterfaceID(ctx, settings.InterfaceID)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		// Check the connection as if it was already upserted
+		connections := []*structs.Connection{c}
+		for _, other := range existing {
+			if other.ID != c.ID {
+				connections = append(connections, other)
+			}
+		}
+
+		for _, conflict := range allowedIPsConflicts(settings.InterfaceID, connections) {
+			if conflict.ConnectionID != c.ID && conflict.ConflictingConnectionID != c.ID {
+				continue
+			}
+			// Report the conflict from the perspective of the connection being upserted
+			if conflict.ConnectionID != c.ID {
+				conflict = &structs.AllowedIPsConflict{
+					InterfaceID:             conflict.InterfaceID,
+					ConnectionID:            conflict.ConflictingConnectionID,
+					AllowedIP:               conflict.ConflictingAllowedIP,
+					ConflictingConnectionID: conflict.ConnectionID,
+					ConflictingAllowedIP:    conflict.AllowedIP,
+					Identical:               conflict.Identical,
+				}
+			}
+			if !conflict.Identical {
+				logger.Warnf("%s", conflict.String())
+				continue
+			}
+			return structs.NewInvalidInputError(conflict.String())
+		}
+	}
+
+	return nil
+}
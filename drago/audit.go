@@ -0,0 +1,399 @@
+package drago
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+	paginator "github.com/seashell/drago/pkg/paginator"
+	uuid "github.com/seashell/drago/pkg/uuid"
+)
+
+const (
+	AuditList = "list"
+	AuditRead = "read"
+)
+
+// serverAuditTokenName is recorded as the token name in audit entries
+// of operations performed by the server on its own.
+const serverAuditTokenName = "server"
+
+// Fields which change on every write, and are thus not recorded in audit entries.
+var auditIgnoredFields = map[string]struct{}{
+	"ModifyIndex": {},
+	"UpdatedAt":   {},
+}
+
+// Auditor records an audit entry for every write operation performed through
+// the RPC services, storing it in the state and optionally mirroring it to a
+// file in the JSON lines format.
+type Auditor struct {
+	config *Config
+	logger log.Logger
+	state  state.Repository
+
+	file     *os.File
+	fileLock sync.Mutex
+}
+
+// NewAuditor ...
+func NewAuditor(config *Config, logger log.Logger, state state.Repository) (*Auditor, error) {
+
+	a := &Auditor{
+		config: config,
+		logger: logger,
+		state:  state,
+	}
+
+	if config.AuditLogFile != "" {
+		f, err := os.OpenFile(config.AuditLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("could not open audit log file: %v", err)
+		}
+		a.file = f
+	}
+
+	return a, nil
+}
+
+// Begin takes a snapshot of the resources targeted by an operation, and returns a function
+// which records the operation once it completes, along with the changes it made to them.
+// The returned function takes a pointer to the error returned by the operation, and is meant
+// to be deferred. Since the IDs of resources being created are only known once the operation
+// completes, they are passed as a function, which is called before and after the operation.
+// The namespace targeted by the operation is recorded in ns, which is empty for operations on
+// resources not belonging to namespaces, such as ACL tokens, so that they are recorded in the
+// default namespace.
+func (a *Auditor) Begin(ctx context.Context, op, secret, ns, resourceType string, ids func() []string) func(*error) {
+
+	if a == nil {
+		return func(*error) {}
+	}
+
+	before := a.snapshots(ctx, resourceType, ids())
+
+	return func(errp *error) {
+		tokenID, tokenName := a.resolveToken(ctx, secret)
+		a.record(ctx, op, tokenID, tokenName, ns, resourceType, ids(), before, *errp)
+	}
+}
+
+// BeginServer is like Begin, but for operations performed by the server on its own,
+// e.g. periodic garbage collection, which are recorded without any ACL token.
+func (a *Auditor) BeginServer(ctx context.Context, op, ns, resourceType string, ids func() []string) func(*error) {
+
+	if a == nil {
+		return func(*error) {}
+	}
+
+	before := a.snapshots(ctx, resourceType, ids())
+
+	return func(errp *error) {
+		a.record(ctx, op, "", serverAuditTokenName, ns, resourceType, ids(), before, *errp)
+	}
+}
+
+func (a *Auditor) record(ctx context.Context, op, tokenID, tokenName, ns, resourceType string, ids []string, before map[string]interface{}, opErr error) {
+
+	if len(ids) == 0 {
+		ids = []string{""}
+	}
+
+	now := time.Now()
+
+	for _, id := range ids {
+
+		e := &structs.AuditEntry{
+			ID:           uuid.Generate(),
+			Timestamp:    now,
+			TokenID:      tokenID,
+			TokenName:    tokenName,
+			Namespace:    structs.NamespaceOrDefault(ns),
+			Operation:    op,
+			ResourceType: resourceType,
+			ResourceID:   id,
+		}
+
+		if opErr != nil {
+			e.Error = opErr.Error()
+		} else if id != "" {
+			e.Changes = auditChanges(before[id], a.snapshot(ctx, resourceType, id))
+		}
+
+		if err := a.state.UpsertAuditEntry(ctx, e); err != nil {
+			a.logger.Warnf("could not store audit entry for operation %s: %v", op, err)
+		}
+
+		a.mirror(e)
+	}
+}
+
+// mirror appends an entry to the audit log file, if one is configured.
+func (a *Auditor) mirror(e *structs.AuditEntry) {
+
+	if a.file == nil {
+		return
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		a.logger.Warnf("could not encode audit entry: %v", err)
+		return
+	}
+
+	a.fileLock.Lock()
+	defer a.fileLock.Unlock()
+
+	if _, err := a.file.Write(append(b, '\n')); err != nil {
+		a.logger.Warnf("could not write to audit log file: %v", err)
+	}
+}
+
+// resolveToken returns the ID and name of the ACL token whose secret is passed
+// as argument, so that the secret itself is never recorded.
+func (a *Auditor) resolveToken(ctx context.Context, secret string) (string, string) {
+
+	if secret == "" {
+		return AnonymousACLToken.ID, AnonymousACLToken.Name
+	}
+
+	t, err := a.state.ACLTokenBySecret(ctx, secret)
+	if err != nil || t == nil {
+		return "", "unknown"
+	}
+
+	return t.ID, t.Name
+}
+
+// snapshots returns the snapshots of the resources whose IDs are passed, indexed by ID.
+func (a *Auditor) snapshots(ctx context.Context, resourceType string, ids []string) map[string]interface{} {
+
+	out := map[string]interface{}{}
+	for _, id := range ids {
+		if id != "" {
+			out[id] = a.snapshot(ctx, resourceType, id)
+		}
+	}
+
+	return out
+}
+
+// snapshot returns a copy of a resource without secrets, or nil if it does not exist.
+func (a *Auditor) snapshot(ctx context.Context, resourceType, id string) interface{} {
+
+	switch resourceType {
+	case "token":
+		if t, err := a.state.ACLTokenByID(ctx, id); err == nil && t != nil {
+			c := *t
+			c.Secret = ""
+			return &c
+		}
+	case "policy":
+		if p, err := a.state.ACLPolicyByName(ctx, id); err == nil && p != nil {
+			return p
+		}
+	case "node":
+		if n, err := a.state.NodeByID(ctx, id); err == nil && n != nil {
+			c := *n
+			c.SecretID = ""
+			return &c
+		}
+	case "join-token":
+		if t, err := a.state.JoinTokenByID(ctx, id); err == nil && t != nil {
+			c := *t
+			c.Secret = ""
+			return &c
+		}
+	case "network":
+		if n, err := a.state.NetworkByID(ctx, id); err == nil && n != nil {
+			return n
+		}
+	case "interface":
+		if i, err := a.state.InterfaceByID(ctx, id); err == nil && i != nil {
+			return i.Sanitize()
+		}
+	case "connection":
+		if c, err := a.state.ConnectionByID(ctx, id); err == nil && c != nil {
+			return c.Sanitize()
+		}
+	}
+
+	return nil
+}
+
+// auditChanges returns the fields whose JSON encoding differs between two
+// snapshots of a resource, either of which may be nil.
+func auditChanges(before, after interface{}) map[string]*structs.AuditChange {
+
+	b, a := auditFields(before), auditFields(after)
+
+	changes := map[string]*structs.AuditChange{}
+
+	for k, v := range b {
+		if w, ok := a[k]; !ok || !bytes.Equal(v, w) {
+			changes[k] = &structs.AuditChange{Before: v, After: a[k]}
+		}
+	}
+	for k, w := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = &structs.AuditChange{Before: nil, After: w}
+		}
+	}
+
+	// Fields which are null on both sides, e.g. empty fields of created
+	// resources, are not considered changed.
+	for k, c := range changes {
+		if isJSONNull(c.Before) && isJSONNull(c.After) {
+			delete(changes, k)
+		}
+	}
+
+	return changes
+}
+
+func auditFields(v interface{}) map[string]json.RawMessage {
+
+	out := map[string]json.RawMessage{}
+
+	if v == nil {
+		return out
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return out
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return out
+	}
+
+	for k := range auditIgnoredFields {
+		delete(out, k)
+	}
+
+	return out
+}
+
+func isJSONNull(v json.RawMessage) bool {
+	return v == nil || string(v) == "null"
+}
+
+// garbageCollectAuditEntries removes audit entries older than the retention
+// period, returning the number of entries removed.
+func garbageCollectAuditEntries(ctx context.Context, repo state.Repository, retention time.Duration) (int, error) {
+
+	entries, err := repo.AuditEntries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-retention)
+
+	ids := []string{}
+	for _, e := range entries {
+		if e.Timestamp.Before(cutoff) {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	if err := repo.DeleteAuditEntries(ctx, ids); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// AuditService is used for querying the audit log.
+type AuditService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	authHandler auth.AuthorizationHandler
+}
+
+// NewAuditService ...
+func NewAuditService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) *AuditService {
+	return &AuditService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		authHandler: authHandler,
+	}
+}
+
+// ListEntries retrieves the audit entries in the namespace of the request matching the
+// filters passed, newest first unless sorted otherwise.
+func (s *AuditService) ListEntries(args *structs.AuditListRequest, out *structs.AuditListResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "audit", namespacePath(ns, ""), AuditList); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	entries, err := s.state.AuditEntries(ctx)
+	if err != nil {
+		return structs.ErrInternal
+	}
+
+	out.Items = []*structs.AuditEntry{}
+
+	for _, e := range entries {
+		if !inNamespace(e.Namespace, ns) {
+			continue
+		}
+		if args.ResourceType != "" && e.ResourceType != args.ResourceType {
+			continue
+		}
+		if args.ResourceID != "" && e.ResourceID != args.ResourceID {
+			continue
+		}
+		if args.TokenID != "" && e.TokenID != args.TokenID {
+			continue
+		}
+		if args.Operation != "" && e.Operation != args.Operation {
+			continue
+		}
+		if !args.Since.IsZero() && e.Timestamp.Before(args.Since) {
+			continue
+		}
+		out.Items = append(out.Items, e)
+	}
+
+	// Entries are listed newest first, unless sorted otherwise
+	opts := args.QueryOptions
+	if opts.Sort == "" {
+		opts.Sort = "Timestamp"
+		if opts.Order == "" {
+			opts.Order = paginator.OrderDesc
+		}
+	}
+
+	if err = filterItems(&out.Items, opts); err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// auditID returns a function returning the ID pointed to by p, for passing to
+// Auditor.Begin the IDs of resources which are only set by the operation.
+func auditID(p *string) func() []string {
+	return func() []string { return []string{*p} }
+}
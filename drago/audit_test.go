@@ -0,0 +1,157 @@
+package drago
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestAuditSnapshotStripsSecrets(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	auditor, err := NewAuditor(testConfig(), testLogger(t), repo)
+	if err != nil {
+		t.Fatalf("NewAuditor() failed: %v", err)
+	}
+
+	repo.UpsertACLToken(ctx, &structs.ACLToken{ID: "token", Type: structs.ACLTokenTypeClient, Secret: "token-secret"})
+	repo.UpsertNode(ctx, &structs.Node{ID: "node", SecretID: "node-secret"})
+	repo.UpsertJoinToken(ctx, &structs.JoinToken{ID: "join-token", Secret: "join-token-secret"})
+	repo.UpsertInterface(ctx, &structs.Interface{ID: "interface", External: true, PrivateKey: testStrPtr("private-key")})
+	repo.UpsertConnection(ctx, &structs.Connection{ID: "connection", PresharedKey: testStrPtr("preshared-key")})
+
+	tests := map[string]string{
+		"token":      "token-secret",
+		"node":       "node-secret",
+		"join-token": "join-token-secret",
+		"interface":  "private-key",
+		"connection": "preshared-key",
+	}
+
+	for resourceType, secret := range tests {
+
+		snapshot := auditor.snapshot(ctx, resourceType, resourceType)
+		if snapshot == nil {
+			t.Fatalf("auditor.snapshot(%s) failed, expected resource to be found", resourceType)
+		}
+
+		b, _ := json.Marshal(snapshot)
+		if strings.Contains(string(b), secret) {
+			t.Fatalf("auditor.snapshot(%s) failed, expected secret to be stripped", resourceType)
+		}
+	}
+
+	// Secrets changed by an operation are not recorded either
+	id := "token"
+	done := auditor.Begin(ctx, "ACL.UpsertToken", "", "", "token", auditID(&id))
+	repo.UpsertACLToken(ctx, &structs.ACLToken{ID: "token", Type: structs.ACLTokenTypeClient, Name: "renamed", Secret: "new-secret"})
+	err = nil
+	done(&err)
+
+	entries, _ := repo.AuditEntries(ctx)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(entries))
+	}
+	if _, ok := entries[0].Changes["Name"]; !ok {
+		t.Fatalf("expected change of token name to be recorded")
+	}
+	if b, _ := json.Marshal(entries[0]); strings.Contains(string(b), "secret") {
+		t.Fatalf("expected audit entry not to contain secrets, got %s", b)
+	}
+}
+
+func TestListEntriesNamespace(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	config := testConfig()
+	authHandler := testACL(t, repo, config)
+
+	auditor, err := NewAuditor(config, testLogger(t), repo)
+	if err != nil {
+		t.Fatalf("NewAuditor() failed: %v", err)
+	}
+
+	// Operations on resources in namespaces, and on resources
+	// which do not belong to any, recorded in the default one.
+	for _, ns := range []string{structs.DefaultNamespace, "team-a", ""} {
+		id := "resource-" + ns
+		err := error(nil)
+		auditor.Begin(ctx, "Network.UpsertNetwork", "", ns, "network", auditID(&id))(&err)
+	}
+
+	secret := testACLToken(t, repo, "auditor", &structs.ACLPolicyRule{Resource: "audit", Namespace: "team-*", Path: "*", Capabilities: []string{"read"}})
+
+	s := NewAuditService(config, testLogger(t), repo, authHandler)
+
+	list := func(ns string) ([]string, error) {
+		out := &structs.AuditListResponse{}
+		err := s.ListEntries(&structs.AuditListRequest{QueryOptions: structs.QueryOptions{AuthToken: secret, Namespace: ns}}, out)
+		ids := []string{}
+		for _, e := range out.Items {
+			ids = append(ids, e.ResourceID)
+		}
+		return ids, err
+	}
+
+	ids, err := list("team-a")
+	if err != nil {
+		t.Fatalf("s.ListEntries() failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "resource-team-a" {
+		t.Fatalf("expected only the entry in namespace team-a, got %v", ids)
+	}
+
+	// The rule only applies in the namespaces it matches
+	if _, err := list(structs.DefaultNamespace); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected s.ListEntries() to be denied in the default namespace, got %v", err)
+	}
+
+	config.ACL.Enabled = false
+
+	ids, err = list("")
+	if err != nil {
+		t.Fatalf("s.ListEntries() failed: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected the entries in the default namespace, got %v", ids)
+	}
+}
+
+func TestGarbageCollectAuditEntries(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	now := time.Now()
+	retention := 24 * time.Hour
+
+	repo.UpsertAuditEntry(ctx, &structs.AuditEntry{ID: "expired", Timestamp: now.Add(-2 * retention)})
+	repo.UpsertAuditEntry(ctx, &structs.AuditEntry{ID: "recent", Timestamp: now.Add(-retention / 2)})
+	repo.UpsertAuditEntry(ctx, &structs.AuditEntry{ID: "new", Timestamp: now})
+
+	n, err := garbageCollectAuditEntries(ctx, repo, retention)
+	if err != nil {
+		t.Fatalf("garbageCollectAuditEntries() failed: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 entry to be collected, got %d", n)
+	}
+
+	entries, _ := repo.AuditEntries(ctx)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries to be kept, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.ID == "expired" {
+			t.Fatalf("expected expired entry to be removed")
+		}
+	}
+}
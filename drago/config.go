@@ -5,6 +5,7 @@ import (
 
 	"github.com/seashell/drago/drago/structs/config"
 	log "github.com/seashell/drago/pkg/log"
+	tlsutil "github.com/seashell/drago/pkg/tlsutil"
 	version "github.com/seashell/drago/version"
 )
 
@@ -51,8 +52,35 @@ type Config struct {
 	// Etcd.
 	Etcd *config.EtcdConfig
 
+	// TLSConfig, if set, is used for serving RPCs over TLS.
+	TLSConfig *tlsutil.Config
+
+	// RequireJoinToken controls whether nodes unknown to the server
+	// must present a valid join token in order to register.
+	RequireJoinToken bool
+
+	// HeartbeatTTL is the interval at which client
+	// nodes are expected to send heartbeats.
+	HeartbeatTTL time.Duration
+
+	// HeartbeatGrace is the additional time the server waits
+	// for a heartbeat before marking a node as down.
+	HeartbeatGrace time.Duration
+
 	// HostGCInterval is how often we perform garbage collection of hosts.
 	HostGCInterval time.Duration
+
+	// HostGCThreshold is how long a host must be down
+	// before being eligible for garbage collection.
+	HostGCThreshold time.Duration
+
+	// AuditRetention is how long entries are kept in the audit log.
+	AuditRetention time.Duration
+
+	// AuditLogFile is the path of a file to which audit entries are
+	// mirrored in the JSON lines format. If empty, they are only stored
+	// in the state.
+	AuditLogFile string
 }
 
 // Ports :
@@ -74,8 +102,13 @@ func DefaultConfig() *Config {
 			HTTP: defaultHTTPPort,
 			RPC:  defaultRPCPort,
 		},
-		ACL:            config.DefaultACLConfig(),
-		Etcd:           config.DefaultEtcdConfig(),
-		HostGCInterval: 5 * time.Minute,
+		ACL:              config.DefaultACLConfig(),
+		Etcd:             config.DefaultEtcdConfig(),
+		RequireJoinToken: true,
+		HeartbeatTTL:     5 * time.Second,
+		HeartbeatGrace:   5 * time.Second,
+		HostGCInterval:   5 * time.Minute,
+		HostGCThreshold:  24 * time.Hour,
+		AuditRetention:   30 * 24 * time.Hour,
 	}
 }
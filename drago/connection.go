@@ -10,6 +10,7 @@ import (
 	structs "github.com/seashell/drago/drago/structs"
 	log "github.com/seashell/drago/pkg/log"
 	uuid "github.com/seashell/drago/pkg/uuid"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
 const (
@@ -22,15 +23,17 @@ type ConnectionService struct {
 	config      *Config
 	logger      log.Logger
 	state       state.Repository
+	auditor     *Auditor
 	authHandler auth.AuthorizationHandler
 }
 
 // NewConnectionService ...
-func NewConnectionService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) *ConnectionService {
+func NewConnectionService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *ConnectionService {
 	return &ConnectionService{
 		config:      config,
 		logger:      logger,
 		state:       state,
+		auditor:     auditor,
 		authHandler: authHandler,
 	}
 }
@@ -39,20 +42,21 @@ func NewConnectionService(config *Config, logger log.Logger, state state.Reposit
 func (s *ConnectionService) GetConnection(args *structs.ConnectionSpecificRequest, out *structs.SingleConnectionResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", args.ConnectionID, ConnectionRead); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, args.ConnectionID), ConnectionRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	n, err := s.state.ConnectionByID(ctx, args.ConnectionID)
+	n, err := connectionInNamespace(ctx, s.state, ns, args.ConnectionID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
-	out.Connection = n
+	out.Connection = n.Sanitize()
 
 	return nil
 }
@@ -61,68 +65,144 @@ func (s *ConnectionService) GetConnection(args *structs.ConnectionSpecificReques
 func (s *ConnectionService) ListConnections(args *structs.ConnectionListRequest, out *structs.ConnectionListResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", "", ConnectionList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, ""), ConnectionList); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
 	out.Items = nil
 
-	var err error
-	var connections []*structs.Connection
+	// Statistics reported by the interfaces read so far, indexed by interface ID
+	stats := map[string][]*structs.PeerStats{}
 
-	if args.InterfaceID != "" {
-		if connections, err = s.state.ConnectionsByInterfaceID(ctx, args.InterfaceID); err != nil {
-			return structs.ErrInternal
+	now := time.Now()
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		var err error
+		var next string
+		var connections []*structs.Connection
+
+		paged := false
+
+		if args.InterfaceID != "" {
+			if connections, err = s.state.ConnectionsByInterfaceID(ctx, args.InterfaceID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else if args.NodeID != "" {
+			if connections, err = s.state.ConnectionsByNodeID(ctx, args.NodeID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else if args.NetworkID != "" {
+			if connections, err = s.state.ConnectionsByNetworkID(ctx, args.NetworkID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else {
+			if connections, next, err = s.state.ConnectionsPage(ctx, opts); err != nil {
+				return "", structs.ErrInternal
+			}
+			paged = opts.PerPage > 0
 		}
-	} else if args.NodeID != "" {
-		if connections, err = s.state.ConnectionsByNodeID(ctx, args.NodeID); err != nil {
-			return structs.ErrInternal
+
+		matching := []*structs.Connection{}
+		for _, c := range connections {
+			if !inNamespace(c.Namespace, ns) {
+				continue
+			}
+			if args.NetworkID != "" && c.NetworkID != args.NetworkID {
+				continue
+			}
+			if args.InterfaceID != "" && !c.ConnectsInterface(args.InterfaceID) {
+				continue
+			}
+			matching = append(matching, c)
 		}
-	} else if args.NetworkID != "" {
-		if connections, err = s.state.ConnectionsByNetworkID(ctx, args.NetworkID); err != nil {
-			return structs.ErrInternal
+
+		// When connections are read one page at a time, only the interfaces they connect
+		// are read. Otherwise, all connections were read, and all interfaces are read at
+		// once, which is faster than reading the interfaces of each connection.
+		if paged {
+			connectedInterfaceStats(ctx, s.state, matching, stats)
+		} else if len(matching) > 0 {
+			interfaces, err := s.state.Interfaces(ctx)
+			if err != nil {
+				return "", structs.ErrInternal
+			}
+			for _, iface := range interfaces {
+				stats[iface.ID] = iface.PeerStats
+			}
 		}
-	} else {
-		if connections, err = s.state.Connections(ctx); err != nil {
-			return structs.ErrInternal
+
+		for _, c := range matching {
+			stub := c.Stub()
+			stub.SetPeerStats(peerStats(c, stats), now)
+			out.Items = append(out.Items, stub)
 		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
 	}
 
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// connectedInterfaceStats adds the statistics reported by the interfaces connected by
+// the connections to stats, indexed by interface ID, unless they are already in it.
+// Interfaces which can not be read are added without statistics.
+func connectedInterfaceStats(ctx context.Context, repo state.Repository, connections []*structs.Connection, stats map[string][]*structs.PeerStats) {
 	for _, c := range connections {
-		shouldAppend := true
-		if args.NetworkID != "" && c.NetworkID != args.NetworkID {
-			shouldAppend = false
-		}
-		if args.InterfaceID != "" && !c.ConnectsInterface(args.InterfaceID) {
-			shouldAppend = false
-		}
-		if shouldAppend {
-			out.Items = append(out.Items, c.Stub())
+		for _, id := range c.ConnectedInterfaceIDs() {
+			if _, ok := stats[id]; ok {
+				continue
+			}
+			iface, err := repo.InterfaceByID(ctx, id)
+			if err != nil {
+				stats[id] = nil
+				continue
+			}
+			stats[id] = iface.PeerStats
 		}
 	}
+}
 
-	return nil
+// peerStats returns the statistics reported for a connection by the nodes on each
+// end, given the statistics reported by each interface, indexed by interface ID.
+func peerStats(c *structs.Connection, stats map[string][]*structs.PeerStats) []*structs.PeerStats {
+	out := []*structs.PeerStats{}
+	for _, id := range c.ConnectedInterfaceIDs() {
+		out = append(out, stats[id]...)
+	}
+	return out
 }
 
 // UpsertConnection upserts a new Connection entity
-func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertRequest, out *structs.GenericResponse) error {
+func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Connection.UpsertConnection", args.AuthToken, ns, "connection", auditID(&args.Connection.ID))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", "", ConnectionWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, ""), ConnectionWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
 	c := args.Connection
 
-	err := c.Validate()
+	err = c.Validate()
 	if err != nil {
 		return structs.NewInvalidInputError(err.Error())
 	}
@@ -131,16 +211,111 @@ func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertReque
 	// Otherwise, we generate a new ID and set the protected attributes in preparation for inserting
 	// the new struct into the repository.
 	if c.ID != "" {
-		old, err := s.state.ConnectionByID(ctx, c.ID)
+		old, err := connectionInNamespace(ctx, s.state, ns, c.ID)
 		if err != nil {
-			return structs.ErrNotFound // connection does not exist
+			return err // connection does not exist
 		}
 		c = old.Merge(c)
 	} else {
 		c.ID = uuid.Generate()
+		c.PresharedKey = nil // Preshared keys are always generated by the server
 		c.CreatedAt = time.Now()
 	}
 
+	// Make sure the connected interfaces are in the namespace of the request
+	for _, id := range c.ConnectedInterfaceIDs() {
+		if _, err := interfaceInNamespace(ctx, s.state, ns, id); err != nil {
+			return structs.NewInvalidInputError(fmt.Sprintf("Interface %s does not exist", id))
+		}
+	}
+
+	// Connections upserted by users are not managed by the network topology,
+	// so that manual changes are not overwritten by the server.
+	c.Managed = false
+
+	return withTransaction(ctx, s.state, func(ctx context.Context) error {
+		if err := checkAllowedIPs(ctx, s.state, s.logger, c); err != nil {
+			return err
+		}
+		return upsertConnection(ctx, s.state, c)
+	})
+}
+
+// RotatePresharedKey replaces the preshared key of a connection with a newly generated one.
+// Since the key is applied by both nodes once they fetch their configurations, the tunnel
+// may be briefly interrupted until both ends are updated.
+func (s *ConnectionService) RotatePresharedKey(args *structs.ConnectionRotatePresharedKeyRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Connection.RotatePresharedKey", args.AuthToken, ns, "connection", auditID(&args.ConnectionID))(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, args.ConnectionID), ConnectionWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	if args.ConnectionID == "" {
+		return structs.NewInvalidInputError("Missing ConnectionID")
+	}
+
+	return retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			c, err := connectionInNamespace(ctx, s.state, ns, args.ConnectionID)
+			if err != nil {
+				return err
+			}
+
+			if c.DisablePresharedKey {
+				return structs.NewInvalidInputError("Connection has preshared keys disabled")
+			}
+
+			key, err := generatePresharedKey()
+			if err != nil {
+				return structs.NewInternalError(err.Error())
+			}
+			c.PresharedKey = &key
+
+			return upsertConnection(ctx, s.state, c)
+		})
+	})
+}
+
+// DeleteConnection deletes a connection entity from the repository
+func (s *ConnectionService) DeleteConnection(args *structs.ConnectionDeleteRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Connection.DeleteConnection", args.AuthToken, ns, "connection", func() []string { return args.ConnectionIDs })(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, ""), ConnectionWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	for _, id := range args.ConnectionIDs {
+		if _, err := connectionInNamespace(ctx, s.state, ns, id); err != nil {
+			return err
+		}
+	}
+
+	return deleteConnections(ctx, s.state, args.ConnectionIDs)
+}
+
+// upsertConnection validates the connection and writes it to the repository, along
+// with the interfaces, nodes and network referencing it. It should be called with
+// a context carrying a transaction, so that all these writes are applied atomically.
+func upsertConnection(ctx context.Context, repo state.Repository, c *structs.Connection) error {
+
+	var err error
+
 	connectedInterfaceIDs := c.ConnectedInterfaceIDs()
 
 	if len(connectedInterfaceIDs) != 2 {
@@ -150,7 +325,7 @@ func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertReque
 		return structs.NewInternalError("Can't connect an interface to itself")
 	}
 	// Make sure interfaces are not already connected
-	if conn, err := s.state.ConnectionByInterfaceIDs(ctx, connectedInterfaceIDs[0], connectedInterfaceIDs[1]); err == nil {
+	if conn, err := repo.ConnectionByInterfaceIDs(ctx, connectedInterfaceIDs[0], connectedInterfaceIDs[1]); err == nil {
 		if conn.ID != c.ID {
 			return structs.NewInternalError("Interfaces already connected")
 		}
@@ -181,7 +356,7 @@ func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertReque
 	// Make sure both peer interfaces exist
 	ifaces := []*structs.Interface{}
 	for _, id := range connectedInterfaceIDs {
-		if iface, err := s.state.InterfaceByID(ctx, id); err == nil {
+		if iface, err := repo.InterfaceByID(ctx, id); err == nil {
 			ifaces = append(ifaces, iface)
 			continue
 		}
@@ -192,38 +367,49 @@ func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertReque
 		return structs.NewInternalError("Interfaces are not in the same network")
 	}
 
-	// Assign network ID in case we're creating a new connection
+	// Assign network ID and namespace in case we're creating a new connection
 	c.NetworkID = ifaces[0].NetworkID
+	c.Namespace = ifaces[0].Namespace
+
+	// Make sure every connection carries a preshared key, including the ones
+	// created before preshared keys were introduced, unless it opted out.
+	if c.DisablePresharedKey {
+		c.PresharedKey = nil
+	} else if c.PresharedKey == nil {
+		key, err := generatePresharedKey()
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+		c.PresharedKey = &key
+	}
 
 	c.UpdatedAt = time.Now()
 
-	// TODO: wrap in a transaction
-
 	for _, iface := range ifaces {
 		iface.UpsertConnection((c.ID))
-		if err = s.state.UpsertInterface(ctx, iface); err != nil {
+		if err = repo.UpsertInterface(ctx, iface); err != nil {
 			return structs.ErrInternal
 		}
 
-		if node, err := s.state.NodeByID(ctx, iface.NodeID); err == nil {
+		if node, err := repo.NodeByID(ctx, iface.NodeID); err == nil {
 
 			c.PeerSettingsByInterfaceID(iface.ID).NodeID = iface.NodeID
 
 			node.UpsertConnection((c.ID))
-			if err = s.state.UpsertNode(ctx, node); err != nil {
+			if err = repo.UpsertNode(ctx, node); err != nil {
 				return structs.ErrInternal
 			}
 		}
 	}
 
-	if network, err := s.state.NetworkByID(ctx, c.NetworkID); err == nil {
+	if network, err := repo.NetworkByID(ctx, c.NetworkID); err == nil {
 		network.UpsertConnection((c.ID))
-		if err = s.state.UpsertNetwork(ctx, network); err != nil {
+		if err = repo.UpsertNetwork(ctx, network); err != nil {
 			return structs.ErrInternal
 		}
 	}
 
-	err = s.state.UpsertConnection(ctx, c)
+	err = repo.UpsertConnection(ctx, c)
 	if err != nil {
 		return structs.ErrInternal
 	}
@@ -231,66 +417,83 @@ func (s *ConnectionService) UpsertConnection(args *structs.ConnectionUpsertReque
 	return nil
 }
 
-// DeleteConnection deletes a connection entity from the repository
-func (s *ConnectionService) DeleteConnection(args *structs.ConnectionDeleteRequest, out *structs.GenericResponse) error {
+// deleteConnections removes connections from the repository, along with the references
+// to them in the connected interfaces, nodes and network. Each connection is removed in a
+// separate transaction, unless the context already carries one, so that the size of the
+// transactions does not grow with the number of connections.
+func deleteConnections(ctx context.Context, repo state.Repository, ids []string) error {
 
-	ctx := context.TODO()
-
-	// Check if authorized
-	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", "", ConnectionWrite); err != nil {
-			return structs.ErrPermissionDenied
+	for _, connID := range ids {
+		err := withTransaction(ctx, repo, func(ctx context.Context) error {
+			return deleteConnection(ctx, repo, connID)
+		})
+		if err != nil {
+			return err
 		}
 	}
 
-	for _, connID := range args.ConnectionIDs {
-		if conn, err := s.state.ConnectionByID(ctx, connID); err == nil {
+	return nil
+}
 
-			var nodes []*structs.Node
-			var ifaces []*structs.Interface
-			var network *structs.Network
+func deleteConnection(ctx context.Context, repo state.Repository, connID string) error {
 
-			for _, nodeID := range conn.ConnectedNodeIDs() {
-				if node, err := s.state.NodeByID(ctx, nodeID); err == nil {
-					nodes = append(nodes, node)
-				}
-			}
+	conn, err := repo.ConnectionByID(ctx, connID)
+	if err != nil {
+		return nil // connection does not exist
+	}
 
-			for _, ifaceID := range conn.ConnectedInterfaceIDs() {
-				if iface, err := s.state.InterfaceByID(ctx, ifaceID); err == nil {
-					ifaces = append(ifaces, iface)
-				}
-			}
+	var nodes []*structs.Node
+	var ifaces []*structs.Interface
+	var network *structs.Network
 
-			if network, err = s.state.NetworkByID(ctx, conn.NetworkID); err != nil {
-				return structs.NewInternalError(err.Error())
-			}
+	for _, nodeID := range conn.ConnectedNodeIDs() {
+		if node, err := repo.NodeByID(ctx, nodeID); err == nil {
+			nodes = append(nodes, node)
+		}
+	}
 
-			for _, node := range nodes {
-				node.RemoveConnection(connID)
-				if err := s.state.UpsertNode(ctx, node); err != nil {
-					return structs.ErrInternal // could not update node
-				}
-			}
+	for _, ifaceID := range conn.ConnectedInterfaceIDs() {
+		if iface, err := repo.InterfaceByID(ctx, ifaceID); err == nil {
+			ifaces = append(ifaces, iface)
+		}
+	}
 
-			for _, iface := range ifaces {
-				iface.RemoveConnection(connID)
-				if err = s.state.UpsertInterface(ctx, iface); err != nil {
-					return structs.ErrInternal // could not update interface
-				}
-			}
+	if network, err = repo.NetworkByID(ctx, conn.NetworkID); err != nil {
+		return structs.NewInternalError(err.Error())
+	}
 
-			network.RemoveConnection(connID)
-			if err := s.state.UpsertNetwork(ctx, network); err != nil {
-				return structs.ErrInternal // could not update network
-			}
+	for _, node := range nodes {
+		node.RemoveConnection(connID)
+		if err := repo.UpsertNode(ctx, node); err != nil {
+			return structs.ErrInternal // could not update node
 		}
 	}
 
-	// Remove connections
-	if err := s.state.DeleteConnections(ctx, args.ConnectionIDs); err != nil {
+	for _, iface := range ifaces {
+		iface.RemoveConnection(connID)
+		if err = repo.UpsertInterface(ctx, iface); err != nil {
+			return structs.ErrInternal // could not update interface
+		}
+	}
+
+	network.RemoveConnection(connID)
+	if err := repo.UpsertNetwork(ctx, network); err != nil {
+		return structs.ErrInternal // could not update network
+	}
+
+	// Remove connection
+	if err := repo.DeleteConnections(ctx, []string{connID}); err != nil {
 		return structs.ErrInternal
 	}
 
 	return nil
 }
+
+// generatePresharedKey returns a new random WireGuard preshared key, base64-encoded.
+func generatePresharedKey() (string, error) {
+	key, err := wgtypes.GenerateKey()
+	if err != nil {
+		return "", fmt.Errorf("could not generate preshared key: %v", err)
+	}
+	return key.String(), nil
+}
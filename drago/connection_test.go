@@ -0,0 +1,65 @@
+package drago
+
+import (
+	"context"
+	"testing"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestUpsertConnectionPresharedKey(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyManual)
+	a := testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	b := testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+	c := testNode(t, repo, "net", "c", "10.0.0.3/24", false)
+
+	s := NewConnectionService(testConfig(), testLogger(t), repo, nil, nil)
+
+	connect := func(x, y *structs.Interface, disable bool) *structs.Connection {
+		args := &structs.ConnectionUpsertRequest{
+			Connection: &structs.Connection{
+				PeerSettings: []*structs.PeerSettings{
+					{InterfaceID: x.ID},
+					{InterfaceID: y.ID},
+				},
+				DisablePresharedKey: disable,
+			},
+		}
+		if err := s.UpsertConnection(args, &structs.GenericResponse{}); err != nil {
+			t.Fatalf("s.UpsertConnection() failed: %v", err)
+		}
+		conn, err := repo.ConnectionByInterfaceIDs(ctx, x.ID, y.ID)
+		if err != nil {
+			t.Fatalf("repo.ConnectionByInterfaceIDs() failed: %v", err)
+		}
+		return conn
+	}
+
+	if conn := connect(a, b, false); conn.PresharedKey == nil {
+		t.Fatalf("expected connection to carry a preshared key")
+	}
+
+	conn := connect(a, c, true)
+	if conn.PresharedKey != nil {
+		t.Fatalf("expected connection not to carry a preshared key")
+	}
+
+	err := s.RotatePresharedKey(&structs.ConnectionRotatePresharedKeyRequest{ConnectionID: conn.ID}, &structs.GenericResponse{})
+	if err == nil {
+		t.Fatalf("expected s.RotatePresharedKey() to fail for connection with preshared keys disabled")
+	}
+
+	// The opt-out is kept when the connection is updated
+	keepalive := 10
+	args := &structs.ConnectionUpsertRequest{Connection: &structs.Connection{ID: conn.ID, PersistentKeepalive: &keepalive}}
+	if err := s.UpsertConnection(args, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpsertConnection() failed: %v", err)
+	}
+	if conn, _ = repo.ConnectionByID(ctx, conn.ID); conn.PresharedKey != nil {
+		t.Fatalf("expected updated connection not to carry a preshared key")
+	}
+}
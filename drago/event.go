@@ -0,0 +1,162 @@
+package drago
+
+import (
+	"context"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	events "github.com/seashell/drago/drago/events"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+const (
+	// defaultMaxQueryTime is the amount of time blocking queries
+	// wait for changes if no MaxQueryTime is specified.
+	defaultMaxQueryTime = 5 * time.Minute
+
+	// maxQueryTime is the upper limit for the MaxQueryTime of
+	// blocking queries.
+	maxQueryTime = 10 * time.Minute
+)
+
+// EventService is used for subscribing to events
+// published whenever the server state changes.
+type EventService struct {
+	config      *Config
+	logger      log.Logger
+	broker      *events.Broker
+	authHandler auth.AuthorizationHandler
+}
+
+// NewEventService ...
+func NewEventService(config *Config, logger log.Logger, broker *events.Broker, authHandler auth.AuthorizationHandler) *EventService {
+	return &EventService{
+		config:      config,
+		logger:      logger,
+		broker:      broker,
+		authHandler: authHandler,
+	}
+}
+
+// ListEvents returns the events published after args.MinQueryIndex and matching the
+// requested topics and keys. Events about namespaced resources are only returned if
+// they are in the namespace of the request. If there are no such events, it blocks
+// until one is published or until args.MaxQueryTime elapses.
+func (s *EventService) ListEvents(args *structs.EventListRequest, out *structs.EventListResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	topics := args.Topics
+	if len(topics) == 0 {
+		topics = structs.EventTopics()
+	}
+
+	// Check if authorized for each of the requested topics. If no topics
+	// were explicitly requested, only the authorized ones are considered.
+	allowed := map[string]struct{}{}
+	for _, t := range topics {
+		resource, capability, ok := eventTopicResource(t)
+		if !ok {
+			return structs.NewInvalidInputError("Unknown topic " + t)
+		}
+		if s.config.ACL.Enabled {
+			path := ""
+			if namespacedResources[resource] {
+				path = namespacePath(ns, "")
+			}
+			if err := s.authHandler.Authorize(ctx, args.AuthToken, resource, path, capability); err != nil {
+				if len(args.Topics) > 0 {
+					return structs.ErrPermissionDenied
+				}
+				continue
+			}
+		}
+		allowed[t] = struct{}{}
+	}
+
+	if len(allowed) == 0 {
+		return structs.ErrPermissionDenied
+	}
+
+	keys := map[string]struct{}{}
+	for _, k := range args.Keys {
+		keys[k] = struct{}{}
+	}
+
+	filter := func(e *structs.Event) bool {
+		if _, ok := allowed[e.Topic]; !ok {
+			return false
+		}
+		if e.Namespace != "" && e.Namespace != ns {
+			return false
+		}
+		if len(keys) > 0 {
+			if _, ok := keys[e.Key]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	index := args.MinQueryIndex
+
+	// If the index is ahead of the broker, the server was likely
+	// restarted, in which case all buffered events are returned.
+	if index > s.broker.Index() {
+		index = 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, blockingQueryTime(args.MaxQueryTime))
+	defer cancel()
+
+	for {
+		current := s.broker.Index()
+
+		out.Items = s.broker.Events(index, filter)
+		out.Index = current
+
+		if len(out.Items) > 0 {
+			out.Index = out.Items[len(out.Items)-1].Index
+			return nil
+		}
+
+		index = current
+		if s.broker.Wait(ctx, current); ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// eventTopicResource returns the ACL resource and capability
+// required for subscribing to events of a given topic.
+func eventTopicResource(topic string) (string, string, bool) {
+	switch topic {
+	case structs.EventTopicNode:
+		return "node", NodeRead, true
+	case structs.EventTopicNetwork:
+		return "network", NetworkRead, true
+	case structs.EventTopicInterface:
+		return "interface", InterfaceRead, true
+	case structs.EventTopicConnection:
+		return "connection", ConnectionRead, true
+	case structs.EventTopicACLToken:
+		return "token", ACLTokenRead, true
+	case structs.EventTopicACLPolicy:
+		return "policy", ACLPolicyRead, true
+	}
+	return "", "", false
+}
+
+// blockingQueryTime returns the amount of time a blocking query
+// should wait for changes, given the requested MaxQueryTime.
+func blockingQueryTime(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultMaxQueryTime
+	}
+	if d > maxQueryTime {
+		return maxQueryTime
+	}
+	return d
+}
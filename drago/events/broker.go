@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+const (
+	// DefaultBufferSize is the default number of events kept in memory by the broker.
+	DefaultBufferSize = 1024
+)
+
+// Broker keeps a bounded buffer of the most recent events published by the
+// Drago server, and allows subscribers to wait for new events. Subscribers
+// which fall behind the buffer miss the events which were discarded.
+type Broker struct {
+	size   int
+	index  uint64
+	events []*structs.Event
+
+	// notifyCh is closed and replaced whenever new events are published,
+	// thus waking up all subscribers waiting on it.
+	notifyCh chan struct{}
+
+	lock sync.RWMutex
+}
+
+// NewBroker creates a new event broker keeping at most size events in memory.
+func NewBroker(size int) *Broker {
+
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	return &Broker{
+		size:     size,
+		events:   make([]*structs.Event, 0, size),
+		notifyCh: make(chan struct{}),
+	}
+}
+
+// Publish assigns an index to each of the events passed as argument,
+// and makes them available to subscribers.
+func (b *Broker) Publish(events ...*structs.Event) {
+
+	if len(events) == 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, e := range events {
+		b.index++
+		e.Index = b.index
+		if e.Timestamp.IsZero() {
+			e.Timestamp = time.Now()
+		}
+		b.events = append(b.events, e)
+	}
+
+	if n := len(b.events); n > b.size {
+		b.events = append(b.events[:0:0], b.events[n-b.size:]...)
+	}
+
+	close(b.notifyCh)
+	b.notifyCh = make(chan struct{})
+}
+
+// Index returns the index of the last published event.
+func (b *Broker) Index() uint64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.index
+}
+
+// Events returns the buffered events with an index greater than the one passed
+// as argument and for which the filter function, if not nil, returns true.
+func (b *Broker) Events(index uint64, filter func(*structs.Event) bool) []*structs.Event {
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	out := []*structs.Event{}
+	for _, e := range b.events {
+		if e.Index <= index {
+			continue
+		}
+		if filter == nil || filter(e) {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// Wait blocks until an event with an index greater than the one passed as
+// argument is published, or until the context is done. It returns the index
+// of the last published event.
+func (b *Broker) Wait(ctx context.Context, index uint64) uint64 {
+
+	for {
+		b.lock.RLock()
+		current, ch := b.index, b.notifyCh
+		b.lock.RUnlock()
+
+		if current > index {
+			return current
+		}
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return current
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestBrokerPublish(t *testing.T) {
+
+	b := NewBroker(2)
+
+	b.Publish(&structs.Event{Key: "a"}, &structs.Event{Key: "b"}, &structs.Event{Key: "c"})
+
+	if b.Index() != 3 {
+		t.Fatalf("b.Index() failed, expected %d, have %d", 3, b.Index())
+	}
+
+	events := b.Events(0, nil)
+	if len(events) != 2 || events[0].Key != "b" || events[1].Key != "c" {
+		t.Fatalf("b.Events() failed, expected oldest event to be discarded, have %v", events)
+	}
+
+	events = b.Events(2, func(e *structs.Event) bool { return e.Key == "c" })
+	if len(events) != 1 || events[0].Index != 3 {
+		t.Fatalf("b.Events() failed, expected a single event with index 3, have %v", events)
+	}
+}
+
+func TestBrokerWait(t *testing.T) {
+
+	b := NewBroker(DefaultBufferSize)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Publish(&structs.Event{Key: "a"})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if index := b.Wait(ctx, 0); index != 1 {
+		t.Fatalf("b.Wait() failed, expected %d, have %d", 1, index)
+	}
+}
@@ -0,0 +1,478 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// Repository wraps a state.Repository, publishing events to a broker
+// whenever resources are created, updated or deleted through it.
+type Repository struct {
+	state.Repository
+
+	broker *Broker
+}
+
+// NewRepository returns a state.Repository which publishes events
+// to the broker passed as argument.
+func NewRepository(repo state.Repository, broker *Broker) *Repository {
+	return &Repository{
+		Repository: repo,
+		broker:     broker,
+	}
+}
+
+// Transaction returns a transaction which publishes the events
+// resulting from the writes staged in it once it is committed.
+func (r *Repository) Transaction(ctx context.Context) state.Transaction {
+	return &transaction{
+		Transaction: r.Repository.Transaction(ctx),
+		broker:      r.broker,
+	}
+}
+
+type transaction struct {
+	state.Transaction
+
+	broker *Broker
+	events []*structs.Event
+	lock   sync.Mutex
+}
+
+// Commit commits the underlying transaction, publishing events if it succeeds.
+func (t *transaction) Commit() (interface{}, error) {
+
+	out, err := t.Transaction.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	t.lock.Lock()
+	events := t.events
+	t.events = nil
+	t.lock.Unlock()
+
+	t.broker.Publish(events...)
+
+	return out, nil
+}
+
+// Unwrap returns the underlying transaction, so that reads made through the
+// wrapped repository with a context carrying this transaction see staged writes.
+func (t *transaction) Unwrap() state.Transaction {
+	return t.Transaction
+}
+
+// unwrap replaces the transaction carried by the context, if any, by the underlying
+// one, so that the context can be passed to the wrapped repository. It also returns
+// the original transaction, to which events must be added instead of being published.
+func (r *Repository) unwrap(ctx context.Context) (context.Context, *transaction) {
+	if txn, ok := state.TransactionFromContext(ctx); ok {
+		if t, ok := txn.(*transaction); ok {
+			return state.WithTransaction(ctx, t.Transaction), t
+		}
+	}
+	return ctx, nil
+}
+
+// publish publishes events to the broker or, if a transaction
+// is passed, defers their publication until it is committed.
+func (r *Repository) publish(txn *transaction, events ...*structs.Event) {
+
+	if txn == nil {
+		r.broker.Publish(events...)
+		return
+	}
+
+	txn.lock.Lock()
+	defer txn.lock.Unlock()
+
+	txn.events = append(txn.events, events...)
+}
+
+// UpsertNode :
+func (r *Repository) UpsertNode(ctx context.Context, n *structs.Node) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.NodeByID(ctx, n.ID)
+
+	if err := r.Repository.UpsertNode(ctx, n); err != nil {
+		return err
+	}
+
+	t := ""
+	switch {
+	case old == nil:
+		t = structs.EventTypeNodeRegistered
+	case n.Status == structs.NodeStatusDown && old.Status != structs.NodeStatusDown:
+		t = structs.EventTypeNodeDown
+	case changed(old, n):
+		t = structs.EventTypeNodeUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:     structs.EventTopicNode,
+		Type:      t,
+		Key:       n.ID,
+		Namespace: structs.NamespaceOrDefault(n.Namespace),
+		Payload:   &structs.EventPayload{Node: n.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteNodes :
+func (r *Repository) DeleteNodes(ctx context.Context, ids []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, id := range ids {
+		if n, err := r.Repository.NodeByID(ctx, id); err == nil && n != nil {
+			events = append(events, &structs.Event{
+				Topic:     structs.EventTopicNode,
+				Type:      structs.EventTypeNodeDeleted,
+				Key:       n.ID,
+				Namespace: structs.NamespaceOrDefault(n.Namespace),
+				Payload:   &structs.EventPayload{Node: n.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteNodes(ctx, ids); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// UpsertNetwork :
+func (r *Repository) UpsertNetwork(ctx context.Context, n *structs.Network) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.NetworkByID(ctx, n.ID)
+
+	if err := r.Repository.UpsertNetwork(ctx, n); err != nil {
+		return err
+	}
+
+	t := ""
+	switch {
+	case old == nil:
+		t = structs.EventTypeNetworkCreated
+	case changed(old, n):
+		t = structs.EventTypeNetworkUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:     structs.EventTopicNetwork,
+		Type:      t,
+		Key:       n.ID,
+		Namespace: structs.NamespaceOrDefault(n.Namespace),
+		Payload:   &structs.EventPayload{Network: n.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteNetworks :
+func (r *Repository) DeleteNetworks(ctx context.Context, ids []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, id := range ids {
+		if n, err := r.Repository.NetworkByID(ctx, id); err == nil && n != nil {
+			events = append(events, &structs.Event{
+				Topic:     structs.EventTopicNetwork,
+				Type:      structs.EventTypeNetworkDeleted,
+				Key:       n.ID,
+				Namespace: structs.NamespaceOrDefault(n.Namespace),
+				Payload:   &structs.EventPayload{Network: n.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteNetworks(ctx, ids); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// UpsertInterface :
+func (r *Repository) UpsertInterface(ctx context.Context, i *structs.Interface) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.InterfaceByID(ctx, i.ID)
+
+	if err := r.Repository.UpsertInterface(ctx, i); err != nil {
+		return err
+	}
+
+	t := ""
+	switch {
+	case old == nil:
+		t = structs.EventTypeInterfaceCreated
+	case changed(old, i):
+		t = structs.EventTypeInterfaceUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:     structs.EventTopicInterface,
+		Type:      t,
+		Key:       i.ID,
+		Namespace: structs.NamespaceOrDefault(i.Namespace),
+		Payload:   &structs.EventPayload{Interface: i.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteInterfaces :
+func (r *Repository) DeleteInterfaces(ctx context.Context, ids []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, id := range ids {
+		if i, err := r.Repository.InterfaceByID(ctx, id); err == nil && i != nil {
+			events = append(events, &structs.Event{
+				Topic:     structs.EventTopicInterface,
+				Type:      structs.EventTypeInterfaceDeleted,
+				Key:       i.ID,
+				Namespace: structs.NamespaceOrDefault(i.Namespace),
+				Payload:   &structs.EventPayload{Interface: i.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteInterfaces(ctx, ids); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// UpsertConnection :
+func (r *Repository) UpsertConnection(ctx context.Context, c *structs.Connection) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.ConnectionByID(ctx, c.ID)
+
+	if err := r.Repository.UpsertConnection(ctx, c); err != nil {
+		return err
+	}
+
+	t := ""
+	switch {
+	case old == nil:
+		t = structs.EventTypeConnectionCreated
+	case changed(old, c):
+		t = structs.EventTypeConnectionUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:     structs.EventTopicConnection,
+		Type:      t,
+		Key:       c.ID,
+		Namespace: structs.NamespaceOrDefault(c.Namespace),
+		Payload:   &structs.EventPayload{Connection: c.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteConnections :
+func (r *Repository) DeleteConnections(ctx context.Context, ids []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, id := range ids {
+		if c, err := r.Repository.ConnectionByID(ctx, id); err == nil && c != nil {
+			events = append(events, &structs.Event{
+				Topic:     structs.EventTopicConnection,
+				Type:      structs.EventTypeConnectionDeleted,
+				Key:       c.ID,
+				Namespace: structs.NamespaceOrDefault(c.Namespace),
+				Payload:   &structs.EventPayload{Connection: c.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteConnections(ctx, ids); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// UpsertACLToken :
+func (r *Repository) UpsertACLToken(ctx context.Context, t *structs.ACLToken) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.ACLTokenByID(ctx, t.ID)
+
+	if err := r.Repository.UpsertACLToken(ctx, t); err != nil {
+		return err
+	}
+
+	typ := ""
+	switch {
+	case old == nil:
+		typ = structs.EventTypeACLTokenCreated
+	case changed(old, t):
+		typ = structs.EventTypeACLTokenUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:   structs.EventTopicACLToken,
+		Type:    typ,
+		Key:     t.ID,
+		Payload: &structs.EventPayload{ACLToken: t.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteACLTokens :
+func (r *Repository) DeleteACLTokens(ctx context.Context, ids []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, id := range ids {
+		if t, err := r.Repository.ACLTokenByID(ctx, id); err == nil && t != nil {
+			events = append(events, &structs.Event{
+				Topic:   structs.EventTopicACLToken,
+				Type:    structs.EventTypeACLTokenDeleted,
+				Key:     t.ID,
+				Payload: &structs.EventPayload{ACLToken: t.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteACLTokens(ctx, ids); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// UpsertACLPolicy :
+func (r *Repository) UpsertACLPolicy(ctx context.Context, p *structs.ACLPolicy) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	old, _ := r.Repository.ACLPolicyByName(ctx, p.Name)
+
+	if err := r.Repository.UpsertACLPolicy(ctx, p); err != nil {
+		return err
+	}
+
+	t := ""
+	switch {
+	case old == nil:
+		t = structs.EventTypeACLPolicyCreated
+	case changed(old, p):
+		t = structs.EventTypeACLPolicyUpdated
+	default:
+		return nil
+	}
+
+	r.publish(txn, &structs.Event{
+		Topic:   structs.EventTopicACLPolicy,
+		Type:    t,
+		Key:     p.Name,
+		Payload: &structs.EventPayload{ACLPolicy: p.Stub()},
+	})
+
+	return nil
+}
+
+// DeleteACLPolicies :
+func (r *Repository) DeleteACLPolicies(ctx context.Context, names []string) error {
+
+	ctx, txn := r.unwrap(ctx)
+
+	events := []*structs.Event{}
+	for _, name := range names {
+		if p, err := r.Repository.ACLPolicyByName(ctx, name); err == nil && p != nil {
+			events = append(events, &structs.Event{
+				Topic:   structs.EventTopicACLPolicy,
+				Type:    structs.EventTypeACLPolicyDeleted,
+				Key:     p.Name,
+				Payload: &structs.EventPayload{ACLPolicy: p.Stub()},
+			})
+		}
+	}
+
+	if err := r.Repository.DeleteACLPolicies(ctx, names); err != nil {
+		return err
+	}
+
+	r.publish(txn, events...)
+
+	return nil
+}
+
+// changed checks whether two versions of a resource differ in any
+// of their exported attributes, except for their update timestamps
+// and modification indexes.
+func changed(x, y interface{}) bool {
+
+	a, b := map[string]interface{}{}, map[string]interface{}{}
+
+	if err := decode(x, &a); err != nil {
+		return true
+	}
+	if err := decode(y, &b); err != nil {
+		return true
+	}
+
+	for _, k := range []string{"UpdatedAt", "ModifyIndex"} {
+		delete(a, k)
+		delete(b, k)
+	}
+
+	return !reflect.DeepEqual(a, b)
+}
+
+func decode(in interface{}, out *map[string]interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,21 @@
+package drago
+
+import (
+	structs "github.com/seashell/drago/drago/structs"
+	filter "github.com/seashell/drago/pkg/filter"
+)
+
+// filterItems removes the items of a list response, passed as a pointer to a slice
+// of stubs, which do not match the filter expression in the query options, if any.
+func filterItems(items interface{}, opts structs.QueryOptions) error {
+
+	if opts.Filter == "" {
+		return nil
+	}
+
+	if err := filter.Filter(items, opts.Filter); err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	return nil
+}
@@ -21,15 +21,17 @@ type InterfaceService struct {
 	config      *Config
 	logger      log.Logger
 	state       state.Repository
+	auditor     *Auditor
 	authHandler auth.AuthorizationHandler
 }
 
 // NewInterfaceService ...
-func NewInterfaceService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) *InterfaceService {
+func NewInterfaceService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *InterfaceService {
 	return &InterfaceService{
 		config:      config,
 		logger:      logger,
 		state:       state,
+		auditor:     auditor,
 		authHandler: authHandler,
 	}
 }
@@ -38,20 +40,21 @@ func NewInterfaceService(config *Config, logger log.Logger, state state.Reposito
 func (s *InterfaceService) GetInterface(args *structs.InterfaceSpecificRequest, out *structs.SingleInterfaceResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", args.InterfaceID, InterfaceRead); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, args.InterfaceID), InterfaceRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	n, err := s.state.InterfaceByID(ctx, args.InterfaceID)
+	n, err := interfaceInNamespace(ctx, s.state, ns, args.InterfaceID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
-	out.Interface = n
+	out.Interface = n.Sanitize()
 
 	return nil
 }
@@ -60,54 +63,76 @@ func (s *InterfaceService) GetInterface(args *structs.InterfaceSpecificRequest,
 func (s *InterfaceService) ListInterfaces(args *structs.InterfaceListRequest, out *structs.InterfaceListResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", "", InterfaceList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceList); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
 	out.Items = nil
 
-	var err error
-	var interfaces []*structs.Interface
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
 
-	if args.NodeID != "" {
-		if interfaces, err = s.state.InterfacesByNodeID(ctx, args.NodeID); err != nil {
-			return structs.ErrInternal
-		}
-	} else if args.NetworkID != "" {
-		if interfaces, err = s.state.InterfacesByNetworkID(ctx, args.NetworkID); err != nil {
-			return structs.ErrInternal
-		}
-	} else {
-		if interfaces, err = s.state.Interfaces(ctx); err != nil {
-			return structs.ErrInternal
+		var err error
+		var next string
+		var interfaces []*structs.Interface
+
+		// Interfaces of a node or network are read at once, as
+		// finding them requires reading all interfaces anyway.
+		if args.NodeID != "" {
+			if interfaces, err = s.state.InterfacesByNodeID(ctx, args.NodeID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else if args.NetworkID != "" {
+			if interfaces, err = s.state.InterfacesByNetworkID(ctx, args.NetworkID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else {
+			if interfaces, next, err = s.state.InterfacesPage(ctx, opts); err != nil {
+				return "", structs.ErrInternal
+			}
 		}
-	}
 
-	for _, i := range interfaces {
-		if args.NetworkID != "" {
-			if i.NetworkID == args.NetworkID {
+		for _, i := range interfaces {
+			if !inNamespace(i.Namespace, ns) {
+				continue
+			}
+			if args.NetworkID != "" {
+				if i.NetworkID == args.NetworkID {
+					out.Items = append(out.Items, i.Stub())
+				}
+			} else {
 				out.Items = append(out.Items, i.Stub())
 			}
-		} else {
-			out.Items = append(out.Items, i.Stub())
 		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // UpsertInterface upserts a new Interface entity, which results in a node being added to a network
-func (s *InterfaceService) UpsertInterface(args *structs.InterfaceUpsertRequest, out *structs.GenericResponse) error {
+func (s *InterfaceService) UpsertInterface(args *structs.InterfaceUpsertRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Interface.UpsertInterface", args.AuthToken, ns, "interface", auditID(&args.Interface.ID))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", "", InterfaceWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
@@ -115,133 +140,301 @@ func (s *InterfaceService) UpsertInterface(args *structs.InterfaceUpsertRequest,
 	i := args.Interface
 
 	// Make sure the input is valid
-	err := i.Validate()
+	err = i.Validate()
 	if err != nil {
 		return structs.NewInvalidInputError(err.Error())
 	}
 
-	// If the interface already exists, we simply merge the new values into the existing struct.
-	// Otherwise, we generate a new ID and set the protected attributes in preparation for inserting
-	// the new struct into the repository.
-	if i.ID != "" {
-		old, err := s.state.InterfaceByID(ctx, i.ID)
-		if err != nil {
-			return structs.ErrNotFound // interface does not exist
-		}
-		i = old.Merge(i)
-	} else {
+	// New interfaces are assigned an ID up front, so that it
+	// is preserved if the upsert is retried after a conflict.
+	isNewInterface := i.ID == ""
+	if isNewInterface {
 		i.ID = uuid.Generate()
-		i.Name = nil                // Setting name is responsibility of the client node
-		i.Address = nil             // TODO: set with leasing plugin if it is loaded and enabled
-		i.Peers = []*structs.Peer{} // TODO: set with meshing plugin if it is loaded and enabled
-		i.CreatedAt = time.Now()
 	}
 
-	// Retrieve the network to which the interface is meant to be added, throwing an error if it does not exist
-	network, err := s.state.NetworkByID(ctx, i.NetworkID)
-	if err != nil {
-		return structs.ErrInternal // network does not exist
-	}
+	var networkID string
+
+	// Addresses are allocated within the transaction, so that concurrent upserts
+	// allocating the same address conflict, and are retried with a fresh view of
+	// the addresses in use.
+	err = retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			i := args.Interface
+
+			// If the interface already exists, we simply merge the new values into the existing struct.
+			// Otherwise, we set the protected attributes in preparation for inserting the new struct
+			// into the repository.
+			if !isNewInterface {
+				old, err := interfaceInNamespace(ctx, s.state, ns, i.ID)
+				if err != nil {
+					return err // interface does not exist
+				}
+				i = old.Merge(i)
+			} else {
+				created := *i
+				i = &created
+				i.Name = nil                // Setting name is responsibility of the client node
+				i.Peers = []*structs.Peer{} // TODO: set with meshing plugin if it is loaded and enabled
+				i.External = false          // External peers are created through the peer service
+				i.PrivateKey = nil
+				i.CreatedAt = time.Now()
+			}
+
+			// External interfaces have no node, so only their address and DNS servers can be updated
+			if i.External {
+				return s.upsertExternalInterface(ctx, i)
+			}
+
+			// Retrieve the network to which the interface is meant to be added, throwing an error if it does not exist
+			network, err := networkInNamespace(ctx, s.state, ns, i.NetworkID)
+			if err != nil {
+				return structs.ErrInternal // network does not exist
+			}
 
-	// Retrieve the node to which the interface is meant to be added, throwing an error if it does not exist
-	node, err := s.state.NodeByID(ctx, i.NodeID)
+			// Retrieve the node to which the interface is meant to be added, throwing an error if it does not exist
+			node, err := nodeInNamespace(ctx, s.state, ns, i.NodeID)
+			if err != nil {
+				return structs.ErrInternal // node does not exist
+			}
+
+			i.Namespace = network.Namespace
+
+			// Retrieve the already existing interfaces of the targeted node
+			nodeInterfaces, err := s.state.InterfacesByNodeID(ctx, node.ID)
+			if err != nil {
+				return structs.ErrInternal // error getting node interfaces
+			}
+
+			// Make sure that the node will not end up with two interfaces in the same network
+			for _, iface := range nodeInterfaces {
+				if iface.NetworkID == network.ID && i.ID != iface.ID {
+					return structs.NewInternalError("Network already joined")
+				}
+			}
+
+			// Allocate an address for the interface in case it has none,
+			// or make sure the address it has does not collide with others.
+			if err := assignInterfaceAddress(ctx, s.state, network, i); err != nil {
+				return err
+			}
+
+			i.UpdatedAt = time.Now()
+
+			node.UpsertInterface(i.ID)
+			if err := s.state.UpsertNode(ctx, node); err != nil {
+				return structs.ErrInternal // could not update network with the new interface
+			}
+
+			network.UpsertInterface(i.ID)
+			if err := s.state.UpsertNetwork(ctx, network); err != nil {
+				return structs.ErrInternal // could not update network with the new interface
+			}
+
+			if err := s.state.UpsertInterface(ctx, i); err != nil {
+				return structs.ErrInternal // could not create interface
+			}
+
+			networkID = network.ID
+
+			return nil
+		})
+	})
 	if err != nil {
-		return structs.ErrInternal // node does not exist
+		return err
 	}
 
-	// Retrieve the already existing interfaces of the targeted node
-	nodeInterfaces, err := s.state.InterfacesByNodeID(ctx, node.ID)
-	if err != nil {
-		return structs.ErrInternal // error getting node interfaces
+	// External interfaces are not part of the network topology
+	if networkID == "" {
+		return nil
 	}
 
-	// Make sure that the node will not end up with two interfaces in the same network
-	for _, iface := range nodeInterfaces {
-		if iface.NetworkID == network.ID && i.ID != iface.ID {
-			return structs.NewInternalError("Network already joined")
-		}
+	if err := reconcileNetworkTopology(ctx, s.state, networkID); err != nil {
+		s.logger.Warnf("error reconciling topology of network %s: %v", networkID, err)
 	}
 
-	i.UpdatedAt = time.Now()
+	return nil
+}
 
-	// TODO: wrap in a transaction
+// upsertExternalInterface updates an existing external interface, along with its network.
+func (s *InterfaceService) upsertExternalInterface(ctx context.Context, i *structs.Interface) error {
 
-	node.UpsertInterface(i.ID)
-	err = s.state.UpsertNode(ctx, node)
-	if err != nil {
-		return structs.ErrInternal // could not update network with the new interface
+	if i.NodeID != "" {
+		return structs.NewInvalidInputError("External interfaces can't be attached to a node")
 	}
 
-	network.UpsertInterface(i.ID)
-	err = s.state.UpsertNetwork(ctx, network)
+	network, err := s.state.NetworkByID(ctx, i.NetworkID)
 	if err != nil {
-		return structs.ErrInternal // could not update network with the new interface
+		return structs.ErrInternal // network does not exist
 	}
 
-	err = s.state.UpsertInterface(ctx, i)
-	if err != nil {
-		return structs.ErrInternal // could not create interface
+	if err := assignInterfaceAddress(ctx, s.state, network, i); err != nil {
+		return err
 	}
 
-	return nil
+	i.UpdatedAt = time.Now()
+
+	return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+		network.UpsertInterface(i.ID)
+		if err := s.state.UpsertNetwork(ctx, network); err != nil {
+			return structs.ErrInternal // could not update network with the interface
+		}
+
+		if err := s.state.UpsertInterface(ctx, i); err != nil {
+			return structs.ErrInternal // could not update interface
+		}
+
+		return nil
+	})
 }
 
-// DeleteInterface deletes an interface entity from the repository
-func (s *InterfaceService) DeleteInterface(args *structs.InterfaceDeleteRequest, out *structs.GenericResponse) error {
+// RotateKeys requests the nodes to rotate the key pairs of the interfaces of a node
+// or of a network. Each node generates a new key pair and publishes its public key
+// before switching to it, so that peers can be updated as soon as it switches.
+func (s *InterfaceService) RotateKeys(args *structs.InterfaceRotateKeysRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	target, id := "network", &args.NetworkID
+	if args.NodeID != "" {
+		target, id = "node", &args.NodeID
+	}
+	defer s.auditor.Begin(ctx, "Interface.RotateKeys", args.AuthToken, ns, target, auditID(id))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", "", InterfaceWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	for _, id := range args.InterfaceIDs {
-		if iface, err := s.state.InterfaceByID(ctx, id); err == nil {
+	if (args.NodeID == "") == (args.NetworkID == "") {
+		return structs.NewInvalidInputError("Either NodeID or NetworkID must be set")
+	}
 
-			network, err := s.state.NetworkByID(ctx, iface.NetworkID)
-			if err != nil {
-				return structs.NewInternalError(err.Error())
+	return retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			var interfaces []*structs.Interface
+			var err error
+
+			if args.NodeID != "" {
+				if _, err := nodeInNamespace(ctx, s.state, ns, args.NodeID); err != nil {
+					return err
+				}
+				interfaces, err = s.state.InterfacesByNodeID(ctx, args.NodeID)
+			} else {
+				if _, err := networkInNamespace(ctx, s.state, ns, args.NetworkID); err != nil {
+					return err
+				}
+				interfaces, err = s.state.InterfacesByNetworkID(ctx, args.NetworkID)
 			}
-
-			node, err := s.state.NodeByID(ctx, iface.NodeID)
 			if err != nil {
 				return structs.NewInternalError(err.Error())
 			}
 
-			connections, err := s.state.ConnectionsByInterfaceID(ctx, iface.ID)
-			if err != nil {
-				return structs.NewInternalError(err.Error())
+			for _, iface := range interfaces {
+				// Interfaces without a public key have not been configured by
+				// their nodes yet, so there is no key to be rotated.
+				// External interfaces are not managed by any node either.
+				if iface.PublicKey == nil || iface.RotateKey || iface.External {
+					continue
+				}
+				iface.RotateKey = true
+				iface.UpdatedAt = time.Now()
+				if err := s.state.UpsertInterface(ctx, iface); err != nil {
+					return structs.NewInternalError(err.Error())
+				}
 			}
 
-			connectionIDs := []string{}
-			for _, c := range connections {
-				connectionIDs = append(connectionIDs, c.ID)
-				network.RemoveConnection(c.ID)
-			}
+			return nil
+		})
+	})
+}
 
-			network.RemoveInterface(iface.ID)
-			node.RemoveInterface(iface.ID)
+// DeleteInterface deletes an interface entity from the repository
+func (s *InterfaceService) DeleteInterface(args *structs.InterfaceDeleteRequest, out *structs.GenericResponse) (err error) {
 
-			if err := s.state.DeleteConnections(ctx, connectionIDs); err != nil {
-				return structs.NewInternalError(err.Error())
-			}
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
-			if err := s.state.UpsertNetwork(ctx, network); err != nil {
+	defer s.auditor.Begin(ctx, "Interface.DeleteInterface", args.AuthToken, ns, "interface", func() []string { return args.InterfaceIDs })(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	networkIDs := map[string]struct{}{}
+
+	for _, id := range args.InterfaceIDs {
+
+		iface, err := interfaceInNamespace(ctx, s.state, ns, id)
+		if err != nil {
+			continue
+		}
+
+		if err := deleteInterface(ctx, s.state, iface); err != nil {
+			return err
+		}
+
+		networkIDs[iface.NetworkID] = struct{}{}
+	}
+
+	for id := range networkIDs {
+		if err := reconcileNetworkTopology(ctx, s.state, id); err != nil {
+			s.logger.Warnf("error reconciling topology of network %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteInterface removes an interface from the repository, along with its connections
+// and the references to it in its node and network. Since addresses are allocated based
+// on the interfaces in the repository, the address of the interface is also released.
+func deleteInterface(ctx context.Context, repo state.Repository, iface *structs.Interface) error {
+
+	// Remove the connections of the interface, which also
+	// updates the peer interfaces, nodes, and network.
+	if err := deleteConnections(ctx, repo, iface.Connections); err != nil {
+		return err
+	}
+
+	return withTransaction(ctx, repo, func(ctx context.Context) error {
+
+		network, err := repo.NetworkByID(ctx, iface.NetworkID)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		network.RemoveInterface(iface.ID)
+		if err := repo.UpsertNetwork(ctx, network); err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		// External interfaces have no node
+		if !iface.External {
+
+			node, err := repo.NodeByID(ctx, iface.NodeID)
+			if err != nil {
 				return structs.NewInternalError(err.Error())
 			}
 
-			if err := s.state.UpsertNode(ctx, node); err != nil {
+			node.RemoveInterface(iface.ID)
+			if err := repo.UpsertNode(ctx, node); err != nil {
 				return structs.NewInternalError(err.Error())
 			}
 		}
-	}
 
-	if err := s.state.DeleteInterfaces(ctx, args.InterfaceIDs); err != nil {
-		return structs.ErrInternal
-	}
+		if err := repo.DeleteInterfaces(ctx, []string{iface.ID}); err != nil {
+			return structs.ErrInternal
+		}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,176 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestRotateKeys(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	a := testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+
+	// Interfaces not configured by their nodes yet have no key to rotate
+	c := testNode(t, repo, "net", "c", "10.0.0.3/24", false)
+	c.PublicKey = nil
+	repo.UpsertInterface(ctx, c)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	interfaces := NewInterfaceService(testConfig(), testLogger(t), repo, nil, nil)
+	nodes := testNodeService(t, repo, testConfig(), nil)
+
+	if err := interfaces.RotateKeys(&structs.InterfaceRotateKeysRequest{}, &structs.GenericResponse{}); err == nil {
+		t.Fatalf("expected interfaces.RotateKeys() to fail without node or network")
+	}
+
+	if err := interfaces.RotateKeys(&structs.InterfaceRotateKeysRequest{NetworkID: "net"}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("interfaces.RotateKeys() failed: %v", err)
+	}
+
+	if iface, _ := repo.InterfaceByID(ctx, a.ID); !iface.RotateKey {
+		t.Fatalf("expected rotation to be requested")
+	}
+	if iface, _ := repo.InterfaceByID(ctx, c.ID); iface.RotateKey {
+		t.Fatalf("expected no rotation to be requested for interface without public key")
+	}
+
+	report := func(publicKey, pendingPublicKey *string) {
+		args := &structs.NodeInterfaceUpdateRequest{
+			NodeID: "a",
+			Interfaces: []*structs.Interface{
+				{ID: a.ID, PublicKey: publicKey, PendingPublicKey: pendingPublicKey},
+			},
+		}
+		if err := nodes.UpdateInterfaces(args, &structs.GenericResponse{}); err != nil {
+			t.Fatalf("nodes.UpdateInterfaces() failed: %v", err)
+		}
+	}
+
+	// The node publishes the public key of its next key pair, which is
+	// recorded as pending, while peers keep using the current one.
+	report(testStrPtr("a-key"), testStrPtr("a-key-2"))
+
+	iface, _ := repo.InterfaceByID(ctx, a.ID)
+	if !iface.RotateKey || iface.PendingPublicKey == nil || *iface.PendingPublicKey != "a-key-2" {
+		t.Fatalf("expected pending public key to be recorded")
+	}
+	if keys := testPeerKeys(t, nodes, "b"); len(keys) != 1 {
+		t.Fatalf("expected 1 peer, got %v", keys)
+	} else if _, ok := keys["a-key"]; !ok {
+		t.Fatalf("expected peers to keep the current key while the rotation is pending, got %v", keys)
+	}
+
+	// Once the node switches to the new key pair, the rotation is
+	// cleared, and the new public key is distributed to peers.
+	report(testStrPtr("a-key-2"), nil)
+
+	iface, _ = repo.InterfaceByID(ctx, a.ID)
+	if iface.RotateKey || iface.PendingPublicKey != nil || *iface.PublicKey != "a-key-2" {
+		t.Fatalf("expected rotation to be completed, got RotateKey %t and PendingPublicKey %v", iface.RotateKey, iface.PendingPublicKey)
+	}
+	if keys := testPeerKeys(t, nodes, "b"); len(keys) != 1 {
+		t.Fatalf("expected 1 peer, got %v", keys)
+	} else if _, ok := keys["a-key-2"]; !ok {
+		t.Fatalf("expected peers to use the new key, got %v", keys)
+	}
+
+	// Reports without a key change leave the interface untouched
+	report(testStrPtr("a-key-2"), nil)
+	if iface, _ = repo.InterfaceByID(ctx, a.ID); iface.RotateKey || *iface.PublicKey != "a-key-2" {
+		t.Fatalf("expected interface to be left untouched")
+	}
+}
+
+// barrierRepository is a repository in which the first n reads of the interfaces
+// of a network all return before any of them does, so that callers act on the
+// same view of the addresses in use.
+type barrierRepository struct {
+	*inmem.StateRepository
+
+	waiting int
+	release chan struct{}
+	lock    sync.Mutex
+}
+
+func (r *barrierRepository) InterfacesByNetworkID(ctx context.Context, id string) ([]*structs.Interface, error) {
+
+	interfaces, err := r.StateRepository.InterfacesByNetworkID(ctx, id)
+
+	r.lock.Lock()
+	if r.waiting--; r.waiting == 0 {
+		close(r.release)
+	}
+	r.lock.Unlock()
+
+	<-r.release
+
+	return interfaces, err
+}
+
+func TestUpsertInterfaceConcurrentAllocation(t *testing.T) {
+
+	n := 4
+
+	repo := &barrierRepository{StateRepository: testState(), waiting: n, release: make(chan struct{})}
+	ctx := context.Background()
+
+	testNetwork(t, repo.StateRepository, "net", "10.0.0.0/24", structs.NetworkTopologyManual)
+
+	s := NewInterfaceService(testConfig(), testLogger(t), repo, nil, nil)
+
+	// Interfaces created concurrently are allocated the same address at first,
+	// and conflict on their network, in which case addresses are allocated
+	// again, instead of the upsert failing.
+	errs := make(chan error, n)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("n%d", i)
+		if err := repo.UpsertNode(ctx, &structs.Node{ID: id, Namespace: structs.DefaultNamespace}); err != nil {
+			t.Fatalf("repo.UpsertNode() failed: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			args := &structs.InterfaceUpsertRequest{Interface: &structs.Interface{NodeID: id, NetworkID: "net"}}
+			errs <- s.UpsertInterface(args, &structs.GenericResponse{})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("s.UpsertInterface() failed: %v", err)
+		}
+	}
+
+	interfaces, err := repo.StateRepository.InterfacesByNetworkID(ctx, "net")
+	if err != nil {
+		t.Fatalf("repo.InterfacesByNetworkID() failed: %v", err)
+	}
+	if len(interfaces) != n {
+		t.Fatalf("expected %d interfaces, got %d", n, len(interfaces))
+	}
+
+	addresses := map[string]struct{}{}
+	for _, iface := range interfaces {
+		if _, ok := addresses[*iface.Address]; ok {
+			t.Fatalf("expected unique addresses, got %s twice", *iface.Address)
+		}
+		addresses[*iface.Address] = struct{}{}
+	}
+}
@@ -0,0 +1,92 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	ipam "github.com/seashell/drago/pkg/ipam"
+)
+
+// newAddressAllocator returns an address allocator for the network's address range,
+// with the addresses of all interfaces in the network already reserved. The interface
+// whose ID is passed as argument, if any, is skipped, so that its address can be validated
+// against the other allocations. Since the allocations are derived from the interfaces in
+// the repository, addresses are released as soon as the interfaces using them are deleted.
+func newAddressAllocator(ctx context.Context, repo state.Repository, network *structs.Network, skipID string) (*ipam.Allocator, error) {
+
+	allocator, err := ipam.NewAllocator(network.AddressRange)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, err := repo.InterfacesByNetworkID(ctx, network.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range interfaces {
+		if iface.ID == skipID || iface.Address == nil {
+			continue
+		}
+		// Addresses which are invalid or outside of the network range are
+		// ignored, as they can't collide with any allocation.
+		allocator.Reserve(*iface.Address)
+	}
+
+	return allocator, nil
+}
+
+// assignInterfaceAddress makes sure the interface has a valid address within the network
+// range. If the interface has no address, a free one is allocated. Otherwise, the address
+// is checked for collisions with the addresses of the other interfaces in the network.
+func assignInterfaceAddress(ctx context.Context, repo state.Repository, network *structs.Network, iface *structs.Interface) error {
+
+	allocator, err := newAddressAllocator(ctx, repo, network, iface.ID)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	if iface.Address == nil {
+		addr, err := allocator.Allocate()
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+		iface.Address = &addr
+		return nil
+	}
+
+	if err := allocator.Reserve(*iface.Address); err != nil {
+		return structs.NewInvalidInputError(fmt.Sprintf("%s: %v", *iface.Address, err))
+	}
+
+	return nil
+}
+
+// checkNetworkAddresses makes sure the addresses of all interfaces in the network are
+// within its address range, which is required before the range can be changed, since
+// interfaces are not assigned new addresses.
+func checkNetworkAddresses(ctx context.Context, repo state.Repository, network *structs.Network) error {
+
+	allocator, err := ipam.NewAllocator(network.AddressRange)
+	if err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	interfaces, err := repo.InterfacesByNetworkID(ctx, network.ID)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	for _, iface := range interfaces {
+		if iface.Address == nil {
+			continue
+		}
+		if err := allocator.Reserve(*iface.Address); err != nil {
+			return structs.NewInvalidInputError(fmt.Sprintf("Address %s of interface %s: %v", *iface.Address, iface.ID, err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+	uuid "github.com/seashell/drago/pkg/uuid"
+)
+
+// JoinTokenService is used for managing the tokens which
+// authorize the registration of new nodes.
+type JoinTokenService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	auditor     *Auditor
+	authHandler auth.AuthorizationHandler
+}
+
+// NewJoinTokenService ...
+func NewJoinTokenService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *JoinTokenService {
+	return &JoinTokenService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		auditor:     auditor,
+		authHandler: authHandler,
+	}
+}
+
+// GetJoinToken returns a JoinToken entity by ID
+func (s *JoinTokenService) GetJoinToken(args *structs.JoinTokenSpecificRequest, out *structs.SingleJoinTokenResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeRead); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	t, err := s.state.JoinTokenByID(ctx, args.JoinTokenID)
+	if err != nil || !inNamespace(t.Namespace, ns) {
+		return structs.ErrNotFound
+	}
+
+	out.JoinToken = t
+
+	return nil
+}
+
+// CreateJoinToken creates a new JoinToken entity in the namespace of the request. Networks
+// the token is bound to must be in the same namespace, and can be referenced either by ID
+// or by name, and are stored by ID.
+func (s *JoinTokenService) CreateJoinToken(args *structs.JoinTokenCreateRequest, out *structs.JoinTokenCreateResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "JoinToken.CreateJoinToken", args.AuthToken, ns, "join-token", func() []string {
+		if out.JoinToken == nil {
+			return nil
+		}
+		return []string{out.JoinToken.ID}
+	})(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	if err := checkNamespaceExists(ctx, s.state, ns); err != nil {
+		return err
+	}
+
+	t := args.JoinToken
+	if t == nil {
+		return structs.NewInvalidInputError("Missing join token")
+	}
+
+	if err := t.Validate(); err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	all, err := s.state.Networks(ctx)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	networks := []string{}
+	for _, ref := range t.Networks {
+		var found *structs.Network
+		for _, n := range all {
+			if inNamespace(n.Namespace, ns) && (n.ID == ref || n.Name == ref) {
+				found = n
+				break
+			}
+		}
+		if found == nil {
+			return structs.NewInvalidInputError(fmt.Sprintf("Network %s not found", ref))
+		}
+		networks = append(networks, found.ID)
+	}
+
+	t.ID = uuid.Generate()
+	t.Namespace = ns
+	t.Secret = uuid.Generate()
+	t.Networks = networks
+	t.Uses = 0
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	t.ModifyIndex = 0
+
+	if err := s.state.UpsertJoinToken(ctx, t); err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	out.JoinToken = t
+
+	return nil
+}
+
+// DeleteJoinToken deletes JoinToken entities from the repository
+func (s *JoinTokenService) DeleteJoinToken(args *structs.JoinTokenDeleteRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "JoinToken.DeleteJoinToken", args.AuthToken, ns, "join-token", func() []string { return args.JoinTokenIDs })(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	for _, id := range args.JoinTokenIDs {
+		if t, err := s.state.JoinTokenByID(ctx, id); err != nil || !inNamespace(t.Namespace, ns) {
+			return structs.ErrNotFound
+		}
+	}
+
+	if err := s.state.DeleteJoinTokens(ctx, args.JoinTokenIDs); err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	return nil
+}
+
+// ListJoinTokens retrieves all JoinToken entities in the repository
+func (s *JoinTokenService) ListJoinTokens(args *structs.JoinTokenListRequest, out *structs.JoinTokenListResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeList); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	out.Items = nil
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		tokens, next, err := s.state.JoinTokensPage(ctx, opts)
+		if err != nil {
+			return "", structs.NewInternalError(err.Error())
+		}
+
+		for _, t := range tokens {
+			if inNamespace(t.Namespace, ns) {
+				out.Items = append(out.Items, t.Stub())
+			}
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// consumeJoinToken checks whether the token with the secret passed as argument can
+// be used for registering a node in namespace ns and, if so, increments its number
+// of uses. It must be called within the transaction in which the node is registered,
+// so that tokens are not consumed if the registration fails, nor used beyond their limit.
+func consumeJoinToken(ctx context.Context, repo state.Repository, ns, secret string) (*structs.JoinToken, error) {
+
+	t, err := repo.JoinTokenBySecret(ctx, secret)
+	if err != nil || t == nil || !inNamespace(t.Namespace, ns) {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	if !t.IsUsable(time.Now()) {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	t.Uses++
+	t.UpdatedAt = time.Now()
+
+	if err := repo.UpsertJoinToken(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,113 @@
+package drago
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seashell/drago/drago/auth"
+	"github.com/seashell/drago/drago/state"
+	"github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "drago",
+		Subsystem: "server",
+		Name:      "rpc_requests_total",
+		Help:      "Number of RPC requests served, by method and status.",
+	}, []string{"method", "status"})
+
+	rpcRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "drago",
+		Subsystem: "server",
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Time taken to serve RPC requests, by method. Includes the time blocking queries wait for changes.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	heartbeatMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "drago",
+		Subsystem: "server",
+		Name:      "heartbeat_misses_total",
+		Help:      "Number of heartbeats missed, by node.",
+	}, []string{"node_id"})
+
+	aclDenials = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "drago",
+		Subsystem: "server",
+		Name:      "acl_denials_total",
+		Help:      "Number of operations denied by the ACL system, by resource and operation.",
+	}, []string{"resource", "operation"})
+)
+
+func init() {
+	prometheus.MustRegister(rpcRequests, rpcRequestDuration, heartbeatMisses, aclDenials)
+}
+
+// observeRPC records the outcome of an RPC request.
+func observeRPC(method string, d time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	rpcRequests.WithLabelValues(method, status).Inc()
+	rpcRequestDuration.WithLabelValues(method).Observe(d.Seconds())
+}
+
+// instrumentedAuthHandler counts the operations denied by an authorization handler.
+type instrumentedAuthHandler struct {
+	auth.AuthorizationHandler
+}
+
+func (h *instrumentedAuthHandler) Authorize(ctx context.Context, sub, res, path, op string) error {
+	err := h.AuthorizationHandler.Authorize(ctx, sub, res, path, op)
+	if err != nil {
+		aclDenials.WithLabelValues(res, op).Inc()
+	}
+	return err
+}
+
+// nodeCollector exports the number of nodes by status, read from the
+// state whenever metrics are collected.
+type nodeCollector struct {
+	state  state.Repository
+	logger log.Logger
+	desc   *prometheus.Desc
+}
+
+func newNodeCollector(state state.Repository, logger log.Logger) *nodeCollector {
+	return &nodeCollector{
+		state:  state,
+		logger: logger,
+		desc: prometheus.NewDesc("drago_server_nodes",
+			"Number of nodes, by status.", []string{"status"}, nil),
+	}
+}
+
+func (c *nodeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *nodeCollector) Collect(ch chan<- prometheus.Metric) {
+
+	nodes, err := c.state.Nodes(context.TODO())
+	if err != nil {
+		c.logger.Warnf("could not collect node metrics: %v", err)
+		return
+	}
+
+	counts := map[string]int{
+		structs.NodeStatusInit:  0,
+		structs.NodeStatusReady: 0,
+		structs.NodeStatusDown:  0,
+	}
+	for _, n := range nodes {
+		counts[n.Status]++
+	}
+
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), status)
+	}
+}
@@ -0,0 +1,304 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+const (
+	NamespaceList  = "list"
+	NamespaceRead  = "read"
+	NamespaceWrite = "write"
+)
+
+// namespacedResources are the ACL resources whose instances belong to namespaces.
+// Their ACL paths are prefixed with the namespace of the instance, so that rules
+// on these resources only apply within the namespaces matched by the rule.
+var namespacedResources = map[string]bool{
+	"network":    true,
+	"node":       true,
+	"interface":  true,
+	"connection": true,
+	"audit":      true,
+}
+
+// namespacePath returns the ACL path of an instance of a namespaced resource.
+func namespacePath(ns, path string) string {
+	return ns + "/" + path
+}
+
+// inNamespace returns whether a resource belongs to a namespace, given the namespace
+// of the resource, which is empty for resources created before namespaces existed.
+func inNamespace(resourceNamespace, ns string) bool {
+	return structs.NamespaceOrDefault(resourceNamespace) == ns
+}
+
+// checkNamespaceExists returns an error if the namespace does not exist, which
+// is never the case for the default namespace.
+func checkNamespaceExists(ctx context.Context, repo state.Repository, ns string) error {
+	if ns == structs.DefaultNamespace {
+		return nil
+	}
+	if _, err := repo.NamespaceByName(ctx, ns); err != nil {
+		return structs.NewInvalidInputError(fmt.Sprintf("namespace %s not found", ns))
+	}
+	return nil
+}
+
+// NamespaceService :
+type NamespaceService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	auditor     *Auditor
+	authHandler auth.AuthorizationHandler
+}
+
+// NewNamespaceService ...
+func NewNamespaceService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *NamespaceService {
+	return &NamespaceService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		auditor:     auditor,
+		authHandler: authHandler,
+	}
+}
+
+// namespaceByName returns a namespace by name. The default namespace is
+// returned even if it was never written, since it always exists.
+func (s *NamespaceService) namespaceByName(ctx context.Context, name string) (*structs.Namespace, error) {
+	ns, err := s.state.NamespaceByName(ctx, name)
+	if err != nil && name == structs.DefaultNamespace {
+		return &structs.Namespace{Name: structs.DefaultNamespace, Description: "Default namespace"}, nil
+	}
+	return ns, err
+}
+
+// GetNamespace returns a Namespace entity by name
+func (s *NamespaceService) GetNamespace(args *structs.NamespaceSpecificRequest, out *structs.SingleNamespaceResponse) error {
+
+	ctx := context.TODO()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "namespace", args.Name, NamespaceRead); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	ns, err := s.namespaceByName(ctx, args.Name)
+	if err != nil {
+		return structs.ErrNotFound
+	}
+
+	out.Namespace = ns
+
+	return nil
+}
+
+// ListNamespaces retrieves the namespaces which the token can read
+func (s *NamespaceService) ListNamespaces(args *structs.NamespaceListRequest, out *structs.NamespaceListResponse) error {
+
+	ctx := context.TODO()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "namespace", "", NamespaceList); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	// The default namespace exists even if it was never stored
+	var def *structs.Namespace
+	if _, err := s.state.NamespaceByName(ctx, structs.DefaultNamespace); err != nil {
+		def, _ = s.namespaceByName(ctx, structs.DefaultNamespace)
+	}
+
+	out.Items = nil
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		namespaces, next, err := s.state.NamespacesPage(ctx, opts)
+		if err != nil {
+			return "", structs.ErrInternal
+		}
+
+		// Add the default namespace to the page it falls within
+		if def != nil && def.Name >= opts.StartID && (next == "" || def.Name < next) {
+			namespaces = append(namespaces, def)
+		}
+
+		for _, ns := range namespaces {
+			if s.config.ACL.Enabled {
+				if err := s.authHandler.Authorize(ctx, args.AuthToken, "namespace", ns.Name, NamespaceRead); err != nil {
+					continue
+				}
+			}
+			out.Items = append(out.Items, ns.Stub())
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpsertNamespace creates a namespace, or updates its description if it exists
+func (s *NamespaceService) UpsertNamespace(args *structs.NamespaceUpsertRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+
+	defer s.auditor.Begin(ctx, "Namespace.UpsertNamespace", args.AuthToken, "", "namespace", auditID(&args.Namespace.Name))(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "namespace", args.Namespace.Name, NamespaceWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	ns := args.Namespace
+
+	if err = ns.Validate(); err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	old, err := s.namespaceByName(ctx, ns.Name)
+	if err != nil {
+		ns.CreatedAt = time.Now()
+	} else {
+		ns = old.Merge(ns)
+	}
+
+	ns.UpdatedAt = time.Now()
+
+	err = withTransaction(ctx, s.state, func(ctx context.Context) error {
+		if err := s.state.UpsertNamespace(ctx, ns); err != nil {
+			return structs.ErrInternal
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNamespaces deletes namespaces, which must not contain
+// any networks, nodes or join tokens. The default namespace
+// can't be deleted.
+func (s *NamespaceService) DeleteNamespaces(args *structs.NamespaceDeleteRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+
+	defer s.auditor.Begin(ctx, "Namespace.DeleteNamespaces", args.AuthToken, "", "namespace", func() []string { return args.Names })(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		for _, name := range args.Names {
+			if err := s.authHandler.Authorize(ctx, args.AuthToken, "namespace", name, NamespaceWrite); err != nil {
+				return structs.ErrPermissionDenied
+			}
+		}
+	}
+
+	for _, name := range args.Names {
+		if name == structs.DefaultNamespace {
+			return structs.NewInvalidInputError("the default namespace can't be deleted")
+		}
+		if _, err := s.state.NamespaceByName(ctx, name); err != nil {
+			return structs.ErrNotFound
+		}
+	}
+
+	return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+		networks, err := s.state.Networks(ctx)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+		nodes, err := s.state.Nodes(ctx)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+		tokens, err := s.state.JoinTokens(ctx)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		for _, name := range args.Names {
+			for _, n := range networks {
+				if inNamespace(n.Namespace, name) {
+					return structs.NewInvalidInputError(fmt.Sprintf("namespace %s still contains networks", name))
+				}
+			}
+			for _, n := range nodes {
+				if inNamespace(n.Namespace, name) {
+					return structs.NewInvalidInputError(fmt.Sprintf("namespace %s still contains nodes", name))
+				}
+			}
+			for _, t := range tokens {
+				if inNamespace(t.Namespace, name) {
+					return structs.NewInvalidInputError(fmt.Sprintf("namespace %s still contains join tokens", name))
+				}
+			}
+		}
+
+		if err := s.state.DeleteNamespaces(ctx, args.Names); err != nil {
+			return structs.ErrInternal
+		}
+
+		return nil
+	})
+}
+
+// The following functions return a resource by ID, or structs.ErrNotFound if it
+// does not exist in the namespace passed, so that requests can't tell resources
+// in other namespaces apart from missing ones.
+
+func networkInNamespace(ctx context.Context, repo state.Repository, ns, id string) (*structs.Network, error) {
+	n, err := repo.NetworkByID(ctx, id)
+	if err != nil || !inNamespace(n.Namespace, ns) {
+		return nil, structs.ErrNotFound
+	}
+	return n, nil
+}
+
+func nodeInNamespace(ctx context.Context, repo state.Repository, ns, id string) (*structs.Node, error) {
+	n, err := repo.NodeByID(ctx, id)
+	if err != nil || !inNamespace(n.Namespace, ns) {
+		return nil, structs.ErrNotFound
+	}
+	return n, nil
+}
+
+func interfaceInNamespace(ctx context.Context, repo state.Repository, ns, id string) (*structs.Interface, error) {
+	i, err := repo.InterfaceByID(ctx, id)
+	if err != nil || !inNamespace(i.Namespace, ns) {
+		return nil, structs.ErrNotFound
+	}
+	return i, nil
+}
+
+func connectionInNamespace(ctx context.Context, repo state.Repository, ns, id string) (*structs.Connection, error) {
+	c, err := repo.ConnectionByID(ctx, id)
+	if err != nil || !inNamespace(c.Namespace, ns) {
+		return nil, structs.ErrNotFound
+	}
+	return c, nil
+}
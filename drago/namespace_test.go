@@ -0,0 +1,329 @@
+package drago
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	events "github.com/seashell/drago/drago/events"
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// testNamespaceResources stores the namespace team-a, along with a network, two nodes
+// with their interfaces, a connection between them, an external peer and a join token,
+// all in the default namespace.
+func testNamespaceResources(t *testing.T, repo *inmem.StateRepository) {
+
+	ctx := context.Background()
+
+	if err := repo.UpsertNamespace(ctx, &structs.Namespace{Name: "team-a"}); err != nil {
+		t.Fatalf("repo.UpsertNamespace() failed: %v", err)
+	}
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyManual)
+	a := testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	b := testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+
+	conn := &structs.Connection{
+		ID:        "conn",
+		Namespace: structs.DefaultNamespace,
+		NetworkID: "net",
+		PeerSettings: []*structs.PeerSettings{
+			{NodeID: a.NodeID, InterfaceID: a.ID, RoutingRules: &structs.RoutingRules{}},
+			{NodeID: b.NodeID, InterfaceID: b.ID, RoutingRules: &structs.RoutingRules{}},
+		},
+	}
+	if err := repo.UpsertConnection(ctx, conn); err != nil {
+		t.Fatalf("repo.UpsertConnection() failed: %v", err)
+	}
+
+	peer := &structs.Interface{
+		ID:        "peer",
+		Namespace: structs.DefaultNamespace,
+		NetworkID: "net",
+		Name:      testStrPtr("peer"),
+		Address:   testStrPtr("10.0.0.3/24"),
+		External:  true,
+	}
+	if err := repo.UpsertInterface(ctx, peer); err != nil {
+		t.Fatalf("repo.UpsertInterface() failed: %v", err)
+	}
+
+	token := &structs.JoinToken{ID: "token", Namespace: structs.DefaultNamespace, Secret: "join-secret"}
+	if err := repo.UpsertJoinToken(ctx, token); err != nil {
+		t.Fatalf("repo.UpsertJoinToken() failed: %v", err)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNamespaceResources(t, repo)
+
+	config := testConfig()
+	logger := testLogger(t)
+
+	networks := NewNetworkService(config, logger, repo, nil, nil)
+	nodes := testNodeService(t, repo, config, nil)
+	interfaces := NewInterfaceService(config, logger, repo, nil, nil)
+	connections := NewConnectionService(config, logger, repo, nil, nil)
+	peers := NewPeerService(config, logger, repo, nil, nil)
+	joinTokens := NewJoinTokenService(config, logger, repo, nil, nil)
+
+	read := structs.QueryOptions{Namespace: "team-a"}
+	write := structs.WriteRequest{Namespace: "team-a"}
+
+	// Resources in the default namespace are reported as not found
+	// from other namespaces, whether they are read or modified.
+	notFound := map[string]func() error{
+		"Network.GetNetwork": func() error {
+			return networks.GetNetwork(&structs.NetworkSpecificRequest{NetworkID: "net", QueryOptions: read}, &structs.SingleNetworkResponse{})
+		},
+		"Network.UpsertNetwork": func() error {
+			args := &structs.NetworkUpsertRequest{Network: &structs.Network{ID: "net", Name: "renamed", AddressRange: "10.0.0.0/24"}, WriteRequest: write}
+			return networks.UpsertNetwork(args, &structs.GenericResponse{})
+		},
+		"Network.DeleteNetwork": func() error {
+			return networks.DeleteNetwork(&structs.NetworkDeleteRequest{NetworkIDs: []string{"net"}, WriteRequest: write}, &structs.GenericResponse{})
+		},
+		"Node.GetNode": func() error {
+			return nodes.GetNode(&structs.NodeSpecificRequest{NodeID: "a", QueryOptions: read}, &structs.SingleNodeResponse{})
+		},
+		"Node.UpdateEligibility": func() error {
+			args := &structs.NodeUpdateEligibilityRequest{NodeID: "a", Eligibility: structs.NodeSchedulingIneligible, WriteRequest: write}
+			return nodes.UpdateEligibility(args, &structs.GenericResponse{})
+		},
+		"Node.UpdateDrain": func() error {
+			return nodes.UpdateDrain(&structs.NodeUpdateDrainRequest{NodeID: "a", Drain: true, WriteRequest: write}, &structs.GenericResponse{})
+		},
+		"Interface.GetInterface": func() error {
+			return interfaces.GetInterface(&structs.InterfaceSpecificRequest{InterfaceID: "a-iface", QueryOptions: read}, &structs.SingleInterfaceResponse{})
+		},
+		"Interface.UpsertInterface": func() error {
+			args := &structs.InterfaceUpsertRequest{Interface: &structs.Interface{ID: "a-iface", Name: testStrPtr("wg1")}, WriteRequest: write}
+			return interfaces.UpsertInterface(args, &structs.GenericResponse{})
+		},
+		"Connection.GetConnection": func() error {
+			return connections.GetConnection(&structs.ConnectionSpecificRequest{ConnectionID: "conn", QueryOptions: read}, &structs.SingleConnectionResponse{})
+		},
+		"Connection.UpsertConnection": func() error {
+			keepalive := 10
+			args := &structs.ConnectionUpsertRequest{Connection: &structs.Connection{ID: "conn", PersistentKeepalive: &keepalive}, WriteRequest: write}
+			return connections.UpsertConnection(args, &structs.GenericResponse{})
+		},
+		"Connection.DeleteConnection": func() error {
+			return connections.DeleteConnection(&structs.ConnectionDeleteRequest{ConnectionIDs: []string{"conn"}, WriteRequest: write}, &structs.GenericResponse{})
+		},
+		"Peer.GetConfig": func() error {
+			return peers.GetConfig(&structs.ExternalPeerConfigRequest{InterfaceID: "peer", QueryOptions: read}, &structs.ExternalPeerConfigResponse{})
+		},
+		"JoinToken.GetJoinToken": func() error {
+			return joinTokens.GetJoinToken(&structs.JoinTokenSpecificRequest{JoinTokenID: "token", QueryOptions: read}, &structs.SingleJoinTokenResponse{})
+		},
+		"JoinToken.DeleteJoinToken": func() error {
+			return joinTokens.DeleteJoinToken(&structs.JoinTokenDeleteRequest{JoinTokenIDs: []string{"token"}, WriteRequest: write}, &structs.GenericResponse{})
+		},
+	}
+
+	for op, fn := range notFound {
+		if err := fn(); err != structs.ErrNotFound {
+			t.Fatalf("%s: expected %v, got %v", op, structs.ErrNotFound, err)
+		}
+	}
+
+	// Interfaces in other namespaces are skipped when deleted in bulk
+	ifaceArgs := &structs.InterfaceDeleteRequest{InterfaceIDs: []string{"a-iface", "peer"}, WriteRequest: write}
+	if err := interfaces.DeleteInterface(ifaceArgs, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("Interface.DeleteInterface failed: %v", err)
+	}
+	if err := peers.DeletePeer(ifaceArgs, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("Peer.DeletePeer failed: %v", err)
+	}
+
+	// Resources in other namespaces can not be connected either
+	args := &structs.ConnectionUpsertRequest{
+		Connection: &structs.Connection{
+			PeerSettings: []*structs.PeerSettings{{InterfaceID: "a-iface"}, {InterfaceID: "peer"}},
+		},
+		WriteRequest: write,
+	}
+	if err := connections.UpsertConnection(args, &structs.GenericResponse{}); err == nil {
+		t.Fatalf("Connection.UpsertConnection: expected interfaces in other namespaces not to be connected")
+	}
+
+	peerArgs := &structs.ExternalPeerCreateRequest{NetworkID: "net", Name: "intruder", WriteRequest: write}
+	if err := peers.CreatePeer(peerArgs, &structs.ExternalPeerCreateResponse{}); err != structs.ErrNotFound {
+		t.Fatalf("Peer.CreatePeer: expected %v, got %v", structs.ErrNotFound, err)
+	}
+
+	// Lists in other namespaces are empty
+	lists := map[string]func() (int, error){
+		"Network.ListNetworks": func() (int, error) {
+			out := &structs.NetworkListResponse{}
+			err := networks.ListNetworks(&structs.NetworkListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+		"Node.ListNodes": func() (int, error) {
+			out := &structs.NodeListResponse{}
+			err := nodes.ListNodes(&structs.NodeListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+		"Interface.ListInterfaces": func() (int, error) {
+			out := &structs.InterfaceListResponse{}
+			err := interfaces.ListInterfaces(&structs.InterfaceListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+		"Connection.ListConnections": func() (int, error) {
+			out := &structs.ConnectionListResponse{}
+			err := connections.ListConnections(&structs.ConnectionListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+		"Peer.ListPeers": func() (int, error) {
+			out := &structs.InterfaceListResponse{}
+			err := peers.ListPeers(&structs.InterfaceListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+		"JoinToken.ListJoinTokens": func() (int, error) {
+			out := &structs.JoinTokenListResponse{}
+			err := joinTokens.ListJoinTokens(&structs.JoinTokenListRequest{QueryOptions: read}, out)
+			return len(out.Items), err
+		},
+	}
+
+	for op, fn := range lists {
+		n, err := fn()
+		if err != nil {
+			t.Fatalf("%s failed: %v", op, err)
+		}
+		if n != 0 {
+			t.Fatalf("%s: expected no items in namespace team-a, got %d", op, n)
+		}
+	}
+
+	// Nothing was modified in the default namespace
+	if n, err := repo.NetworkByID(ctx, "net"); err != nil || n.Name != "net" {
+		t.Fatalf("expected network to be left untouched")
+	}
+	if n, err := repo.NodeByID(ctx, "a"); err != nil || n.Drain || !n.IsEligible() {
+		t.Fatalf("expected node to be left untouched")
+	}
+	if i, err := repo.InterfaceByID(ctx, "a-iface"); err != nil || *i.Name != "wg0" {
+		t.Fatalf("expected interface to be left untouched")
+	}
+	if c, err := repo.ConnectionByID(ctx, "conn"); err != nil || c.PersistentKeepalive != nil {
+		t.Fatalf("expected connection to be left untouched")
+	}
+	if _, err := repo.InterfaceByID(ctx, "peer"); err != nil {
+		t.Fatalf("expected peer to be left untouched")
+	}
+	if _, err := repo.JoinTokenByID(ctx, "token"); err != nil {
+		t.Fatalf("expected join token to be left untouched")
+	}
+
+	// Whereas they are all visible from the default namespace
+	for op, fn := range lists {
+		if n, err := func() (int, error) {
+			read.Namespace = ""
+			defer func() { read.Namespace = "team-a" }()
+			return fn()
+		}(); err != nil || n == 0 {
+			t.Fatalf("%s: expected items in the default namespace, got %d (%v)", op, n, err)
+		}
+	}
+}
+
+func TestNamespaceEvents(t *testing.T) {
+
+	broker := events.NewBroker(events.DefaultBufferSize)
+	repo := events.NewRepository(testState(), broker)
+	ctx := context.Background()
+
+	repo.UpsertNetwork(ctx, &structs.Network{ID: "default-net", Namespace: structs.DefaultNamespace, Name: "default-net"})
+	repo.UpsertNetwork(ctx, &structs.Network{ID: "team-net", Namespace: "team-a", Name: "team-net"})
+
+	s := NewEventService(testConfig(), testLogger(t), broker, nil)
+
+	list := func(ns string) []string {
+		out := &structs.EventListResponse{}
+		args := &structs.EventListRequest{QueryOptions: structs.QueryOptions{Namespace: ns, MaxQueryTime: time.Millisecond}}
+		if err := s.ListEvents(args, out); err != nil {
+			t.Fatalf("s.ListEvents() failed: %v", err)
+		}
+		keys := []string{}
+		for _, e := range out.Items {
+			keys = append(keys, e.Key)
+		}
+		return keys
+	}
+
+	if keys := list("team-a"); len(keys) != 1 || keys[0] != "team-net" {
+		t.Fatalf("expected only the events in namespace team-a, got %v", keys)
+	}
+	if keys := list(""); len(keys) != 1 || keys[0] != "default-net" {
+		t.Fatalf("expected only the events in the default namespace, got %v", keys)
+	}
+}
+
+func TestNamespaceACLRules(t *testing.T) {
+
+	repo := testState()
+	config := testConfig()
+
+	authHandler := testACL(t, repo, config)
+	testNamespaceResources(t, repo)
+
+	secret := testACLToken(t, repo, "team",
+		&structs.ACLPolicyRule{Resource: "network", Namespace: "team-*", Path: "*", Capabilities: []string{"write"}},
+		&structs.ACLPolicyRule{Resource: "node", Path: "*", Capabilities: []string{"read"}},
+	)
+
+	networks := NewNetworkService(config, testLogger(t), repo, nil, authHandler)
+	nodes := testNodeService(t, repo, config, authHandler)
+
+	listNetworks := func(ns string) error {
+		args := &structs.NetworkListRequest{QueryOptions: structs.QueryOptions{AuthToken: secret, Namespace: ns}}
+		return networks.ListNetworks(args, &structs.NetworkListResponse{})
+	}
+	listNodes := func(ns string) error {
+		args := &structs.NodeListRequest{QueryOptions: structs.QueryOptions{AuthToken: secret, Namespace: ns}}
+		return nodes.ListNodes(args, &structs.NodeListResponse{})
+	}
+
+	// Rules with a namespace glob apply in the namespaces it matches only
+	if err := listNetworks("team-a"); err != nil {
+		t.Fatalf("expected networks to be listed in namespace team-a, got %v", err)
+	}
+	if err := listNetworks(""); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected networks not to be listed in the default namespace, got %v", err)
+	}
+
+	args := &structs.NetworkSpecificRequest{NetworkID: "net", QueryOptions: structs.QueryOptions{AuthToken: secret}}
+	if err := networks.GetNetwork(args, &structs.SingleNetworkResponse{}); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected network in the default namespace not to be read, got %v", err)
+	}
+
+	upsert := &structs.NetworkUpsertRequest{
+		Network:      &structs.Network{Name: "team-net", AddressRange: "10.1.0.0/24"},
+		WriteRequest: structs.WriteRequest{AuthToken: secret, Namespace: "team-a"},
+	}
+	if err := networks.UpsertNetwork(upsert, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("expected network to be created in namespace team-a, got %v", err)
+	}
+
+	upsert.Network = &structs.Network{Name: "default-net", AddressRange: "10.2.0.0/24"}
+	upsert.Namespace = ""
+	if err := networks.UpsertNetwork(upsert, &structs.GenericResponse{}); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected network not to be created in the default namespace, got %v", err)
+	}
+
+	// Rules without a namespace apply in the default namespace only
+	if err := listNodes(""); err != nil {
+		t.Fatalf("expected nodes to be listed in the default namespace, got %v", err)
+	}
+	if err := listNodes("team-a"); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected nodes not to be listed in namespace team-a, got %v", err)
+	}
+}
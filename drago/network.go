@@ -2,6 +2,7 @@ package drago
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	auth "github.com/seashell/drago/drago/auth"
@@ -21,15 +22,17 @@ type NetworkService struct {
 	config      *Config
 	logger      log.Logger
 	state       state.Repository
+	auditor     *Auditor
 	authHandler auth.AuthorizationHandler
 }
 
 // NewNetworkService ...
-func NewNetworkService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) *NetworkService {
+func NewNetworkService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *NetworkService {
 	return &NetworkService{
 		config:      config,
 		logger:      logger,
 		state:       state,
+		auditor:     auditor,
 		authHandler: authHandler,
 	}
 }
@@ -38,17 +41,18 @@ func NewNetworkService(config *Config, logger log.Logger, state state.Repository
 func (s *NetworkService) GetNetwork(args *structs.NetworkSpecificRequest, out *structs.SingleNetworkResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", args.NetworkID, NetworkRead); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, args.NetworkID), NetworkRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	n, err := s.state.NetworkByID(ctx, args.NetworkID)
+	n, err := networkInNamespace(ctx, s.state, ns, args.NetworkID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
 	out.Network = n
@@ -56,47 +60,107 @@ func (s *NetworkService) GetNetwork(args *structs.NetworkSpecificRequest, out *s
 	return nil
 }
 
-// ListNetworks retrieves all network entities in the repository
-func (s *NetworkService) ListNetworks(args *structs.NetworkListRequest, out *structs.NetworkListResponse) error {
+// ValidateNetwork checks the connections of a network for AllowedIPs which overlap on
+// the same interface, returning the conflicts found, if any. Conflicts between identical
+// prefixes are errors, whereas partial overlaps are reported for information, except the
+// ones between managed connections, which are created on purpose by the network topology.
+func (s *NetworkService) ValidateNetwork(args *structs.NetworkSpecificRequest, out *structs.NetworkValidateResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", "", NetworkList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, args.NetworkID), NetworkRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	networks, err := s.state.Networks(ctx)
+	if _, err := networkInNamespace(ctx, s.state, ns, args.NetworkID); err != nil {
+		return err
+	}
+
+	interfaces, err := s.state.InterfacesByNetworkID(ctx, args.NetworkID)
 	if err != nil {
-		return structs.ErrInternal
+		return structs.NewInternalError(err.Error())
+	}
+
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].ID < interfaces[j].ID })
+
+	out.Conflicts = []*structs.AllowedIPsConflict{}
+
+	for _, iface := range interfaces {
+
+		connections, err := s.state.ConnectionsByInterfaceID(ctx, iface.ID)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		out.Conflicts = append(out.Conflicts, allowedIPsConflicts(iface.ID, connections)...)
+	}
+
+	return nil
+}
+
+// ListNetworks retrieves all network entities in the repository
+func (s *NetworkService) ListNetworks(args *structs.NetworkListRequest, out *structs.NetworkListResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, ""), NetworkList); err != nil {
+			return structs.ErrPermissionDenied
+		}
 	}
 
 	out.Items = nil
 
-	for _, n := range networks {
-		out.Items = append(out.Items, n.Stub())
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		networks, next, err := s.state.NetworksPage(ctx, opts)
+		if err != nil {
+			return "", structs.ErrInternal
+		}
+
+		for _, n := range networks {
+			if inNamespace(n.Namespace, ns) {
+				out.Items = append(out.Items, n.Stub())
+			}
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // UpsertNetwork upserts a new Network entity
-func (s *NetworkService) UpsertNetwork(args *structs.NetworkUpsertRequest, out *structs.GenericResponse) error {
+func (s *NetworkService) UpsertNetwork(args *structs.NetworkUpsertRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Network.UpsertNetwork", args.AuthToken, ns, "network", auditID(&args.Network.ID))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", "", NetworkWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, ""), NetworkWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
 	n := args.Network
 
-	err := n.Validate()
+	err = n.Validate()
 	if err != nil {
 		return structs.NewInvalidInputError(err.Error())
 	}
@@ -104,56 +168,91 @@ func (s *NetworkService) UpsertNetwork(args *structs.NetworkUpsertRequest, out *
 	isNewNetwork := n.ID == ""
 
 	if isNewNetwork {
+
+		if err := checkNamespaceExists(ctx, s.state, ns); err != nil {
+			return err
+		}
+
 		n.ID = uuid.Generate()
+		n.Namespace = ns
 		n.CreatedAt = time.Now()
 
+		if n.Topology == "" {
+			n.Topology = structs.NetworkTopologyManual
+		}
+
 		networks, err := s.state.Networks(ctx)
 		if err != nil {
 			return structs.NewInternalError(err.Error())
 		}
 
 		for _, net := range networks {
-			if net.Name == n.Name {
+			if net.Name == n.Name && inNamespace(net.Namespace, ns) {
 				return structs.NewInvalidInputError("network name already in use")
 			}
 		}
-
-	} else {
-		old, err := s.state.NetworkByID(ctx, n.ID)
-		if err != nil {
-			return structs.ErrNotFound
-		}
-		n = old.Merge(n)
 	}
 
-	n.UpdatedAt = time.Now()
+	// Updates are retried on conflicts, e.g. with interfaces being added to the network
+	// concurrently, so that address range changes are checked against all interfaces.
+	err = retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			if !isNewNetwork {
+				old, err := networkInNamespace(ctx, s.state, ns, args.Network.ID)
+				if err != nil {
+					return err
+				}
+				n = old.Merge(args.Network)
+
+				if n.AddressRange != old.AddressRange {
+					if err := checkNetworkAddresses(ctx, s.state, n); err != nil {
+						return err
+					}
+				}
+			}
+
+			n.UpdatedAt = time.Now()
 
-	err = s.state.UpsertNetwork(ctx, n)
+			if err := s.state.UpsertNetwork(ctx, n); err != nil {
+				return structs.ErrInternal
+			}
+			return nil
+		})
+	})
 	if err != nil {
-		return structs.ErrInternal
+		return err
+	}
+
+	if err := reconcileNetworkTopology(ctx, s.state, n.ID); err != nil {
+		s.logger.Warnf("error reconciling topology of network %s: %v", n.ID, err)
 	}
 
 	return nil
 }
 
 // DeleteNetwork deletes a network entity from the repository
-func (s *NetworkService) DeleteNetwork(args *structs.NetworkDeleteRequest, out *structs.GenericResponse) error {
+func (s *NetworkService) DeleteNetwork(args *structs.NetworkDeleteRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Network.DeleteNetwork", args.AuthToken, ns, "network", func() []string { return args.NetworkIDs })(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", "", NetworkWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, ""), NetworkWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
 	for _, id := range args.NetworkIDs {
-
-		interfaces, err := s.state.InterfacesByNetworkID(ctx, id)
-		if err != nil {
-			return structs.NewInternalError(err.Error())
+		if _, err := networkInNamespace(ctx, s.state, ns, id); err != nil {
+			return err
 		}
+	}
+
+	for _, id := range args.NetworkIDs {
 
 		connections, err := s.state.ConnectionsByNetworkID(ctx, id)
 		if err != nil {
@@ -161,27 +260,49 @@ func (s *NetworkService) DeleteNetwork(args *structs.NetworkDeleteRequest, out *
 		}
 
 		connectionIDs := []string{}
-		interfaceIDs := []string{}
-
-		for _, iface := range interfaces {
-			interfaceIDs = append(interfaceIDs, iface.ID)
-		}
-
 		for _, conn := range connections {
 			connectionIDs = append(connectionIDs, conn.ID)
 		}
 
-		if err := s.state.DeleteInterfaces(ctx, interfaceIDs); err != nil {
-			return structs.NewInternalError(err.Error())
+		// Remove the connections within the network, which also
+		// updates the connected interfaces and nodes.
+		if err := deleteConnections(ctx, s.state, connectionIDs); err != nil {
+			return err
 		}
 
-		if err := s.state.DeleteConnections(ctx, connectionIDs); err != nil {
-			return structs.NewInternalError(err.Error())
-		}
-	}
+		// Remove the interfaces in the network, along with the references to
+		// them in their nodes, and finally the network itself.
+		err = withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			interfaces, err := s.state.InterfacesByNetworkID(ctx, id)
+			if err != nil {
+				return structs.NewInternalError(err.Error())
+			}
+
+			interfaceIDs := []string{}
+			for _, iface := range interfaces {
+				if node, err := s.state.NodeByID(ctx, iface.NodeID); err == nil {
+					node.RemoveInterface(iface.ID)
+					if err := s.state.UpsertNode(ctx, node); err != nil {
+						return structs.NewInternalError(err.Error())
+					}
+				}
+				interfaceIDs = append(interfaceIDs, iface.ID)
+			}
+
+			if err := s.state.DeleteInterfaces(ctx, interfaceIDs); err != nil {
+				return structs.NewInternalError(err.Error())
+			}
 
-	if err := s.state.DeleteNetworks(ctx, args.NetworkIDs); err != nil {
-		return structs.ErrInternal
+			if err := s.state.DeleteNetworks(ctx, []string{id}); err != nil {
+				return structs.ErrInternal
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
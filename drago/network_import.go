@@ -0,0 +1,460 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+	ipam "github.com/seashell/drago/pkg/ipam"
+	uuid "github.com/seashell/drago/pkg/uuid"
+	wgquick "github.com/seashell/drago/pkg/wgquick"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// ImportNetwork imports wg-quick configuration files into a network. Interfaces are matched
+// with the existing interfaces of the network by public key, and those which do not exist are
+// created as external peers. A connection is created for every peer in the files, or updated
+// if the interfaces are already connected. The plan of the import is returned, and only
+// applied if DryRun is not set.
+func (s *NetworkService) ImportNetwork(args *structs.NetworkImportRequest, out *structs.NetworkImportResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	if !args.DryRun {
+		defer s.auditor.Begin(ctx, "Network.ImportNetwork", args.AuthToken, ns, "network", auditID(&args.NetworkID))(&err)
+	}
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		for _, r := range []struct{ resource, capability string }{
+			{"network", NetworkWrite},
+			{"interface", InterfaceWrite},
+			{"connection", ConnectionWrite},
+		} {
+			if err := s.authHandler.Authorize(ctx, args.AuthToken, r.resource, namespacePath(ns, ""), r.capability); err != nil {
+				return structs.ErrPermissionDenied
+			}
+		}
+	}
+
+	network, err := networkInNamespace(ctx, s.state, ns, args.NetworkID)
+	if err != nil {
+		return err
+	}
+
+	interfaces, err := s.state.InterfacesByNetworkID(ctx, network.ID)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	connections, err := s.state.ConnectionsByNetworkID(ctx, network.ID)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	imp, err := planNetworkImport(network, interfaces, connections, args.Files)
+	if err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	out.Plan = imp.plan
+
+	if args.DryRun {
+		return nil
+	}
+
+	return imp.apply(ctx, s)
+}
+
+// networkImport contains the plan of an import, along with the
+// private keys found in the files, indexed by public key.
+type networkImport struct {
+	network     *structs.Network
+	plan        *structs.NetworkImportPlan
+	privateKeys map[string]string
+	interfaces  map[string]*structs.NetworkImportInterface
+}
+
+// planNetworkImport parses wg-quick configuration files, and plans the changes needed for
+// the network to contain their interfaces and connections. Files are expected to describe
+// one interface each, whose private key is thus known. Peers without a file of their own
+// get an address within the network range from their AllowedIPs, if possible. Connections
+// described in a single file route the address of the other side on the missing side.
+func planNetworkImport(network *structs.Network, interfaces []*structs.Interface, connections []*structs.Connection, files []*structs.NetworkImportFile) (*networkImport, error) {
+
+	imp := &networkImport{
+		network:     network,
+		plan:        &structs.NetworkImportPlan{},
+		privateKeys: map[string]string{},
+		interfaces:  map[string]*structs.NetworkImportInterface{},
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files to import")
+	}
+
+	allocator, err := ipam.NewAllocator(network.AddressRange)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]*structs.Interface{}
+	for _, iface := range interfaces {
+		if iface.PublicKey != nil {
+			existing[*iface.PublicKey] = iface
+		}
+		if iface.Address != nil {
+			allocator.Reserve(*iface.Address)
+		}
+	}
+
+	configs := make([]*wgquick.Config, len(files))
+	hostKeys := make([]string, len(files))
+
+	// Register the interfaces described by the files first, so that
+	// their addresses take precedence over the ones derived from peers.
+	for i, f := range files {
+
+		config, err := wgquick.Parse(strings.NewReader(f.Content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+
+		key, err := wgtypes.ParseKey(config.Interface.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid or missing PrivateKey", f.Name)
+		}
+
+		publicKey := key.PublicKey().String()
+		if _, ok := imp.interfaces[publicKey]; ok {
+			return nil, fmt.Errorf("%s: interface with public key %s is described by more than one file", f.Name, publicKey)
+		}
+
+		iface := imp.addInterface(existing, publicKey)
+		iface.File = f.Name
+
+		if iface.Action == structs.NetworkImportActionCreate {
+			imp.privateKeys[publicKey] = key.String()
+			if iface.Address, err = chooseAddress(allocator, network, config.Interface.Address, false); err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+		}
+
+		configs[i], hostKeys[i] = config, publicKey
+	}
+
+	planned := map[string]*structs.NetworkImportConnection{}
+
+	for i, config := range configs {
+
+		hostKey := hostKeys[i]
+
+		for _, p := range config.Peers {
+
+			if _, err := wgtypes.ParseKey(p.PublicKey); err != nil {
+				return nil, fmt.Errorf("%s: invalid PublicKey %q", files[i].Name, p.PublicKey)
+			}
+			if p.PublicKey == hostKey {
+				return nil, fmt.Errorf("%s: interface can't be its own peer", files[i].Name)
+			}
+
+			peer, ok := imp.interfaces[p.PublicKey]
+			if !ok {
+				peer = imp.addInterface(existing, p.PublicKey)
+				if peer.Action == structs.NetworkImportActionCreate {
+					if peer.Address, err = chooseAddress(allocator, network, p.AllowedIPs, true); err != nil {
+						return nil, fmt.Errorf("%s: peer %s: %v", files[i].Name, p.PublicKey, err)
+					}
+				}
+			}
+			if peer.Action == structs.NetworkImportActionCreate && peer.Endpoint == "" {
+				peer.Endpoint = p.Endpoint
+			}
+
+			k := connectionKey(hostKey, p.PublicKey)
+
+			c, ok := planned[k]
+			if !ok {
+				keys := []string{hostKey, p.PublicKey}
+				sort.Strings(keys)
+				c = &structs.NetworkImportConnection{
+					PublicKeys: keys,
+					AllowedIPs: make([][]string, 2),
+				}
+				planned[k] = c
+			}
+
+			side := 0
+			if c.PublicKeys[1] == hostKey {
+				side = 1
+			}
+			c.AllowedIPs[side] = append([]string{}, p.AllowedIPs...)
+
+			if p.PersistentKeepalive > c.PersistentKeepalive {
+				c.PersistentKeepalive = p.PersistentKeepalive
+			}
+		}
+	}
+
+	for _, k := range sortedImportKeys(planned) {
+
+		c := planned[k]
+
+		// Sides not described by any file route the address of the other side
+		for side := range c.AllowedIPs {
+			if c.AllowedIPs[side] == nil {
+				other := imp.interfaces[c.PublicKeys[1-side]]
+				c.AllowedIPs[side] = hostPrefixes(&structs.Interface{Address: &other.Address})
+			}
+		}
+
+		a, b := imp.interfaces[c.PublicKeys[0]], imp.interfaces[c.PublicKeys[1]]
+
+		c.Action = structs.NetworkImportActionCreate
+
+		for _, old := range connections {
+			if !old.ConnectsInterfaces(a.InterfaceID, b.InterfaceID) {
+				continue
+			}
+			c.ConnectionID = old.ID
+			c.Action = structs.NetworkImportActionMatch
+			if importedConnectionChanged(old, a.InterfaceID, b.InterfaceID, c) {
+				c.Action = structs.NetworkImportActionUpdate
+			}
+			break
+		}
+
+		if c.ConnectionID == "" {
+			c.ConnectionID = uuid.Generate()
+		}
+
+		imp.plan.Connections = append(imp.plan.Connections, c)
+	}
+
+	return imp, nil
+}
+
+// addInterface adds the interface with the public key passed as argument to the plan,
+// matching it with an existing interface, or planning its creation as an external peer.
+func (imp *networkImport) addInterface(existing map[string]*structs.Interface, publicKey string) *structs.NetworkImportInterface {
+
+	iface := &structs.NetworkImportInterface{
+		Action:    structs.NetworkImportActionCreate,
+		PublicKey: publicKey,
+	}
+
+	if old, ok := existing[publicKey]; ok {
+		iface.Action = structs.NetworkImportActionMatch
+		iface.InterfaceID = old.ID
+		iface.NodeID = old.NodeID
+		if old.Address != nil {
+			iface.Address = *old.Address
+		}
+	} else {
+		iface.InterfaceID = uuid.Generate()
+	}
+
+	imp.interfaces[publicKey] = iface
+	imp.plan.Interfaces = append(imp.plan.Interfaces, iface)
+
+	return iface
+}
+
+// chooseAddress returns the first of the candidate addresses which is within the network
+// range, reserving it, or allocates a free one if there is none. If the candidates are
+// AllowedIPs, only host prefixes (i.e. /32 or /128) are considered.
+func chooseAddress(allocator *ipam.Allocator, network *structs.Network, candidates []string, hostOnly bool) (string, error) {
+
+	_, prefix, err := net.ParseCIDR(network.AddressRange)
+	if err != nil {
+		return "", err
+	}
+	ones, _ := prefix.Mask.Size()
+
+	for _, c := range candidates {
+
+		ip, ipnet, err := net.ParseCIDR(c)
+		if err != nil || !prefix.Contains(ip) {
+			continue
+		}
+		if hostOnly {
+			if o, bits := ipnet.Mask.Size(); o != bits {
+				continue
+			}
+		}
+
+		addr := fmt.Sprintf("%s/%d", ip.String(), ones)
+		if err := allocator.Reserve(addr); err != nil {
+			return "", fmt.Errorf("%s: %v", addr, err)
+		}
+
+		return addr, nil
+	}
+
+	return allocator.Allocate()
+}
+
+// importedConnectionChanged checks whether applying an imported connection
+// would change the existing connection between the interfaces a and b.
+func importedConnectionChanged(old *structs.Connection, a, b string, c *structs.NetworkImportConnection) bool {
+
+	keepalive := 0
+	if old.PersistentKeepalive != nil {
+		keepalive = *old.PersistentKeepalive
+	}
+	if keepalive != c.PersistentKeepalive || old.Managed {
+		return true
+	}
+
+	for i, id := range []string{a, b} {
+		settings := old.PeerSettingsByInterfaceID(id)
+		if settings == nil || settings.RoutingRules == nil {
+			return true
+		}
+		if !reflect.DeepEqual(settings.RoutingRules.AllowedIPs, c.AllowedIPs[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apply creates the interfaces and writes the connections in the plan. Each
+// of them is written in a separate transaction, so that the size of the
+// transactions does not grow with the number of peers.
+func (imp *networkImport) apply(ctx context.Context, s *NetworkService) error {
+
+	now := time.Now()
+
+	for _, planned := range imp.plan.Interfaces {
+
+		if planned.Action != structs.NetworkImportActionCreate {
+			continue
+		}
+
+		publicKey, address := planned.PublicKey, planned.Address
+
+		iface := &structs.Interface{
+			ID:          planned.InterfaceID,
+			Namespace:   imp.network.Namespace,
+			NetworkID:   imp.network.ID,
+			PublicKey:   &publicKey,
+			Address:     &address,
+			External:    true,
+			Peers:       []*structs.Peer{},
+			Connections: []string{},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if privateKey, ok := imp.privateKeys[publicKey]; ok {
+			iface.PrivateKey = &privateKey
+		}
+		if planned.File != "" {
+			name := strings.TrimSuffix(filepath.Base(planned.File), filepath.Ext(planned.File))
+			iface.Name = &name
+		}
+		if planned.Endpoint != "" {
+			endpoint := planned.Endpoint
+			iface.Endpoint = &endpoint
+		}
+
+		err := retryOnConflict(func() error {
+			return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+				network, err := s.state.NetworkByID(ctx, imp.network.ID)
+				if err != nil {
+					return structs.ErrNotFound
+				}
+
+				if err := assignInterfaceAddress(ctx, s.state, network, iface); err != nil {
+					return err
+				}
+
+				network.UpsertInterface(iface.ID)
+				if err := s.state.UpsertNetwork(ctx, network); err != nil {
+					return structs.NewInternalError(err.Error())
+				}
+
+				return s.state.UpsertInterface(ctx, iface)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, planned := range imp.plan.Connections {
+
+		if planned.Action == structs.NetworkImportActionMatch {
+			continue
+		}
+
+		ids := []string{
+			imp.interfaces[planned.PublicKeys[0]].InterfaceID,
+			imp.interfaces[planned.PublicKeys[1]].InterfaceID,
+		}
+
+		err := retryOnConflict(func() error {
+			return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+				c := &structs.Connection{
+					ID:        planned.ConnectionID,
+					NetworkID: imp.network.ID,
+					PeerSettings: []*structs.PeerSettings{
+						{InterfaceID: ids[0]},
+						{InterfaceID: ids[1]},
+					},
+					CreatedAt: now,
+				}
+
+				if planned.Action == structs.NetworkImportActionUpdate {
+					old, err := s.state.ConnectionByID(ctx, planned.ConnectionID)
+					if err != nil {
+						return structs.ErrNotFound
+					}
+					c = old
+				}
+
+				for i, id := range ids {
+					c.PeerSettingsByInterfaceID(id).RoutingRules = &structs.RoutingRules{AllowedIPs: planned.AllowedIPs[i]}
+				}
+
+				c.PersistentKeepalive = nil
+				if planned.PersistentKeepalive > 0 {
+					keepalive := planned.PersistentKeepalive
+					c.PersistentKeepalive = &keepalive
+				}
+
+				// Imported connections are not managed by the network topology
+				c.Managed = false
+
+				if err := checkAllowedIPs(ctx, s.state, s.logger, c); err != nil {
+					return err
+				}
+
+				return upsertConnection(ctx, s.state, c)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedImportKeys(m map[string]*structs.NetworkImportConnection) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,41 @@
+package drago
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestUpsertNetworkAddressRange(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyManual)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.200/24", false)
+
+	s := NewNetworkService(testConfig(), testLogger(t), repo, nil, nil)
+
+	update := func(addressRange string) error {
+		args := &structs.NetworkUpsertRequest{Network: &structs.Network{ID: "net", Name: "net", AddressRange: addressRange}}
+		return s.UpsertNetwork(args, &structs.GenericResponse{})
+	}
+
+	// Ranges leaving out the addresses of interfaces are rejected
+	if err := update("10.0.0.0/25"); err == nil || !strings.Contains(err.Error(), "10.0.0.200/24") {
+		t.Fatalf("expected s.UpsertNetwork() to fail for a range not containing all interface addresses, got %v", err)
+	}
+	if n, _ := repo.NetworkByID(ctx, "net"); n.AddressRange != "10.0.0.0/24" {
+		t.Fatalf("expected address range to be left unchanged, got %s", n.AddressRange)
+	}
+
+	if err := update("10.0.0.0/16"); err != nil {
+		t.Fatalf("s.UpsertNetwork() failed: %v", err)
+	}
+	if n, _ := repo.NetworkByID(ctx, "net"); n.AddressRange != "10.0.0.0/16" {
+		t.Fatalf("expected address range to be updated, got %s", n.AddressRange)
+	}
+}
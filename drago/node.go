@@ -2,13 +2,20 @@ package drago
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"net"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	auth "github.com/seashell/drago/drago/auth"
+	events "github.com/seashell/drago/drago/events"
 	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 	log "github.com/seashell/drago/pkg/log"
@@ -19,25 +26,32 @@ const (
 	NodeList  = "list"
 	NodeRead  = "read"
 	NodeWrite = "write"
-
-	defaultExpectedHeartbeatInterval = 10 * time.Second
 )
 
 type NodeService struct {
 	config              *Config
 	logger              log.Logger
 	state               state.Repository
+	auditor             *Auditor
+	broker              *events.Broker
 	authHandler         auth.AuthorizationHandler
 	heartbeatTimers     map[string]*time.Timer
 	heartbeatTimersLock sync.Mutex
 }
 
+// interfacesPollInterval is the interval at which blocking queries for the
+// interfaces of a node re-read the state, so that changes committed through
+// other servers sharing it, which publish no events here, are also noticed.
+var interfacesPollInterval = 5 * time.Second
+
 // NewNodeService ...
-func NewNodeService(config *Config, logger log.Logger, state state.Repository, authHandler auth.AuthorizationHandler) (*NodeService, error) {
+func NewNodeService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, broker *events.Broker, authHandler auth.AuthorizationHandler) (*NodeService, error) {
 
 	s := &NodeService{
 		config:          config,
 		state:           state,
+		auditor:         auditor,
+		broker:          broker,
 		authHandler:     authHandler,
 		logger:          logger,
 		heartbeatTimers: map[string]*time.Timer{},
@@ -67,34 +81,57 @@ func (s *NodeService) setupHeartbeatTimers() error {
 	return nil
 }
 
+// heartbeatTimeout returns how long the server waits for a heartbeat before
+// marking a node as down, which is the heartbeat TTL plus a grace period.
+func (s *NodeService) heartbeatTimeout() time.Duration {
+	return s.config.HeartbeatTTL + s.config.HeartbeatGrace
+}
+
 func (s *NodeService) resetHeartbeatTimer(id string) {
 
 	s.heartbeatTimersLock.Lock()
 	defer s.heartbeatTimersLock.Unlock()
 
 	if timer, ok := s.heartbeatTimers[id]; ok {
-		timer.Reset(defaultExpectedHeartbeatInterval)
+		timer.Reset(s.heartbeatTimeout())
 		return
 	}
 
-	timer := time.AfterFunc(defaultExpectedHeartbeatInterval, func() {
+	timer := time.AfterFunc(s.heartbeatTimeout(), func() {
 
 		ctx := context.TODO()
 
 		s.logger.Debugf("heartbeat missed by node %s", id)
 
-		old, err := s.state.NodeByID(ctx, id)
-		if err != nil {
-			s.logger.Debugf("failed to set node status after heartbeat miss: %v", err)
+		// Nodes removed in the meantime, e.g. by the garbage
+		// collector, do not need their heartbeats tracked anymore.
+		if _, err := s.state.NodeByID(ctx, id); err != nil {
+			s.stopHeartbeatTimer(id)
+			return
 		}
 
-		n := old.Merge(&structs.Node{
-			ID:     id,
-			Status: structs.NodeStatusDown,
-		})
-		n.UpdatedAt = time.Now()
+		heartbeatMisses.WithLabelValues(id).Inc()
+
+		err := retryOnConflict(func() error {
+
+			old, err := s.state.NodeByID(ctx, id)
+			if err != nil {
+				return err
+			}
 
-		if err := s.state.UpsertNode(ctx, n); err != nil {
+			// Leave nodes which were already down untouched,
+			// so that the time they went down is preserved.
+			if old.Status == structs.NodeStatusDown {
+				return nil
+			}
+
+			n := old.Merge(&structs.Node{ID: id})
+			n.UpdatedAt = time.Now()
+			n.SetStatus(structs.NodeStatusDown, n.UpdatedAt)
+
+			return s.state.UpsertNode(ctx, n)
+		})
+		if err != nil {
 			s.logger.Debugf("failed to set node status after hearbeat miss: %v", err)
 		}
 	})
@@ -102,18 +139,68 @@ func (s *NodeService) resetHeartbeatTimer(id string) {
 	s.heartbeatTimers[id] = timer
 }
 
-func (s *NodeService) Register(args *structs.NodeRegisterRequest, out *structs.NodeUpdateResponse) error {
+// resetHeartbeatTimers replaces all heartbeat timers with ones for the nodes
+// in the state, which is needed whenever the state is replaced as a whole.
+func (s *NodeService) resetHeartbeatTimers() error {
+
+	if s == nil {
+		return nil
+	}
+
+	s.heartbeatTimersLock.Lock()
+	for id, timer := range s.heartbeatTimers {
+		timer.Stop()
+		delete(s.heartbeatTimers, id)
+	}
+	s.heartbeatTimersLock.Unlock()
+
+	return s.setupHeartbeatTimers()
+}
+
+func (s *NodeService) stopHeartbeatTimer(id string) {
+
+	if s == nil {
+		return
+	}
+
+	s.heartbeatTimersLock.Lock()
+	defer s.heartbeatTimersLock.Unlock()
+
+	if timer, ok := s.heartbeatTimers[id]; ok {
+		timer.Stop()
+		delete(s.heartbeatTimers, id)
+	}
+}
+
+// Register registers a node, or updates it if it is already known. Unknown nodes must
+// present a valid join token if the server requires one, and join the networks specified
+// by the token they present, if any. Known nodes are authorized by their secret ID, except
+// for preregistered nodes without one, which are bound to the secret ID of the first node
+// registering, and must thus be authorized by a join token or an ACL token. Nodes are
+// registered in the namespace of the request, which must be the namespace of the join
+// token, if any.
+func (s *NodeService) Register(args *structs.NodeRegisterRequest, out *structs.NodeUpdateResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
-	// Check if authorized
-	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", "", NodeWrite); err != nil {
+	defer s.auditor.Begin(ctx, "Node.Register", args.AuthToken, ns, "node", func() []string {
+		if args.Node == nil {
+			return nil
+		}
+		return []string{args.Node.ID}
+	})(&err)
+
+	// Check if authorized. Nodes presenting a join token are authorized by their
+	// secret ID if they are known, or by the join token otherwise, which is
+	// checked and consumed below.
+	if s.config.ACL.Enabled && args.JoinToken == "" {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	err := args.Validate()
+	err = args.Validate()
 	if err != nil {
 		return structs.NewInvalidInputError(err.Error())
 	}
@@ -128,39 +215,127 @@ func (s *NodeService) Register(args *structs.NodeRegisterRequest, out *structs.N
 		return structs.NewInvalidInputError(err.Error())
 	}
 
-	old, err := s.state.NodeByID(ctx, n.ID)
-	if err != nil {
-		s.logger.Debugf("registering a new node with id %s!", n.ID)
-		n.CreatedAt = time.Now()
-	} else {
-		s.logger.Debugf("node %s already registered.", n.ID)
-		if old != nil {
-			if args.Node.SecretID != old.SecretID {
-				return structs.NewInvalidInputError("Node secret does not match")
+	status := n.Status
+	wasHub := false
+
+	var token *structs.JoinToken
+
+	// Retry in case the node is modified concurrently, for
+	// example by a missed heartbeat setting its status to down.
+	err = retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			n = args.Node
+			token = nil
+
+			old, err := s.state.NodeByID(ctx, n.ID)
+			if err != nil {
+
+				if err := checkNamespaceExists(ctx, s.state, ns); err != nil {
+					return err
+				}
+
+				if s.config.RequireJoinToken || args.JoinToken != "" {
+					if token, err = consumeJoinToken(ctx, s.state, ns, args.JoinToken); err != nil {
+						return err
+					}
+				}
+
+				s.logger.Debugf("registering a new node with id %s!", n.ID)
+				n.CreatedAt = time.Now()
+				n.StatusHistory = nil
+				n.SchedulingEligibility = structs.NodeSchedulingEligible
+				n.Drain = false
+				n.ServerMeta = nil
+				n.JoinTokenID = ""
+				n.Namespace = ns
+
+				if token != nil {
+					n.ServerMeta = token.Meta
+					n.JoinTokenID = token.ID
+				}
+
+				n.SetMeta(n.Meta)
+			} else {
+				wasHub = old.IsHub()
+				s.logger.Debugf("node %s already registered.", n.ID)
+				// Nodes in other namespaces are reported as not found,
+				// so that their existence is not disclosed.
+				if !inNamespace(old.Namespace, ns) {
+					return structs.ErrNotFound
+				}
+				if old.SecretID != "" && args.Node.SecretID != old.SecretID {
+					return structs.NewInvalidInputError("Node secret does not match")
+				}
+				// Preregistered nodes without a secret ID are bound to the one of the
+				// first node registering, which can't be authorized by its secret ID.
+				// Unless it was authorized by an ACL token, it must present a join token.
+				if old.SecretID == "" && (args.JoinToken != "" || s.config.RequireJoinToken || !s.config.ACL.Enabled) {
+					if token, err = consumeJoinToken(ctx, s.state, ns, args.JoinToken); err != nil {
+						return err
+					}
+				}
+				n = old.Merge(n)
+				n.Status = old.Status
+				if token != nil && n.JoinTokenID == "" {
+					n.JoinTokenID = token.ID
+				}
 			}
-		}
-		n = old.Merge(n)
-	}
 
-	n.UpdatedAt = time.Now()
+			n.UpdatedAt = time.Now()
+			n.SetStatus(status, n.UpdatedAt)
 
-	err = s.state.UpsertNode(ctx, n)
+			return s.state.UpsertNode(ctx, n)
+		})
+	})
 	if err != nil {
+		if err == structs.ErrPermissionDenied || err == structs.ErrNotFound || strings.HasPrefix(err.Error(), structs.ErrInvalidInput.Error()) {
+			return err
+		}
 		return structs.NewInternalError(err.Error())
 	}
 
+	if token != nil {
+		s.joinTokenNetworks(ctx, n.ID, token)
+	}
+
+	if n.IsHub() != wasHub {
+		s.reconcileNodeNetworks(ctx, n.ID)
+	}
+
+	out.Servers = s.knownServers(ctx)
+	out.HeartbeatTTL = s.config.HeartbeatTTL
+
 	s.resetHeartbeatTimer(n.ID)
 
 	return nil
 }
 
+// knownServers returns the RPC addresses of the servers known to this one, which
+// are returned to nodes so that they can fail over to any of them. Failures are
+// logged, in which case only the address of this server is returned.
+func (s *NodeService) knownServers(ctx context.Context) []string {
+
+	servers, err := knownServers(ctx, s.state, s.config.RPCAdvertiseAddr)
+	if err != nil {
+		s.logger.Warnf("error retrieving known servers: %v", err)
+		if s.config.RPCAdvertiseAddr == "" {
+			return nil
+		}
+		return []string{s.config.RPCAdvertiseAddr}
+	}
+
+	return servers
+}
+
 func (s *NodeService) UpdateStatus(args *structs.NodeUpdateStatusRequest, out *structs.NodeUpdateResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", args.NodeID, NodeWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
@@ -172,41 +347,273 @@ func (s *NodeService) UpdateStatus(args *structs.NodeUpdateStatusRequest, out *s
 		return structs.NewInvalidInputError("Invalid node status")
 	}
 
-	n, err := s.state.NodeByID(ctx, args.NodeID)
+	var n *structs.Node
+	wasHub := false
+
+	err := retryOnConflict(func() error {
+
+		var err error
+
+		n, err = nodeInNamespace(ctx, s.state, ns, args.NodeID)
+		if err != nil {
+			return err
+		}
+
+		wasHub = n.IsHub()
+
+		n.AdvertiseAddress = args.AdvertiseAddress
+
+		if args.Meta != nil {
+			n.SetMeta(args.Meta)
+		}
+
+		n.UpdatedAt = time.Now()
+		n.SetStatus(args.Status, n.UpdatedAt)
+
+		return s.state.UpsertNode(ctx, n)
+	})
 	if err != nil {
+		if err == structs.ErrNotFound {
+			return err
+		}
 		return structs.NewInternalError(err.Error())
 	}
 
-	n.Status = args.Status
-	n.AdvertiseAddress = args.AdvertiseAddress
+	if n.IsHub() != wasHub {
+		s.reconcileNodeNetworks(ctx, n.ID)
+	}
+
+	out.Servers = s.knownServers(ctx)
+	out.HeartbeatTTL = s.config.HeartbeatTTL
+
+	s.logger.Debugf("heartbeat from node %s", n.ID)
+	s.resetHeartbeatTimer(n.ID)
+
+	return nil
+}
+
+// PreregisterNode creates a node before it registers itself. If a secret ID is set, the
+// node can register without a join token. Otherwise, it is bound to the secret ID of the
+// first node registering with the same ID and a valid join token, or with an ACL token if
+// join tokens are not required. Metadata set on preregistration takes precedence over the
+// metadata reported by the node.
+func (s *NodeService) PreregisterNode(args *structs.NodePreregisterRequest, out *structs.NodePreregisterResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
-	if args.Meta != nil {
-		n.Meta = args.Meta
+	defer s.auditor.Begin(ctx, "Node.PreregisterNode", args.AuthToken, ns, "node", func() []string {
+		if out.Node == nil {
+			return nil
+		}
+		return []string{out.Node.ID}
+	})(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
 	}
 
-	n.UpdatedAt = time.Now()
+	n := args.Node
+	if n == nil || n.ID == "" {
+		return structs.NewInvalidInputError("Missing node ID")
+	}
 
-	err = s.state.UpsertNode(ctx, n)
-	if err != nil {
+	if err := checkNamespaceExists(ctx, s.state, ns); err != nil {
+		return err
+	}
+
+	if _, err := s.state.NodeByID(ctx, n.ID); err == nil {
+		return structs.NewInvalidInputError("Node already registered")
+	}
+
+	node := &structs.Node{
+		ID:                    n.ID,
+		SecretID:              n.SecretID,
+		Namespace:             ns,
+		Name:                  n.Name,
+		SchedulingEligibility: structs.NodeSchedulingEligible,
+		ServerMeta:            n.Meta,
+		CreatedAt:             time.Now(),
+	}
+
+	node.SetMeta(nil)
+	node.UpdatedAt = node.CreatedAt
+	node.SetStatus(structs.NodeStatusInit, node.UpdatedAt)
+
+	if err := s.state.UpsertNode(ctx, node); err != nil {
 		return structs.NewInternalError(err.Error())
 	}
 
-	out.Servers = []string{s.config.RPCAdvertiseAddr}
+	s.resetHeartbeatTimer(node.ID)
 
-	s.logger.Debugf("heartbeat from node %s", n.ID)
-	s.resetHeartbeatTimer(n.ID)
+	out.Node = node
 
 	return nil
 }
 
-// GetInterfaces :
+// joinTokenNetworks has a newly registered node join the networks specified by
+// the join token it registered with. Failures are logged, since the node itself
+// was registered successfully, and networks can still be joined manually.
+func (s *NodeService) joinTokenNetworks(ctx context.Context, id string, token *structs.JoinToken) {
+
+	for _, networkID := range token.Networks {
+
+		err := retryOnConflict(func() error {
+
+			node, err := s.state.NodeByID(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			network, err := s.state.NetworkByID(ctx, networkID)
+			if err != nil {
+				return err
+			}
+
+			_, err = joinNetwork(ctx, s.state, node, network)
+			return err
+		})
+		if err != nil {
+			s.logger.Warnf("node %s couldn't join network %s: %v", id, networkID, err)
+			continue
+		}
+
+		if err := reconcileNetworkTopology(ctx, s.state, networkID); err != nil {
+			s.logger.Warnf("error reconciling topology of network %s: %v", networkID, err)
+		}
+	}
+}
+
+// UpdateEligibility updates the scheduling eligibility of a node. Ineligible nodes keep
+// their existing connections, but are not automatically connected to other nodes.
+func (s *NodeService) UpdateEligibility(args *structs.NodeUpdateEligibilityRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Node.UpdateEligibility", args.AuthToken, ns, "node", auditID(&args.NodeID))(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	if args.NodeID == "" {
+		return structs.NewInvalidInputError("Missing NodeID")
+	}
+	if !structs.IsValidNodeSchedulingEligibility(args.Eligibility) {
+		return structs.NewInvalidInputError("Invalid scheduling eligibility")
+	}
+
+	return s.updateNode(ctx, ns, args.NodeID, func(n *structs.Node) error {
+		if n.Drain && args.Eligibility == structs.NodeSchedulingEligible {
+			return structs.NewInvalidInputError("Node is draining, disable the drain instead")
+		}
+		n.SchedulingEligibility = args.Eligibility
+		return nil
+	})
+}
+
+// UpdateDrain enables or disables the drain of a node. Draining nodes are ineligible,
+// and their peers are removed from the configuration of other nodes until the drain
+// is disabled, which also makes them eligible again.
+func (s *NodeService) UpdateDrain(args *structs.NodeUpdateDrainRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Node.UpdateDrain", args.AuthToken, ns, "node", auditID(&args.NodeID))(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	if args.NodeID == "" {
+		return structs.NewInvalidInputError("Missing NodeID")
+	}
+
+	return s.updateNode(ctx, ns, args.NodeID, func(n *structs.Node) error {
+		n.Drain = args.Drain
+		if args.Drain {
+			n.SchedulingEligibility = structs.NodeSchedulingIneligible
+		} else {
+			n.SchedulingEligibility = structs.NodeSchedulingEligible
+		}
+		return nil
+	})
+}
+
+// updateNode applies fn to a node in namespace ns and persists the result, retrying in case
+// the node is modified concurrently. The networks joined by the node are reconciled afterwards,
+// so that connections skipped while it was ineligible are created.
+func (s *NodeService) updateNode(ctx context.Context, ns, id string, fn func(n *structs.Node) error) error {
+
+	err := retryOnConflict(func() error {
+
+		n, err := nodeInNamespace(ctx, s.state, ns, id)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(n); err != nil {
+			return err
+		}
+
+		n.UpdatedAt = time.Now()
+
+		return s.state.UpsertNode(ctx, n)
+	})
+	if err != nil {
+		if err == structs.ErrNotFound || strings.HasPrefix(err.Error(), structs.ErrInvalidInput.Error()) {
+			return err
+		}
+		return structs.NewInternalError(err.Error())
+	}
+
+	s.reconcileNodeNetworks(ctx, id)
+
+	return nil
+}
+
+// reconcileNodeNetworks reconciles the topology of all networks joined by a node,
+// which is necessary whenever node attributes affecting the topology change.
+func (s *NodeService) reconcileNodeNetworks(ctx context.Context, id string) {
+
+	interfaces, err := s.state.InterfacesByNodeID(ctx, id)
+	if err != nil {
+		s.logger.Warnf("couldn't get interfaces for node %s", id)
+		return
+	}
+
+	for _, iface := range interfaces {
+		if err := reconcileNetworkTopology(ctx, s.state, iface.NetworkID); err != nil {
+			s.logger.Warnf("error reconciling topology of network %s: %v", iface.NetworkID, err)
+		}
+	}
+}
+
+// GetInterfaces returns the interfaces of a node, along with their peers. If args.MinQueryIndex
+// is set and the node configuration did not change since then, the call blocks until it changes
+// or until args.MaxQueryTime elapses, which allows clients to long-poll for changes. Preshared
+// keys are only returned to the node itself, as identified by its secret ID. Indexes are
+// derived from the configuration itself, so they are the same on every server sharing the
+// state, and remain valid when a client fails over to another server.
 func (s *NodeService) GetInterfaces(args *structs.NodeSpecificRequest, out *structs.NodeInterfacesResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", args.NodeID, NodeRead); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
@@ -215,45 +622,130 @@ func (s *NodeService) GetInterfaces(args *structs.NodeSpecificRequest, out *stru
 		return structs.NewInvalidInputError("Missing NodeID")
 	}
 
-	interfaces, err := s.state.InterfacesByNodeID(ctx, args.NodeID)
+	node, err := nodeInNamespace(ctx, s.state, ns, args.NodeID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
+	self := node.SecretID != "" && args.SecretID == node.SecretID
+
+	ctx, cancel := context.WithTimeout(ctx, blockingQueryTime(args.MaxQueryTime))
+	defer cancel()
+
+	for {
+		// Take the broker index before reading the state, so that
+		// changes made while computing the result are not missed.
+		current := s.broker.Index()
+
+		interfaces, err := s.nodeInterfaces(ctx, args.NodeID)
+		if err != nil {
+			return structs.ErrNotFound
+		}
+
+		index, err := interfacesIndex(interfaces)
+		if err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+
+		if !self {
+			for _, iface := range interfaces {
+				for _, p := range iface.Peers {
+					p.PresharedKey = nil
+				}
+			}
+		}
+
+		out.Items = interfaces
+		out.Index = index
+
+		// Return immediately for non-blocking queries, or if the configuration
+		// is not the one the index passed by the client was derived from.
+		if args.MinQueryIndex == 0 || args.MinQueryIndex != index {
+			return nil
+		}
+
+		if !s.waitInterfacesChange(ctx, current) {
+			return nil
+		}
+	}
+}
+
+// nodeInterfaces returns the interfaces of a node, sorted by ID, with the peers
+// derived from their connections.
+func (s *NodeService) nodeInterfaces(ctx context.Context, nodeID string) ([]*structs.Interface, error) {
+
+	interfaces, err := s.state.InterfacesByNodeID(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].ID < interfaces[j].ID })
+
 	for _, iface := range interfaces {
 
 		iface.Peers = []*structs.Peer{}
 
+		// Statistics are reported by the node, and change continuously
+		iface.PeerStats = nil
+
 		connections, err := s.state.ConnectionsByInterfaceID(ctx, iface.ID)
 		if err != nil {
 			s.logger.Warnf("couldn't get connections for interface %s", iface.ID)
 		}
 
+		sort.Slice(connections, func(i, j int) bool { return connections[i].ID < connections[j].ID })
+
 		for _, conn := range connections {
 
 			ifaceSettings := conn.PeerSettingsByInterfaceID(iface.ID)
 			peerSettings := conn.OtherPeerSettingsByInterfaceID(iface.ID)
 
 			if ifaceSettings == nil || peerSettings == nil {
-				s.logger.Warnf("couldn't get settings for connection")
+				s.logger.Warnf("couldn't get settings for connection %s", conn.ID)
+				continue
 			}
 
 			peerIface, err := s.state.InterfaceByID(ctx, peerSettings.InterfaceID)
 			if err != nil {
 				s.logger.Warnf("couldn't get peer interface %s", peerSettings.InterfaceID)
+				continue
 			}
 
-			peerNode, err := s.state.NodeByID(ctx, peerIface.NodeID)
-			if err != nil {
-				s.logger.Warnf("couldn't get peer node %s", peerIface.NodeID)
+			// External peers have no node, so unless they have a static
+			// endpoint, the tunnel is always initiated by them.
+			var address *string
+			port := peerIface.ListenPort
+
+			if peerIface.External && peerIface.Endpoint != nil {
+				address, port = splitEndpoint(*peerIface.Endpoint)
+			}
+
+			if !peerIface.External {
+
+				peerNode, err := s.state.NodeByID(ctx, peerIface.NodeID)
+				if err != nil {
+					s.logger.Warnf("couldn't get peer node %s", peerIface.NodeID)
+					continue
+				}
+
+				// Drained nodes are removed from the configuration of their peers,
+				// which is restored once the drain is disabled. Connections are
+				// kept, so that nothing needs to be recreated.
+				if peerNode.Drain {
+					continue
+				}
+
+				address = &peerNode.AdvertiseAddress
 			}
 
 			peer := &structs.Peer{
+				ConnectionID:        &conn.ID,
 				PublicKey:           peerIface.PublicKey,
-				Address:             &peerNode.AdvertiseAddress,
-				Port:                peerIface.ListenPort,
+				Address:             address,
+				Port:                port,
 				AllowedIPs:          []string{},
 				PersistentKeepalive: conn.PersistentKeepalive,
+				PresharedKey:        conn.PresharedKey,
 			}
 
 			if ifaceSettings.RoutingRules != nil {
@@ -261,76 +753,157 @@ func (s *NodeService) GetInterfaces(args *structs.NodeSpecificRequest, out *stru
 			}
 
 			iface.Peers = append(iface.Peers, peer)
-
 		}
 	}
 
-	out.Items = append(out.Items, interfaces...)
+	return interfaces, nil
+}
 
-	return nil
+// interfacesIndex returns the index of the configuration of a node's interfaces. Since
+// configurations are not versioned in the state, the index is derived from a hash of the
+// configuration, and is only meant to be compared for equality. Fields which do not affect
+// the configuration, such as timestamps, are not hashed.
+func interfacesIndex(interfaces []*structs.Interface) (uint64, error) {
+
+	stripped := make([]structs.Interface, 0, len(interfaces))
+	for _, iface := range interfaces {
+		i := *iface
+		i.CreatedAt, i.UpdatedAt, i.ModifyIndex = time.Time{}, time.Time{}, 0
+		stripped = append(stripped, i)
+	}
+
+	b, err := json.Marshal(stripped)
+	if err != nil {
+		return 0, err
+	}
+	hash := sha256.Sum256(b)
+
+	// Zero indexes denote non-blocking queries
+	index := binary.BigEndian.Uint64(hash[:8])
+	if index == 0 {
+		index = 1
+	}
+
+	return index, nil
+}
+
+// waitInterfacesChange blocks until an event which might affect the configuration of
+// interfaces is published after the index passed as argument, or until it is time to
+// re-read the state. It returns false if the context is done before that.
+func (s *NodeService) waitInterfacesChange(ctx context.Context, index uint64) bool {
+
+	pollCtx, cancel := context.WithTimeout(ctx, interfacesPollInterval)
+	defer cancel()
+
+	for {
+		current := s.broker.Wait(pollCtx, index)
+		if ctx.Err() != nil {
+			return false
+		}
+		if pollCtx.Err() != nil {
+			return true
+		}
+
+		events := s.broker.Events(index, func(e *structs.Event) bool {
+			switch e.Topic {
+			case structs.EventTopicNode, structs.EventTopicNetwork,
+				structs.EventTopicInterface, structs.EventTopicConnection:
+				return true
+			}
+			return false
+		})
+		if len(events) > 0 {
+			return true
+		}
+
+		index = current
+	}
 }
 
 // UpdateInterfaces :
 func (s *NodeService) UpdateInterfaces(args *structs.NodeInterfaceUpdateRequest, out *structs.GenericResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", "", NodeWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	node, err := s.state.NodeByID(ctx, args.NodeID)
+	node, err := nodeInNamespace(ctx, s.state, ns, args.NodeID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
-	nodeInterfaces, err := s.state.InterfacesByNodeID(ctx, node.ID)
-	if err != nil {
-		return structs.NewInternalError(err.Error())
-	}
+	// Retry in case interfaces are modified concurrently, since nodes
+	// report the status of their interfaces periodically.
+	return retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
 
-	// Create a map for more efficient lookup
-	nodeInterfacesMap := map[string]*structs.Interface{}
-	for _, i := range nodeInterfaces {
-		nodeInterfacesMap[i.ID] = i
-	}
+			nodeInterfaces, err := s.state.InterfacesByNodeID(ctx, node.ID)
+			if err != nil {
+				return structs.NewInternalError(err.Error())
+			}
 
-	for _, i := range args.Interfaces {
-		old, found := nodeInterfacesMap[i.ID]
-		if !found {
-			return structs.NewInternalError("Interface does not belong to node")
-		}
+			// Create a map for more efficient lookup
+			nodeInterfacesMap := map[string]*structs.Interface{}
+			for _, i := range nodeInterfaces {
+				nodeInterfacesMap[i.ID] = i
+			}
 
-		i = old.Merge(i)
-		i.UpdatedAt = time.Now()
+			for _, i := range args.Interfaces {
+				old, found := nodeInterfacesMap[i.ID]
+				if !found {
+					return structs.NewInternalError("Interface does not belong to node")
+				}
 
-		err := s.state.UpsertInterface(ctx, i)
-		if err != nil {
-			return structs.NewInternalError("Can't update interface")
-		}
-	}
+				reported := i
 
-	return nil
+				i = old.Merge(reported)
+				i.UpdatedAt = time.Now()
+
+				// Once the node switches to the key pair it published ahead of a
+				// rotation, the rotation is complete. Otherwise, record the pending
+				// public key, which signals the node that it can switch to it.
+				if old.PublicKey != nil && reported.PublicKey != nil && *old.PublicKey != *reported.PublicKey {
+					i.PendingPublicKey = nil
+					i.RotateKey = false
+				} else {
+					i.PendingPublicKey = reported.PendingPublicKey
+				}
+
+				i.PeerStats = reported.PeerStats
+
+				err := s.state.UpsertInterface(ctx, i)
+				if err != nil {
+					return structs.NewInternalError("Can't update interface")
+				}
+			}
+
+			return nil
+		})
+	})
 }
 
 // GetNode returns a Node entity by ID
 func (s *NodeService) GetNode(args *structs.NodeSpecificRequest, out *structs.SingleNodeResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", args.NodeID, NodeRead); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeRead); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	n, err := s.state.NodeByID(ctx, args.NodeID)
+	n, err := nodeInNamespace(ctx, s.state, ns, args.NodeID)
 	if err != nil {
-		return structs.ErrNotFound
+		return err
 	}
 
 	out.Node = n
@@ -342,119 +915,169 @@ func (s *NodeService) GetNode(args *structs.NodeSpecificRequest, out *structs.Si
 func (s *NodeService) ListNodes(args *structs.NodeListRequest, out *structs.NodeListResponse) error {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", "", NodeList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeList); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	nodes, err := s.state.Nodes(ctx)
-	if err != nil {
-		return structs.NewInternalError(err.Error())
-	}
-
 	out.Items = nil
 
-	for _, n := range nodes {
-		out.Items = append(out.Items, n.Stub())
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		nodes, next, err := s.state.NodesPage(ctx, opts)
+		if err != nil {
+			return "", structs.NewInternalError(err.Error())
+		}
+
+		for _, n := range nodes {
+			if inNamespace(n.Namespace, ns) {
+				out.Items = append(out.Items, n.Stub())
+			}
+		}
+
+		out.Items = filterNodes(out.Items, args.Filters)
+
+		return next, nil
+	})
+	if err != nil {
+		return err
 	}
 
-	out.Items = filterNodes(out.Items, args.Filters)
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
+	}
 
 	return nil
 }
 
 // JoinNetwork : connects a node to a network
-func (s *NetworkService) JoinNetwork(args *structs.NodeJoinNetworkRequest, out *structs.GenericResponse) error {
+func (s *NetworkService) JoinNetwork(args *structs.NodeJoinNetworkRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Network.JoinNetwork", args.AuthToken, ns, "node", auditID(&args.NodeID))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", args.NodeID, NodeWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", args.NetworkID, NetworkWrite); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, args.NetworkID), NetworkWrite); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	network, err := s.state.NetworkByID(ctx, args.NetworkID)
+	network, err := networkInNamespace(ctx, s.state, ns, args.NetworkID)
 	if err != nil {
-		return structs.ErrNotFound // network not found
+		return err // network not found
 	}
 
-	node, err := s.state.NodeByID(ctx, args.NodeID)
+	node, err := nodeInNamespace(ctx, s.state, ns, args.NodeID)
 	if err != nil {
-		return structs.ErrNotFound // node not found
+		return err // node not found
 	}
 
-	interfaces, err := s.state.InterfacesByNodeID(ctx, node.ID)
+	if _, err := joinNetwork(ctx, s.state, node, network); err != nil {
+		return err
+	}
+
+	if err := reconcileNetworkTopology(ctx, s.state, network.ID); err != nil {
+		s.logger.Warnf("error reconciling topology of network %s: %v", network.ID, err)
+	}
+
+	return nil
+}
+
+// joinNetwork creates an interface connecting a node to a network, assigning it an address
+// from the network address range. The node and the network must be in the same namespace.
+// The network topology must be reconciled afterwards.
+func joinNetwork(ctx context.Context, repo state.Repository, node *structs.Node, network *structs.Network) (*structs.Interface, error) {
+
+	if structs.NamespaceOrDefault(node.Namespace) != structs.NamespaceOrDefault(network.Namespace) {
+		return nil, structs.NewInvalidInputError("Node and network are not in the same namespace")
+	}
+
+	interfaces, err := repo.InterfacesByNodeID(ctx, node.ID)
 	if err != nil {
-		return structs.NewInternalError(err.Error())
+		return nil, structs.NewInternalError(err.Error())
 	}
 
 	// Check whether node has already joined the network
 	for _, iface := range interfaces {
 		if iface.NetworkID == network.ID {
-			return structs.NewInternalError("Network already joined")
+			return nil, structs.NewInternalError("Network already joined")
 		}
 	}
 
 	iface := &structs.Interface{
 		ID:        uuid.Generate(),
+		Namespace: network.Namespace,
 		NodeID:    node.ID,
 		NetworkID: network.ID,
 		Name:      nil,               // Setting name is responsibility of the client node
-		Address:   nil,               // TODO: set with leasing plugin if it is loaded and enabled
 		Peers:     []*structs.Peer{}, // TODO: set with meshing plugin if it is loaded and enabled
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	err = s.state.UpsertInterface(ctx, iface)
-	if err != nil {
-		return structs.NewInternalError("Can't create interface")
+	if err := assignInterfaceAddress(ctx, repo, network, iface); err != nil {
+		return nil, err
 	}
 
-	node.UpsertInterface(iface.ID)
-	err = s.state.UpsertNode(ctx, node)
-	if err != nil {
-		return structs.NewInternalError("Can't add interface to node")
-	}
+	err = withTransaction(ctx, repo, func(ctx context.Context) error {
+
+		if err := repo.UpsertInterface(ctx, iface); err != nil {
+			return structs.NewInternalError("Can't create interface")
+		}
+
+		node.UpsertInterface(iface.ID)
+		if err := repo.UpsertNode(ctx, node); err != nil {
+			return structs.NewInternalError("Can't add interface to node")
+		}
 
-	network.UpsertInterface(iface.ID)
-	err = s.state.UpsertNetwork(ctx, network)
+		network.UpsertInterface(iface.ID)
+		if err := repo.UpsertNetwork(ctx, network); err != nil {
+			return structs.NewInternalError("Can't add interface to network")
+		}
+
+		return nil
+	})
 	if err != nil {
-		return structs.NewInternalError("Can't add interface to network")
+		return nil, err
 	}
 
-	return nil
+	return iface, nil
 }
 
 // LeaveNetwork : disconnects a node from a network
-func (s *NetworkService) LeaveNetwork(args *structs.NodeLeaveNetworkRequest, out *structs.GenericResponse) error {
+func (s *NetworkService) LeaveNetwork(args *structs.NodeLeaveNetworkRequest, out *structs.GenericResponse) (err error) {
 
 	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Network.LeaveNetwork", args.AuthToken, ns, "node", auditID(&args.NodeID))(&err)
 
 	// Check if authorized
 	if s.config.ACL.Enabled {
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", args.NodeID, NodeList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, args.NodeID), NodeList); err != nil {
 			return structs.ErrPermissionDenied
 		}
-		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", args.NetworkID, NodeList); err != nil {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "network", namespacePath(ns, args.NetworkID), NodeList); err != nil {
 			return structs.ErrPermissionDenied
 		}
 	}
 
-	network, err := s.state.NetworkByID(ctx, args.NodeID)
+	network, err := networkInNamespace(ctx, s.state, ns, args.NetworkID)
 	if err != nil {
 		return structs.NewInternalError("Network does not exist")
 	}
 
-	node, err := s.state.NodeByID(ctx, args.NodeID)
+	node, err := nodeInNamespace(ctx, s.state, ns, args.NodeID)
 	if err != nil {
 		return structs.NewInternalError("Node does not exist")
 	}
@@ -467,23 +1090,16 @@ func (s *NetworkService) LeaveNetwork(args *structs.NodeLeaveNetworkRequest, out
 	// Check whether node is in the network
 	for _, iface := range interfaces {
 		if iface.NetworkID == network.ID {
-
-			network.RemoveInterface(iface.ID)
-			if err := s.state.UpsertNetwork(ctx, network); err != nil {
-				return structs.NewInternalError("Can't update network")
-			}
-
-			node.RemoveInterface(iface.ID)
-			if err := s.state.UpsertNode(ctx, node); err != nil {
-				return structs.NewInternalError("Can't update node")
-			}
-
-			if err := s.state.DeleteInterfaces(ctx, []string{iface.ID}); err != nil {
-				return structs.NewInternalError("Can't delete interface")
+			if err := deleteInterface(ctx, s.state, iface); err != nil {
+				return err
 			}
 		}
 	}
 
+	if err := reconcileNetworkTopology(ctx, s.state, network.ID); err != nil {
+		s.logger.Warnf("error reconciling topology of network %s: %v", network.ID, err)
+	}
+
 	return nil
 }
 
@@ -529,3 +1145,21 @@ func filterNodes(nodes []*structs.NodeListStub, filters structs.Filters) []*stru
 
 	return out
 }
+
+// splitEndpoint splits an endpoint in the host:port format, returning nil
+// values if it is invalid. Hostnames are not resolved here, but by the nodes
+// when configuring their interfaces, as wg-quick does.
+func splitEndpoint(endpoint string) (*string, *int) {
+
+	host, p, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, nil
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return nil, nil
+	}
+
+	return &host, &port
+}
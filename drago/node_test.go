@@ -0,0 +1,435 @@
+package drago
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// testPeerKeys returns the public keys of the peers of a node's interfaces,
+// skipping peers which have not published one yet.
+func testPeerKeys(t *testing.T, s *NodeService, nodeID string) map[string]struct{} {
+
+	out := &structs.NodeInterfacesResponse{}
+	if err := s.GetInterfaces(&structs.NodeSpecificRequest{NodeID: nodeID}, out); err != nil {
+		t.Fatalf("s.GetInterfaces() failed: %v", err)
+	}
+
+	keys := map[string]struct{}{}
+	for _, iface := range out.Items {
+		for _, p := range iface.Peers {
+			if p.PublicKey != nil {
+				keys[*p.PublicKey] = struct{}{}
+			}
+		}
+	}
+
+	return keys
+}
+
+func TestUpdateDrain(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+	testNode(t, repo, "net", "c", "10.0.0.3/24", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	s := testNodeService(t, repo, testConfig(), nil)
+
+	if keys := testPeerKeys(t, s, "a"); len(keys) != 2 {
+		t.Fatalf("expected 2 peers before drain, got %d", len(keys))
+	}
+
+	if err := s.UpdateDrain(&structs.NodeUpdateDrainRequest{NodeID: "c", Drain: true}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpdateDrain() failed: %v", err)
+	}
+
+	node, _ := repo.NodeByID(ctx, "c")
+	if !node.Drain || node.SchedulingEligibility != structs.NodeSchedulingIneligible {
+		t.Fatalf("expected drained node to be ineligible")
+	}
+
+	// The drained node is removed from the configuration of its
+	// peers, but its connections are kept.
+	keys := testPeerKeys(t, s, "a")
+	if _, ok := keys["c-key"]; ok || len(keys) != 1 {
+		t.Fatalf("expected drained node to be removed from peers, got %v", keys)
+	}
+	if keys := testPeerKeys(t, s, "b"); len(keys) != 1 {
+		t.Fatalf("expected drained node to be removed from peers, got %v", keys)
+	}
+	if connections, _ := repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 3 {
+		t.Fatalf("expected connections to be kept during drain, got %d", len(connections))
+	}
+
+	// A draining node can't be made eligible, other than by disabling the drain
+	err := s.UpdateEligibility(&structs.NodeUpdateEligibilityRequest{NodeID: "c", Eligibility: structs.NodeSchedulingEligible}, &structs.GenericResponse{})
+	if err == nil {
+		t.Fatalf("expected s.UpdateEligibility() to fail for draining node")
+	}
+
+	if err := s.UpdateDrain(&structs.NodeUpdateDrainRequest{NodeID: "c", Drain: false}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpdateDrain() failed: %v", err)
+	}
+
+	node, _ = repo.NodeByID(ctx, "c")
+	if node.Drain || node.SchedulingEligibility != structs.NodeSchedulingEligible {
+		t.Fatalf("expected node to be eligible once the drain is disabled")
+	}
+
+	if keys := testPeerKeys(t, s, "a"); len(keys) != 2 {
+		t.Fatalf("expected peers to be restored once the drain is disabled, got %v", keys)
+	}
+}
+
+func TestUpdateEligibility(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+
+	s := testNodeService(t, repo, testConfig(), nil)
+
+	if err := s.UpdateEligibility(&structs.NodeUpdateEligibilityRequest{NodeID: "b", Eligibility: "maybe"}, &structs.GenericResponse{}); err == nil {
+		t.Fatalf("expected s.UpdateEligibility() to fail for invalid eligibility")
+	}
+
+	if err := s.UpdateEligibility(&structs.NodeUpdateEligibilityRequest{NodeID: "b", Eligibility: structs.NodeSchedulingIneligible}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpdateEligibility() failed: %v", err)
+	}
+
+	// No connections are created for ineligible nodes
+	if connections, _ := repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 0 {
+		t.Fatalf("expected no connections to ineligible node, got %d", len(connections))
+	}
+
+	if err := s.UpdateEligibility(&structs.NodeUpdateEligibilityRequest{NodeID: "b", Eligibility: structs.NodeSchedulingEligible}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpdateEligibility() failed: %v", err)
+	}
+
+	// Connections skipped while the node was ineligible are created
+	if connections, _ := repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 1 {
+		t.Fatalf("expected connection to be created once the node is eligible, got %d", len(connections))
+	}
+	if keys := testPeerKeys(t, s, "a"); len(keys) != 1 {
+		t.Fatalf("expected 1 peer, got %v", keys)
+	}
+
+	// Ineligible nodes keep their existing connections
+	if err := s.UpdateEligibility(&structs.NodeUpdateEligibilityRequest{NodeID: "b", Eligibility: structs.NodeSchedulingIneligible}, &structs.GenericResponse{}); err != nil {
+		t.Fatalf("s.UpdateEligibility() failed: %v", err)
+	}
+	if keys := testPeerKeys(t, s, "a"); len(keys) != 1 {
+		t.Fatalf("expected ineligible node to be kept in peers, got %v", keys)
+	}
+}
+
+func TestRegisterPreregisteredNode(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+	config := testConfig()
+
+	authHandler := testACL(t, repo, config)
+	s := testNodeService(t, repo, config, authHandler)
+
+	repo.UpsertJoinToken(ctx, &structs.JoinToken{ID: "token", Namespace: structs.DefaultNamespace, Secret: "join-secret"})
+
+	preregister := func(id string) {
+		node := &structs.Node{ID: id, Namespace: structs.DefaultNamespace, SchedulingEligibility: structs.NodeSchedulingEligible}
+		node.SetStatus(structs.NodeStatusInit, time.Now())
+		if err := repo.UpsertNode(ctx, node); err != nil {
+			t.Fatalf("repo.UpsertNode() failed: %v", err)
+		}
+	}
+
+	register := func(id, secretID, joinToken string) error {
+		return s.Register(&structs.NodeRegisterRequest{
+			Node:      &structs.Node{ID: id, SecretID: secretID, Name: id, AdvertiseAddress: "1.2.3.4"},
+			JoinToken: joinToken,
+		}, &structs.NodeUpdateResponse{})
+	}
+
+	// An invalid join token does not authorize binding a preregistered node
+	preregister("a")
+	if err := register("a", "attacker", "bogus"); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected s.Register() to be denied, got %v", err)
+	}
+	if node, _ := repo.NodeByID(ctx, "a"); node.SecretID != "" {
+		t.Fatalf("expected preregistered node not to be bound")
+	}
+
+	// Neither does the lack of an ACL token
+	if err := register("a", "attacker", ""); err != structs.ErrPermissionDenied {
+		t.Fatalf("expected s.Register() to be denied, got %v", err)
+	}
+
+	// A valid join token binds the node, and is consumed
+	if err := register("a", "a-secret", "join-secret"); err != nil {
+		t.Fatalf("s.Register() failed: %v", err)
+	}
+	node, _ := repo.NodeByID(ctx, "a")
+	if node.SecretID != "a-secret" || node.JoinTokenID != "token" {
+		t.Fatalf("expected node to be bound with join token, got secret ID %q and join token %q", node.SecretID, node.JoinTokenID)
+	}
+	if token, _ := repo.JoinTokenByID(ctx, "token"); token.Uses != 1 {
+		t.Fatalf("expected join token to be consumed, got %d uses", token.Uses)
+	}
+
+	// Once bound, the node is authorized by its secret ID only
+	if err := register("a", "a-secret", "bogus"); err != nil {
+		t.Fatalf("s.Register() failed: %v", err)
+	}
+	if err := register("a", "attacker", "join-secret"); err == nil {
+		t.Fatalf("expected s.Register() to fail for a mismatching secret ID")
+	}
+
+	// An ACL token with write access to nodes binds the node as well
+	preregister("b")
+	secret := testACLToken(t, repo, "nodes", &structs.ACLPolicyRule{Resource: "node", Path: "*", Capabilities: []string{"write"}})
+	err := s.Register(&structs.NodeRegisterRequest{
+		Node:         &structs.Node{ID: "b", SecretID: "b-secret", Name: "b", AdvertiseAddress: "1.2.3.4"},
+		WriteRequest: structs.WriteRequest{AuthToken: secret},
+	}, &structs.NodeUpdateResponse{})
+	if err != nil {
+		t.Fatalf("s.Register() failed: %v", err)
+	}
+	if node, _ := repo.NodeByID(ctx, "b"); node.SecretID != "b-secret" {
+		t.Fatalf("expected preregistered node to be bound")
+	}
+}
+
+func TestRegisterPreregisteredNodeRequireJoinToken(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	config := testConfig()
+	config.RequireJoinToken = true
+
+	s := testNodeService(t, repo, config, nil)
+
+	node := &structs.Node{ID: "a", Namespace: structs.DefaultNamespace, SchedulingEligibility: structs.NodeSchedulingEligible}
+	node.SetStatus(structs.NodeStatusInit, time.Now())
+	if err := repo.UpsertNode(ctx, node); err != nil {
+		t.Fatalf("repo.UpsertNode() failed: %v", err)
+	}
+
+	// Without ACLs, nothing but a join token authorizes binding a preregistered node
+	err := s.Register(&structs.NodeRegisterRequest{
+		Node: &structs.Node{ID: "a", SecretID: "attacker", Name: "a", AdvertiseAddress: "1.2.3.4"},
+	}, &structs.NodeUpdateResponse{})
+	if err != structs.ErrPermissionDenied {
+		t.Fatalf("expected s.Register() to be denied, got %v", err)
+	}
+	if node, _ := repo.NodeByID(ctx, "a"); node.SecretID != "" {
+		t.Fatalf("expected preregistered node not to be bound")
+	}
+}
+
+func TestRegisterNodeInOtherNamespace(t *testing.T) {
+
+	repo := testState()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyManual)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+
+	s := testNodeService(t, repo, testConfig(), nil)
+
+	register := func(id string) error {
+		return s.Register(&structs.NodeRegisterRequest{
+			Node:         &structs.Node{ID: id, SecretID: id + "-secret", Name: id, AdvertiseAddress: "1.2.3.4"},
+			WriteRequest: structs.WriteRequest{Namespace: "team-a"},
+		}, &structs.NodeUpdateResponse{})
+	}
+
+	// A node registered in another namespace is reported as missing
+	if err := register("a"); err != structs.ErrNotFound {
+		t.Fatalf("expected s.Register() to fail with %v, got %v", structs.ErrNotFound, err)
+	}
+}
+
+func TestKnownServers(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	config := testConfig()
+	config.RPCAdvertiseAddr = "10.0.0.1:8081"
+
+	// Servers sharing the state refresh their entries, while
+	// servers which stopped doing so are no longer advertised.
+	for _, address := range []string{config.RPCAdvertiseAddr, "10.0.0.2:8081"} {
+		if err := heartbeatServer(ctx, repo, address, time.Now()); err != nil {
+			t.Fatalf("heartbeatServer() failed: %v", err)
+		}
+	}
+	repo.UpsertServer(ctx, &structs.Server{Address: "10.0.0.3:8081", UpdatedAt: time.Now().Add(-2 * serverTTL)})
+
+	s := testNodeService(t, repo, config, nil)
+
+	expected := []string{"10.0.0.1:8081", "10.0.0.2:8081"}
+
+	out := &structs.NodeUpdateResponse{}
+	err := s.Register(&structs.NodeRegisterRequest{
+		Node: &structs.Node{ID: "a", SecretID: "a-secret", Name: "a", AdvertiseAddress: "1.2.3.4"},
+	}, out)
+	if err != nil {
+		t.Fatalf("s.Register() failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Servers, expected) {
+		t.Fatalf("expected servers %v on registration, got %v", expected, out.Servers)
+	}
+
+	out = &structs.NodeUpdateResponse{}
+	err = s.UpdateStatus(&structs.NodeUpdateStatusRequest{NodeID: "a", Status: structs.NodeStatusReady, AdvertiseAddress: "1.2.3.4"}, out)
+	if err != nil {
+		t.Fatalf("s.UpdateStatus() failed: %v", err)
+	}
+	if !reflect.DeepEqual(out.Servers, expected) {
+		t.Fatalf("expected servers %v on heartbeat, got %v", expected, out.Servers)
+	}
+
+	// Stale entries are removed as servers refresh theirs
+	if err := heartbeatServer(ctx, repo, config.RPCAdvertiseAddr, time.Now()); err != nil {
+		t.Fatalf("heartbeatServer() failed: %v", err)
+	}
+	if servers, _ := repo.Servers(ctx); len(servers) != 2 {
+		t.Fatalf("expected stale server entry to be removed, got %d entries", len(servers))
+	}
+}
+
+func TestGetInterfacesPresharedKeys(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	s := testNodeService(t, repo, testConfig(), nil)
+
+	tests := map[string]bool{
+		"a-secret": true,
+		"b-secret": false,
+		"":         false,
+	}
+
+	for secretID, expected := range tests {
+
+		out := &structs.NodeInterfacesResponse{}
+		if err := s.GetInterfaces(&structs.NodeSpecificRequest{NodeID: "a", SecretID: secretID}, out); err != nil {
+			t.Fatalf("s.GetInterfaces() failed: %v", err)
+		}
+
+		peers := out.Items[0].Peers
+		if len(peers) != 1 {
+			t.Fatalf("expected 1 peer, got %d", len(peers))
+		}
+		if (peers[0].PresharedKey != nil) != expected {
+			t.Fatalf("expected preshared key to be returned for secret ID %q: %t", secretID, expected)
+		}
+	}
+}
+
+func TestSplitEndpoint(t *testing.T) {
+
+	tests := map[string]string{
+		"1.2.3.4:51820":              "1.2.3.4",
+		"[fd00::1]:51820":            "fd00::1",
+		"peer.invalid.example:51820": "peer.invalid.example",
+	}
+
+	for endpoint, expected := range tests {
+		address, port := splitEndpoint(endpoint)
+		if address == nil || *address != expected {
+			t.Fatalf("expected address %s for endpoint %s, got %v", expected, endpoint, address)
+		}
+		if port == nil || *port != 51820 {
+			t.Fatalf("expected port 51820 for endpoint %s, got %v", endpoint, port)
+		}
+	}
+
+	if address, port := splitEndpoint("1.2.3.4"); address != nil || port != nil {
+		t.Fatalf("expected nil values for an endpoint without a port")
+	}
+}
+
+func TestGetInterfacesBlockingAcrossServers(t *testing.T) {
+
+	defer func(d time.Duration) { interfacesPollInterval = d }(interfacesPollInterval)
+	interfacesPollInterval = 10 * time.Millisecond
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+
+	// Servers sharing the state, each with its own broker
+	s1 := testNodeService(t, repo, testConfig(), nil)
+	s2 := testNodeService(t, repo, testConfig(), nil)
+
+	out := &structs.NodeInterfacesResponse{}
+	if err := s1.GetInterfaces(&structs.NodeSpecificRequest{NodeID: "a"}, out); err != nil {
+		t.Fatalf("s1.GetInterfaces() failed: %v", err)
+	}
+	index := out.Index
+
+	// The index returned by one server blocks on the other
+	start := time.Now()
+	args := &structs.NodeSpecificRequest{NodeID: "a"}
+	args.MinQueryIndex, args.MaxQueryTime = index, 100*time.Millisecond
+
+	out = &structs.NodeInterfacesResponse{}
+	if err := s2.GetInterfaces(args, out); err != nil {
+		t.Fatalf("s2.GetInterfaces() failed: %v", err)
+	}
+	if out.Index != index {
+		t.Fatalf("expected index %d on other server, got %d", index, out.Index)
+	}
+	if elapsed := time.Since(start); elapsed < args.MaxQueryTime {
+		t.Fatalf("expected query to block for %v, returned after %v", args.MaxQueryTime, elapsed)
+	}
+
+	// Changes which publish no events on the server are noticed when re-reading the state
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		iface, _ := repo.InterfaceByID(ctx, "a-iface")
+		port := 51820
+		iface.ListenPort = &port
+		repo.UpsertInterface(ctx, iface)
+	}()
+
+	start = time.Now()
+	args.MaxQueryTime = 10 * time.Second
+
+	out = &structs.NodeInterfacesResponse{}
+	if err := s2.GetInterfaces(args, out); err != nil {
+		t.Fatalf("s2.GetInterfaces() failed: %v", err)
+	}
+	if out.Index == index {
+		t.Fatalf("expected index to change after %v", time.Since(start))
+	}
+	if p := out.Items[0].ListenPort; p == nil || *p != 51820 {
+		t.Fatalf("expected updated listen port, got %v", p)
+	}
+}
@@ -0,0 +1,120 @@
+package drago
+
+import (
+	"bytes"
+	"context"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	snapshot "github.com/seashell/drago/drago/state/snapshot"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+const (
+	OperatorRead  = "read"
+	OperatorWrite = "write"
+)
+
+// OperatorService is used for performing operator tasks, such
+// as saving and restoring snapshots of the server state.
+type OperatorService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	auditor     *Auditor
+	nodes       *NodeService
+	authHandler auth.AuthorizationHandler
+}
+
+// NewOperatorService ...
+func NewOperatorService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, nodes *NodeService, authHandler auth.AuthorizationHandler) *OperatorService {
+	return &OperatorService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		auditor:     auditor,
+		nodes:       nodes,
+		authHandler: authHandler,
+	}
+}
+
+// SnapshotSave returns a snapshot of all resources in the state. Since snapshots contain
+// all secrets, saving one requires the same capability as restoring it, and is audited.
+func (s *OperatorService) SnapshotSave(args *structs.SnapshotSaveRequest, out *structs.SnapshotSaveResponse) (err error) {
+
+	ctx := context.TODO()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "operator", "", OperatorWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	defer s.auditor.Begin(ctx, "Operator.SnapshotSave", args.AuthToken, "", "snapshot", func() []string { return nil })(&err)
+
+	buf := &bytes.Buffer{}
+	if _, err := snapshot.Save(ctx, s.state, buf); err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	out.Snapshot = buf.Bytes()
+
+	return nil
+}
+
+// AuthorizeMetrics checks whether the token of the request is allowed to read the metrics
+// exposed by agents, which requires the operator:read capability, since they contain the
+// IDs of nodes and interfaces, as well as the public keys of peers.
+func (s *OperatorService) AuthorizeMetrics(args *structs.MetricsAuthorizeRequest, out *structs.GenericResponse) error {
+
+	ctx := context.TODO()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "operator", "", OperatorRead); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+// SnapshotRestore replaces all resources in the state with those in a snapshot.
+func (s *OperatorService) SnapshotRestore(args *structs.SnapshotRestoreRequest, out *structs.SnapshotRestoreResponse) (err error) {
+
+	ctx := context.TODO()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "operator", "", OperatorWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	defer s.auditor.Begin(ctx, "Operator.SnapshotRestore", args.AuthToken, "", "snapshot", func() []string { return nil })(&err)
+
+	// Verify the snapshot before touching the state, so that an
+	// invalid one is reported as such, and not as an internal error.
+	if _, _, err := snapshot.Read(bytes.NewReader(args.Snapshot)); err != nil {
+		return structs.NewInvalidInputError(err.Error())
+	}
+
+	meta, err := snapshot.Restore(ctx, s.state, bytes.NewReader(args.Snapshot))
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	s.logger.Infof("restored snapshot created at %s", meta.CreatedAt)
+
+	// Track the heartbeats of the restored nodes, which are marked
+	// as down unless they heartbeat within the usual timeout.
+	if err := s.nodes.resetHeartbeatTimers(); err != nil {
+		s.logger.Warnf("error resetting heartbeat timers: %v", err)
+	}
+
+	out.CreatedAt = meta.CreatedAt
+
+	return nil
+}
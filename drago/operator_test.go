@@ -0,0 +1,43 @@
+package drago
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	snapshot "github.com/seashell/drago/drago/state/snapshot"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestSnapshotRestoreHeartbeatTimers(t *testing.T) {
+
+	ctx := context.Background()
+
+	src := testState()
+	src.UpsertNode(ctx, &structs.Node{ID: "restored", Namespace: structs.DefaultNamespace})
+
+	buf := &bytes.Buffer{}
+	if _, err := snapshot.Save(ctx, src, buf); err != nil {
+		t.Fatalf("snapshot.Save() failed: %v", err)
+	}
+
+	repo := testState()
+	repo.UpsertNode(ctx, &structs.Node{ID: "replaced", Namespace: structs.DefaultNamespace})
+
+	config := testConfig()
+	nodes := testNodeService(t, repo, config, nil)
+
+	s := NewOperatorService(config, testLogger(t), repo, nil, nodes, nil)
+
+	if err := s.SnapshotRestore(&structs.SnapshotRestoreRequest{Snapshot: buf.Bytes()}, &structs.SnapshotRestoreResponse{}); err != nil {
+		t.Fatalf("s.SnapshotRestore() failed: %v", err)
+	}
+
+	// Heartbeats are tracked for the restored nodes only
+	nodes.heartbeatTimersLock.Lock()
+	defer nodes.heartbeatTimersLock.Unlock()
+
+	if _, ok := nodes.heartbeatTimers["restored"]; !ok || len(nodes.heartbeatTimers) != 1 {
+		t.Fatalf("expected heartbeat timers for the restored nodes only, got %d timers", len(nodes.heartbeatTimers))
+	}
+}
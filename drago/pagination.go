@@ -0,0 +1,73 @@
+package drago
+
+import (
+	"reflect"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	paginator "github.com/seashell/drago/pkg/paginator"
+)
+
+// paginate sorts the items of a list response, passed as a pointer to a slice of
+// stubs, and replaces them with the page requested in the query options. It returns
+// the token of the next page, to be set in the response.
+func paginate(items interface{}, opts structs.QueryOptions) (string, error) {
+
+	next, err := paginator.Paginate(items, paginator.Options{
+		PerPage:   opts.PerPage,
+		NextToken: opts.NextToken,
+		Sort:      opts.Sort,
+		Order:     opts.Order,
+	})
+	if err != nil {
+		return "", structs.NewInvalidInputError(err.Error())
+	}
+
+	return next, nil
+}
+
+// listPages fills the items of a list response, passed as a pointer to a slice of stubs,
+// with resources read from the repository one page at a time, so that only the resources
+// needed for the page requested in the query options are read. The list function reads
+// a page of resources, appends the ones to be returned to the items, and returns the ID
+// of the first resource of the next page, if any. Items are filtered with the expression
+// in the query options after each page is read, and pages are read until enough items
+// are left to fill the page requested, which paginate then extracts. Repositories sort
+// resources by ID, so unless items are sorted by ID, all resources are read at once.
+func listPages(items interface{}, opts structs.QueryOptions, list func(state.ListOptions) (string, error)) error {
+
+	lopts := state.ListOptions{}
+
+	if opts.PerPage > 0 && paginator.SortedByID(paginator.Options{Sort: opts.Sort, Order: opts.Order}) {
+
+		// Read one resource more than requested, so
+		// that paginate can tell if there is a next page.
+		lopts.PerPage = opts.PerPage + 1
+
+		if opts.NextToken != "" {
+			id, err := paginator.TokenID(opts.NextToken)
+			if err != nil {
+				return structs.NewInvalidInputError(err.Error())
+			}
+			lopts.StartID = id
+		}
+	}
+
+	for {
+
+		next, err := list(lopts)
+		if err != nil {
+			return err
+		}
+
+		if err := filterItems(items, opts); err != nil {
+			return err
+		}
+
+		if next == "" || lopts.PerPage == 0 || reflect.ValueOf(items).Elem().Len() > opts.PerPage {
+			return nil
+		}
+
+		lopts.StartID = next
+	}
+}
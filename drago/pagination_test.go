@@ -0,0 +1,139 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestListPages(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	// Networks in other namespaces, and those not matching the
+	// filter, are interleaved with the ones to be listed.
+	for i := 0; i < 10; i++ {
+		ns := structs.DefaultNamespace
+		if i%2 == 1 {
+			ns = "other"
+		}
+		id := fmt.Sprintf("n%d", i)
+		repo.UpsertNetwork(ctx, &structs.Network{ID: id, Namespace: ns, Name: id})
+	}
+
+	s := NewNetworkService(testConfig(), testLogger(t), repo, nil, nil)
+
+	list := func(opts structs.QueryOptions) []string {
+		ids := []string{}
+		for {
+			out := &structs.NetworkListResponse{}
+			if err := s.ListNetworks(&structs.NetworkListRequest{QueryOptions: opts}, out); err != nil {
+				t.Fatalf("s.ListNetworks() failed: %v", err)
+			}
+			if len(out.Items) == 0 {
+				t.Fatalf("s.ListNetworks() failed, expected no empty pages")
+			}
+			if opts.PerPage > 0 && len(out.Items) > opts.PerPage {
+				t.Fatalf("s.ListNetworks() failed, expected at most %d items, have %d", opts.PerPage, len(out.Items))
+			}
+			for _, n := range out.Items {
+				ids = append(ids, n.ID)
+			}
+			if out.NextToken == "" {
+				return ids
+			}
+			opts.NextToken = out.NextToken
+		}
+	}
+
+	filter := `Name != "n4"`
+
+	if ids, expected := list(structs.QueryOptions{PerPage: 2, Filter: filter}), []string{"n0", "n2", "n6", "n8"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected %v, have %v", expected, ids)
+	}
+	if ids, expected := list(structs.QueryOptions{PerPage: 3, Filter: filter, Sort: "name", Order: "desc"}), []string{"n8", "n6", "n2", "n0"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected %v, have %v", expected, ids)
+	}
+}
+
+// interfaceReadsRepository is a repository which records the interfaces read.
+type interfaceReadsRepository struct {
+	*inmem.StateRepository
+
+	read map[string]bool
+	all  bool
+}
+
+func (r *interfaceReadsRepository) InterfaceByID(ctx context.Context, id string) (*structs.Interface, error) {
+	r.read[id] = true
+	return r.StateRepository.InterfaceByID(ctx, id)
+}
+
+func (r *interfaceReadsRepository) Interfaces(ctx context.Context) ([]*structs.Interface, error) {
+	r.all = true
+	return r.StateRepository.Interfaces(ctx)
+}
+
+func TestListConnectionsPage(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	for i := 1; i <= 6; i++ {
+		testNode(t, repo, "net", fmt.Sprintf("n%d", i), fmt.Sprintf("10.0.0.%d/24", i), false)
+	}
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	connections, err := repo.Connections(ctx)
+	if err != nil {
+		t.Fatalf("repo.Connections() failed: %v", err)
+	}
+
+	// Report a recent handshake for every connection
+	interfaces, err := repo.Interfaces(ctx)
+	if err != nil {
+		t.Fatalf("repo.Interfaces() failed: %v", err)
+	}
+	for _, iface := range interfaces {
+		for _, c := range connections {
+			if c.ConnectsInterface(iface.ID) {
+				iface.PeerStats = append(iface.PeerStats, &structs.PeerStats{ConnectionID: c.ID, LastHandshake: time.Now()})
+			}
+		}
+		if err := repo.UpsertInterface(ctx, iface); err != nil {
+			t.Fatalf("repo.UpsertInterface() failed: %v", err)
+		}
+	}
+
+	wrapped := &interfaceReadsRepository{StateRepository: repo, read: map[string]bool{}}
+
+	s := NewConnectionService(testConfig(), testLogger(t), wrapped, nil, nil)
+
+	out := &structs.ConnectionListResponse{}
+	if err := s.ListConnections(&structs.ConnectionListRequest{QueryOptions: structs.QueryOptions{PerPage: 1}}, out); err != nil {
+		t.Fatalf("s.ListConnections() failed: %v", err)
+	}
+
+	if len(out.Items) != 1 || out.NextToken == "" {
+		t.Fatalf("expected a page with 1 connection, followed by another page")
+	}
+	if out.Items[0].State != structs.ConnectionStateEstablished {
+		t.Fatalf("expected connection state %s, have %s", structs.ConnectionStateEstablished, out.Items[0].State)
+	}
+
+	// Only the interfaces of the connection in the page, and of the
+	// first connection of the next page, are expected to be read.
+	if wrapped.all || len(wrapped.read) > 4 {
+		t.Fatalf("expected only the interfaces of the connections in the page to be read, have %d", len(wrapped.read))
+	}
+}
@@ -0,0 +1,367 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+	uuid "github.com/seashell/drago/pkg/uuid"
+	wgquick "github.com/seashell/drago/pkg/wgquick"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+const (
+	// defaultExternalPeerKeepalive is the persistent keepalive interval, in seconds,
+	// set on connections to external peers, which are usually behind a NAT.
+	defaultExternalPeerKeepalive = 25
+)
+
+// PeerService is used for managing external peers, i.e. devices which do not
+// run the Drago agent. External peers are backed by interfaces without a node,
+// so they can be connected to other interfaces like any interface, and are
+// subject to the same ACL rules.
+type PeerService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	auditor     *Auditor
+	authHandler auth.AuthorizationHandler
+}
+
+// NewPeerService ...
+func NewPeerService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, authHandler auth.AuthorizationHandler) *PeerService {
+	return &PeerService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		auditor:     auditor,
+		authHandler: authHandler,
+	}
+}
+
+// ListPeers retrieves all external peers in the repository
+func (s *PeerService) ListPeers(args *structs.InterfaceListRequest, out *structs.InterfaceListResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceList); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	out.Items = nil
+
+	err := listPages(&out.Items, args.QueryOptions, func(opts state.ListOptions) (string, error) {
+
+		var err error
+		var next string
+		var interfaces []*structs.Interface
+
+		if args.NetworkID != "" {
+			if interfaces, err = s.state.InterfacesByNetworkID(ctx, args.NetworkID); err != nil {
+				return "", structs.ErrInternal
+			}
+		} else {
+			if interfaces, next, err = s.state.InterfacesPage(ctx, opts); err != nil {
+				return "", structs.ErrInternal
+			}
+		}
+
+		for _, i := range interfaces {
+			if i.External && inNamespace(i.Namespace, ns) {
+				out.Items = append(out.Items, i.Stub())
+			}
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if out.NextToken, err = paginate(&out.Items, args.QueryOptions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreatePeer creates an external peer in a network, generating its key pair and
+// allocating its address, and optionally connects it to existing interfaces.
+func (s *PeerService) CreatePeer(args *structs.ExternalPeerCreateRequest, out *structs.ExternalPeerCreateResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Peer.CreatePeer", args.AuthToken, ns, "interface", auditID(&out.InterfaceID))(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+		if len(args.ConnectTo) > 0 {
+			if err := s.authHandler.Authorize(ctx, args.AuthToken, "connection", namespacePath(ns, ""), ConnectionWrite); err != nil {
+				return structs.ErrPermissionDenied
+			}
+		}
+	}
+
+	if args.NetworkID == "" {
+		return structs.NewInvalidInputError("Missing NetworkID")
+	}
+
+	network, err := networkInNamespace(ctx, s.state, ns, args.NetworkID)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the interfaces to connect to exist before creating anything
+	targets := []string{}
+	for _, id := range args.ConnectTo {
+		target, err := s.state.InterfaceByID(ctx, id)
+		if err != nil {
+			return structs.NewInvalidInputError(fmt.Sprintf("Interface %s does not exist", id))
+		}
+		if target.NetworkID != network.ID {
+			return structs.NewInvalidInputError(fmt.Sprintf("Interface %s is not in network %s", id, network.ID))
+		}
+		targets = append(targets, target.ID)
+	}
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	privateKey, publicKey := key.String(), key.PublicKey().String()
+
+	now := time.Now()
+
+	i := &structs.Interface{
+		ID:          uuid.Generate(),
+		Namespace:   network.Namespace,
+		NetworkID:   network.ID,
+		Address:     args.Address,
+		PublicKey:   &publicKey,
+		PrivateKey:  &privateKey,
+		DNS:         args.DNS,
+		Endpoint:    args.Endpoint,
+		External:    true,
+		Peers:       []*structs.Peer{},
+		Connections: []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if args.Name != "" {
+		i.Name = &args.Name
+	}
+
+	keepalive := defaultExternalPeerKeepalive
+	if args.PersistentKeepalive != nil {
+		keepalive = *args.PersistentKeepalive
+	}
+
+	// The peer and its connections are created in the same transaction,
+	// so that no peer is left behind if any of the connections fails.
+	err = retryOnConflict(func() error {
+		return withTransaction(ctx, s.state, func(ctx context.Context) error {
+
+			network, err := s.state.NetworkByID(ctx, args.NetworkID)
+			if err != nil {
+				return structs.ErrNotFound
+			}
+
+			// Start from a copy, so that an address assigned
+			// in a conflicting attempt is not reused on retries.
+			peer := *i
+
+			if err := assignInterfaceAddress(ctx, s.state, network, &peer); err != nil {
+				return err
+			}
+
+			network.UpsertInterface(peer.ID)
+			if err := s.state.UpsertNetwork(ctx, network); err != nil {
+				return structs.NewInternalError(err.Error())
+			}
+
+			if err := s.state.UpsertInterface(ctx, &peer); err != nil {
+				return err
+			}
+
+			for _, id := range targets {
+
+				target, err := s.state.InterfaceByID(ctx, id)
+				if err != nil {
+					return structs.NewInvalidInputError(fmt.Sprintf("Interface %s does not exist", id))
+				}
+
+				// Traffic to the peer's address is routed through the peer, and
+				// the peer routes the address of the interface it connects to.
+				c := newManagedConnection(target, hostPrefixes(&peer), &peer, hostPrefixes(target), &keepalive)
+				c.ID = uuid.Generate()
+				c.Managed = false
+				c.CreatedAt = now
+
+				if err := checkAllowedIPs(ctx, s.state, s.logger, c); err != nil {
+					return err
+				}
+				if err := upsertConnection(ctx, s.state, c); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	out.InterfaceID = i.ID
+
+	return nil
+}
+
+// GetConfig returns the configuration of an external peer in the wg-quick format.
+// The configuration is rendered from the current connections of the peer, so it
+// has to be exported again whenever they change. Since it contains the private
+// key of the peer, it requires write access to interfaces.
+func (s *PeerService) GetConfig(args *structs.ExternalPeerConfigRequest, out *structs.ExternalPeerConfigResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, args.InterfaceID), InterfaceWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	iface, err := interfaceInNamespace(ctx, s.state, ns, args.InterfaceID)
+	if err != nil || !iface.External {
+		return structs.ErrNotFound
+	}
+
+	config, err := externalPeerConfig(ctx, s.state, iface)
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	out.Config = string(config.Marshal())
+
+	return nil
+}
+
+// DeletePeer deletes external peers, along with their connections
+func (s *PeerService) DeletePeer(args *structs.InterfaceDeleteRequest, out *structs.GenericResponse) (err error) {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	defer s.auditor.Begin(ctx, "Peer.DeletePeer", args.AuthToken, ns, "interface", func() []string { return args.InterfaceIDs })(&err)
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "interface", namespacePath(ns, ""), InterfaceWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	for _, id := range args.InterfaceIDs {
+
+		iface, err := interfaceInNamespace(ctx, s.state, ns, id)
+		if err != nil || !iface.External {
+			continue
+		}
+
+		if err := deleteInterface(ctx, s.state, iface); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// externalPeerConfig builds the wg-quick configuration of an external interface. Each
+// connection results in a peer, whose endpoint is the advertised address of its node.
+// Peers which are not configured yet, or whose node is drained, are left out, in the
+// same way as in the configurations sent to nodes.
+func externalPeerConfig(ctx context.Context, repo state.Repository, iface *structs.Interface) (*wgquick.Config, error) {
+
+	config := &wgquick.Config{
+		Interface: wgquick.Interface{
+			DNS: iface.DNS,
+		},
+	}
+
+	if iface.PrivateKey != nil {
+		config.Interface.PrivateKey = *iface.PrivateKey
+	}
+	if iface.Address != nil {
+		config.Interface.Address = []string{*iface.Address}
+	}
+
+	connections, err := repo.ConnectionsByInterfaceID(ctx, iface.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(connections, func(i, j int) bool { return connections[i].ID < connections[j].ID })
+
+	for _, conn := range connections {
+
+		ifaceSettings := conn.PeerSettingsByInterfaceID(iface.ID)
+		peerSettings := conn.OtherPeerSettingsByInterfaceID(iface.ID)
+		if ifaceSettings == nil || peerSettings == nil {
+			continue
+		}
+
+		peerIface, err := repo.InterfaceByID(ctx, peerSettings.InterfaceID)
+		if err != nil || peerIface.PublicKey == nil {
+			continue
+		}
+
+		peer := &wgquick.Peer{
+			PublicKey:  *peerIface.PublicKey,
+			AllowedIPs: []string{},
+		}
+
+		if !peerIface.External {
+			peerNode, err := repo.NodeByID(ctx, peerIface.NodeID)
+			if err != nil || peerNode.Drain {
+				continue
+			}
+			if peerNode.AdvertiseAddress != "" && peerIface.ListenPort != nil {
+				peer.Endpoint = net.JoinHostPort(peerNode.AdvertiseAddress, strconv.Itoa(*peerIface.ListenPort))
+			}
+		} else if peerIface.Endpoint != nil {
+			peer.Endpoint = *peerIface.Endpoint
+		}
+
+		if conn.PresharedKey != nil {
+			peer.PresharedKey = *conn.PresharedKey
+		}
+		if conn.PersistentKeepalive != nil {
+			peer.PersistentKeepalive = *conn.PersistentKeepalive
+		}
+		if ifaceSettings.RoutingRules != nil {
+			peer.AllowedIPs = ifaceSettings.RoutingRules.AllowedIPs
+		}
+
+		config.Peers = append(config.Peers, peer)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,66 @@
+package drago
+
+import (
+	"context"
+	"testing"
+
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestCreatePeerConnectionFailure(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+
+	// Routing the same address through both interfaces is a conflict
+	testNode(t, repo, "net", "b", "10.0.0.1/24", false)
+
+	s := NewPeerService(testConfig(), testLogger(t), repo, nil, nil)
+
+	args := &structs.ExternalPeerCreateRequest{NetworkID: "net", Name: "laptop", ConnectTo: []string{"a-iface", "b-iface"}}
+	for i := 0; i < 2; i++ {
+		if err := s.CreatePeer(args, &structs.ExternalPeerCreateResponse{}); err == nil {
+			t.Fatalf("expected s.CreatePeer() to fail")
+		}
+	}
+
+	// Nothing is left behind by failed attempts
+	interfaces, err := repo.InterfacesByNetworkID(ctx, "net")
+	if err != nil {
+		t.Fatalf("repo.InterfacesByNetworkID() failed: %v", err)
+	}
+	if len(interfaces) != 2 {
+		t.Fatalf("expected 2 interfaces, got %d", len(interfaces))
+	}
+	network, err := repo.NetworkByID(ctx, "net")
+	if err != nil {
+		t.Fatalf("repo.NetworkByID() failed: %v", err)
+	}
+	if len(network.Interfaces) != 2 {
+		t.Fatalf("expected 2 interfaces in network, got %d", len(network.Interfaces))
+	}
+	connections, err := repo.Connections(ctx)
+	if err != nil {
+		t.Fatalf("repo.Connections() failed: %v", err)
+	}
+	if len(connections) != 0 {
+		t.Fatalf("expected no connections, got %d", len(connections))
+	}
+
+	args.ConnectTo = []string{"a-iface"}
+	out := &structs.ExternalPeerCreateResponse{}
+	if err := s.CreatePeer(args, out); err != nil {
+		t.Fatalf("s.CreatePeer() failed: %v", err)
+	}
+
+	connections, err = repo.ConnectionsByInterfaceID(ctx, out.InterfaceID)
+	if err != nil {
+		t.Fatalf("repo.ConnectionsByInterfaceID() failed: %v", err)
+	}
+	if len(connections) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(connections))
+	}
+}
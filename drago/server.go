@@ -3,10 +3,13 @@ package drago
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	prometheus "github.com/prometheus/client_golang/prometheus"
 	auth "github.com/seashell/drago/drago/auth"
+	events "github.com/seashell/drago/drago/events"
 	state "github.com/seashell/drago/drago/state"
 	"github.com/seashell/drago/drago/state/etcd"
 	structs "github.com/seashell/drago/drago/structs"
@@ -17,6 +20,14 @@ import (
 	"github.com/seashell/drago/pkg/uuid"
 )
 
+const (
+	// serverHeartbeatInterval is the interval at which servers refresh their
+	// entry in the state, and serverTTL is how long entries are kept without
+	// being refreshed, after which servers are no longer advertised to nodes.
+	serverHeartbeatInterval = 10 * time.Second
+	serverTTL               = 3 * serverHeartbeatInterval
+)
+
 var (
 	// AnonymousACLToken is used when no secret is provided,
 	// and the request is made anonymously.
@@ -37,6 +48,7 @@ type Server struct {
 	rpcClient   *rpc.Client
 	state       state.Repository
 	authHandler auth.AuthorizationHandler
+	eventBroker *events.Broker
 
 	services struct {
 		ACL         *ACLService
@@ -45,6 +57,13 @@ type Server struct {
 		Interfaces  *InterfaceService
 		Connections *ConnectionService
 		Status      *StatusService
+		Events      *EventService
+		System      *SystemService
+		JoinTokens  *JoinTokenService
+		Audit       *AuditService
+		Operator    *OperatorService
+		Peers       *PeerService
+		Namespaces  *NamespaceService
 	}
 
 	shutdown     bool
@@ -82,9 +101,121 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	go s.runGarbageCollector()
+
+	go s.runServerHeartbeat()
+
 	return s, nil
 }
 
+// runServerHeartbeat periodically records the RPC address of the server in the
+// state shared by all servers until shutdown, so that client nodes connected
+// to any of them learn about the others.
+func (s *Server) runServerHeartbeat() {
+
+	if s.config.RPCAdvertiseAddr == "" {
+		s.logger.Warnf("no RPC advertise address, not advertising server to nodes")
+		return
+	}
+
+	for {
+		if err := heartbeatServer(context.TODO(), s.state, s.config.RPCAdvertiseAddr, time.Now()); err != nil {
+			s.logger.Warnf("error refreshing server entry: %v", err)
+		}
+
+		select {
+		case <-time.After(serverHeartbeatInterval):
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
+// heartbeatServer refreshes the entry of the server with the given address,
+// and removes the entries of servers which have not refreshed theirs in time.
+func heartbeatServer(ctx context.Context, repo state.Repository, address string, now time.Time) error {
+
+	if err := repo.UpsertServer(ctx, &structs.Server{Address: address, UpdatedAt: now}); err != nil {
+		return err
+	}
+
+	servers, err := repo.Servers(ctx)
+	if err != nil {
+		return err
+	}
+
+	stale := []string{}
+	for _, server := range servers {
+		if now.Sub(server.UpdatedAt) > serverTTL {
+			stale = append(stale, server.Address)
+		}
+	}
+
+	return repo.DeleteServers(ctx, stale)
+}
+
+// knownServers returns the RPC addresses of the servers which refreshed their
+// entry in the state recently, along with the address passed, if not empty.
+func knownServers(ctx context.Context, repo state.Repository, self string) ([]string, error) {
+
+	servers, err := repo.Servers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{}
+	if self != "" {
+		addresses = append(addresses, self)
+	}
+
+	for _, server := range servers {
+		if server.Address != self && time.Since(server.UpdatedAt) <= serverTTL {
+			addresses = append(addresses, server.Address)
+		}
+	}
+
+	sort.Strings(addresses)
+
+	return addresses, nil
+}
+
+// runGarbageCollector periodically removes nodes which have been down for
+// longer than the configured threshold, as well as expired audit entries,
+// until shutdown.
+func (s *Server) runGarbageCollector() {
+
+	if s.config.HostGCInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.HostGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ids, err := s.services.System.runGarbageCollection(context.TODO())
+			if err != nil {
+				s.logger.Warnf("error garbage collecting nodes: %v", err)
+			}
+			if len(ids) > 0 {
+				s.logger.Infof("garbage collected %d nodes", len(ids))
+			}
+			if s.config.AuditRetention > 0 {
+				n, err := garbageCollectAuditEntries(context.TODO(), s.state, s.config.AuditRetention)
+				if err != nil {
+					s.logger.Warnf("error garbage collecting audit entries: %v", err)
+				}
+				if n > 0 {
+					s.logger.Debugf("garbage collected %d audit entries", n)
+				}
+			}
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
 // Stats is used to return statistics for the server
 func (s *Server) Stats() map[string]map[string]string {
 
@@ -137,7 +268,10 @@ func (s *Server) setupApplication() error {
 	if err != nil {
 		return err
 	}
-	s.state = state
+
+	// Wrap the repository so that events are published whenever the state changes
+	s.eventBroker = events.NewBroker(events.DefaultBufferSize)
+	s.state = events.NewRepository(state, s.eventBroker)
 
 	ctx := context.TODO()
 
@@ -162,24 +296,40 @@ func (s *Server) setupApplication() error {
 		}
 	}
 
-	s.authHandler = auth.NewAuthorizationHandler(
+	s.authHandler = &instrumentedAuthHandler{auth.NewAuthorizationHandler(
 		s.config.ACL.Model,
 		s.secretResolver(),
 		s.policyResolver(),
-	)
+	)}
+
+	if err := prometheus.Register(newNodeCollector(s.state, s.logger)); err != nil {
+		s.logger.Warnf("could not register node metrics: %v", err)
+	}
+
+	auditor, err := NewAuditor(s.config, s.logger, s.state)
+	if err != nil {
+		return fmt.Errorf("failed to create auditor: %v", err)
+	}
 
-	nodeService, err := NewNodeService(s.config, s.logger, s.state, s.authHandler)
+	nodeService, err := NewNodeService(s.config, s.logger, s.state, auditor, s.eventBroker, s.authHandler)
 	if err != nil {
 		return fmt.Errorf("failed to create node service: %v", err)
 	}
 
 	s.services.Nodes = nodeService
-	s.services.ACL = NewACLService(s.config, s.logger, s.state, s.authHandler)
-	s.services.Networks = NewNetworkService(s.config, s.logger, s.state, s.authHandler)
-	s.services.Interfaces = NewInterfaceService(s.config, s.logger, s.state, s.authHandler)
-	s.services.Connections = NewConnectionService(s.config, s.logger, s.state, s.authHandler)
+	s.services.ACL = NewACLService(s.config, s.logger, s.state, auditor, s.authHandler)
+	s.services.Networks = NewNetworkService(s.config, s.logger, s.state, auditor, s.authHandler)
+	s.services.Interfaces = NewInterfaceService(s.config, s.logger, s.state, auditor, s.authHandler)
+	s.services.Connections = NewConnectionService(s.config, s.logger, s.state, auditor, s.authHandler)
 
 	s.services.Status = NewStatusService(s.config, s.state, s.authHandler)
+	s.services.Events = NewEventService(s.config, s.logger, s.eventBroker, s.authHandler)
+	s.services.System = NewSystemService(s.config, s.logger, s.state, auditor, nodeService, s.authHandler)
+	s.services.JoinTokens = NewJoinTokenService(s.config, s.logger, s.state, auditor, s.authHandler)
+	s.services.Audit = NewAuditService(s.config, s.logger, s.state, s.authHandler)
+	s.services.Operator = NewOperatorService(s.config, s.logger, s.state, auditor, nodeService, s.authHandler)
+	s.services.Peers = NewPeerService(s.config, s.logger, s.state, auditor, s.authHandler)
+	s.services.Namespaces = NewNamespaceService(s.config, s.logger, s.state, auditor, s.authHandler)
 
 	return nil
 }
@@ -200,6 +350,11 @@ func (s *Server) setupACLModel() error {
 		Alias("read", ACLPolicyRead, ACLPolicyList).
 		Alias("write", ACLPolicyWrite, ACLPolicyRead, ACLPolicyList)
 
+	model.Resource("namespace").
+		Capabilities(NamespaceWrite, NamespaceRead, NamespaceList).
+		Alias("read", NamespaceRead, NamespaceList).
+		Alias("write", NamespaceWrite, NamespaceRead, NamespaceList)
+
 	model.Resource("network").
 		Capabilities(NetworkWrite, NetworkRead, NetworkList).
 		Alias("read", NetworkRead, NetworkList).
@@ -220,6 +375,15 @@ func (s *Server) setupACLModel() error {
 		Alias("read", ConnectionRead, ConnectionList).
 		Alias("write", ConnectionWrite, ConnectionRead, ConnectionList)
 
+	model.Resource("audit").
+		Capabilities(AuditRead, AuditList).
+		Alias("read", AuditRead, AuditList)
+
+	model.Resource("operator").
+		Capabilities(OperatorWrite, OperatorRead).
+		Alias("read", OperatorRead).
+		Alias("write", OperatorWrite, OperatorRead)
+
 	s.config.ACL.Model = model
 
 	return nil
@@ -279,7 +443,11 @@ func (s *Server) policyResolver() acl.PolicyResolverFunc {
 
 		res := auth.NewPolicy(pol.Name, []acl.Rule{})
 		for _, r := range pol.Rules {
-			res.AddRule(auth.NewRule(r.Resource, r.Path, r.Capabilities))
+			path := r.Path
+			if namespacedResources[r.Resource] {
+				path = namespacePath(structs.NamespaceOrDefault(r.Namespace), r.Path)
+			}
+			res.AddRule(auth.NewRule(r.Resource, path, r.Capabilities))
 		}
 		return res, nil
 	}
@@ -297,7 +465,23 @@ func (s *Server) setupRPCServer() error {
 			"Connection": s.services.Connections,
 			"Network":    s.services.Networks,
 			"Status":     s.services.Status,
+			"Event":      s.services.Events,
+			"System":     s.services.System,
+			"JoinToken":  s.services.JoinTokens,
+			"Audit":      s.services.Audit,
+			"Operator":   s.services.Operator,
+			"Peer":       s.services.Peers,
+			"Namespace":  s.services.Namespaces,
 		},
+		Observer: observeRPC,
+	}
+
+	if s.config.TLSConfig != nil {
+		tlsConfig, err := s.config.TLSConfig.IncomingTLSConfig()
+		if err != nil {
+			return fmt.Errorf("invalid tls config: %v", err)
+		}
+		config.TLSConfig = tlsConfig
 	}
 
 	rpcServer, err := rpc.NewServer(config)
@@ -4,32 +4,39 @@ import (
 	"context"
 	"errors"
 
+	"github.com/seashell/drago/drago/state"
 	"github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // ACLPolicies :
 func (r *StateRepository) ACLPolicies(ctx context.Context) ([]*structs.ACLPolicy, error) {
+	items, _, err := r.ACLPoliciesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ACLPoliciesPage :
+func (r *StateRepository) ACLPoliciesPage(ctx context.Context, opts state.ListOptions) ([]*structs.ACLPolicy, string, error) {
 
 	prefix := resourceKey(resourceTypeACLPolicy, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.ACLPolicy{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		policy := &structs.ACLPolicy{}
 		err := decodeValue(el.Value, policy)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		policy.ModifyIndex = el.ModifyIndex
 		items = append(items, policy)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // ACLPolicyByName :
@@ -37,21 +44,22 @@ func (r *StateRepository) ACLPolicyByName(ctx context.Context, name string) (*st
 
 	key := resourceKey(resourceTypeACLPolicy, name)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	policy := &structs.ACLPolicy{}
 
-	err = decodeValue(res.Kvs[0].Value, policy)
+	err = decodeValue(res.Value, policy)
 	if err != nil {
 		return nil, err
 	}
+	policy.ModifyIndex = res.ModifyIndex
 
 	return policy, nil
 }
@@ -60,10 +68,13 @@ func (r *StateRepository) ACLPolicyByName(ctx context.Context, name string) (*st
 func (r *StateRepository) UpsertACLPolicy(ctx context.Context, p *structs.ACLPolicy) error {
 	key := resourceKey(resourceTypeACLPolicy, p.Name)
 
-	_, err := r.client.Put(ctx, key, encodeValue(p))
+	index, err := r.put(ctx, key, p, p.ModifyIndex)
 	if err != nil {
 		return err
 	}
+	if index != 0 {
+		p.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -71,7 +82,7 @@ func (r *StateRepository) UpsertACLPolicy(ctx context.Context, p *structs.ACLPol
 func (r *StateRepository) DeleteACLPolicies(ctx context.Context, names []string) error {
 	for _, name := range names {
 		key := resourceKey(resourceTypeACLPolicy, name)
-		_, err := r.client.Delete(ctx, key)
+		err := r.delete(ctx, key)
 		if err != nil {
 			return err
 		}
@@ -13,18 +13,18 @@ func (r *StateRepository) ACLState(ctx context.Context) (*structs.ACLState, erro
 
 	key := aclStateKey()
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	state := &structs.ACLState{}
 
-	err = decodeValue(res.Kvs[0].Value, state)
+	err = decodeValue(res.Value, state)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +37,7 @@ func (r *StateRepository) ACLSetState(ctx context.Context, s *structs.ACLState)
 
 	key := aclStateKey()
 
-	_, err := r.client.Put(ctx, key, encodeValue(s))
+	_, err := r.put(ctx, key, s, 0)
 	if err != nil {
 		return err
 	}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/seashell/drago/drago/state"
 	"github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 const (
@@ -14,26 +14,33 @@ const (
 
 // ACLTokens :
 func (r *StateRepository) ACLTokens(ctx context.Context) ([]*structs.ACLToken, error) {
+	items, _, err := r.ACLTokensPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ACLTokensPage :
+func (r *StateRepository) ACLTokensPage(ctx context.Context, opts state.ListOptions) ([]*structs.ACLToken, string, error) {
 
 	prefix := resourceKey(resourceTypeToken, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.ACLToken{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		token := &structs.ACLToken{}
 		err := decodeValue(el.Value, token)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		token.ModifyIndex = el.ModifyIndex
 		items = append(items, token)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // ACLTokenByID :
@@ -41,21 +48,22 @@ func (r *StateRepository) ACLTokenByID(ctx context.Context, id string) (*structs
 
 	key := resourceKey(resourceTypeToken, id)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	token := &structs.ACLToken{}
 
-	err = decodeValue(res.Kvs[0].Value, token)
+	err = decodeValue(res.Value, token)
 	if err != nil {
 		return nil, err
 	}
+	token.ModifyIndex = res.ModifyIndex
 
 	return token, nil
 }
@@ -65,12 +73,12 @@ func (r *StateRepository) ACLTokenBySecret(ctx context.Context, secret string) (
 
 	prefix := resourceKey(resourceTypeToken, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix())
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 
 		token := &structs.ACLToken{}
 
@@ -80,6 +88,7 @@ func (r *StateRepository) ACLTokenBySecret(ctx context.Context, secret string) (
 		}
 
 		if token.Secret == secret {
+			token.ModifyIndex = el.ModifyIndex
 			return token, nil
 		}
 	}
@@ -90,10 +99,13 @@ func (r *StateRepository) ACLTokenBySecret(ctx context.Context, secret string) (
 // UpsertACLToken :
 func (r *StateRepository) UpsertACLToken(ctx context.Context, t *structs.ACLToken) error {
 	key := resourceKey(resourceTypeToken, t.ID)
-	_, err := r.client.Put(ctx, key, encodeValue(t))
+	index, err := r.put(ctx, key, t, t.ModifyIndex)
 	if err != nil {
 		return err
 	}
+	if index != 0 {
+		t.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -101,7 +113,7 @@ func (r *StateRepository) UpsertACLToken(ctx context.Context, t *structs.ACLToke
 func (r *StateRepository) DeleteACLTokens(ctx context.Context, ids []string) error {
 	for _, id := range ids {
 		key := resourceKey(resourceTypeToken, id)
-		_, err := r.client.Delete(ctx, key)
+		err := r.delete(ctx, key)
 		if err != nil {
 			return err
 		}
@@ -5,32 +5,39 @@ import (
 	"errors"
 	"fmt"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // Connections :
 func (r *StateRepository) Connections(ctx context.Context) ([]*structs.Connection, error) {
+	items, _, err := r.ConnectionsPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ConnectionsPage :
+func (r *StateRepository) ConnectionsPage(ctx context.Context, opts state.ListOptions) ([]*structs.Connection, string, error) {
 
 	prefix := resourceKey(resourceTypeConnection, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.Connection{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		conn := &structs.Connection{}
 		err := decodeValue(el.Value, conn)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		conn.ModifyIndex = el.ModifyIndex
 		items = append(items, conn)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // ConnectionByID :
@@ -38,21 +45,22 @@ func (r *StateRepository) ConnectionByID(ctx context.Context, id string) (*struc
 
 	key := resourceKey(resourceTypeConnection, id)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	network := &structs.Connection{}
 
-	err = decodeValue(res.Kvs[0].Value, network)
+	err = decodeValue(res.Value, network)
 	if err != nil {
 		return nil, err
 	}
+	network.ModifyIndex = res.ModifyIndex
 
 	return network, nil
 }
@@ -62,19 +70,20 @@ func (r *StateRepository) ConnectionsByNetworkID(ctx context.Context, id string)
 
 	prefix := resourceKey(resourceTypeConnection, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*structs.Connection{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		conn := &structs.Connection{}
 		if err := decodeValue(el.Value, conn); err != nil {
 			return nil, err
 		}
 		if conn.NetworkID == id {
+			conn.ModifyIndex = el.ModifyIndex
 			items = append(items, conn)
 		}
 	}
@@ -87,20 +96,21 @@ func (r *StateRepository) ConnectionsByNodeID(ctx context.Context, id string) ([
 
 	prefix := resourceKey(resourceTypeConnection, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*structs.Connection{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		conn := &structs.Connection{}
 		if err := decodeValue(el.Value, conn); err != nil {
 			return nil, err
 		}
 
 		if conn.PeerSettings[0].NodeID == id || conn.PeerSettings[1].NodeID == id {
+			conn.ModifyIndex = el.ModifyIndex
 			items = append(items, conn)
 		}
 	}
@@ -113,20 +123,21 @@ func (r *StateRepository) ConnectionsByInterfaceID(ctx context.Context, id strin
 
 	prefix := resourceKey(resourceTypeConnection, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*structs.Connection{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		conn := &structs.Connection{}
 		if err := decodeValue(el.Value, conn); err != nil {
 			return nil, err
 		}
 
 		if conn.ConnectsInterface(id) {
+			conn.ModifyIndex = el.ModifyIndex
 			items = append(items, conn)
 		}
 	}
@@ -139,18 +150,19 @@ func (r *StateRepository) ConnectionByInterfaceIDs(ctx context.Context, a, b str
 
 	prefix := resourceKey(resourceTypeConnection, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		conn := &structs.Connection{}
 		if err := decodeValue(el.Value, conn); err != nil {
 			return nil, err
 		}
 
 		if conn.ConnectsInterfaces(a, b) {
+			conn.ModifyIndex = el.ModifyIndex
 			return conn, nil
 		}
 	}
@@ -162,10 +174,13 @@ func (r *StateRepository) ConnectionByInterfaceIDs(ctx context.Context, a, b str
 func (r *StateRepository) UpsertConnection(ctx context.Context, n *structs.Connection) error {
 	key := resourceKey(resourceTypeConnection, n.ID)
 
-	_, err := r.client.Put(ctx, key, encodeValue(n))
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
 	if err != nil {
 		return err
 	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -174,7 +189,7 @@ func (r *StateRepository) DeleteConnections(ctx context.Context, ids []string) e
 
 	for _, id := range ids {
 		key := resourceKey(resourceTypeConnection, id)
-		_, err := r.client.Delete(ctx, key)
+		err := r.delete(ctx, key)
 		if err != nil {
 			return err
 		}
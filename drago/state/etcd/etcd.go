@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/seashell/drago/drago/state"
@@ -21,12 +23,13 @@ const (
 
 	resourceTypeACLPolicy  = "policy"
 	resourceTypeACLToken   = "token"
+	resourceTypeNamespace  = "namespace"
 	resourceTypeNetwork    = "network"
 	resourceTypeNode       = "node"
 	resourceTypeInterface  = "interface"
 	resourceTypeConnection = "connection"
 
-	transactionContextKey = "etcdtxn"
+	defaultMaxTxnOps = 4096
 )
 
 type Config struct {
@@ -68,18 +71,311 @@ func (r *StateRepository) Name() string {
 	return "etcd"
 }
 
+// Transaction returns a transaction in which writes are staged until committed.
+// All writes are then applied atomically, provided that none of the resources
+// written was modified since it was read, as indicated by its ModifyIndex.
+func (r *StateRepository) Transaction(ctx context.Context) state.Transaction {
+	return &transaction{
+		ctx:    ctx,
+		client: r.client,
+		staged: map[string]*stagedWrite{},
+	}
+}
+
 type transaction struct {
-	txn clientv3.Txn
+	ctx    context.Context
+	client *clientv3.Client
+
+	// Writes are staged by key, since etcd does not allow a key
+	// to be written more than once within a transaction. The keys
+	// slice keeps track of the order in which keys were staged.
+	keys   []string
+	staged map[string]*stagedWrite
+	lock   sync.Mutex
 }
 
-func (t transaction) Commit() (interface{}, error) {
-	out, err := t.txn.Commit()
-	return out, err
+// stagedWrite is a write staged in a transaction. Deletes have a nil value.
+// Revision is the modification revision expected for the key when the
+// transaction is committed, or zero if it should not be checked.
+type stagedWrite struct {
+	value    *string
+	revision int64
 }
 
-// Transaction
-func (r *StateRepository) Transaction(ctx context.Context) state.Transaction {
-	return transaction{r.client.Txn(ctx)}
+// Commit applies all staged writes atomically. It returns state.ErrConflict
+// if any of the keys was modified after being read.
+func (t *transaction) Commit() (interface{}, error) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.keys) == 0 {
+		return nil, nil
+	}
+
+	cmps := []clientv3.Cmp{}
+	ops := []clientv3.Op{}
+
+	for _, key := range t.keys {
+		w := t.staged[key]
+		if w.revision != 0 {
+			cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", w.revision))
+		}
+		if w.value == nil {
+			ops = append(ops, clientv3.OpDelete(key))
+		} else {
+			ops = append(ops, clientv3.OpPut(key, *w.value))
+		}
+	}
+
+	res, err := t.client.Txn(t.ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Succeeded {
+		return nil, state.ErrConflict
+	}
+
+	t.keys, t.staged = nil, map[string]*stagedWrite{}
+
+	return res, nil
+}
+
+func (t *transaction) stage(key string, value *string, revision int64) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Keep the revision from the first time the key was staged,
+	// since subsequent reads within the transaction return it.
+	if w, ok := t.staged[key]; ok {
+		w.value = value
+		return
+	}
+
+	t.keys = append(t.keys, key)
+	t.staged[key] = &stagedWrite{value: value, revision: revision}
+}
+
+func (t *transaction) get(key string) (*stagedWrite, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	w, ok := t.staged[key]
+	return w, ok
+}
+
+func (t *transaction) keysWithPrefix(prefix string) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	keys := []string{}
+	for _, key := range t.keys {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func transactionFromContext(ctx context.Context) *transaction {
+
+	txn, ok := state.TransactionFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Look for the transaction of this repository
+	// within transactions wrapping it, if any.
+	for {
+		switch t := txn.(type) {
+		case *transaction:
+			return t
+		case state.TransactionWrapper:
+			txn = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// kv is a value read from the repository, along with its modification revision.
+type kv struct {
+	Key         string
+	Value       []byte
+	ModifyIndex uint64
+}
+
+// get returns the value stored under a key, or nil if the key does not exist,
+// taking into account the writes staged in the transaction carried by the context.
+func (r *StateRepository) get(ctx context.Context, key string) (*kv, error) {
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		if w, ok := txn.get(key); ok {
+			if w.value == nil {
+				return nil, nil
+			}
+			return &kv{Value: []byte(*w.value), ModifyIndex: uint64(w.revision)}, nil
+		}
+	}
+
+	res, err := r.client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Count == 0 {
+		return nil, nil
+	}
+
+	return &kv{Value: res.Kvs[0].Value, ModifyIndex: uint64(res.Kvs[0].ModRevision)}, nil
+}
+
+// list returns the values stored under a prefix, taking into account the writes
+// staged in the transaction carried by the context. Values are sorted by key, and
+// thus by resource ID, which is the order in which list requests are paginated by
+// default, except for values staged under new keys, which come last.
+func (r *StateRepository) list(ctx context.Context, prefix string) ([]*kv, error) {
+
+	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	txn := transactionFromContext(ctx)
+
+	items := []*kv{}
+	seen := map[string]struct{}{}
+
+	for _, el := range res.Kvs {
+		seen[string(el.Key)] = struct{}{}
+		if txn != nil {
+			if w, ok := txn.get(string(el.Key)); ok {
+				if w.value != nil {
+					items = append(items, &kv{Key: string(el.Key), Value: []byte(*w.value), ModifyIndex: uint64(w.revision)})
+				}
+				continue
+			}
+		}
+		items = append(items, &kv{Key: string(el.Key), Value: el.Value, ModifyIndex: uint64(el.ModRevision)})
+	}
+
+	if txn != nil {
+		for _, key := range txn.keysWithPrefix(prefix) {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			if w, ok := txn.get(key); ok && w.value != nil {
+				items = append(items, &kv{Key: key, Value: []byte(*w.value), ModifyIndex: uint64(w.revision)})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// listPage returns a page of the values stored under a prefix, sorted by key, starting at the
+// key of the ID in the options, along with the ID the next page starts at, if any. Unless
+// the context carries a transaction, the page is read with a single range request, limited
+// to the size of the page, so that values outside of the page are not read.
+func (r *StateRepository) listPage(ctx context.Context, prefix string, opts state.ListOptions) ([]*kv, string, error) {
+
+	// Writes staged in the transaction may fall anywhere
+	// in the page, so all values under the prefix are read.
+	if transactionFromContext(ctx) != nil {
+		items, err := r.list(ctx, prefix)
+		if err != nil {
+			return nil, "", err
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+		items, next := page(items, prefix, opts)
+		return items, next, nil
+	}
+
+	getOpts := []clientv3.OpOption{
+		clientv3.WithRange(clientv3.GetPrefixRangeEnd(prefix)),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+	}
+
+	// Read one value more than requested, which is the first of the next page
+	if opts.PerPage > 0 {
+		getOpts = append(getOpts, clientv3.WithLimit(int64(opts.PerPage+1)))
+	}
+
+	res, err := r.client.Get(ctx, prefix+opts.StartID, getOpts...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := make([]*kv, 0, len(res.Kvs))
+	for _, el := range res.Kvs {
+		items = append(items, &kv{Key: string(el.Key), Value: el.Value, ModifyIndex: uint64(el.ModRevision)})
+	}
+
+	items, next := page(items, prefix, opts)
+
+	return items, next, nil
+}
+
+// page returns the values in the page requested, given values sorted by key,
+// along with the ID of the first value of the next page, if there is one.
+func page(items []*kv, prefix string, opts state.ListOptions) ([]*kv, string) {
+
+	start := sort.Search(len(items), func(i int) bool { return items[i].Key >= prefix+opts.StartID })
+	items = items[start:]
+
+	if opts.PerPage > 0 && len(items) > opts.PerPage {
+		return items[:opts.PerPage], strings.TrimPrefix(items[opts.PerPage].Key, prefix)
+	}
+
+	return items, ""
+}
+
+// put writes a value under a key, or stages the write in the transaction carried
+// by the context, if any. If modifyIndex is not zero, the write only succeeds if the
+// key was not modified since that revision. It returns the revision of the write,
+// or zero if the write was staged.
+func (r *StateRepository) put(ctx context.Context, key string, value interface{}, modifyIndex uint64) (uint64, error) {
+
+	encoded := encodeValue(value)
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		txn.stage(key, &encoded, int64(modifyIndex))
+		return 0, nil
+	}
+
+	if modifyIndex == 0 {
+		res, err := r.client.Put(ctx, key, encoded)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(res.Header.Revision), nil
+	}
+
+	res, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", int64(modifyIndex))).
+		Then(clientv3.OpPut(key, encoded)).
+		Commit()
+	if err != nil {
+		return 0, err
+	}
+	if !res.Succeeded {
+		return 0, state.ErrConflict
+	}
+
+	return uint64(res.Header.Revision), nil
+}
+
+// delete removes a key, or stages the removal in the
+// transaction carried by the context, if any.
+func (r *StateRepository) delete(ctx context.Context, key string) error {
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		txn.stage(key, nil, 0)
+		return nil
+	}
+
+	_, err := r.client.Delete(ctx, key)
+
+	return err
 }
 
 func (r *StateRepository) setupEtcdClient() error {
@@ -135,6 +431,10 @@ func (r *StateRepository) setupEtcdServer() error {
 	cfg.ACUrls = acURLs
 	cfg.LCUrls = lcURLs
 
+	// Allow multi-resource writes, such as the ones made when reconciling
+	// network topologies, to be committed in a single transaction.
+	cfg.MaxTxnOps = defaultMaxTxnOps
+
 	cfg.LogOutputs = []string{"stderr", path.Join(r.config.DataDir, "/etcd.log")}
 	cfg.LogLevel = strings.ToLower(r.config.LogLevel)
 
@@ -152,6 +452,10 @@ func strToPtr(s string) *string {
 	return &s
 }
 
+// resourceKey returns the key under which a resource is stored. Resources are keyed
+// by type and ID alone, so that they can be looked up without knowing their namespace,
+// which is a field of the resources themselves. The default namespace segment is kept
+// so that the keys of existing resources remain valid.
 func resourceKey(resourceType, resourceID string) string {
 	key := fmt.Sprintf("%s/%s/%s/%s", defaultPrefix, resourceType, defaultNamespace, resourceID)
 	return key
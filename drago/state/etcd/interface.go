@@ -4,31 +4,38 @@ import (
 	"context"
 	"errors"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // Interfaces :
 func (r *StateRepository) Interfaces(ctx context.Context) ([]*structs.Interface, error) {
+	items, _, err := r.InterfacesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// InterfacesPage :
+func (r *StateRepository) InterfacesPage(ctx context.Context, opts state.ListOptions) ([]*structs.Interface, string, error) {
 
 	prefix := resourceKey(resourceTypeInterface, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.Interface{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		iface := &structs.Interface{}
 		if err := decodeValue(el.Value, iface); err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		iface.ModifyIndex = el.ModifyIndex
 		items = append(items, iface)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // InterfacesByNodeID :
@@ -36,19 +43,20 @@ func (r *StateRepository) InterfacesByNodeID(ctx context.Context, id string) ([]
 
 	prefix := resourceKey(resourceTypeInterface, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*structs.Interface{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		iface := &structs.Interface{}
 		if err := decodeValue(el.Value, iface); err != nil {
 			return nil, err
 		}
 		if iface.NodeID == id {
+			iface.ModifyIndex = el.ModifyIndex
 			items = append(items, iface)
 		}
 	}
@@ -61,20 +69,21 @@ func (r *StateRepository) InterfacesByNetworkID(ctx context.Context, id string)
 
 	prefix := resourceKey(resourceTypeInterface, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*structs.Interface{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		iface := &structs.Interface{}
 		if err := decodeValue(el.Value, iface); err != nil {
 			return nil, err
 		}
 
 		if iface.NetworkID == id {
+			iface.ModifyIndex = el.ModifyIndex
 			items = append(items, iface)
 		}
 	}
@@ -87,20 +96,21 @@ func (r *StateRepository) InterfaceByID(ctx context.Context, id string) (*struct
 
 	key := resourceKey(resourceTypeInterface, id)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	iface := &structs.Interface{}
 
-	if err = decodeValue(res.Kvs[0].Value, iface); err != nil {
+	if err = decodeValue(res.Value, iface); err != nil {
 		return nil, err
 	}
+	iface.ModifyIndex = res.ModifyIndex
 
 	return iface, nil
 }
@@ -108,9 +118,13 @@ func (r *StateRepository) InterfaceByID(ctx context.Context, id string) (*struct
 // UpsertInterface :
 func (r *StateRepository) UpsertInterface(ctx context.Context, n *structs.Interface) error {
 	key := resourceKey(resourceTypeInterface, n.ID)
-	if _, err := r.client.Put(ctx, key, encodeValue(n)); err != nil {
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
 		return err
 	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -119,7 +133,7 @@ func (r *StateRepository) DeleteInterfaces(ctx context.Context, ids []string) er
 
 	for _, id := range ids {
 		key := resourceKey(resourceTypeInterface, id)
-		if _, err := r.client.Delete(ctx, key); err != nil {
+		if err := r.delete(ctx, key); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,91 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/seashell/drago/drago/state"
+	"github.com/seashell/drago/drago/structs"
+)
+
+// Namespaces :
+func (r *StateRepository) Namespaces(ctx context.Context) ([]*structs.Namespace, error) {
+	items, _, err := r.NamespacesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// NamespacesPage :
+func (r *StateRepository) NamespacesPage(ctx context.Context, opts state.ListOptions) ([]*structs.Namespace, string, error) {
+
+	prefix := resourceKey(resourceTypeNamespace, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := []*structs.Namespace{}
+
+	for _, el := range res {
+		namespace := &structs.Namespace{}
+		err := decodeValue(el.Value, namespace)
+		if err != nil {
+			return nil, "", err
+		}
+		namespace.ModifyIndex = el.ModifyIndex
+		items = append(items, namespace)
+	}
+
+	return items, next, nil
+}
+
+// NamespaceByName :
+func (r *StateRepository) NamespaceByName(ctx context.Context, name string) (*structs.Namespace, error) {
+
+	key := resourceKey(resourceTypeNamespace, name)
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	namespace := &structs.Namespace{}
+
+	err = decodeValue(res.Value, namespace)
+	if err != nil {
+		return nil, err
+	}
+	namespace.ModifyIndex = res.ModifyIndex
+
+	return namespace, nil
+}
+
+// UpsertNamespace :
+func (r *StateRepository) UpsertNamespace(ctx context.Context, n *structs.Namespace) error {
+	key := resourceKey(resourceTypeNamespace, n.Name)
+
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
+	return nil
+}
+
+// DeleteNamespaces :
+func (r *StateRepository) DeleteNamespaces(ctx context.Context, names []string) error {
+	for _, name := range names {
+		key := resourceKey(resourceTypeNamespace, name)
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
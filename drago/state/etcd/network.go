@@ -5,31 +5,38 @@ import (
 	"errors"
 	"fmt"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // Networks :
 func (r *StateRepository) Networks(ctx context.Context) ([]*structs.Network, error) {
+	items, _, err := r.NetworksPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// NetworksPage :
+func (r *StateRepository) NetworksPage(ctx context.Context, opts state.ListOptions) ([]*structs.Network, string, error) {
 
 	prefix := resourceKey(resourceTypeNetwork, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.Network{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		network := &structs.Network{}
 		if err := decodeValue(el.Value, network); err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		network.ModifyIndex = el.ModifyIndex
 		items = append(items, network)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // NetworkByID :
@@ -37,19 +44,20 @@ func (r *StateRepository) NetworkByID(ctx context.Context, id string) (*structs.
 
 	key := resourceKey(resourceTypeNetwork, id)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	network := &structs.Network{}
-	if err = decodeValue(res.Kvs[0].Value, network); err != nil {
+	if err = decodeValue(res.Value, network); err != nil {
 		return nil, err
 	}
+	network.ModifyIndex = res.ModifyIndex
 
 	return network, nil
 }
@@ -59,18 +67,19 @@ func (r *StateRepository) NetworkByName(ctx context.Context, s string) (*structs
 
 	prefix := resourceKey(resourceTypeNetwork, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		network := &structs.Network{}
 		if err := decodeValue(el.Value, network); err != nil {
 			return nil, err
 		}
 
 		if network.Name == s {
+			network.ModifyIndex = el.ModifyIndex
 			return network, nil
 		}
 	}
@@ -81,9 +90,13 @@ func (r *StateRepository) NetworkByName(ctx context.Context, s string) (*structs
 // UpsertNetwork :
 func (r *StateRepository) UpsertNetwork(ctx context.Context, n *structs.Network) error {
 	key := resourceKey(resourceTypeNetwork, n.ID)
-	if _, err := r.client.Put(ctx, key, encodeValue(n)); err != nil {
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
 		return err
 	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -92,7 +105,7 @@ func (r *StateRepository) DeleteNetworks(ctx context.Context, ids []string) erro
 
 	for _, id := range ids {
 		key := resourceKey(resourceTypeNetwork, id)
-		if _, err := r.client.Delete(ctx, key); err != nil {
+		if err := r.delete(ctx, key); err != nil {
 			return err
 		}
 	}
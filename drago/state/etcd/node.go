@@ -5,31 +5,38 @@ import (
 	"errors"
 	"fmt"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
-	"go.etcd.io/etcd/clientv3"
 )
 
 // Nodes :
 func (r *StateRepository) Nodes(ctx context.Context) ([]*structs.Node, error) {
+	items, _, err := r.NodesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// NodesPage :
+func (r *StateRepository) NodesPage(ctx context.Context, opts state.ListOptions) ([]*structs.Node, string, error) {
 
 	prefix := resourceKey(resourceTypeNode, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, next, err := r.listPage(ctx, prefix, opts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	items := []*structs.Node{}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		node := &structs.Node{}
 		if err := decodeValue(el.Value, node); err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		node.ModifyIndex = el.ModifyIndex
 		items = append(items, node)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // NodeByID :
@@ -37,20 +44,21 @@ func (r *StateRepository) NodeByID(ctx context.Context, id string) (*structs.Nod
 
 	key := resourceKey(resourceTypeNode, id)
 
-	res, err := r.client.Get(ctx, key)
+	res, err := r.get(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Count == 0 {
+	if res == nil {
 		return nil, errors.New("not found")
 	}
 
 	node := &structs.Node{}
 
-	if err := decodeValue(res.Kvs[0].Value, node); err != nil {
+	if err := decodeValue(res.Value, node); err != nil {
 		return nil, err
 	}
+	node.ModifyIndex = res.ModifyIndex
 
 	return node, nil
 }
@@ -60,17 +68,18 @@ func (r *StateRepository) NodeBySecretID(ctx context.Context, s string) (*struct
 
 	prefix := resourceKey(resourceTypeNode, "")
 
-	res, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByVersion, clientv3.SortDescend))
+	res, err := r.list(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, el := range res.Kvs {
+	for _, el := range res {
 		node := &structs.Node{}
 		if err := decodeValue(el.Value, node); err != nil {
 			return nil, err
 		}
 		if node.SecretID == s {
+			node.ModifyIndex = el.ModifyIndex
 			return node, nil
 		}
 	}
@@ -82,9 +91,13 @@ func (r *StateRepository) NodeBySecretID(ctx context.Context, s string) (*struct
 func (r *StateRepository) UpsertNode(ctx context.Context, n *structs.Node) error {
 	key := resourceKey(resourceTypeNode, n.ID)
 
-	if _, err := r.client.Put(ctx, key, encodeValue(n)); err != nil {
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
 		return err
 	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -93,7 +106,7 @@ func (r *StateRepository) DeleteNodes(ctx context.Context, ids []string) error {
 
 	for _, id := range ids {
 		key := resourceKey(resourceTypeNode, id)
-		if _, err := r.client.Delete(ctx, key); err != nil {
+		if err := r.delete(ctx, key); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,61 @@
+package etcd
+
+import (
+	"context"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	resourceTypeServer = "server"
+)
+
+// Servers :
+func (r *StateRepository) Servers(ctx context.Context) ([]*structs.Server, error) {
+
+	prefix := resourceKey(resourceTypeServer, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.Server{}
+
+	for _, el := range res {
+		server := &structs.Server{}
+		err := decodeValue(el.Value, server)
+		if err != nil {
+			return nil, err
+		}
+		server.ModifyIndex = el.ModifyIndex
+		items = append(items, server)
+	}
+
+	return items, nil
+}
+
+// UpsertServer :
+func (r *StateRepository) UpsertServer(ctx context.Context, s *structs.Server) error {
+	key := resourceKey(resourceTypeServer, s.Address)
+	index, err := r.put(ctx, key, s, s.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		s.ModifyIndex = index
+	}
+	return nil
+}
+
+// DeleteServers :
+func (r *StateRepository) DeleteServers(ctx context.Context, addresses []string) error {
+	for _, address := range addresses {
+		key := resourceKey(resourceTypeServer, address)
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
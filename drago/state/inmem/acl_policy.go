@@ -3,9 +3,9 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
 
-	structs "github.com/seashell/drago/drago/structs"
+	"github.com/seashell/drago/drago/state"
+	"github.com/seashell/drago/drago/structs"
 )
 
 const (
@@ -14,33 +14,71 @@ const (
 
 // ACLPolicies :
 func (r *StateRepository) ACLPolicies(ctx context.Context) ([]*structs.ACLPolicy, error) {
-	prefix := resourcePrefix(resourceTypePolicy)
+	items, _, err := r.ACLPoliciesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ACLPoliciesPage :
+func (r *StateRepository) ACLPoliciesPage(ctx context.Context, opts state.ListOptions) ([]*structs.ACLPolicy, string, error) {
+
+	prefix := resourceKey(resourceTypePolicy, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
 
 	items := []*structs.ACLPolicy{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.ACLPolicy); ok {
-				items = append(items, t)
-			}
+
+	for _, el := range res {
+		policy := &structs.ACLPolicy{}
+		err := decodeValue(el.Value, policy)
+		if err != nil {
+			return nil, "", err
 		}
+		policy.ModifyIndex = el.ModifyIndex
+		items = append(items, policy)
 	}
 
-	return items, nil
+	return items, next, nil
 }
 
 // ACLPolicyByName :
 func (r *StateRepository) ACLPolicyByName(ctx context.Context, name string) (*structs.ACLPolicy, error) {
+
 	key := resourceKey(resourceTypePolicy, name)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.ACLPolicy), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
 	}
-	return nil, errors.New("not found")
+
+	policy := &structs.ACLPolicy{}
+
+	err = decodeValue(res.Value, policy)
+	if err != nil {
+		return nil, err
+	}
+	policy.ModifyIndex = res.ModifyIndex
+
+	return policy, nil
 }
 
 // UpsertACLPolicy :
 func (r *StateRepository) UpsertACLPolicy(ctx context.Context, p *structs.ACLPolicy) error {
 	key := resourceKey(resourceTypePolicy, p.Name)
-	r.kv.Set(key, p)
+
+	index, err := r.put(ctx, key, p, p.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		p.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -48,7 +86,10 @@ func (r *StateRepository) UpsertACLPolicy(ctx context.Context, p *structs.ACLPol
 func (r *StateRepository) DeleteACLPolicies(ctx context.Context, names []string) error {
 	for _, name := range names {
 		key := resourceKey(resourceTypePolicy, name)
-		r.kv.Delete((key))
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
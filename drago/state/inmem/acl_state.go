@@ -5,22 +5,43 @@ import (
 	"errors"
 	"fmt"
 
-	structs "github.com/seashell/drago/drago/structs"
+	"github.com/seashell/drago/drago/structs"
 )
 
 // ACLState :
 func (r *StateRepository) ACLState(ctx context.Context) (*structs.ACLState, error) {
+
 	key := aclStateKey()
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.ACLState), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	state := &structs.ACLState{}
+
+	err = decodeValue(res.Value, state)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("not found")
+
+	return state, nil
 }
 
 // ACLSetState :
 func (r *StateRepository) ACLSetState(ctx context.Context, s *structs.ACLState) error {
+
 	key := aclStateKey()
-	r.kv.Set(key, s)
+
+	_, err := r.put(ctx, key, s, 0)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -3,43 +3,109 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
 
-	structs "github.com/seashell/drago/drago/structs"
+	"github.com/seashell/drago/drago/state"
+	"github.com/seashell/drago/drago/structs"
 )
 
 const (
 	resourceTypeToken = "token"
 )
 
+// ACLTokens :
+func (r *StateRepository) ACLTokens(ctx context.Context) ([]*structs.ACLToken, error) {
+	items, _, err := r.ACLTokensPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ACLTokensPage :
+func (r *StateRepository) ACLTokensPage(ctx context.Context, opts state.ListOptions) ([]*structs.ACLToken, string, error) {
+
+	prefix := resourceKey(resourceTypeToken, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := []*structs.ACLToken{}
+
+	for _, el := range res {
+		token := &structs.ACLToken{}
+		err := decodeValue(el.Value, token)
+		if err != nil {
+			return nil, "", err
+		}
+		token.ModifyIndex = el.ModifyIndex
+		items = append(items, token)
+	}
+
+	return items, next, nil
+}
+
 // ACLTokenByID ...
 func (r *StateRepository) ACLTokenByID(ctx context.Context, id string) (*structs.ACLToken, error) {
+
 	key := resourceKey(resourceTypeToken, id)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.ACLToken), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	token := &structs.ACLToken{}
+
+	err = decodeValue(res.Value, token)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("not found")
+	token.ModifyIndex = res.ModifyIndex
+
+	return token, nil
 }
 
 // ACLTokenBySecret :
 func (r *StateRepository) ACLTokenBySecret(ctx context.Context, secret string) (*structs.ACLToken, error) {
-	prefix := resourcePrefix(resourceTypeToken)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.ACLToken); ok {
-				if t.Secret == secret {
-					return t, nil
-				}
-			}
+
+	prefix := resourceKey(resourceTypeToken, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, el := range res {
+
+		token := &structs.ACLToken{}
+
+		err := decodeValue(el.Value, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Secret == secret {
+			token.ModifyIndex = el.ModifyIndex
+			return token, nil
 		}
 	}
+
 	return nil, nil
 }
 
 // UpsertACLToken :
 func (r *StateRepository) UpsertACLToken(ctx context.Context, t *structs.ACLToken) error {
 	key := resourceKey(resourceTypeToken, t.ID)
-	r.kv.Set(key, t)
+	index, err := r.put(ctx, key, t, t.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		t.ModifyIndex = index
+	}
 	return nil
 }
 
@@ -47,21 +113,10 @@ func (r *StateRepository) UpsertACLToken(ctx context.Context, t *structs.ACLToke
 func (r *StateRepository) DeleteACLTokens(ctx context.Context, ids []string) error {
 	for _, id := range ids {
 		key := resourceKey(resourceTypeToken, id)
-		r.kv.Delete(key)
-	}
-	return nil
-}
-
-// ACLTokens :
-func (r *StateRepository) ACLTokens(ctx context.Context) ([]*structs.ACLToken, error) {
-	prefix := resourcePrefix(resourceTypeToken)
-	items := []*structs.ACLToken{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.ACLToken); ok {
-				items = append(items, t)
-			}
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
 		}
 	}
-	return items, nil
+	return nil
 }
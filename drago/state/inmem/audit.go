@@ -0,0 +1,61 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	resourceTypeAuditEntry = "audit"
+)
+
+// AuditEntries :
+func (r *StateRepository) AuditEntries(ctx context.Context) ([]*structs.AuditEntry, error) {
+
+	prefix := resourceKey(resourceTypeAuditEntry, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.AuditEntry{}
+
+	for _, el := range res {
+		entry := &structs.AuditEntry{}
+		err := decodeValue(el.Value, entry)
+		if err != nil {
+			return nil, err
+		}
+		entry.ModifyIndex = el.ModifyIndex
+		items = append(items, entry)
+	}
+
+	return items, nil
+}
+
+// UpsertAuditEntry :
+func (r *StateRepository) UpsertAuditEntry(ctx context.Context, e *structs.AuditEntry) error {
+	key := resourceKey(resourceTypeAuditEntry, e.ID)
+	index, err := r.put(ctx, key, e, e.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		e.ModifyIndex = index
+	}
+	return nil
+}
+
+// DeleteAuditEntries :
+func (r *StateRepository) DeleteAuditEntries(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		key := resourceKey(resourceTypeAuditEntry, id)
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
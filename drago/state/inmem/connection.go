@@ -3,8 +3,9 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 )
 
@@ -14,113 +15,189 @@ const (
 
 // Connections :
 func (r *StateRepository) Connections(ctx context.Context) ([]*structs.Connection, error) {
-	prefix := resourcePrefix(resourceTypeConnection)
+	items, _, err := r.ConnectionsPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// ConnectionsPage :
+func (r *StateRepository) ConnectionsPage(ctx context.Context, opts state.ListOptions) ([]*structs.Connection, string, error) {
+
+	prefix := resourceKey(resourceTypeConnection, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
 	items := []*structs.Connection{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.Connection); ok {
-				items = append(items, t)
-			}
+
+	for _, el := range res {
+		conn := &structs.Connection{}
+		err := decodeValue(el.Value, conn)
+		if err != nil {
+			return nil, "", err
 		}
+		conn.ModifyIndex = el.ModifyIndex
+		items = append(items, conn)
 	}
-	return items, nil
+
+	return items, next, nil
 }
 
 // ConnectionByID ...
 func (r *StateRepository) ConnectionByID(ctx context.Context, id string) (*structs.Connection, error) {
+
 	key := resourceKey(resourceTypeConnection, id)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.Connection), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("not found")
-}
 
-// ConnectionByInterfaceIDs ...
-func (r *StateRepository) ConnectionByInterfaceIDs(ctx context.Context, a, b string) (*structs.Connection, error) {
-	prefix := resourcePrefix(resourceTypeConnection)
-
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if c, ok := el.Value.(*structs.Connection); ok {
-				if c.ConnectsInterfaces(a, b) {
-					return c, nil
-				}
-			}
-		}
+	if res == nil {
+		return nil, errors.New("not found")
 	}
 
-	return nil, errors.New("not found")
+	network := &structs.Connection{}
+
+	err = decodeValue(res.Value, network)
+	if err != nil {
+		return nil, err
+	}
+	network.ModifyIndex = res.ModifyIndex
+
+	return network, nil
 }
 
 // ConnectionsByNetworkID ...
 func (r *StateRepository) ConnectionsByNetworkID(ctx context.Context, id string) ([]*structs.Connection, error) {
 
-	res := []*structs.Connection{}
+	prefix := resourceKey(resourceTypeConnection, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.Connection{}
 
-	prefix := resourcePrefix(resourceTypeConnection)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if conn, ok := el.Value.(*structs.Connection); ok {
-				if conn.NetworkID == id {
-					res = append(res, conn)
-				}
-			}
+	for _, el := range res {
+		conn := &structs.Connection{}
+		if err := decodeValue(el.Value, conn); err != nil {
+			return nil, err
+		}
+		if conn.NetworkID == id {
+			conn.ModifyIndex = el.ModifyIndex
+			items = append(items, conn)
 		}
 	}
 
-	return res, nil
+	return items, nil
 }
 
 // ConnectionsByNodeID ...
 func (r *StateRepository) ConnectionsByNodeID(ctx context.Context, id string) ([]*structs.Connection, error) {
 
-	res := []*structs.Connection{}
+	prefix := resourceKey(resourceTypeConnection, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
 
-	prefix := resourcePrefix(resourceTypeConnection)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if conn, ok := el.Value.(*structs.Connection); ok {
-				if conn.PeerSettings[0].NodeID == id || conn.PeerSettings[1].NodeID == id {
-					res = append(res, conn)
-				}
-			}
+	items := []*structs.Connection{}
+
+	for _, el := range res {
+		conn := &structs.Connection{}
+		if err := decodeValue(el.Value, conn); err != nil {
+			return nil, err
+		}
+
+		if conn.PeerSettings[0].NodeID == id || conn.PeerSettings[1].NodeID == id {
+			conn.ModifyIndex = el.ModifyIndex
+			items = append(items, conn)
 		}
 	}
 
-	return res, nil
+	return items, nil
 }
 
 // ConnectionsByInterfaceID ...
 func (r *StateRepository) ConnectionsByInterfaceID(ctx context.Context, id string) ([]*structs.Connection, error) {
 
-	res := []*structs.Connection{}
+	prefix := resourceKey(resourceTypeConnection, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.Connection{}
+
+	for _, el := range res {
+		conn := &structs.Connection{}
+		if err := decodeValue(el.Value, conn); err != nil {
+			return nil, err
+		}
+
+		if conn.ConnectsInterface(id) {
+			conn.ModifyIndex = el.ModifyIndex
+			items = append(items, conn)
+		}
+	}
+
+	return items, nil
+}
+
+// ConnectionByInterfaceIDs ...
+func (r *StateRepository) ConnectionByInterfaceIDs(ctx context.Context, a, b string) (*structs.Connection, error) {
+
+	prefix := resourceKey(resourceTypeConnection, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, el := range res {
+		conn := &structs.Connection{}
+		if err := decodeValue(el.Value, conn); err != nil {
+			return nil, err
+		}
 
-	prefix := resourcePrefix(resourceTypeConnection)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if conn, ok := el.Value.(*structs.Connection); ok {
-				if conn.ConnectsInterface(id) {
-					res = append(res, conn)
-				}
-			}
+		if conn.ConnectsInterfaces(a, b) {
+			conn.ModifyIndex = el.ModifyIndex
+			return conn, nil
 		}
 	}
 
-	return res, nil
+	return nil, fmt.Errorf("not found")
 }
 
 // UpsertConnection :
 func (r *StateRepository) UpsertConnection(ctx context.Context, n *structs.Connection) error {
 	key := resourceKey(resourceTypeConnection, n.ID)
-	r.kv.Set(key, n)
+
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
 // DeleteConnections ...
 func (r *StateRepository) DeleteConnections(ctx context.Context, ids []string) error {
+
 	for _, id := range ids {
 		key := resourceKey(resourceTypeConnection, id)
-		r.kv.Delete(key)
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
@@ -2,8 +2,11 @@ package inmem
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	state "github.com/seashell/drago/drago/state"
 	concurrent "github.com/seashell/drago/pkg/concurrent"
@@ -19,6 +22,20 @@ const (
 type StateRepository struct {
 	kv     *concurrent.Map
 	logger log.Logger
+
+	// index is incremented on every write, and used as the
+	// modification index of the entries written. Writes are
+	// serialized, so that modification indexes can be checked
+	// before applying them.
+	index     uint64
+	writeLock sync.Mutex
+}
+
+// entry is a value stored in the repository. Values are stored encoded,
+// so that resources returned by the repository can be freely modified.
+type entry struct {
+	value       []byte
+	modifyIndex uint64
 }
 
 // NewStateRepository ...
@@ -34,16 +51,265 @@ func (b *StateRepository) Name() string {
 	return "inmem"
 }
 
+// Transaction returns a transaction in which writes are staged until committed.
+// All writes are then applied atomically, provided that none of the resources
+// written was modified since it was read, as indicated by its ModifyIndex.
+func (b *StateRepository) Transaction(ctx context.Context) state.Transaction {
+	return &transaction{
+		repo:   b,
+		staged: map[string]*stagedWrite{},
+	}
+}
+
 type transaction struct {
+	repo *StateRepository
+
+	keys   []string
+	staged map[string]*stagedWrite
+	lock   sync.Mutex
+}
+
+// stagedWrite is a write staged in a transaction. Deletes have a nil value.
+// ModifyIndex is the modification index expected for the key when the
+// transaction is committed, or zero if it should not be checked.
+type stagedWrite struct {
+	value       []byte
+	modifyIndex uint64
 }
 
-func (t transaction) Commit() (interface{}, error) {
+// Commit applies all staged writes atomically. It returns state.ErrConflict
+// if any of the keys was modified after being read.
+func (t *transaction) Commit() (interface{}, error) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.keys) == 0 {
+		return nil, nil
+	}
+
+	t.repo.writeLock.Lock()
+	defer t.repo.writeLock.Unlock()
+
+	for _, key := range t.keys {
+		if w := t.staged[key]; w.modifyIndex != 0 && !t.repo.hasModifyIndex(key, w.modifyIndex) {
+			return nil, state.ErrConflict
+		}
+	}
+
+	t.repo.index++
+
+	for _, key := range t.keys {
+		if w := t.staged[key]; w.value == nil {
+			t.repo.kv.Delete(key)
+		} else {
+			t.repo.kv.Set(key, &entry{value: w.value, modifyIndex: t.repo.index})
+		}
+	}
+
+	t.keys, t.staged = nil, map[string]*stagedWrite{}
+
 	return nil, nil
 }
 
-// Transaction ...
-func (b *StateRepository) Transaction(ctx context.Context) state.Transaction {
-	return transaction{}
+func (t *transaction) stage(key string, value []byte, modifyIndex uint64) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Keep the index from the first time the key was staged,
+	// since subsequent reads within the transaction return it.
+	if w, ok := t.staged[key]; ok {
+		w.value = value
+		return
+	}
+
+	t.keys = append(t.keys, key)
+	t.staged[key] = &stagedWrite{value: value, modifyIndex: modifyIndex}
+}
+
+func (t *transaction) get(key string) (*stagedWrite, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	w, ok := t.staged[key]
+	return w, ok
+}
+
+func (t *transaction) keysWithPrefix(prefix string) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	keys := []string{}
+	for _, key := range t.keys {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func transactionFromContext(ctx context.Context) *transaction {
+
+	txn, ok := state.TransactionFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Look for the transaction of this repository
+	// within transactions wrapping it, if any.
+	for {
+		switch t := txn.(type) {
+		case *transaction:
+			return t
+		case state.TransactionWrapper:
+			txn = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// kv is a value read from the repository, along with its modification index.
+type kv struct {
+	Key         string
+	Value       []byte
+	ModifyIndex uint64
+}
+
+// get returns the value stored under a key, or nil if the key does not exist,
+// taking into account the writes staged in the transaction carried by the context.
+func (b *StateRepository) get(ctx context.Context, key string) (*kv, error) {
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		if w, ok := txn.get(key); ok {
+			if w.value == nil {
+				return nil, nil
+			}
+			return &kv{Value: w.value, ModifyIndex: w.modifyIndex}, nil
+		}
+	}
+
+	v, found := b.kv.Get(key)
+	if !found {
+		return nil, nil
+	}
+
+	e := v.(*entry)
+
+	return &kv{Value: e.value, ModifyIndex: e.modifyIndex}, nil
+}
+
+// list returns the values stored under a prefix, taking into account the writes
+// staged in the transaction carried by the context. Values are sorted by key, as
+// in the etcd repository, which is the order in which list requests are paginated
+// by default.
+func (b *StateRepository) list(ctx context.Context, prefix string) ([]*kv, error) {
+
+	txn := transactionFromContext(ctx)
+
+	items := map[string]*kv{}
+
+	for el := range b.kv.Iter() {
+		if !strings.HasPrefix(el.Key, prefix) {
+			continue
+		}
+		e := el.Value.(*entry)
+		items[el.Key] = &kv{Key: el.Key, Value: e.value, ModifyIndex: e.modifyIndex}
+	}
+
+	if txn != nil {
+		for _, key := range txn.keysWithPrefix(prefix) {
+			if w, ok := txn.get(key); ok {
+				if w.value == nil {
+					delete(items, key)
+					continue
+				}
+				items[key] = &kv{Key: key, Value: w.value, ModifyIndex: w.modifyIndex}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]*kv, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, items[key])
+	}
+
+	return out, nil
+}
+
+// listPage returns a page of the values stored under a prefix, sorted by key, starting
+// at the key of the ID in the options, along with the ID the next page starts at, if any.
+func (b *StateRepository) listPage(ctx context.Context, prefix string, opts state.ListOptions) ([]*kv, string, error) {
+
+	items, err := b.list(ctx, prefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := sort.Search(len(items), func(i int) bool { return items[i].Key >= prefix+opts.StartID })
+	items = items[start:]
+
+	if opts.PerPage > 0 && len(items) > opts.PerPage {
+		return items[:opts.PerPage], strings.TrimPrefix(items[opts.PerPage].Key, prefix), nil
+	}
+
+	return items, "", nil
+}
+
+// put writes a value under a key, or stages the write in the transaction carried
+// by the context, if any. If modifyIndex is not zero, the write only succeeds if the
+// key was not modified since that index. It returns the modification index of the
+// write, or zero if the write was staged.
+func (b *StateRepository) put(ctx context.Context, key string, value interface{}, modifyIndex uint64) (uint64, error) {
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		txn.stage(key, encoded, modifyIndex)
+		return 0, nil
+	}
+
+	b.writeLock.Lock()
+	defer b.writeLock.Unlock()
+
+	if modifyIndex != 0 && !b.hasModifyIndex(key, modifyIndex) {
+		return 0, state.ErrConflict
+	}
+
+	b.index++
+	b.kv.Set(key, &entry{value: encoded, modifyIndex: b.index})
+
+	return b.index, nil
+}
+
+// delete removes a key, or stages the removal in the
+// transaction carried by the context, if any.
+func (b *StateRepository) delete(ctx context.Context, key string) error {
+
+	if txn := transactionFromContext(ctx); txn != nil {
+		txn.stage(key, nil, 0)
+		return nil
+	}
+
+	b.writeLock.Lock()
+	defer b.writeLock.Unlock()
+
+	b.kv.Delete(key)
+
+	return nil
+}
+
+func (b *StateRepository) hasModifyIndex(key string, modifyIndex uint64) bool {
+	v, found := b.kv.Get(key)
+	return found && v.(*entry).modifyIndex == modifyIndex
 }
 
 // Dump ...
@@ -52,7 +318,7 @@ func (b *StateRepository) Dump() <-chan string {
 	padding := 72
 	go func() {
 		for el := range b.kv.Iter() {
-			dumpCh <- fmt.Sprintf("%s%s %T", el.Key, strings.Repeat(" ", padding-len(el.Key)), el.Value)
+			dumpCh <- fmt.Sprintf("%s%s %s", el.Key, strings.Repeat(" ", padding-len(el.Key)), el.Value.(*entry).value)
 		}
 		close(dumpCh)
 	}()
@@ -61,9 +327,11 @@ func (b *StateRepository) Dump() <-chan string {
 
 // Clear ...
 func (b *StateRepository) Clear() {
-	b.kv = &concurrent.Map{}
+	b.kv = concurrent.NewMap()
 }
 
+// resourcePrefix returns the prefix of the keys of a resource type. As in the etcd
+// repository, resources are keyed by type and ID alone, regardless of their namespace.
 func resourcePrefix(resourceType string) string {
 	return fmt.Sprintf("%s/%s/%s", defaultPrefix, resourceType, defaultNamespace)
 }
@@ -71,3 +339,7 @@ func resourcePrefix(resourceType string) string {
 func resourceKey(resourceType, resourceID string) string {
 	return fmt.Sprintf("%s/%s", resourcePrefix(resourceType), resourceID)
 }
+
+func decodeValue(data []byte, out interface{}) error {
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,189 @@
+package inmem
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestTransaction(t *testing.T) {
+
+	r := NewStateRepository(nil)
+	ctx := context.Background()
+
+	txn := r.Transaction(ctx)
+	tctx := state.WithTransaction(ctx, txn)
+
+	if err := r.UpsertNetwork(tctx, &structs.Network{ID: "a", Name: "a"}); err != nil {
+		t.Fatalf("r.UpsertNetwork() failed: %v", err)
+	}
+	if err := r.UpsertNode(tctx, &structs.Node{ID: "b"}); err != nil {
+		t.Fatalf("r.UpsertNode() failed: %v", err)
+	}
+
+	if _, err := r.NetworkByID(tctx, "a"); err != nil {
+		t.Fatalf("r.NetworkByID() failed, expected staged network to be visible within transaction")
+	}
+	if _, err := r.NetworkByID(ctx, "a"); err == nil {
+		t.Fatalf("r.NetworkByID() failed, expected staged network not to be visible outside of transaction")
+	}
+
+	if _, err := txn.Commit(); err != nil {
+		t.Fatalf("txn.Commit() failed: %v", err)
+	}
+
+	if _, err := r.NetworkByID(ctx, "a"); err != nil {
+		t.Fatalf("r.NetworkByID() failed: %v", err)
+	}
+	if _, err := r.NodeByID(ctx, "b"); err != nil {
+		t.Fatalf("r.NodeByID() failed: %v", err)
+	}
+}
+
+func TestTransactionConflict(t *testing.T) {
+
+	r := NewStateRepository(nil)
+	ctx := context.Background()
+
+	r.UpsertNetwork(ctx, &structs.Network{ID: "a", Name: "a"})
+
+	n1, _ := r.NetworkByID(ctx, "a")
+	n2, _ := r.NetworkByID(ctx, "a")
+
+	n1.Name = "b"
+	if err := r.UpsertNetwork(ctx, n1); err != nil {
+		t.Fatalf("r.UpsertNetwork() failed: %v", err)
+	}
+
+	txn := r.Transaction(ctx)
+	tctx := state.WithTransaction(ctx, txn)
+
+	n2.Name = "c"
+	r.UpsertNetwork(tctx, n2)
+	r.UpsertNode(tctx, &structs.Node{ID: "b"})
+
+	if _, err := txn.Commit(); err != state.ErrConflict {
+		t.Fatalf("txn.Commit() failed, expected %v, have %v", state.ErrConflict, err)
+	}
+
+	if n, _ := r.NetworkByID(ctx, "a"); n.Name != "b" {
+		t.Fatalf("txn.Commit() failed, expected network name %s, have %s", "b", n.Name)
+	}
+	if _, err := r.NodeByID(ctx, "b"); err == nil {
+		t.Fatalf("txn.Commit() failed, expected no writes to be applied")
+	}
+}
+
+type wrappedTransaction struct {
+	state.Transaction
+}
+
+func (t *wrappedTransaction) Unwrap() state.Transaction {
+	return t.Transaction
+}
+
+func TestWrappedTransaction(t *testing.T) {
+
+	r := NewStateRepository(nil)
+	ctx := context.Background()
+
+	txn := &wrappedTransaction{r.Transaction(ctx)}
+	tctx := state.WithTransaction(ctx, txn)
+
+	if err := r.UpsertJoinToken(tctx, &structs.JoinToken{ID: "a", Secret: "b"}); err != nil {
+		t.Fatalf("r.UpsertJoinToken() failed: %v", err)
+	}
+
+	if _, err := r.JoinTokenBySecret(tctx, "b"); err != nil {
+		t.Fatalf("r.JoinTokenBySecret() failed, expected staged token to be visible within wrapped transaction")
+	}
+	if _, err := r.JoinTokenBySecret(ctx, "b"); err == nil {
+		t.Fatalf("r.JoinTokenBySecret() failed, expected staged token not to be visible outside of transaction")
+	}
+
+	if _, err := txn.Commit(); err != nil {
+		t.Fatalf("txn.Commit() failed: %v", err)
+	}
+
+	if _, err := r.JoinTokenByID(ctx, "a"); err != nil {
+		t.Fatalf("r.JoinTokenByID() failed: %v", err)
+	}
+}
+
+func TestListSortedByID(t *testing.T) {
+
+	r := NewStateRepository(nil)
+	ctx := context.Background()
+
+	for _, id := range []string{"c", "a", "d"} {
+		r.UpsertNetwork(ctx, &structs.Network{ID: id, Name: id})
+	}
+
+	txn := r.Transaction(ctx)
+	tctx := state.WithTransaction(ctx, txn)
+
+	r.UpsertNetwork(tctx, &structs.Network{ID: "b", Name: "b"})
+	r.DeleteNetworks(tctx, []string{"d"})
+
+	networks, err := r.Networks(tctx)
+	if err != nil {
+		t.Fatalf("r.Networks() failed: %v", err)
+	}
+
+	ids := []string{}
+	for _, n := range networks {
+		ids = append(ids, n.ID)
+	}
+
+	if expected := "a,b,c"; strings.Join(ids, ",") != expected {
+		t.Fatalf("r.Networks() failed, expected %s, have %s", expected, strings.Join(ids, ","))
+	}
+}
+
+func TestListPages(t *testing.T) {
+
+	r := NewStateRepository(nil)
+	ctx := context.Background()
+
+	for _, id := range []string{"c", "a", "e", "b", "d"} {
+		r.UpsertNetwork(ctx, &structs.Network{ID: id, Name: id})
+	}
+
+	pages := []string{}
+	opts := state.ListOptions{PerPage: 2}
+
+	for {
+		networks, next, err := r.NetworksPage(ctx, opts)
+		if err != nil {
+			t.Fatalf("r.NetworksPage() failed: %v", err)
+		}
+		if len(networks) > 2 {
+			t.Fatalf("r.NetworksPage() failed, expected at most 2 networks, have %d", len(networks))
+		}
+		ids := []string{}
+		for _, n := range networks {
+			ids = append(ids, n.ID)
+		}
+		pages = append(pages, strings.Join(ids, ","))
+		if next == "" {
+			break
+		}
+		opts.StartID = next
+	}
+
+	if expected := "a,b|c,d|e"; strings.Join(pages, "|") != expected {
+		t.Fatalf("r.NetworksPage() failed, expected %s, have %s", expected, strings.Join(pages, "|"))
+	}
+
+	// Pages may start at IDs which do not exist, such as removed resources
+	networks, next, err := r.NetworksPage(ctx, state.ListOptions{PerPage: 1, StartID: "bb"})
+	if err != nil {
+		t.Fatalf("r.NetworksPage() failed: %v", err)
+	}
+	if len(networks) != 1 || networks[0].ID != "c" || next != "d" {
+		t.Fatalf("r.NetworksPage() failed, expected page starting at c followed by d")
+	}
+}
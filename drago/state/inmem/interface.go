@@ -3,8 +3,8 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 )
 
@@ -14,75 +14,133 @@ const (
 
 // Interfaces :
 func (r *StateRepository) Interfaces(ctx context.Context) ([]*structs.Interface, error) {
-	prefix := resourcePrefix(resourceTypeInterface)
+	items, _, err := r.InterfacesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// InterfacesPage :
+func (r *StateRepository) InterfacesPage(ctx context.Context, opts state.ListOptions) ([]*structs.Interface, string, error) {
+
+	prefix := resourceKey(resourceTypeInterface, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
 	items := []*structs.Interface{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.Interface); ok {
-				items = append(items, t)
-			}
+
+	for _, el := range res {
+		iface := &structs.Interface{}
+		if err := decodeValue(el.Value, iface); err != nil {
+			return nil, "", err
 		}
+		iface.ModifyIndex = el.ModifyIndex
+		items = append(items, iface)
 	}
-	return items, nil
+
+	return items, next, nil
 }
 
 // InterfacesByNodeID ...
-func (r *StateRepository) InterfacesByNodeID(ctx context.Context, s string) ([]*structs.Interface, error) {
-
-	res := []*structs.Interface{}
-
-	prefix := resourcePrefix(resourceTypeInterface)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if iface, ok := el.Value.(*structs.Interface); ok {
-				if iface.NodeID == s {
-					res = append(res, iface)
-				}
-			}
+func (r *StateRepository) InterfacesByNodeID(ctx context.Context, id string) ([]*structs.Interface, error) {
+
+	prefix := resourceKey(resourceTypeInterface, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.Interface{}
+
+	for _, el := range res {
+		iface := &structs.Interface{}
+		if err := decodeValue(el.Value, iface); err != nil {
+			return nil, err
+		}
+		if iface.NodeID == id {
+			iface.ModifyIndex = el.ModifyIndex
+			items = append(items, iface)
 		}
 	}
-	return res, nil
+
+	return items, nil
 }
 
 // InterfacesByNetworkID ...
-func (r *StateRepository) InterfacesByNetworkID(ctx context.Context, s string) ([]*structs.Interface, error) {
-
-	res := []*structs.Interface{}
-
-	prefix := resourcePrefix(resourceTypeInterface)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if iface, ok := el.Value.(*structs.Interface); ok {
-				if iface.NetworkID == s {
-					res = append(res, iface)
-				}
-			}
+func (r *StateRepository) InterfacesByNetworkID(ctx context.Context, id string) ([]*structs.Interface, error) {
+
+	prefix := resourceKey(resourceTypeInterface, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*structs.Interface{}
+
+	for _, el := range res {
+		iface := &structs.Interface{}
+		if err := decodeValue(el.Value, iface); err != nil {
+			return nil, err
+		}
+
+		if iface.NetworkID == id {
+			iface.ModifyIndex = el.ModifyIndex
+			items = append(items, iface)
 		}
 	}
-	return res, nil
+
+	return items, nil
 }
 
 // InterfaceByID ...
 func (r *StateRepository) InterfaceByID(ctx context.Context, id string) (*structs.Interface, error) {
+
 	key := resourceKey(resourceTypeInterface, id)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.Interface), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
 	}
-	return nil, errors.New("not found")
+
+	iface := &structs.Interface{}
+
+	if err = decodeValue(res.Value, iface); err != nil {
+		return nil, err
+	}
+	iface.ModifyIndex = res.ModifyIndex
+
+	return iface, nil
 }
 
 // UpsertInterface :
 func (r *StateRepository) UpsertInterface(ctx context.Context, n *structs.Interface) error {
 	key := resourceKey(resourceTypeInterface, n.ID)
-	r.kv.Set(key, n)
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
 // DeleteInterfaces ...
 func (r *StateRepository) DeleteInterfaces(ctx context.Context, ids []string) error {
+
 	for _, id := range ids {
 		key := resourceKey(resourceTypeInterface, id)
-		r.kv.Delete(key)
+		if err := r.delete(ctx, key); err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
@@ -0,0 +1,122 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+
+	"github.com/seashell/drago/drago/state"
+	"github.com/seashell/drago/drago/structs"
+)
+
+const (
+	resourceTypeJoinToken = "join-token"
+)
+
+// JoinTokens :
+func (r *StateRepository) JoinTokens(ctx context.Context) ([]*structs.JoinToken, error) {
+	items, _, err := r.JoinTokensPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// JoinTokensPage :
+func (r *StateRepository) JoinTokensPage(ctx context.Context, opts state.ListOptions) ([]*structs.JoinToken, string, error) {
+
+	prefix := resourceKey(resourceTypeJoinToken, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := []*structs.JoinToken{}
+
+	for _, el := range res {
+		token := &structs.JoinToken{}
+		err := decodeValue(el.Value, token)
+		if err != nil {
+			return nil, "", err
+		}
+		token.ModifyIndex = el.ModifyIndex
+		items = append(items, token)
+	}
+
+	return items, next, nil
+}
+
+// JoinTokenByID :
+func (r *StateRepository) JoinTokenByID(ctx context.Context, id string) (*structs.JoinToken, error) {
+
+	key := resourceKey(resourceTypeJoinToken, id)
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	token := &structs.JoinToken{}
+
+	err = decodeValue(res.Value, token)
+	if err != nil {
+		return nil, err
+	}
+	token.ModifyIndex = res.ModifyIndex
+
+	return token, nil
+}
+
+// JoinTokenBySecret :
+func (r *StateRepository) JoinTokenBySecret(ctx context.Context, secret string) (*structs.JoinToken, error) {
+
+	prefix := resourceKey(resourceTypeJoinToken, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, el := range res {
+
+		token := &structs.JoinToken{}
+
+		err := decodeValue(el.Value, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Secret == secret {
+			token.ModifyIndex = el.ModifyIndex
+			return token, nil
+		}
+	}
+
+	return nil, errors.New("not found")
+}
+
+// UpsertJoinToken :
+func (r *StateRepository) UpsertJoinToken(ctx context.Context, t *structs.JoinToken) error {
+	key := resourceKey(resourceTypeJoinToken, t.ID)
+	index, err := r.put(ctx, key, t, t.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		t.ModifyIndex = index
+	}
+	return nil
+}
+
+// DeleteJoinTokens :
+func (r *StateRepository) DeleteJoinTokens(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		key := resourceKey(resourceTypeJoinToken, id)
+		err := r.delete(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,8 +3,9 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 )
 
@@ -14,54 +15,104 @@ const (
 
 // Networks :
 func (r *StateRepository) Networks(ctx context.Context) ([]*structs.Network, error) {
-	prefix := resourcePrefix(resourceTypeNetwork)
+	items, _, err := r.NetworksPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// NetworksPage :
+func (r *StateRepository) NetworksPage(ctx context.Context, opts state.ListOptions) ([]*structs.Network, string, error) {
+
+	prefix := resourceKey(resourceTypeNetwork, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
 	items := []*structs.Network{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.Network); ok {
-				items = append(items, t)
-			}
+
+	for _, el := range res {
+		network := &structs.Network{}
+		if err := decodeValue(el.Value, network); err != nil {
+			return nil, "", err
 		}
+		network.ModifyIndex = el.ModifyIndex
+		items = append(items, network)
 	}
-	return items, nil
+
+	return items, next, nil
 }
 
 // NetworkByID ...
 func (r *StateRepository) NetworkByID(ctx context.Context, id string) (*structs.Network, error) {
+
 	key := resourceKey(resourceTypeNetwork, id)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.Network), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	network := &structs.Network{}
+	if err = decodeValue(res.Value, network); err != nil {
+		return nil, err
 	}
-	return nil, errors.New("not found")
+	network.ModifyIndex = res.ModifyIndex
+
+	return network, nil
 }
 
 // NetworkByName ...
-func (r *StateRepository) NetworkByName(ctx context.Context, name string) (*structs.Network, error) {
-	prefix := resourcePrefix(resourceTypeNetwork)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if n, ok := el.Value.(*structs.Network); ok {
-				if n.Name == name {
-					return n, nil
-				}
-			}
+func (r *StateRepository) NetworkByName(ctx context.Context, s string) (*structs.Network, error) {
+
+	prefix := resourceKey(resourceTypeNetwork, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, el := range res {
+		network := &structs.Network{}
+		if err := decodeValue(el.Value, network); err != nil {
+			return nil, err
+		}
+
+		if network.Name == s {
+			network.ModifyIndex = el.ModifyIndex
+			return network, nil
 		}
 	}
-	return nil, errors.New("not found")
+
+	return nil, fmt.Errorf("not found")
 }
 
 // UpsertNetwork :
 func (r *StateRepository) UpsertNetwork(ctx context.Context, n *structs.Network) error {
 	key := resourceKey(resourceTypeNetwork, n.ID)
-	r.kv.Set(key, n)
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
 // DeleteNetworks ...
 func (r *StateRepository) DeleteNetworks(ctx context.Context, ids []string) error {
+
 	for _, id := range ids {
 		key := resourceKey(resourceTypeNetwork, id)
-		r.kv.Delete(key)
+		if err := r.delete(ctx, key); err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
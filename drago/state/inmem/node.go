@@ -3,8 +3,8 @@ package inmem
 import (
 	"context"
 	"errors"
-	"strings"
 
+	state "github.com/seashell/drago/drago/state"
 	structs "github.com/seashell/drago/drago/structs"
 )
 
@@ -14,54 +14,105 @@ const (
 
 // Nodes :
 func (r *StateRepository) Nodes(ctx context.Context) ([]*structs.Node, error) {
-	prefix := resourcePrefix(resourceTypeNode)
+	items, _, err := r.NodesPage(ctx, state.ListOptions{})
+	return items, err
+}
+
+// NodesPage :
+func (r *StateRepository) NodesPage(ctx context.Context, opts state.ListOptions) ([]*structs.Node, string, error) {
+
+	prefix := resourceKey(resourceTypeNode, "")
+
+	res, next, err := r.listPage(ctx, prefix, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
 	items := []*structs.Node{}
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if t, ok := el.Value.(*structs.Node); ok {
-				items = append(items, t)
-			}
+
+	for _, el := range res {
+		node := &structs.Node{}
+		if err := decodeValue(el.Value, node); err != nil {
+			return nil, "", err
 		}
+		node.ModifyIndex = el.ModifyIndex
+		items = append(items, node)
 	}
-	return items, nil
+
+	return items, next, nil
 }
 
 // NodeByID ...
 func (r *StateRepository) NodeByID(ctx context.Context, id string) (*structs.Node, error) {
+
 	key := resourceKey(resourceTypeNode, id)
-	if v, found := r.kv.Get(key); found {
-		return v.(*structs.Node), nil
+
+	res, err := r.get(ctx, key)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("not found")
+
+	if res == nil {
+		return nil, errors.New("not found")
+	}
+
+	node := &structs.Node{}
+
+	if err := decodeValue(res.Value, node); err != nil {
+		return nil, err
+	}
+	node.ModifyIndex = res.ModifyIndex
+
+	return node, nil
 }
 
 // NodeBySecretID ...
 func (r *StateRepository) NodeBySecretID(ctx context.Context, s string) (*structs.Node, error) {
-	prefix := resourcePrefix(resourceTypeNode)
-	for el := range r.kv.Iter() {
-		if strings.HasPrefix(el.Key, prefix) {
-			if n, ok := el.Value.(*structs.Node); ok {
-				if n.SecretID == s {
-					return n, nil
-				}
-			}
+
+	prefix := resourceKey(resourceTypeNode, "")
+
+	res, err := r.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, el := range res {
+		node := &structs.Node{}
+		if err := decodeValue(el.Value, node); err != nil {
+			return nil, err
+		}
+		if node.SecretID == s {
+			node.ModifyIndex = el.ModifyIndex
+			return node, nil
 		}
 	}
+
 	return nil, errors.New("not found")
 }
 
 // UpsertNode :
 func (r *StateRepository) UpsertNode(ctx context.Context, n *structs.Node) error {
 	key := resourceKey(resourceTypeNode, n.ID)
-	r.kv.Set(key, n)
+
+	index, err := r.put(ctx, key, n, n.ModifyIndex)
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		n.ModifyIndex = index
+	}
 	return nil
 }
 
 // DeleteNodes ...
 func (r *StateRepository) DeleteNodes(ctx context.Context, ids []string) error {
+
 	for _, id := range ids {
 		key := resourceKey(resourceTypeNode, id)
-		r.kv.Delete(key)
+		if err := r.delete(ctx, key); err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
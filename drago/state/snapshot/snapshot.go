@@ -0,0 +1,416 @@
+// Package snapshot implements point-in-time snapshots of the server state,
+// which can be saved from and restored into any state.Repository.
+//
+// A snapshot is a gzipped tar archive containing two files: meta.json, which
+// holds the version of the archive format and the SHA-256 checksum of the
+// data, and state.json, which holds the resources themselves.
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+const (
+	// Version is the version of the archive format written by Save.
+	Version = 1
+
+	metaFileName  = "meta.json"
+	stateFileName = "state.json"
+)
+
+var (
+	// ErrChecksumMismatch is returned when the data in a snapshot
+	// does not match the checksum it was saved with.
+	ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+	// ErrTooLarge is returned when the uncompressed contents of a
+	// snapshot exceed the maximum size.
+	ErrTooLarge = errors.New("snapshot too large")
+
+	// maxSize is the maximum size of the uncompressed contents of a snapshot,
+	// which protects against archives decompressing to arbitrarily large sizes.
+	maxSize int64 = 256 << 20
+
+	// maxBatchOps and maxBatchBytes bound the number of writes, and the size of
+	// the data written, in each transaction committed when restoring a snapshot.
+	// They are kept well below the limits of etcd, which by default accepts up to
+	// 4096 operations and 1.5MiB per transaction.
+	maxBatchOps   = 1024
+	maxBatchBytes = 1 << 20
+)
+
+// Meta describes a snapshot.
+type Meta struct {
+	Version   int
+	CreatedAt time.Time
+	Checksum  string
+}
+
+// Data contains all resources in a snapshot.
+type Data struct {
+	ACLState    *structs.ACLState
+	ACLTokens   []*structs.ACLToken
+	ACLPolicies []*structs.ACLPolicy
+	Namespaces  []*structs.Namespace
+	Nodes       []*structs.Node
+	JoinTokens  []*structs.JoinToken
+	Networks    []*structs.Network
+	Interfaces  []*structs.Interface
+	Connections []*structs.Connection
+}
+
+// Save writes a snapshot of all resources in the repository to w.
+func Save(ctx context.Context, repo state.Repository, w io.Writer) (*Meta, error) {
+
+	data, err := read(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Meta{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Checksum:  checksum(b),
+	}
+
+	mb, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	for _, f := range []struct {
+		name string
+		body []byte
+	}{{metaFileName, mb}, {stateFileName, b}} {
+		hdr := &tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.body)),
+			ModTime: meta.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// Read reads and verifies a snapshot, without restoring it.
+func Read(r io.Reader) (*Meta, *Data, error) {
+
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+	defer gzr.Close()
+
+	files := map[string][]byte{}
+
+	// Read one byte past the maximum size, so that larger
+	// snapshots can be told apart from truncated ones.
+	lr := &io.LimitedReader{R: gzr, N: maxSize + 1}
+
+	tr := tar.NewReader(lr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if lr.N <= 0 {
+			return nil, nil, ErrTooLarge
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid snapshot: %v", err)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if lr.N <= 0 {
+			return nil, nil, ErrTooLarge
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid snapshot: %v", err)
+		}
+		files[hdr.Name] = b
+	}
+
+	mb, ok := files[metaFileName]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid snapshot: missing %s", metaFileName)
+	}
+	b, ok := files[stateFileName]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid snapshot: missing %s", stateFileName)
+	}
+
+	meta := &Meta{}
+	if err := json.Unmarshal(mb, meta); err != nil {
+		return nil, nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+
+	if meta.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported snapshot version %d", meta.Version)
+	}
+
+	if checksum(b) != meta.Checksum {
+		return nil, nil, ErrChecksumMismatch
+	}
+
+	data := &Data{}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(data); err != nil {
+		return nil, nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+
+	return meta, data, nil
+}
+
+// Restore replaces all resources in the repository with those in the snapshot read
+// from r. The snapshot is fully read and verified before the repository is modified.
+// Since snapshots may hold more resources than fit in a single transaction, writes are
+// committed in batches. If any batch fails, the resources held before the restore are
+// written back. Should that fail as well, the repository is left partially restored,
+// in which case restoring the snapshot again is safe, as it replaces all resources.
+func Restore(ctx context.Context, repo state.Repository, r io.Reader) (*Meta, error) {
+
+	meta, data, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := read(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %v", err)
+	}
+
+	if err := replace(ctx, repo, data); err != nil {
+		if rerr := replace(ctx, repo, previous); rerr != nil {
+			return nil, fmt.Errorf("failed to restore state: %v (rolling back also failed, leaving the state partially restored: %v)", err, rerr)
+		}
+		return nil, fmt.Errorf("failed to restore state: %v", err)
+	}
+
+	return meta, nil
+}
+
+func read(ctx context.Context, repo state.Repository) (*Data, error) {
+
+	var err error
+	data := &Data{}
+
+	// The ACL state does not exist until ACLs are bootstrapped
+	if s, err := repo.ACLState(ctx); err == nil {
+		data.ACLState = s
+	}
+	if data.ACLTokens, err = repo.ACLTokens(ctx); err != nil {
+		return nil, err
+	}
+	if data.ACLPolicies, err = repo.ACLPolicies(ctx); err != nil {
+		return nil, err
+	}
+	if data.Namespaces, err = repo.Namespaces(ctx); err != nil {
+		return nil, err
+	}
+	if data.Nodes, err = repo.Nodes(ctx); err != nil {
+		return nil, err
+	}
+	if data.JoinTokens, err = repo.JoinTokens(ctx); err != nil {
+		return nil, err
+	}
+	if data.Networks, err = repo.Networks(ctx); err != nil {
+		return nil, err
+	}
+	if data.Interfaces, err = repo.Interfaces(ctx); err != nil {
+		return nil, err
+	}
+	if data.Connections, err = repo.Connections(ctx); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// op is a single write to the repository, along with an
+// estimate of the size of the data it writes, in bytes.
+type op struct {
+	size  int
+	apply func(ctx context.Context) error
+}
+
+// replace removes all resources which are part of snapshots from the
+// repository, and writes the ones in data instead.
+func replace(ctx context.Context, repo state.Repository, data *Data) error {
+
+	current, err := read(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	return apply(ctx, repo, append(deletes(repo, current), writes(repo, data)...))
+}
+
+// apply commits the ops passed in order, in transactions of at most maxBatchOps
+// ops and maxBatchBytes bytes, so that they stay within the limits imposed by
+// the repository on the size of transactions.
+func apply(ctx context.Context, repo state.Repository, ops []op) error {
+
+	for len(ops) > 0 {
+
+		txn := repo.Transaction(ctx)
+		tctx := state.WithTransaction(ctx, txn)
+
+		n, size := 0, 0
+		for n < len(ops) && n < maxBatchOps && (n == 0 || size+ops[n].size <= maxBatchBytes) {
+			if err := ops[n].apply(tctx); err != nil {
+				return err
+			}
+			size += ops[n].size
+			n++
+		}
+
+		if _, err := txn.Commit(); err != nil {
+			return err
+		}
+
+		ops = ops[n:]
+	}
+
+	return nil
+}
+
+// deletes returns the ops removing the resources in data from the repository.
+func deletes(repo state.Repository, data *Data) []op {
+
+	ops := []op{}
+
+	del := func(id string, fn func(ctx context.Context, ids []string) error) {
+		ops = append(ops, op{size: len(id), apply: func(ctx context.Context) error {
+			return fn(ctx, []string{id})
+		}})
+	}
+
+	for _, c := range data.Connections {
+		del(c.ID, repo.DeleteConnections)
+	}
+	for _, i := range data.Interfaces {
+		del(i.ID, repo.DeleteInterfaces)
+	}
+	for _, n := range data.Networks {
+		del(n.ID, repo.DeleteNetworks)
+	}
+	for _, t := range data.JoinTokens {
+		del(t.ID, repo.DeleteJoinTokens)
+	}
+	for _, n := range data.Nodes {
+		del(n.ID, repo.DeleteNodes)
+	}
+	for _, n := range data.Namespaces {
+		del(n.Name, repo.DeleteNamespaces)
+	}
+	for _, t := range data.ACLTokens {
+		del(t.ID, repo.DeleteACLTokens)
+	}
+	for _, p := range data.ACLPolicies {
+		del(p.Name, repo.DeleteACLPolicies)
+	}
+
+	return ops
+}
+
+// writes returns the ops upserting the resources in data into the repository.
+// Modify indexes are reset, since they are only meaningful to the repository
+// the resources were read from.
+func writes(repo state.Repository, data *Data) []op {
+
+	ops := []op{}
+
+	write := func(v interface{}, fn func(ctx context.Context) error) {
+		b, _ := json.Marshal(v)
+		ops = append(ops, op{size: len(b), apply: fn})
+	}
+
+	for _, p := range data.ACLPolicies {
+		p := p
+		p.ModifyIndex = 0
+		write(p, func(ctx context.Context) error { return repo.UpsertACLPolicy(ctx, p) })
+	}
+	for _, t := range data.ACLTokens {
+		t := t
+		t.ModifyIndex = 0
+		write(t, func(ctx context.Context) error { return repo.UpsertACLToken(ctx, t) })
+	}
+
+	aclState := data.ACLState
+	if aclState == nil {
+		aclState = &structs.ACLState{}
+	}
+	write(aclState, func(ctx context.Context) error { return repo.ACLSetState(ctx, aclState) })
+
+	for _, n := range data.Namespaces {
+		n := n
+		n.ModifyIndex = 0
+		write(n, func(ctx context.Context) error { return repo.UpsertNamespace(ctx, n) })
+	}
+	for _, n := range data.Nodes {
+		n := n
+		n.ModifyIndex = 0
+		write(n, func(ctx context.Context) error { return repo.UpsertNode(ctx, n) })
+	}
+	for _, t := range data.JoinTokens {
+		t := t
+		t.ModifyIndex = 0
+		write(t, func(ctx context.Context) error { return repo.UpsertJoinToken(ctx, t) })
+	}
+	for _, n := range data.Networks {
+		n := n
+		n.ModifyIndex = 0
+		write(n, func(ctx context.Context) error { return repo.UpsertNetwork(ctx, n) })
+	}
+	for _, i := range data.Interfaces {
+		i := i
+		i.ModifyIndex = 0
+		write(i, func(ctx context.Context) error { return repo.UpsertInterface(ctx, i) })
+	}
+	for _, c := range data.Connections {
+		c := c
+		c.ModifyIndex = 0
+		write(c, func(ctx context.Context) error { return repo.UpsertConnection(ctx, c) })
+	}
+
+	return ops
+}
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,286 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	state "github.com/seashell/drago/drago/state"
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestSaveRestore(t *testing.T) {
+
+	ctx := context.Background()
+
+	src := inmem.NewStateRepository(nil)
+	src.UpsertACLPolicy(ctx, &structs.ACLPolicy{Name: "p"})
+	src.UpsertACLToken(ctx, &structs.ACLToken{ID: "t", Secret: "s", Policies: []string{"p"}})
+	src.ACLSetState(ctx, &structs.ACLState{RootTokenID: "t"})
+	src.UpsertNamespace(ctx, &structs.Namespace{Name: "ns"})
+	src.UpsertNode(ctx, &structs.Node{ID: "n", Namespace: "ns"})
+	src.UpsertNetwork(ctx, &structs.Network{ID: "net", Namespace: "ns", Name: "net"})
+	src.UpsertInterface(ctx, &structs.Interface{ID: "i", NodeID: "n", NetworkID: "net"})
+	src.UpsertConnection(ctx, &structs.Connection{ID: "c", NetworkID: "net"})
+
+	buf := &bytes.Buffer{}
+	if _, err := Save(ctx, src, buf); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	b := buf.Bytes()
+
+	dst := inmem.NewStateRepository(nil)
+	dst.UpsertNode(ctx, &structs.Node{ID: "stale"})
+
+	if _, err := Restore(ctx, dst, bytes.NewReader(b)); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+
+	if _, err := dst.NodeByID(ctx, "stale"); err == nil {
+		t.Fatalf("expected existing node to be removed on restore")
+	}
+	if n, err := dst.NodeByID(ctx, "n"); err != nil || n.Namespace != "ns" {
+		t.Fatalf("dst.NodeByID() failed, expected node in namespace ns: %v", err)
+	}
+	if _, err := dst.NamespaceByName(ctx, "ns"); err != nil {
+		t.Fatalf("dst.NamespaceByName() failed: %v", err)
+	}
+	if _, err := dst.ACLTokenBySecret(ctx, "s"); err != nil {
+		t.Fatalf("dst.ACLTokenBySecret() failed: %v", err)
+	}
+	if _, err := dst.ACLPolicyByName(ctx, "p"); err != nil {
+		t.Fatalf("dst.ACLPolicyByName() failed: %v", err)
+	}
+	if s, err := dst.ACLState(ctx); err != nil || s.RootTokenID != "t" {
+		t.Fatalf("expected ACL state to be restored")
+	}
+	if _, err := dst.InterfaceByID(ctx, "i"); err != nil {
+		t.Fatalf("dst.InterfaceByID() failed: %v", err)
+	}
+	if _, err := dst.ConnectionByID(ctx, "c"); err != nil {
+		t.Fatalf("dst.ConnectionByID() failed: %v", err)
+	}
+
+	// Restoring again must succeed even though resources now exist
+	if _, err := Restore(ctx, dst, bytes.NewReader(b)); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+}
+
+func TestRestoreChecksumMismatch(t *testing.T) {
+
+	ctx := context.Background()
+
+	src := inmem.NewStateRepository(nil)
+	src.UpsertNode(ctx, &structs.Node{ID: "n"})
+
+	buf := &bytes.Buffer{}
+	if _, err := Save(ctx, src, buf); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	_, data, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if len(data.Nodes) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(data.Nodes))
+	}
+
+	// Tamper with the archive by writing a snapshot with a bogus checksum
+	tampered := &bytes.Buffer{}
+	writeArchive(t, tampered, []byte(`{"Version":1,"Checksum":"sha256:00"}`), []byte(`{}`))
+
+	dst := inmem.NewStateRepository(nil)
+	dst.UpsertNode(ctx, &structs.Node{ID: "keep"})
+
+	if _, err := Restore(ctx, dst, tampered); err != ErrChecksumMismatch {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := dst.NodeByID(ctx, "keep"); err != nil {
+		t.Fatalf("expected state to be left untouched on a failed restore")
+	}
+}
+
+// failingRepository is a repository which fails to upsert networks.
+type failingRepository struct {
+	*inmem.StateRepository
+}
+
+func (r *failingRepository) UpsertNetwork(ctx context.Context, n *structs.Network) error {
+	return errors.New("failed")
+}
+
+func TestRestoreFailureKeepsState(t *testing.T) {
+
+	ctx := context.Background()
+
+	src := inmem.NewStateRepository(nil)
+	src.UpsertNode(ctx, &structs.Node{ID: "n"})
+	src.UpsertNetwork(ctx, &structs.Network{ID: "net"})
+
+	buf := &bytes.Buffer{}
+	if _, err := Save(ctx, src, buf); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	dst := &failingRepository{inmem.NewStateRepository(nil)}
+	dst.UpsertNode(ctx, &structs.Node{ID: "keep"})
+
+	if _, err := Restore(ctx, dst, buf); err == nil {
+		t.Fatalf("expected Restore() to fail")
+	}
+	if _, err := dst.NodeByID(ctx, "keep"); err != nil {
+		t.Fatalf("expected state to be left untouched on a failed restore")
+	}
+	if _, err := dst.NodeByID(ctx, "n"); err == nil {
+		t.Fatalf("expected no resources from the snapshot to be written on a failed restore")
+	}
+}
+
+func TestRestoreFailureRollsBack(t *testing.T) {
+
+	defer func(n int) { maxBatchOps = n }(maxBatchOps)
+	maxBatchOps = 1
+
+	ctx := context.Background()
+
+	src := inmem.NewStateRepository(nil)
+	for i := 0; i < 5; i++ {
+		src.UpsertNode(ctx, &structs.Node{ID: fmt.Sprintf("n%d", i)})
+	}
+	src.UpsertNetwork(ctx, &structs.Network{ID: "net"})
+
+	buf := &bytes.Buffer{}
+	if _, err := Save(ctx, src, buf); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	dst := &failingRepository{inmem.NewStateRepository(nil)}
+	dst.UpsertNode(ctx, &structs.Node{ID: "keep"})
+
+	// The nodes are committed in batches preceding the one failing,
+	// so they must be removed for the previous state to be restored.
+	if _, err := Restore(ctx, dst, buf); err == nil {
+		t.Fatalf("expected Restore() to fail")
+	}
+	if _, err := dst.NodeByID(ctx, "keep"); err != nil {
+		t.Fatalf("expected previous state to be restored on a failed restore")
+	}
+	if nodes, _ := dst.Nodes(ctx); len(nodes) != 1 {
+		t.Fatalf("expected no resources from the snapshot to be kept on a failed restore, got %d nodes", len(nodes))
+	}
+}
+
+// errTooManyOps is returned by limitedRepository when a transaction exceeds its limit.
+var errTooManyOps = errors.New("too many operations in transaction")
+
+// limitedRepository is a repository which, like etcd, limits the number of
+// operations in a transaction. Only writes to nodes are counted.
+type limitedRepository struct {
+	*inmem.StateRepository
+	maxOps int
+}
+
+type limitedTransaction struct {
+	state.Transaction
+	ops int
+}
+
+func (t *limitedTransaction) Unwrap() state.Transaction {
+	return t.Transaction
+}
+
+func (r *limitedRepository) Transaction(ctx context.Context) state.Transaction {
+	return &limitedTransaction{Transaction: r.StateRepository.Transaction(ctx)}
+}
+
+func (r *limitedRepository) count(ctx context.Context, n int) error {
+	if txn, ok := state.TransactionFromContext(ctx); ok {
+		t := txn.(*limitedTransaction)
+		if t.ops += n; t.ops > r.maxOps {
+			return errTooManyOps
+		}
+	}
+	return nil
+}
+
+func (r *limitedRepository) UpsertNode(ctx context.Context, n *structs.Node) error {
+	if err := r.count(ctx, 1); err != nil {
+		return err
+	}
+	return r.StateRepository.UpsertNode(ctx, n)
+}
+
+func (r *limitedRepository) DeleteNodes(ctx context.Context, ids []string) error {
+	if err := r.count(ctx, len(ids)); err != nil {
+		return err
+	}
+	return r.StateRepository.DeleteNodes(ctx, ids)
+}
+
+func TestRestoreLarge(t *testing.T) {
+
+	ctx := context.Background()
+
+	src := inmem.NewStateRepository(nil)
+	dst := &limitedRepository{inmem.NewStateRepository(nil), 4096}
+
+	// More nodes than fit in a single transaction are
+	// both removed from the state and restored into it.
+	for i := 0; i < 5000; i++ {
+		src.UpsertNode(ctx, &structs.Node{ID: fmt.Sprintf("n%d", i)})
+		dst.StateRepository.UpsertNode(ctx, &structs.Node{ID: fmt.Sprintf("stale%d", i)})
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := Save(ctx, src, buf); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	if _, err := Restore(ctx, dst, buf); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+
+	nodes, err := dst.Nodes(ctx)
+	if err != nil {
+		t.Fatalf("dst.Nodes() failed: %v", err)
+	}
+	if len(nodes) != 5000 {
+		t.Fatalf("expected 5000 nodes, got %d", len(nodes))
+	}
+	if _, err := dst.NodeByID(ctx, "stale0"); err == nil {
+		t.Fatalf("expected existing nodes to be removed on restore")
+	}
+}
+
+func TestReadTooLarge(t *testing.T) {
+
+	defer func(n int64) { maxSize = n }(maxSize)
+	maxSize = 16
+
+	buf := &bytes.Buffer{}
+	writeArchive(t, buf, []byte(`{"Version":1}`), bytes.Repeat([]byte("x"), 64))
+
+	if _, _, err := Read(buf); err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func writeArchive(t *testing.T, buf *bytes.Buffer, meta, state []byte) {
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	for name, body := range map[string][]byte{metaFileName: meta, stateFileName: state} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(body)
+	}
+	tw.Close()
+	gzw.Close()
+}
@@ -2,15 +2,56 @@ package state
 
 import (
 	"context"
+	"errors"
 
 	"github.com/seashell/drago/drago/structs"
 )
 
-// Transaction :
+// ErrConflict is returned when writing a resource which was modified
+// since it was read, as indicated by its ModifyIndex.
+var ErrConflict = errors.New("conflict")
+
+// Transaction groups writes to multiple resources, so that they are either all
+// applied or none of them is. Writes made with a context returned by WithTransaction
+// are staged in the transaction, and only applied when Commit is called. Reads made
+// with the same context reflect the writes staged so far.
 type Transaction interface {
 	Commit() (interface{}, error)
 }
 
+// TransactionWrapper is implemented by transactions wrapping the transaction
+// of another repository, which allows the latter to find it in a context.
+type TransactionWrapper interface {
+	Unwrap() Transaction
+}
+
+type transactionContextKey struct{}
+
+// WithTransaction returns a copy of the context carrying the transaction.
+func WithTransaction(ctx context.Context, txn Transaction) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, txn)
+}
+
+// TransactionFromContext returns the transaction carried by the context, if any.
+func TransactionFromContext(ctx context.Context) (Transaction, bool) {
+	txn, ok := ctx.Value(transactionContextKey{}).(Transaction)
+	return txn, ok
+}
+
+// ListOptions controls which resources are returned by the paginated list methods
+// of a repository, such as NetworksPage. These return resources sorted by ID, along
+// with the ID of the first resource of the next page, if there are more resources.
+// Resources identified by name, such as namespaces, are sorted by name instead.
+type ListOptions struct {
+	// PerPage is the maximum number of resources returned.
+	// If zero, all resources starting at StartID are returned.
+	PerPage int
+
+	// StartID is the ID of the first resource returned, which need
+	// not exist. If empty, resources are returned from the start.
+	StartID string
+}
+
 // Repository :
 type Repository interface {
 	Name() string
@@ -19,12 +60,19 @@ type Repository interface {
 	ACLTokenRepository
 	ACLPolicyRepository
 
+	NamespaceRepository
+
 	NodeRepository
+	JoinTokenRepository
 
 	NetworkRepository
 	InterfaceRepository
 	ConnectionRepository
 
+	AuditRepository
+
+	ServerRepository
+
 	ACLState(ctx context.Context) (*structs.ACLState, error)
 	ACLSetState(ctx context.Context, state *structs.ACLState) error
 }
@@ -32,6 +80,7 @@ type Repository interface {
 // ACLTokenRepository : ACLToken repository interface
 type ACLTokenRepository interface {
 	ACLTokens(ctx context.Context) ([]*structs.ACLToken, error)
+	ACLTokensPage(ctx context.Context, opts ListOptions) ([]*structs.ACLToken, string, error)
 	ACLTokenByID(ctx context.Context, id string) (*structs.ACLToken, error)
 	ACLTokenBySecret(ctx context.Context, id string) (*structs.ACLToken, error)
 	UpsertACLToken(ctx context.Context, t *structs.ACLToken) error
@@ -41,14 +90,25 @@ type ACLTokenRepository interface {
 // ACLPolicyRepository : Policy repository interface
 type ACLPolicyRepository interface {
 	ACLPolicies(ctx context.Context) ([]*structs.ACLPolicy, error)
+	ACLPoliciesPage(ctx context.Context, opts ListOptions) ([]*structs.ACLPolicy, string, error)
 	ACLPolicyByName(ctx context.Context, name string) (*structs.ACLPolicy, error)
 	UpsertACLPolicy(ctx context.Context, p *structs.ACLPolicy) error
 	DeleteACLPolicies(ctx context.Context, names []string) error
 }
 
+// NamespaceRepository : Namespace repository interface
+type NamespaceRepository interface {
+	Namespaces(ctx context.Context) ([]*structs.Namespace, error)
+	NamespacesPage(ctx context.Context, opts ListOptions) ([]*structs.Namespace, string, error)
+	NamespaceByName(ctx context.Context, name string) (*structs.Namespace, error)
+	UpsertNamespace(ctx context.Context, n *structs.Namespace) error
+	DeleteNamespaces(ctx context.Context, names []string) error
+}
+
 // NetworkRepository : Network repository interface
 type NetworkRepository interface {
 	Networks(ctx context.Context) ([]*structs.Network, error)
+	NetworksPage(ctx context.Context, opts ListOptions) ([]*structs.Network, string, error)
 	NetworkByID(ctx context.Context, id string) (*structs.Network, error)
 	NetworkByName(ctx context.Context, name string) (*structs.Network, error)
 	UpsertNetwork(ctx context.Context, n *structs.Network) error
@@ -58,15 +118,43 @@ type NetworkRepository interface {
 // NodeRepository : Node repository interface
 type NodeRepository interface {
 	Nodes(ctx context.Context) ([]*structs.Node, error)
+	NodesPage(ctx context.Context, opts ListOptions) ([]*structs.Node, string, error)
 	NodeByID(ctx context.Context, id string) (*structs.Node, error)
 	NodeBySecretID(ctx context.Context, sid string) (*structs.Node, error)
 	UpsertNode(ctx context.Context, n *structs.Node) error
 	DeleteNodes(ctx context.Context, ids []string) error
 }
 
+// AuditRepository : AuditEntry repository interface. Entries are
+// never updated, and are only deleted once they expire.
+type AuditRepository interface {
+	AuditEntries(ctx context.Context) ([]*structs.AuditEntry, error)
+	UpsertAuditEntry(ctx context.Context, e *structs.AuditEntry) error
+	DeleteAuditEntries(ctx context.Context, ids []string) error
+}
+
+// ServerRepository : Server repository interface. Servers are
+// keyed by the RPC address they advertise to client nodes.
+type ServerRepository interface {
+	Servers(ctx context.Context) ([]*structs.Server, error)
+	UpsertServer(ctx context.Context, s *structs.Server) error
+	DeleteServers(ctx context.Context, addresses []string) error
+}
+
+// JoinTokenRepository : JoinToken repository interface
+type JoinTokenRepository interface {
+	JoinTokens(ctx context.Context) ([]*structs.JoinToken, error)
+	JoinTokensPage(ctx context.Context, opts ListOptions) ([]*structs.JoinToken, string, error)
+	JoinTokenByID(ctx context.Context, id string) (*structs.JoinToken, error)
+	JoinTokenBySecret(ctx context.Context, secret string) (*structs.JoinToken, error)
+	UpsertJoinToken(ctx context.Context, t *structs.JoinToken) error
+	DeleteJoinTokens(ctx context.Context, ids []string) error
+}
+
 // InterfaceRepository : Interface repository interface
 type InterfaceRepository interface {
 	Interfaces(ctx context.Context) ([]*structs.Interface, error)
+	InterfacesPage(ctx context.Context, opts ListOptions) ([]*structs.Interface, string, error)
 	InterfacesByNodeID(ctx context.Context, s string) ([]*structs.Interface, error)
 	InterfacesByNetworkID(ctx context.Context, s string) ([]*structs.Interface, error)
 	InterfaceByID(ctx context.Context, id string) (*structs.Interface, error)
@@ -77,6 +165,7 @@ type InterfaceRepository interface {
 // ConnectionRepository : Connection repository interface
 type ConnectionRepository interface {
 	Connections(ctx context.Context) ([]*structs.Connection, error)
+	ConnectionsPage(ctx context.Context, opts ListOptions) ([]*structs.Connection, string, error)
 	ConnectionsByNetworkID(ctx context.Context, s string) ([]*structs.Connection, error)
 	ConnectionsByNodeID(ctx context.Context, s string) ([]*structs.Connection, error)
 	ConnectionsByInterfaceID(ctx context.Context, s string) ([]*structs.Connection, error)
@@ -11,6 +11,7 @@ type ACLPolicy struct {
 	Rules       []*ACLPolicyRule
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	ModifyIndex uint64
 }
 
 func (p *ACLPolicy) Validate() error {
@@ -54,8 +55,14 @@ type ACLPolicyListStub struct {
 
 // ACLPolicyRule ...
 type ACLPolicyRule struct {
-	Resource     string
-	Path         string
+	Resource string
+	Path     string
+
+	// Namespace is a glob pattern matching the namespaces in which the rule
+	// applies, for resources which belong to namespaces. If empty, the rule
+	// only applies in the default namespace.
+	Namespace string
+
 	Capabilities []string
 }
 
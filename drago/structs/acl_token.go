@@ -15,13 +15,14 @@ const (
 
 // ACLToken :
 type ACLToken struct {
-	ID        string
-	Type      string
-	Name      string
-	Secret    string
-	Policies  []string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          string
+	Type        string
+	Name        string
+	Secret      string
+	Policies    []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModifyIndex uint64
 }
 
 func (t *ACLToken) Validate() error {
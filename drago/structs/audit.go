@@ -0,0 +1,66 @@
+package structs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records a write operation performed through the API, along with
+// the token used for performing it and the changes it made to the target resource.
+// Secrets are never recorded, neither from the token nor from the resource.
+type AuditEntry struct {
+	ID        string
+	Timestamp time.Time
+
+	// TokenID and TokenName identify the ACL token used for the operation.
+	TokenID   string
+	TokenName string
+
+	// Namespace is the namespace targeted by the operation. Operations on
+	// resources which do not belong to namespaces, such as ACL tokens, are
+	// recorded in the default namespace.
+	Namespace string
+
+	// Operation is the name of the RPC method, e.g. "Network.UpsertNetwork".
+	Operation string
+
+	ResourceType string
+	ResourceID   string
+
+	// Changes contains the fields of the resource modified by the
+	// operation, indexed by name. It is empty if the operation failed.
+	Changes map[string]*AuditChange
+
+	// Error contains the error returned by the operation, if any.
+	Error string
+
+	ModifyIndex uint64
+}
+
+// AuditChange contains the JSON-encoded values of a resource field before
+// and after an operation. Before is empty if the resource was created, and
+// After is empty if it was deleted.
+type AuditChange struct {
+	Before json.RawMessage
+	After  json.RawMessage
+}
+
+// AuditListRequest :
+type AuditListRequest struct {
+	ResourceType string
+	ResourceID   string
+	TokenID      string
+	Operation    string
+
+	// Since filters out entries older than the time passed.
+	Since time.Time
+
+	QueryOptions
+}
+
+// AuditListResponse :
+type AuditListResponse struct {
+	Items []*AuditEntry
+
+	Response
+}
@@ -1,13 +1,29 @@
 package structs
 
 import (
+	"fmt"
 	"sort"
 	"time"
 )
 
+const (
+	// ConnectionStateEstablished indicates that a handshake completed recently.
+	ConnectionStateEstablished = "established"
+	// ConnectionStateStale indicates that no handshake completed recently.
+	ConnectionStateStale = "stale"
+	// ConnectionStateNever indicates that no handshake ever completed.
+	ConnectionStateNever = "never"
+
+	// ConnectionStaleAfter is the time after the last handshake at which a
+	// connection is considered stale. WireGuard renews sessions every two
+	// minutes while traffic flows, and rejects sessions older than three.
+	ConnectionStaleAfter = 3 * time.Minute
+)
+
 // Connection :
 type Connection struct {
 	ID        string
+	Namespace string
 	NetworkID string
 
 	// PeerSettings contains the ID and the configurations to be applied
@@ -20,8 +36,24 @@ type Connection struct {
 	// connection table.
 	PersistentKeepalive *int
 
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	// Managed indicates whether the connection was created by the server
+	// according to the network topology, in which case it is automatically
+	// updated and removed as interfaces join and leave the network.
+	Managed bool
+
+	// PresharedKey is a symmetric key generated by the server, which is mixed
+	// into the handshake of the tunnel for post-quantum resistance. It is only
+	// sent to the two nodes on the connection, and never returned in stubs.
+	PresharedKey *string
+
+	// DisablePresharedKey indicates that the connection carries no preshared
+	// key, e.g. for peers which do not support them. It can only be set when
+	// the connection is created.
+	DisablePresharedKey bool
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModifyIndex uint64
 }
 
 // Validate :
@@ -133,6 +165,14 @@ func (c *Connection) Merge(in *Connection) *Connection {
 	return &result
 }
 
+// Sanitize returns a copy of the connection without the preshared key,
+// so that it can be returned to users.
+func (c *Connection) Sanitize() *Connection {
+	result := *c
+	result.PresharedKey = nil
+	return &result
+}
+
 // Stub :
 func (c *Connection) Stub() *ConnectionListStub {
 
@@ -143,11 +183,14 @@ func (c *Connection) Stub() *ConnectionListStub {
 
 	return &ConnectionListStub{
 		ID:                  c.ID,
+		Namespace:           NamespaceOrDefault(c.Namespace),
 		NetworkID:           c.NetworkID,
 		Peers:               peers,
 		PeerSettings:        c.PeerSettings,
 		PersistentKeepalive: c.PersistentKeepalive,
+		Managed:             c.Managed,
 		BytesTransferred:    0,
+		State:               ConnectionStateNever,
 		CreatedAt:           c.CreatedAt,
 		UpdatedAt:           c.UpdatedAt,
 	}
@@ -156,16 +199,47 @@ func (c *Connection) Stub() *ConnectionListStub {
 // ConnectionListStub :
 type ConnectionListStub struct {
 	ID                  string
+	Namespace           string
 	NetworkID           string
 	NodeIDs             []string
 	Peers               []string
 	PeerSettings        []*PeerSettings
 	PersistentKeepalive *int
+	Managed             bool
 	BytesTransferred    uint64
+	LastHandshake       time.Time
+	State               string
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
 
+// SetPeerStats derives the traffic and state of the connection from the
+// statistics reported by the nodes on each end. Since both ends count the
+// same traffic, the highest count is used, as a node may lag in reporting.
+func (c *ConnectionListStub) SetPeerStats(stats []*PeerStats, now time.Time) {
+
+	for _, s := range stats {
+		if s == nil || s.ConnectionID != c.ID {
+			continue
+		}
+		if n := uint64(s.ReceiveBytes + s.TransmitBytes); n > c.BytesTransferred {
+			c.BytesTransferred = n
+		}
+		if s.LastHandshake.After(c.LastHandshake) {
+			c.LastHandshake = s.LastHandshake
+		}
+	}
+
+	switch {
+	case c.LastHandshake.IsZero():
+		c.State = ConnectionStateNever
+	case now.Sub(c.LastHandshake) < ConnectionStaleAfter:
+		c.State = ConnectionStateEstablished
+	default:
+		c.State = ConnectionStateStale
+	}
+}
+
 // PeerSettings :
 type PeerSettings struct {
 	NodeID       string
@@ -227,6 +301,13 @@ type ConnectionUpsertRequest struct {
 	WriteRequest
 }
 
+// ConnectionRotatePresharedKeyRequest :
+type ConnectionRotatePresharedKeyRequest struct {
+	ConnectionID string
+
+	WriteRequest
+}
+
 // ConnectionDeleteRequest :
 type ConnectionDeleteRequest struct {
 	ConnectionIDs []string
@@ -249,3 +330,22 @@ type ConnectionListResponse struct {
 
 	Response
 }
+
+// AllowedIPsConflict describes two connections of an interface whose AllowedIPs
+// overlap. If both prefixes are identical, WireGuard silently routes traffic to them
+// through only one of the peers. Otherwise, the most specific prefix takes precedence,
+// which may be intended, e.g. for routing a whole range through a gateway peer.
+type AllowedIPsConflict struct {
+	InterfaceID             string
+	ConnectionID            string
+	AllowedIP               string
+	ConflictingConnectionID string
+	ConflictingAllowedIP    string
+	Identical               bool
+}
+
+// String :
+func (c *AllowedIPsConflict) String() string {
+	return fmt.Sprintf("AllowedIP %s of connection %s overlaps with %s of connection %s on interface %s",
+		c.AllowedIP, c.ConnectionID, c.ConflictingAllowedIP, c.ConflictingConnectionID, c.InterfaceID)
+}
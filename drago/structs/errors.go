@@ -14,6 +14,7 @@ const (
 	errInvalidInput           = "Invalid input"
 	errNotFound               = "Resource not found"
 	errInternal               = "Internal error"
+	errConflict               = "Conflict"
 )
 
 var (
@@ -34,6 +35,9 @@ var (
 
 	// ErrNotFound ...
 	ErrNotFound = errors.New(errNotFound)
+
+	// ErrConflict ...
+	ErrConflict = errors.New(errConflict)
 )
 
 // Error :
@@ -63,3 +67,7 @@ func NewInternalError(msg string) error {
 func NewInvalidInputError(msg string) error {
 	return NewError(ErrInvalidInput, msg)
 }
+
+func NewConflictError(msg string) error {
+	return NewError(ErrConflict, msg)
+}
@@ -0,0 +1,107 @@
+package structs
+
+import (
+	"time"
+)
+
+const (
+	EventTopicNode       = "node"
+	EventTopicNetwork    = "network"
+	EventTopicInterface  = "interface"
+	EventTopicConnection = "connection"
+	EventTopicACLToken   = "token"
+	EventTopicACLPolicy  = "policy"
+)
+
+const (
+	EventTypeNodeRegistered = "NodeRegistered"
+	EventTypeNodeUpdated    = "NodeUpdated"
+	EventTypeNodeDown       = "NodeDown"
+	EventTypeNodeDeleted    = "NodeDeleted"
+
+	EventTypeNetworkCreated = "NetworkCreated"
+	EventTypeNetworkUpdated = "NetworkUpdated"
+	EventTypeNetworkDeleted = "NetworkDeleted"
+
+	EventTypeInterfaceCreated = "InterfaceCreated"
+	EventTypeInterfaceUpdated = "InterfaceUpdated"
+	EventTypeInterfaceDeleted = "InterfaceDeleted"
+
+	EventTypeConnectionCreated = "ConnectionCreated"
+	EventTypeConnectionUpdated = "ConnectionUpdated"
+	EventTypeConnectionDeleted = "ConnectionDeleted"
+
+	EventTypeACLTokenCreated = "ACLTokenCreated"
+	EventTypeACLTokenUpdated = "ACLTokenUpdated"
+	EventTypeACLTokenDeleted = "ACLTokenDeleted"
+
+	EventTypeACLPolicyCreated = "ACLPolicyCreated"
+	EventTypeACLPolicyUpdated = "ACLPolicyUpdated"
+	EventTypeACLPolicyDeleted = "ACLPolicyDeleted"
+)
+
+// EventTopics returns all valid event topics.
+func EventTopics() []string {
+	return []string{
+		EventTopicNode,
+		EventTopicNetwork,
+		EventTopicInterface,
+		EventTopicConnection,
+		EventTopicACLToken,
+		EventTopicACLPolicy,
+	}
+}
+
+// Event represents a change to the state of the Drago server.
+type Event struct {
+	// Index is a monotonically increasing number identifying the event.
+	Index uint64
+
+	// Topic is the type of resource affected by the event (e.g. node).
+	Topic string
+
+	// Type describes the change (e.g. NodeRegistered).
+	Type string
+
+	// Key is the ID (or name, for ACL policies) of the affected resource.
+	Key string
+
+	// Namespace is the namespace of the affected resource, or empty
+	// for resources which do not belong to namespaces, such as ACL tokens.
+	Namespace string `json:",omitempty"`
+
+	Timestamp time.Time
+
+	// Payload contains a representation of the resource after the change or,
+	// for deletions, before it. Secrets are never included in payloads.
+	Payload *EventPayload
+}
+
+// EventPayload :
+type EventPayload struct {
+	Node       *NodeListStub       `json:",omitempty"`
+	Network    *NetworkListStub    `json:",omitempty"`
+	Interface  *InterfaceListStub  `json:",omitempty"`
+	Connection *ConnectionListStub `json:",omitempty"`
+	ACLToken   *ACLTokenListStub   `json:",omitempty"`
+	ACLPolicy  *ACLPolicyListStub  `json:",omitempty"`
+}
+
+// EventListRequest :
+type EventListRequest struct {
+	// Topics used for filtering events. If empty, events
+	// of all topics the token has access to are returned.
+	Topics []string
+
+	// Keys used for filtering events by resource ID.
+	Keys []string
+
+	QueryOptions
+}
+
+// EventListResponse :
+type EventListResponse struct {
+	Items []*Event
+
+	Response
+}
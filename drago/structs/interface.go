@@ -6,6 +6,7 @@ import (
 
 type Interface struct {
 	ID          string
+	Namespace   string
 	NodeID      string
 	NetworkID   string
 	Name        *string
@@ -16,6 +17,40 @@ type Interface struct {
 	Connections []string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	ModifyIndex uint64
+
+	// PendingPublicKey is the public key of the next key pair generated by the
+	// node for this interface. Nodes publish it before switching to the new key,
+	// and only switch once it is acknowledged by the server, after which the
+	// public key is updated and distributed to peers.
+	PendingPublicKey *string
+
+	// RotateKey indicates whether the node was requested to rotate the
+	// key pair of this interface, regardless of the age of its key.
+	RotateKey bool
+
+	// PeerStats contains live statistics of the peers of this interface,
+	// periodically reported by the node. They are not part of the
+	// configuration sent to nodes.
+	PeerStats []*PeerStats
+
+	// External indicates that the interface belongs to an external peer, i.e. a
+	// device which does not run the Drago agent, such as a phone or a router.
+	// External interfaces have no node, their key pair is generated by the
+	// server, and their configuration is exported in the wg-quick format.
+	External bool
+
+	// PrivateKey is only set for external interfaces, and is never returned
+	// except as part of their exported configuration.
+	PrivateKey *string
+
+	// DNS contains the DNS servers set in the configuration of external interfaces.
+	DNS []string
+
+	// Endpoint is the static endpoint (host:port) of an external interface, if it
+	// has one, e.g. a router with a public address. External interfaces without
+	// an endpoint can only initiate tunnels themselves.
+	Endpoint *string
 
 	// Underlying struct for efficiently adding/removing connections.
 	// Always use the lazyConnectionsMap() method for accessing it.
@@ -51,10 +86,23 @@ func (i *Interface) Merge(in *Interface) *Interface {
 	if in.Peers != nil {
 		result.Peers = in.Peers
 	}
+	if in.DNS != nil {
+		result.DNS = in.DNS
+	}
+	if in.Endpoint != nil {
+		result.Endpoint = in.Endpoint
+	}
 
 	return &result
 }
 
+// Sanitize returns a copy of the interface without its private key.
+func (i *Interface) Sanitize() *Interface {
+	c := *i
+	c.PrivateKey = nil
+	return &c
+}
+
 // Validate : validate interface fields
 func (i *Interface) Validate() error {
 	return nil
@@ -100,6 +148,7 @@ func (i *Interface) RemoveConnection(id string) {
 func (i *Interface) Stub() *InterfaceListStub {
 	return &InterfaceListStub{
 		ID:               i.ID,
+		Namespace:        NamespaceOrDefault(i.Namespace),
 		Name:             i.Name,
 		Address:          i.Address,
 		ListenPort:       i.ListenPort,
@@ -108,6 +157,7 @@ func (i *Interface) Stub() *InterfaceListStub {
 		ConnectionsCount: len(i.Connections),
 		PublicKey:        i.PublicKey,
 		HasPublicKey:     i.PublicKey != nil,
+		External:         i.External,
 		CreatedAt:        i.CreatedAt,
 		UpdatedAt:        i.UpdatedAt,
 	}
@@ -116,6 +166,7 @@ func (i *Interface) Stub() *InterfaceListStub {
 // InterfaceListStub :
 type InterfaceListStub struct {
 	ID               string
+	Namespace        string
 	NodeID           string
 	NetworkID        string
 	Name             *string
@@ -124,6 +175,7 @@ type InterfaceListStub struct {
 	ConnectionsCount int
 	PublicKey        *string
 	HasPublicKey     bool
+	External         bool
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
@@ -156,6 +208,15 @@ type InterfaceDeleteRequest struct {
 	WriteRequest
 }
 
+// InterfaceRotateKeysRequest : requests the rotation of the key pairs of all interfaces
+// of a node or of a network.
+type InterfaceRotateKeysRequest struct {
+	NodeID    string
+	NetworkID string
+
+	WriteRequest
+}
+
 // InterfaceListRequest :
 type InterfaceListRequest struct {
 	NodeID    string
@@ -171,10 +232,29 @@ type InterfaceListResponse struct {
 	Response
 }
 
+// PeerStats contains live statistics of a peer, read from WireGuard by the node.
+type PeerStats struct {
+	ConnectionID string
+	PublicKey    string
+
+	// Endpoint is the address the peer was last seen at, if any.
+	Endpoint string
+
+	// LastHandshake is zero if no handshake ever completed.
+	LastHandshake time.Time
+
+	ReceiveBytes  int64
+	TransmitBytes int64
+}
+
 type Peer struct {
-	PublicKey           *string
+	// ConnectionID is the ID of the connection the peer is configured from.
+	ConnectionID *string
+	PublicKey    *string
+	// Address is the IP address or hostname the peer is reachable at, if any.
 	Address             *string
 	Port                *int
 	AllowedIPs          []string
 	PersistentKeepalive *int
+	PresharedKey        *string
 }
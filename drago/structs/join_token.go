@@ -0,0 +1,135 @@
+package structs
+
+import (
+	"fmt"
+	"time"
+)
+
+// JoinToken is used for authorizing the registration of new nodes. Tokens can be
+// used a limited number of times, and may be bound to networks which nodes join
+// automatically, and to metadata which is added to nodes.
+type JoinToken struct {
+	ID   string
+	Name string
+
+	// Namespace is the namespace of the networks the token joins, and
+	// the one in which nodes registering with the token are created.
+	Namespace string
+
+	Secret   string
+	MaxUses  int
+	Uses     int
+	Networks []string
+	Meta     map[string]string
+
+	// ExpiresAt is the time after which the token can't be
+	// used anymore. Tokens with a zero value never expire.
+	ExpiresAt time.Time
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModifyIndex uint64
+}
+
+// Validate validates a structs.JoinToken object
+func (t *JoinToken) Validate() error {
+
+	if t.MaxUses < 0 {
+		return fmt.Errorf("invalid maximum number of uses %d", t.MaxUses)
+	}
+
+	return nil
+}
+
+// IsUsable returns true if the token can still be used to register
+// a node, i.e. if it did not expire nor reach its maximum number of uses.
+// Tokens with a maximum number of uses equal to zero can be used indefinitely.
+func (t *JoinToken) IsUsable(now time.Time) bool {
+
+	if !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt) {
+		return false
+	}
+
+	if t.MaxUses > 0 && t.Uses >= t.MaxUses {
+		return false
+	}
+
+	return true
+}
+
+// Stub :
+func (t *JoinToken) Stub() *JoinTokenListStub {
+	return &JoinTokenListStub{
+		ID:        t.ID,
+		Name:      t.Name,
+		Namespace: NamespaceOrDefault(t.Namespace),
+		MaxUses:   t.MaxUses,
+		Uses:      t.Uses,
+		Networks:  t.Networks,
+		Meta:      t.Meta,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// JoinTokenListStub :
+type JoinTokenListStub struct {
+	ID        string
+	Name      string
+	Namespace string
+	MaxUses   int
+	Uses      int
+	Networks  []string
+	Meta      map[string]string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// JoinTokenListRequest :
+type JoinTokenListRequest struct {
+	QueryOptions
+}
+
+// JoinTokenListResponse :
+type JoinTokenListResponse struct {
+	Items []*JoinTokenListStub
+
+	Response
+}
+
+// JoinTokenSpecificRequest :
+type JoinTokenSpecificRequest struct {
+	JoinTokenID string
+
+	QueryOptions
+}
+
+// SingleJoinTokenResponse :
+type SingleJoinTokenResponse struct {
+	JoinToken *JoinToken
+
+	Response
+}
+
+// JoinTokenCreateRequest :
+type JoinTokenCreateRequest struct {
+	JoinToken *JoinToken
+
+	WriteRequest
+}
+
+// JoinTokenCreateResponse :
+type JoinTokenCreateResponse struct {
+	JoinToken *JoinToken
+
+	Response
+}
+
+// JoinTokenDeleteRequest :
+type JoinTokenDeleteRequest struct {
+	JoinTokenIDs []string
+
+	WriteRequest
+}
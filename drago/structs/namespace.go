@@ -0,0 +1,118 @@
+package structs
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	// DefaultNamespace is the namespace of requests which do not specify one,
+	// and of resources created before namespaces were introduced. It always
+	// exists, and can't be deleted.
+	DefaultNamespace = "default"
+)
+
+var validNamespaceName = regexp.MustCompile("^[a-zA-Z0-9-_]{1,128}$")
+
+// Namespace isolates networks, nodes, interfaces and connections, along with the
+// join tokens used for registering nodes, from those in other namespaces. Requests
+// only ever see and modify the resources in the namespace they target.
+type Namespace struct {
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModifyIndex uint64
+}
+
+// Validate :
+func (n *Namespace) Validate() error {
+	if !validNamespaceName.MatchString(n.Name) {
+		return fmt.Errorf("Invalid name, must only contain alphanumeric characters, dashes and underscores")
+	}
+	return nil
+}
+
+// Merge :
+func (n *Namespace) Merge(in *Namespace) *Namespace {
+
+	result := *n
+
+	if in.Description != "" {
+		result.Description = in.Description
+	}
+
+	return &result
+}
+
+// Stub :
+func (n *Namespace) Stub() *NamespaceListStub {
+	return &NamespaceListStub{
+		Name:        n.Name,
+		Description: n.Description,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+	}
+}
+
+// NamespaceListStub :
+type NamespaceListStub struct {
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NamespaceOrDefault returns the namespace passed, or DefaultNamespace if it is empty,
+// as is the case for requests not specifying one, and resources created before
+// namespaces were introduced.
+func NamespaceOrDefault(ns string) string {
+	if ns == "" {
+		return DefaultNamespace
+	}
+	return ns
+}
+
+// NamespaceSpecificRequest :
+type NamespaceSpecificRequest struct {
+	// Name contains the name of the namespace to be retrieved.
+	Name string
+
+	QueryOptions
+}
+
+// SingleNamespaceResponse :
+type SingleNamespaceResponse struct {
+	Namespace *Namespace
+
+	Response
+}
+
+// NamespaceUpsertRequest :
+type NamespaceUpsertRequest struct {
+	Namespace *Namespace
+
+	WriteRequest
+}
+
+// NamespaceDeleteRequest :
+type NamespaceDeleteRequest struct {
+	// Names contains the names of the namespaces to be deleted.
+	Names []string
+
+	WriteRequest
+}
+
+// NamespaceListRequest :
+type NamespaceListRequest struct {
+	QueryOptions
+}
+
+// NamespaceListResponse :
+type NamespaceListResponse struct {
+	Response
+
+	// Items contains the namespaces found.
+	Items []*NamespaceListStub
+}
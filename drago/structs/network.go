@@ -7,15 +7,33 @@ import (
 	"time"
 )
 
+const (
+	// NetworkTopologyManual means that connections between interfaces
+	// in the network are created and removed by users.
+	NetworkTopologyManual = "manual"
+
+	// NetworkTopologyMesh means that the server automatically connects
+	// every interface in the network to every other interface.
+	NetworkTopologyMesh = "mesh"
+
+	// NetworkTopologyHubAndSpoke means that the server automatically connects
+	// interfaces of nodes marked as hubs to every other interface in the network,
+	// with spokes routing the whole network address range through a hub.
+	NetworkTopologyHubAndSpoke = "hub-and-spoke"
+)
+
 // Network :
 type Network struct {
 	ID           string
+	Namespace    string
 	Name         string
 	AddressRange string
+	Topology     string
 	Interfaces   []string
 	Connections  []string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	ModifyIndex  uint64
 
 	// Underlying structs for efficiently adding/removing interfaces and connections.
 	// Always use the lazyInterfacesMap() and lazyConnectionsMap() methods for accessing them.
@@ -31,9 +49,25 @@ func (n *Network) Validate() error {
 	if n.AddressRange == "" {
 		return fmt.Errorf("Address range is empty")
 	}
+	if _, _, err := net.ParseCIDR(n.AddressRange); err != nil {
+		return fmt.Errorf("Invalid address range")
+	}
+	if n.Topology != "" && !IsValidNetworkTopology(n.Topology) {
+		return fmt.Errorf("Invalid topology")
+	}
 	return nil
 }
 
+// IsValidNetworkTopology returns true if the topology passed as argument
+// corresponds to a valid network topology. Otherwise returns false.
+func IsValidNetworkTopology(s string) bool {
+	switch s {
+	case NetworkTopologyManual, NetworkTopologyMesh, NetworkTopologyHubAndSpoke:
+		return true
+	}
+	return false
+}
+
 // CheckAddressInRange : Check whether an IP address in CIDR notation
 // is within the allowed range of the network.
 func (n *Network) CheckAddressInRange(ip string) error {
@@ -137,6 +171,9 @@ func (n *Network) Merge(in *Network) *Network {
 	if in.AddressRange != "" {
 		result.AddressRange = in.AddressRange
 	}
+	if in.Topology != "" {
+		result.Topology = in.Topology
+	}
 
 	return &result
 }
@@ -145,8 +182,10 @@ func (n *Network) Merge(in *Network) *Network {
 func (n *Network) Stub() *NetworkListStub {
 	return &NetworkListStub{
 		ID:               n.ID,
+		Namespace:        NamespaceOrDefault(n.Namespace),
 		Name:             n.Name,
 		AddressRange:     n.AddressRange,
+		Topology:         n.Topology,
 		InterfacesCount:  len(n.Interfaces),
 		ConnectionsCount: len(n.Connections),
 		CreatedAt:        n.CreatedAt,
@@ -157,8 +196,10 @@ func (n *Network) Stub() *NetworkListStub {
 // NetworkListStub :
 type NetworkListStub struct {
 	ID               string
+	Namespace        string
 	Name             string
 	AddressRange     string
+	Topology         string
 	InterfacesCount  int
 	ConnectionsCount int
 	CreatedAt        time.Time
@@ -204,3 +245,72 @@ type NetworkListResponse struct {
 
 	Response
 }
+
+// NetworkValidateResponse :
+type NetworkValidateResponse struct {
+	Conflicts []*AllowedIPsConflict
+
+	Response
+}
+
+const (
+	// NetworkImportActionCreate indicates that a resource is created by an import.
+	NetworkImportActionCreate = "create"
+	// NetworkImportActionUpdate indicates that an existing resource is updated by an import.
+	NetworkImportActionUpdate = "update"
+	// NetworkImportActionMatch indicates that an existing resource is left as is.
+	NetworkImportActionMatch = "match"
+)
+
+// NetworkImportFile is a configuration file in the wg-quick format.
+type NetworkImportFile struct {
+	Name    string
+	Content string
+}
+
+// NetworkImportRequest : requests the import of wg-quick configuration files into a
+// network. If DryRun is set, the plan is computed and returned, but not applied.
+type NetworkImportRequest struct {
+	NetworkID string
+	Files     []*NetworkImportFile
+	DryRun    bool
+
+	WriteRequest
+}
+
+// NetworkImportResponse :
+type NetworkImportResponse struct {
+	Plan *NetworkImportPlan
+
+	Response
+}
+
+// NetworkImportPlan describes the changes made by an import.
+type NetworkImportPlan struct {
+	Interfaces  []*NetworkImportInterface
+	Connections []*NetworkImportConnection
+}
+
+// NetworkImportInterface is an interface created or matched by an import. Interfaces
+// are matched by public key, and created as external peers if no match is found.
+type NetworkImportInterface struct {
+	Action      string
+	InterfaceID string
+	NodeID      string
+	PublicKey   string
+	Address     string
+	Endpoint    string
+
+	// File is the name of the file holding the configuration of the
+	// interface itself, if any, whose private key is then imported.
+	File string
+}
+
+// NetworkImportConnection is a connection created or updated by an import.
+type NetworkImportConnection struct {
+	Action              string
+	ConnectionID        string
+	PublicKeys          []string
+	AllowedIPs          [][]string
+	PersistentKeepalive int
+}
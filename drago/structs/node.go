@@ -9,20 +9,51 @@ const (
 	NodeStatusInit  = "initializing"
 	NodeStatusReady = "ready"
 	NodeStatusDown  = "down"
+
+	NodeSchedulingEligible   = "eligible"
+	NodeSchedulingIneligible = "ineligible"
+
+	// NodeMetaHub is the metadata key used for marking a node as a hub
+	// in networks with a hub-and-spoke topology (e.g. hub=true).
+	NodeMetaHub = "hub"
+
+	// maxNodeStatusHistory is the maximum number of status
+	// transitions kept in the status history of a node.
+	maxNodeStatusHistory = 10
 )
 
 // Node :
 type Node struct {
 	ID               string
 	SecretID         string
+	Namespace        string
 	Name             string
 	AdvertiseAddress string
 	Status           string
+	StatusUpdatedAt  time.Time
+	StatusHistory    []*NodeStatusTransition
 	Interfaces       []string
 	Connections      []string
 	Meta             map[string]string
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	ModifyIndex      uint64
+
+	// SchedulingEligibility indicates whether the node can be
+	// automatically connected to other nodes by the server.
+	SchedulingEligibility string
+
+	// Drain indicates that the node is being taken out of service, in which
+	// case it is ineligible and its peers are removed from other nodes.
+	Drain bool
+
+	// ServerMeta is the metadata set by operators through join tokens or
+	// preregistration, which takes precedence over the metadata reported
+	// by the node itself.
+	ServerMeta map[string]string
+
+	// JoinTokenID is the ID of the join token used for registering the node.
+	JoinTokenID string
 
 	// Underlying struct for efficiently adding/removing interfaces and connections.
 	// Always use the lazyInterfacesMap() and lazyConnectionsMap() methods for accessing them.
@@ -30,6 +61,12 @@ type Node struct {
 	connectionsMap map[string]struct{}
 }
 
+// NodeStatusTransition records a change in the status of a node.
+type NodeStatusTransition struct {
+	Status    string
+	Timestamp time.Time
+}
+
 // Validate validates a structs.Node object
 func (n *Node) Validate() error {
 
@@ -40,6 +77,39 @@ func (n *Node) Validate() error {
 	return nil
 }
 
+// SetMeta sets the metadata of the node, overriding
+// the keys set by operators in the node's ServerMeta.
+func (n *Node) SetMeta(meta map[string]string) {
+
+	result := map[string]string{}
+	for k, v := range meta {
+		result[k] = v
+	}
+	for k, v := range n.ServerMeta {
+		result[k] = v
+	}
+
+	n.Meta = result
+}
+
+// IsHub returns true if the node is marked as a hub through its metadata.
+func (n *Node) IsHub() bool {
+	v, ok := n.Meta[NodeMetaHub]
+	return ok && (v == "true" || v == "1")
+}
+
+// IsEligible returns true if the node can be automatically connected to
+// other nodes. Nodes registered before eligibility was tracked are eligible.
+func (n *Node) IsEligible() bool {
+	return !n.Drain && n.SchedulingEligibility != NodeSchedulingIneligible
+}
+
+// IsValidNodeSchedulingEligibility returns true if the eligibility passed
+// as argument is valid. Otherwise returns false.
+func IsValidNodeSchedulingEligibility(s string) bool {
+	return s == NodeSchedulingEligible || s == NodeSchedulingIneligible
+}
+
 // IsValidNodeStatus returns true if the status passed as argument
 // corresponds to a valid node status. Otherwise returns false.
 func IsValidNodeStatus(s string) bool {
@@ -84,6 +154,36 @@ func (n *Node) Merge(in *Node) *Node {
 	return &result
 }
 
+// SetStatus sets the status of the node. If the status changed, the time of the
+// change is recorded, and a transition is appended to the node's status history,
+// which is bounded so that only the most recent transitions are kept.
+func (n *Node) SetStatus(status string, t time.Time) {
+
+	if n.Status == status && len(n.StatusHistory) > 0 {
+		return
+	}
+
+	n.Status = status
+	n.StatusUpdatedAt = t
+
+	history := append([]*NodeStatusTransition{}, n.StatusHistory...)
+	history = append(history, &NodeStatusTransition{Status: status, Timestamp: t})
+	if len(history) > maxNodeStatusHistory {
+		history = history[len(history)-maxNodeStatusHistory:]
+	}
+
+	n.StatusHistory = history
+}
+
+// DownSince returns the time at which the node went down. For nodes registered
+// before status changes were tracked, the time of the last update is returned.
+func (n *Node) DownSince() time.Time {
+	if n.StatusUpdatedAt.IsZero() {
+		return n.UpdatedAt
+	}
+	return n.StatusUpdatedAt
+}
+
 // If the node's interfacesMap was already initialized, return it.
 // Otherwise initialize and synchronize it with the node interfaces slice.
 func (n *Node) lazyInterfacesMap() map[string]struct{} {
@@ -160,9 +260,12 @@ func (n *Node) RemoveConnection(id string) {
 func (n *Node) Stub() *NodeListStub {
 	return &NodeListStub{
 		ID:               n.ID,
+		Namespace:        NamespaceOrDefault(n.Namespace),
 		Name:             n.Name,
 		AdvertiseAddress: n.AdvertiseAddress,
 		Status:           n.Status,
+		Eligibility:      n.SchedulingEligibility,
+		Drain:            n.Drain,
 		InterfacesCount:  len(n.Interfaces),
 		ConnectionsCount: len(n.Connections),
 		Meta:             n.Meta,
@@ -174,9 +277,12 @@ func (n *Node) Stub() *NodeListStub {
 // NodeListStub :
 type NodeListStub struct {
 	ID               string
+	Namespace        string
 	Name             string
 	AdvertiseAddress string
 	Status           string
+	Eligibility      string
+	Drain            bool
 	InterfacesCount  int
 	ConnectionsCount int
 	Meta             map[string]string
@@ -217,6 +323,10 @@ type NodePreregisterResponse struct {
 type NodeRegisterRequest struct {
 	Node *Node
 
+	// JoinToken is the secret of a join token, which is
+	// required for registering nodes unknown to the server.
+	JoinToken string
+
 	WriteRequest
 }
 
@@ -248,10 +358,32 @@ type NodeUpdateStatusRequest struct {
 	WriteRequest
 }
 
+// NodeUpdateEligibilityRequest is used for updating
+// the scheduling eligibility of a node.
+type NodeUpdateEligibilityRequest struct {
+	NodeID      string
+	Eligibility string
+
+	WriteRequest
+}
+
+// NodeUpdateDrainRequest is used for enabling or disabling the drain of a node.
+// Disabling the drain also makes the node eligible again.
+type NodeUpdateDrainRequest struct {
+	NodeID string
+	Drain  bool
+
+	WriteRequest
+}
+
 // NodeUpdateResponse is used to update nodes
 type NodeUpdateResponse struct {
 	Servers []string
 
+	// HeartbeatTTL is the interval at which the node
+	// is expected to send heartbeats to the servers.
+	HeartbeatTTL time.Duration
+
 	Response
 }
 
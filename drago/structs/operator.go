@@ -0,0 +1,38 @@
+package structs
+
+import "time"
+
+// SnapshotSaveRequest :
+type SnapshotSaveRequest struct {
+	QueryOptions
+}
+
+// SnapshotSaveResponse :
+type SnapshotSaveResponse struct {
+	// Snapshot contains the snapshot archive.
+	Snapshot []byte
+
+	Response
+}
+
+// SnapshotRestoreRequest :
+type SnapshotRestoreRequest struct {
+	// Snapshot contains the snapshot archive to be restored.
+	Snapshot []byte
+
+	WriteRequest
+}
+
+// SnapshotRestoreResponse :
+type SnapshotRestoreResponse struct {
+	// CreatedAt is the time at which the restored snapshot was saved.
+	CreatedAt time.Time
+
+	Response
+}
+
+// MetricsAuthorizeRequest is used for checking whether
+// a token is allowed to read the metrics of agents.
+type MetricsAuthorizeRequest struct {
+	QueryOptions
+}
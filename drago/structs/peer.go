@@ -0,0 +1,44 @@
+package structs
+
+// ExternalPeerCreateRequest : requests the creation of an external peer in a
+// network, optionally connecting it to the interfaces passed in ConnectTo.
+type ExternalPeerCreateRequest struct {
+	NetworkID string
+	Name      string
+
+	// Address is allocated from the network address range if not set.
+	Address  *string
+	DNS      []string
+	Endpoint *string
+
+	// ConnectTo contains the IDs of the interfaces the peer is connected to.
+	ConnectTo []string
+
+	// PersistentKeepalive is set on the connections created. Since
+	// external peers are often behind a NAT, it defaults to 25 seconds.
+	PersistentKeepalive *int
+
+	WriteRequest
+}
+
+// ExternalPeerCreateResponse :
+type ExternalPeerCreateResponse struct {
+	InterfaceID string
+
+	Response
+}
+
+// ExternalPeerConfigRequest :
+type ExternalPeerConfigRequest struct {
+	InterfaceID string
+
+	QueryOptions
+}
+
+// ExternalPeerConfigResponse :
+type ExternalPeerConfigResponse struct {
+	// Config contains the configuration of the peer in the wg-quick format.
+	Config string
+
+	Response
+}
@@ -0,0 +1,17 @@
+package structs
+
+import (
+	"time"
+)
+
+// Server is a Drago server, as recorded in the state shared by all servers,
+// which allows them to tell client nodes about each other.
+type Server struct {
+	// Address is the RPC address advertised by the server to client nodes.
+	Address string
+
+	// UpdatedAt is the time at which the server last refreshed its entry.
+	UpdatedAt time.Time
+
+	ModifyIndex uint64
+}
@@ -2,6 +2,7 @@ package structs
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/seashell/drago/pkg/validator"
@@ -24,15 +25,66 @@ func (f Filters) Add(k, v string) {
 type QueryOptions struct {
 	AuthToken string
 	Filters   Filters
+
+	// Namespace is the namespace targeted by the request. If
+	// empty, the request targets the default namespace.
+	Namespace string
+
+	// MinQueryIndex is used for performing blocking queries. If set,
+	// the request blocks until the result changes from the one that
+	// index was returned with, or until MaxQueryTime is reached.
+	MinQueryIndex uint64
+
+	// MaxQueryTime is the maximum amount of time a blocking query
+	// is allowed to wait for changes.
+	MaxQueryTime time.Duration
+
+	// PerPage is the maximum number of items returned by list requests.
+	// If zero, all items are returned.
+	PerPage int
+
+	// NextToken is the token returned by the previous list request,
+	// used for retrieving the next page of items.
+	NextToken string
+
+	// Sort is the name of the field by which the items returned by list
+	// requests are sorted, and Order is either "asc" or "desc".
+	Sort  string
+	Order string
+
+	// Filter is an expression, such as `Status == "ready"`, which the items
+	// returned by list requests must match. See the filter package for details.
+	Filter string
+}
+
+// RequestNamespace returns the namespace targeted by the request.
+func (q QueryOptions) RequestNamespace() string {
+	return NamespaceOrDefault(q.Namespace)
 }
 
 // WriteRequest contains information that is common to all write requests.
 type WriteRequest struct {
 	AuthToken string
+
+	// Namespace is the namespace targeted by the request. If
+	// empty, the request targets the default namespace.
+	Namespace string
+}
+
+// RequestNamespace returns the namespace targeted by the request.
+func (w WriteRequest) RequestNamespace() string {
+	return NamespaceOrDefault(w.Namespace)
 }
 
 // Response contains information that is common to all responses.
 type Response struct {
+	// Index is the index of the result, which can be used
+	// as MinQueryIndex in subsequent blocking queries.
+	Index uint64
+
+	// NextToken is returned by list requests when there are more items
+	// than requested, and can be passed in the next request to retrieve them.
+	NextToken string
 }
 
 // GenericRequest is used to request where no
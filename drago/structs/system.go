@@ -0,0 +1,14 @@
+package structs
+
+// SystemGCRequest :
+type SystemGCRequest struct {
+	QueryOptions
+}
+
+// SystemGCResponse :
+type SystemGCResponse struct {
+	// NodeIDs contains the IDs of the nodes removed by the garbage collector.
+	NodeIDs []string
+
+	Response
+}
@@ -0,0 +1,167 @@
+package drago
+
+import (
+	"context"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	log "github.com/seashell/drago/pkg/log"
+)
+
+// SystemService is used for performing system-wide operations,
+// such as garbage collection.
+type SystemService struct {
+	config      *Config
+	logger      log.Logger
+	state       state.Repository
+	auditor     *Auditor
+	nodes       *NodeService
+	authHandler auth.AuthorizationHandler
+}
+
+// NewSystemService ...
+func NewSystemService(config *Config, logger log.Logger, state state.Repository, auditor *Auditor, nodes *NodeService, authHandler auth.AuthorizationHandler) *SystemService {
+	return &SystemService{
+		config:      config,
+		logger:      logger,
+		state:       state,
+		auditor:     auditor,
+		nodes:       nodes,
+		authHandler: authHandler,
+	}
+}
+
+// GarbageCollect removes nodes in the namespace of the request which have been down
+// for longer than the configured threshold, along with their interfaces and connections.
+func (s *SystemService) GarbageCollect(args *structs.SystemGCRequest, out *structs.SystemGCResponse) error {
+
+	ctx := context.TODO()
+	ns := args.RequestNamespace()
+
+	// Check if authorized
+	if s.config.ACL.Enabled {
+		if err := s.authHandler.Authorize(ctx, args.AuthToken, "node", namespacePath(ns, ""), NodeWrite); err != nil {
+			return structs.ErrPermissionDenied
+		}
+	}
+
+	ids, err := s.garbageCollectNodes(ctx, ns, func(ns string, ids func() []string) func(*error) {
+		return s.auditor.Begin(ctx, "System.GarbageCollect", args.AuthToken, ns, "node", ids)
+	})
+	if err != nil {
+		return structs.NewInternalError(err.Error())
+	}
+
+	out.NodeIDs = ids
+
+	return nil
+}
+
+// runGarbageCollection removes nodes in all namespaces which have been down for longer
+// than the configured threshold. It is called periodically by the server, and the nodes
+// removed are audited as such, since no ACL token is involved.
+func (s *SystemService) runGarbageCollection(ctx context.Context) ([]string, error) {
+	return s.garbageCollectNodes(ctx, "", func(ns string, ids func() []string) func(*error) {
+		return s.auditor.BeginServer(ctx, "System.GarbageCollect", ns, "node", ids)
+	})
+}
+
+// garbageCollectNodes removes nodes in namespace ns, or in all namespaces if ns is empty,
+// which have been down for longer than the configured threshold, along with their interfaces
+// and connections, releasing the addresses allocated to them. The removal of each node is
+// recorded through the audit function passed, along with its namespace, and its heartbeat timer is stopped. The topology
+// of the networks affected is reconciled afterwards. It returns the IDs of the nodes removed.
+func (s *SystemService) garbageCollectNodes(ctx context.Context, ns string, audit func(ns string, ids func() []string) func(*error)) ([]string, error) {
+
+	repo, logger := s.state, s.logger
+
+	nodes, err := repo.Nodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-s.config.HostGCThreshold)
+
+	removed := []string{}
+	networkIDs := map[string]struct{}{}
+
+	for _, node := range nodes {
+
+		if ns != "" && !inNamespace(node.Namespace, ns) {
+			continue
+		}
+
+		if node.Status != structs.NodeStatusDown || node.DownSince().After(cutoff) {
+			continue
+		}
+
+		logger.Debugf("garbage collecting node %s, down since %s", node.ID, node.DownSince())
+
+		deleted, err := s.garbageCollectNode(ctx, node, networkIDs, audit)
+		if err != nil {
+			return removed, err
+		}
+
+		if deleted {
+			s.nodes.stopHeartbeatTimer(node.ID)
+			removed = append(removed, node.ID)
+		}
+	}
+
+	for id := range networkIDs {
+		if err := reconcileNetworkTopology(ctx, repo, id); err != nil {
+			logger.Warnf("error reconciling topology of network %s: %v", id, err)
+		}
+	}
+
+	return removed, nil
+}
+
+// garbageCollectNode removes a node along with its interfaces and connections, adding the
+// networks affected to networkIDs. The removal is only audited if the node was actually
+// removed, or if it failed, as nodes which came back up in the meantime are left untouched.
+func (s *SystemService) garbageCollectNode(ctx context.Context, node *structs.Node, networkIDs map[string]struct{}, audit func(ns string, ids func() []string) func(*error)) (deleted bool, err error) {
+
+	repo := s.state
+
+	done := audit(structs.NamespaceOrDefault(node.Namespace), auditID(&node.ID))
+	defer func() {
+		if deleted || err != nil {
+			done(&err)
+		}
+	}()
+
+	interfaces, err := repo.InterfacesByNodeID(ctx, node.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, iface := range interfaces {
+		if err := deleteInterface(ctx, repo, iface); err != nil {
+			return false, err
+		}
+		networkIDs[iface.NetworkID] = struct{}{}
+	}
+
+	// Remove any connections still referencing the node, as well as the
+	// node itself, unless it came back up in the meantime.
+	if err := deleteConnections(ctx, repo, node.Connections); err != nil {
+		return false, err
+	}
+
+	err = withTransaction(ctx, repo, func(ctx context.Context) error {
+		n, err := repo.NodeByID(ctx, node.ID)
+		if err != nil || n.Status != structs.NodeStatusDown {
+			return nil
+		}
+		if err := repo.DeleteNodes(ctx, []string{node.ID}); err != nil {
+			return structs.NewInternalError(err.Error())
+		}
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
@@ -0,0 +1,93 @@
+package drago
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	events "github.com/seashell/drago/drago/events"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+func TestGarbageCollectNodes(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+	config := testConfig()
+	logger := testLogger(t)
+
+	network := testNetwork(t, repo, "net", "10.0.0.0/30", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "old", "10.0.0.1/30", false)
+	testNode(t, repo, "net", "recent", "10.0.0.2/30", false)
+	testNode(t, repo, "net", "ready", "10.0.0.3/30", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	// Only nodes down for longer than the threshold are collected
+	testNodeDown(t, repo, "old", time.Now().Add(-2*config.HostGCThreshold))
+	testNodeDown(t, repo, "recent", time.Now().Add(-config.HostGCThreshold/2))
+
+	auditor, err := NewAuditor(config, logger, repo)
+	if err != nil {
+		t.Fatalf("NewAuditor() failed: %v", err)
+	}
+
+	nodes, err := NewNodeService(config, logger, repo, auditor, events.NewBroker(events.DefaultBufferSize), nil)
+	if err != nil {
+		t.Fatalf("NewNodeService() failed: %v", err)
+	}
+
+	s := NewSystemService(config, logger, repo, auditor, nodes, nil)
+
+	ids, err := s.runGarbageCollection(ctx)
+	if err != nil {
+		t.Fatalf("s.runGarbageCollection() failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "old" {
+		t.Fatalf("expected only node old to be collected, got %v", ids)
+	}
+
+	for _, id := range []string{"recent", "ready"} {
+		if _, err := repo.NodeByID(ctx, id); err != nil {
+			t.Fatalf("expected node %s to be kept", id)
+		}
+	}
+	if _, err := repo.NodeByID(ctx, "old"); err == nil {
+		t.Fatalf("expected node old to be removed")
+	}
+	if _, err := repo.InterfaceByID(ctx, "old-iface"); err == nil {
+		t.Fatalf("expected interface of node old to be removed")
+	}
+	if connections, _ := repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 1 {
+		t.Fatalf("expected only the connection between the remaining nodes to be kept, got %d", len(connections))
+	}
+
+	// The heartbeat timer of the node removed is stopped
+	nodes.heartbeatTimersLock.Lock()
+	_, tracked := nodes.heartbeatTimers["old"]
+	nodes.heartbeatTimersLock.Unlock()
+	if tracked {
+		t.Fatalf("expected heartbeat timer of node old to be stopped")
+	}
+
+	// The address of the node removed is released
+	network, _ = repo.NetworkByID(ctx, network.ID)
+	iface := &structs.Interface{ID: "new-iface", NetworkID: network.ID}
+	if err := assignInterfaceAddress(ctx, repo, network, iface); err != nil {
+		t.Fatalf("assignInterfaceAddress() failed: %v", err)
+	}
+	if *iface.Address != "10.0.0.1/30" {
+		t.Fatalf("expected released address 10.0.0.1/30 to be allocated, got %s", *iface.Address)
+	}
+
+	// The removal is audited as performed by the server
+	entries, _ := repo.AuditEntries(ctx)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Operation != "System.GarbageCollect" || e.ResourceID != "old" || e.TokenName != serverAuditTokenName {
+		t.Fatalf("unexpected audit entry %+v", e)
+	}
+}
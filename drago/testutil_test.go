@@ -0,0 +1,177 @@
+package drago
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auth "github.com/seashell/drago/drago/auth"
+	events "github.com/seashell/drago/drago/events"
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+	config "github.com/seashell/drago/drago/structs/config"
+	log "github.com/seashell/drago/pkg/log"
+	simple "github.com/seashell/drago/pkg/log/simple"
+)
+
+// testConfig returns a server configuration suitable for
+// exercising services in tests, with ACLs disabled.
+func testConfig() *Config {
+	return &Config{
+		ACL:             &config.ACLConfig{},
+		HeartbeatTTL:    time.Hour,
+		HostGCThreshold: time.Hour,
+	}
+}
+
+func testLogger(t *testing.T) log.Logger {
+	logger, err := simple.NewLoggerAdapter(simple.Config{})
+	if err != nil {
+		t.Fatalf("simple.NewLoggerAdapter() failed: %v", err)
+	}
+	return logger
+}
+
+// testACL enables ACLs in the configuration passed, and returns an authorization handler
+// resolving tokens and policies from the repository, in which the default policies are
+// stored.
+func testACL(t *testing.T, repo *inmem.StateRepository, config *Config) auth.AuthorizationHandler {
+
+	s := &Server{config: config, state: repo}
+
+	if err := s.setupACLModel(); err != nil {
+		t.Fatalf("s.setupACLModel() failed: %v", err)
+	}
+	config.ACL.Enabled = true
+
+	for _, p := range s.defaultACLPolicies() {
+		if err := repo.UpsertACLPolicy(context.Background(), p); err != nil {
+			t.Fatalf("repo.UpsertACLPolicy() failed: %v", err)
+		}
+	}
+
+	return auth.NewAuthorizationHandler(config.ACL.Model, s.secretResolver(), s.policyResolver())
+}
+
+// testACLToken stores a client token with a policy made of the rules
+// passed, and returns its secret.
+func testACLToken(t *testing.T, repo *inmem.StateRepository, name string, rules ...*structs.ACLPolicyRule) string {
+
+	ctx := context.Background()
+
+	if err := repo.UpsertACLPolicy(ctx, &structs.ACLPolicy{Name: name, Rules: rules}); err != nil {
+		t.Fatalf("repo.UpsertACLPolicy() failed: %v", err)
+	}
+
+	token := &structs.ACLToken{
+		ID:       name,
+		Type:     structs.ACLTokenTypeClient,
+		Name:     name,
+		Secret:   name + "-secret",
+		Policies: []string{name},
+	}
+	if err := repo.UpsertACLToken(ctx, token); err != nil {
+		t.Fatalf("repo.UpsertACLToken() failed: %v", err)
+	}
+
+	return token.Secret
+}
+
+// testNodeService returns a node service over the repository passed, without auditing.
+func testNodeService(t *testing.T, repo *inmem.StateRepository, config *Config, authHandler auth.AuthorizationHandler) *NodeService {
+	s, err := NewNodeService(config, testLogger(t), repo, nil, events.NewBroker(events.DefaultBufferSize), authHandler)
+	if err != nil {
+		t.Fatalf("NewNodeService() failed: %v", err)
+	}
+	return s
+}
+
+func testState() *inmem.StateRepository {
+	return inmem.NewStateRepository(nil)
+}
+
+func testStrPtr(s string) *string {
+	return &s
+}
+
+// testNetwork stores a network with the given topology and returns it.
+func testNetwork(t *testing.T, repo *inmem.StateRepository, id, addressRange, topology string) *structs.Network {
+	n := &structs.Network{
+		ID:           id,
+		Namespace:    structs.DefaultNamespace,
+		Name:         id,
+		AddressRange: addressRange,
+		Topology:     topology,
+	}
+	if err := repo.UpsertNetwork(context.Background(), n); err != nil {
+		t.Fatalf("repo.UpsertNetwork() failed: %v", err)
+	}
+	return n
+}
+
+// testNode stores a node with an interface with the given address in a
+// network, and returns the interface. The node is marked as a hub if hub
+// is true.
+func testNode(t *testing.T, repo *inmem.StateRepository, networkID, id, address string, hub bool) *structs.Interface {
+
+	ctx := context.Background()
+
+	iface := &structs.Interface{
+		ID:        id + "-iface",
+		Namespace: structs.DefaultNamespace,
+		NodeID:    id,
+		NetworkID: networkID,
+		Name:      testStrPtr("wg0"),
+		Address:   testStrPtr(address),
+		PublicKey: testStrPtr(id + "-key"),
+	}
+
+	node := &structs.Node{
+		ID:                    id,
+		Namespace:             structs.DefaultNamespace,
+		Name:                  id,
+		SecretID:              id + "-secret",
+		Status:                structs.NodeStatusReady,
+		SchedulingEligibility: structs.NodeSchedulingEligible,
+		Meta:                  map[string]string{},
+	}
+	if hub {
+		node.Meta[structs.NodeMetaHub] = "true"
+	}
+	node.UpsertInterface(iface.ID)
+
+	network, err := repo.NetworkByID(ctx, networkID)
+	if err != nil {
+		t.Fatalf("repo.NetworkByID() failed: %v", err)
+	}
+	network.UpsertInterface(iface.ID)
+
+	if err := repo.UpsertNode(ctx, node); err != nil {
+		t.Fatalf("repo.UpsertNode() failed: %v", err)
+	}
+	if err := repo.UpsertInterface(ctx, iface); err != nil {
+		t.Fatalf("repo.UpsertInterface() failed: %v", err)
+	}
+	if err := repo.UpsertNetwork(ctx, network); err != nil {
+		t.Fatalf("repo.UpsertNetwork() failed: %v", err)
+	}
+
+	return iface
+}
+
+// testNodeDown marks a node as down since the time passed.
+func testNodeDown(t *testing.T, repo *inmem.StateRepository, id string, since time.Time) {
+
+	ctx := context.Background()
+
+	node, err := repo.NodeByID(ctx, id)
+	if err != nil {
+		t.Fatalf("repo.NodeByID() failed: %v", err)
+	}
+
+	node.SetStatus(structs.NodeStatusDown, since)
+
+	if err := repo.UpsertNode(ctx, node); err != nil {
+		t.Fatalf("repo.UpsertNode() failed: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package drago
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+	uuid "github.com/seashell/drago/pkg/uuid"
+)
+
+const (
+	// defaultHubKeepalive is the persistent keepalive interval, in seconds, set on
+	// connections between hubs and spokes, as spokes are usually behind a NAT.
+	defaultHubKeepalive = 25
+)
+
+// topologyLock serializes topology reconciliations, so that concurrent
+// requests do not end up creating duplicate connections.
+var topologyLock sync.Mutex
+
+// reconcileNetworkTopology creates, updates and removes the connections managed by the
+// server within a network, so that they match the network topology. Connections created
+// by users are never modified, and interfaces already connected by them are skipped.
+// Nodes which are ineligible keep their connections, but no new ones are created for them.
+// Each connection is written in a separate transaction, and the whole reconciliation
+// is retried if any of them conflicts with a concurrent modification.
+func reconcileNetworkTopology(ctx context.Context, repo state.Repository, networkID string) error {
+
+	topologyLock.Lock()
+	defer topologyLock.Unlock()
+
+	return retryOnConflict(func() error {
+		return reconcileNetworkTopologyOnce(ctx, repo, networkID)
+	})
+}
+
+func reconcileNetworkTopologyOnce(ctx context.Context, repo state.Repository, networkID string) error {
+
+	network, err := repo.NetworkByID(ctx, networkID)
+	if err != nil {
+		return err
+	}
+
+	all, err := repo.InterfacesByNetworkID(ctx, network.ID)
+	if err != nil {
+		return err
+	}
+
+	// External peers are never part of the topology, and
+	// are only connected to the interfaces chosen by users.
+	interfaces := []*structs.Interface{}
+	for _, iface := range all {
+		if !iface.External {
+			interfaces = append(interfaces, iface)
+		}
+	}
+
+	connections, err := repo.ConnectionsByNetworkID(ctx, network.ID)
+	if err != nil {
+		return err
+	}
+
+	nodes := map[string]*structs.Node{}
+	for _, iface := range interfaces {
+		if node, err := repo.NodeByID(ctx, iface.NodeID); err == nil {
+			nodes[iface.NodeID] = node
+		}
+	}
+
+	var desired map[string]*structs.Connection
+
+	switch network.Topology {
+	case structs.NetworkTopologyMesh:
+		desired = meshConnections(interfaces)
+	case structs.NetworkTopologyHubAndSpoke:
+		hubs, spokes := []*structs.Interface{}, []*structs.Interface{}
+		for _, iface := range interfaces {
+			if node, ok := nodes[iface.NodeID]; ok && node.IsHub() {
+				hubs = append(hubs, iface)
+				continue
+			}
+			spokes = append(spokes, iface)
+		}
+		desired = hubAndSpokeConnections(network, hubs, spokes)
+	default:
+		// Networks with a manual topology have no managed connections.
+		// Connections created while the network had some other topology
+		// are left untouched, and can be removed by users.
+		return nil
+	}
+
+	existing := map[string]*structs.Connection{}
+	for _, c := range connections {
+		existing[connectionKey(c.ConnectedInterfaceIDs()...)] = c
+	}
+
+	// Remove managed connections which are not part of the topology anymore
+	obsolete := []string{}
+	for k, c := range existing {
+		if _, ok := desired[k]; !ok && c.Managed {
+			obsolete = append(obsolete, c.ID)
+		}
+	}
+	if len(obsolete) > 0 {
+		if err := deleteConnections(ctx, repo, obsolete); err != nil {
+			return err
+		}
+	}
+
+	// Create missing connections, and update the managed ones whose settings changed
+	for _, k := range sortedKeys(desired) {
+
+		c := desired[k]
+
+		if old, ok := existing[k]; ok {
+			if !old.Managed || !managedSettingsChanged(old, c) {
+				continue
+			}
+			c.ID = old.ID
+			c.CreatedAt = old.CreatedAt
+			c.PresharedKey = old.PresharedKey
+		} else {
+			if !eligibleConnection(c, nodes) {
+				continue
+			}
+			c.ID = uuid.Generate()
+			c.CreatedAt = time.Now()
+		}
+
+		err := withTransaction(ctx, repo, func(ctx context.Context) error {
+			return upsertConnection(ctx, repo, c)
+		})
+		if err != nil {
+			if isConflict(err) {
+				return err
+			}
+			return fmt.Errorf("error upserting connection between interfaces %s: %v", k, err)
+		}
+	}
+
+	return nil
+}
+
+// eligibleConnection checks whether all nodes connected by a connection are
+// eligible, in which case the connection can be created automatically.
+func eligibleConnection(c *structs.Connection, nodes map[string]*structs.Node) bool {
+	for _, p := range c.PeerSettings {
+		if node, ok := nodes[p.NodeID]; !ok || !node.IsEligible() {
+			return false
+		}
+	}
+	return true
+}
+
+// meshConnections returns the connections of a full-mesh topology, in which every
+// interface is connected to every other interface, routing each peer's address.
+func meshConnections(interfaces []*structs.Interface) map[string]*structs.Connection {
+
+	out := map[string]*structs.Connection{}
+
+	for i, a := range interfaces {
+		for _, b := range interfaces[i+1:] {
+			out[connectionKey(a.ID, b.ID)] = newManagedConnection(a, hostPrefixes(b), b, hostPrefixes(a), nil)
+		}
+	}
+
+	return out
+}
+
+// hubAndSpokeConnections returns the connections of a hub-and-spoke topology, in which hubs
+// are connected to each other and to every spoke. On the hub side, each spoke's address is
+// routed through the spoke, whereas spokes route the whole network address range through
+// the first hub (ordered by interface ID). Additional hubs are routed only by their address,
+// which is contained in the range routed through the first hub. The overlap is intended, as
+// WireGuard picks the most specific prefix, so that traffic to each hub goes straight to it.
+func hubAndSpokeConnections(network *structs.Network, hubs, spokes []*structs.Interface) map[string]*structs.Connection {
+
+	out := map[string]*structs.Connection{}
+
+	if len(hubs) == 0 {
+		return out
+	}
+
+	sort.Slice(hubs, func(i, j int) bool { return hubs[i].ID < hubs[j].ID })
+
+	keepalive := defaultHubKeepalive
+
+	for i, a := range hubs {
+		for _, b := range hubs[i+1:] {
+			out[connectionKey(a.ID, b.ID)] = newManagedConnection(a, hostPrefixes(b), b, hostPrefixes(a), nil)
+		}
+	}
+
+	for _, spoke := range spokes {
+		for i, hub := range hubs {
+			routes := hostPrefixes(hub)
+			if i == 0 {
+				routes = []string{network.AddressRange}
+			}
+			out[connectionKey(hub.ID, spoke.ID)] = newManagedConnection(hub, hostPrefixes(spoke), spoke, routes, &keepalive)
+		}
+	}
+
+	return out
+}
+
+// newManagedConnection returns a connection between the interfaces a and b, in which traffic
+// to the addresses in aAllowedIPs is routed by a through b, and vice-versa.
+func newManagedConnection(a *structs.Interface, aAllowedIPs []string, b *structs.Interface, bAllowedIPs []string, keepalive *int) *structs.Connection {
+	return &structs.Connection{
+		NetworkID: a.NetworkID,
+		PeerSettings: []*structs.PeerSettings{
+			{
+				NodeID:       a.NodeID,
+				InterfaceID:  a.ID,
+				RoutingRules: &structs.RoutingRules{AllowedIPs: aAllowedIPs},
+			},
+			{
+				NodeID:       b.NodeID,
+				InterfaceID:  b.ID,
+				RoutingRules: &structs.RoutingRules{AllowedIPs: bAllowedIPs},
+			},
+		},
+		PersistentKeepalive: keepalive,
+		Managed:             true,
+	}
+}
+
+// managedSettingsChanged checks whether the settings managed by the topology
+// differ between an existing connection and the desired one.
+func managedSettingsChanged(old, desired *structs.Connection) bool {
+
+	if !reflect.DeepEqual(old.PersistentKeepalive, desired.PersistentKeepalive) {
+		return true
+	}
+
+	for _, p := range desired.PeerSettings {
+		s := old.PeerSettingsByInterfaceID(p.InterfaceID)
+		if s == nil || s.RoutingRules == nil {
+			return true
+		}
+		if !reflect.DeepEqual(s.RoutingRules.AllowedIPs, p.RoutingRules.AllowedIPs) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostPrefixes returns the address of an interface as a host prefix
+// (i.e. /32 for IPv4 and /128 for IPv6), which can be used in AllowedIPs.
+func hostPrefixes(iface *structs.Interface) []string {
+
+	if iface.Address == nil {
+		return []string{}
+	}
+
+	ip, _, err := net.ParseCIDR(*iface.Address)
+	if err != nil {
+		if ip = net.ParseIP(*iface.Address); ip == nil {
+			return []string{}
+		}
+	}
+
+	if ip.To4() != nil {
+		return []string{ip.String() + "/32"}
+	}
+
+	return []string{ip.String() + "/128"}
+}
+
+func connectionKey(ids ...string) string {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ":")
+}
+
+func sortedKeys(m map[string]*structs.Connection) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,190 @@
+package drago
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	inmem "github.com/seashell/drago/drago/state/inmem"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+// allowedIPs returns the AllowedIPs routed by interface a through interface b.
+func allowedIPs(t *testing.T, repo *inmem.StateRepository, a, b *structs.Interface) []string {
+
+	c, err := repo.ConnectionByInterfaceIDs(context.Background(), a.ID, b.ID)
+	if err != nil {
+		t.Fatalf("repo.ConnectionByInterfaceIDs(%s, %s) failed: %v", a.ID, b.ID, err)
+	}
+	if !c.Managed {
+		t.Fatalf("expected connection between %s and %s to be managed", a.ID, b.ID)
+	}
+
+	return c.PeerSettingsByInterfaceID(a.ID).RoutingRules.AllowedIPs
+}
+
+func TestReconcileMeshTopology(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	a := testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	b := testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+	c := testNode(t, repo, "net", "c", "10.0.0.3/24", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	connections, err := repo.ConnectionsByNetworkID(ctx, "net")
+	if err != nil {
+		t.Fatalf("repo.ConnectionsByNetworkID() failed: %v", err)
+	}
+	if len(connections) != 3 {
+		t.Fatalf("expected 3 connections, got %d", len(connections))
+	}
+
+	tests := []struct {
+		from, to *structs.Interface
+		want     []string
+	}{
+		{a, b, []string{"10.0.0.2/32"}},
+		{b, a, []string{"10.0.0.1/32"}},
+		{a, c, []string{"10.0.0.3/32"}},
+		{c, b, []string{"10.0.0.2/32"}},
+	}
+	for _, tt := range tests {
+		if got := allowedIPs(t, repo, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllowedIPs of %s through %s = %v, want %v", tt.from.ID, tt.to.ID, got, tt.want)
+		}
+	}
+
+	// Reconciling again must not create duplicate connections
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+	if connections, _ = repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 3 {
+		t.Fatalf("expected 3 connections after reconciling again, got %d", len(connections))
+	}
+}
+
+func TestReconcileMeshTopologyIneligibleNode(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	testNode(t, repo, "net", "a", "10.0.0.1/24", false)
+	testNode(t, repo, "net", "b", "10.0.0.2/24", false)
+	c := testNode(t, repo, "net", "c", "10.0.0.3/24", false)
+
+	node, _ := repo.NodeByID(ctx, c.NodeID)
+	node.SchedulingEligibility = structs.NodeSchedulingIneligible
+	repo.UpsertNode(ctx, node)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	if connections, _ := repo.ConnectionsByInterfaceID(ctx, c.ID); len(connections) != 0 {
+		t.Fatalf("expected no connections to ineligible node, got %d", len(connections))
+	}
+	if connections, _ := repo.ConnectionsByNetworkID(ctx, "net"); len(connections) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(connections))
+	}
+}
+
+func TestReconcileHubAndSpokeTopology(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyHubAndSpoke)
+	hub1 := testNode(t, repo, "net", "hub1", "10.0.0.1/24", true)
+	hub2 := testNode(t, repo, "net", "hub2", "10.0.0.2/24", true)
+	spoke1 := testNode(t, repo, "net", "spoke1", "10.0.0.10/24", false)
+	spoke2 := testNode(t, repo, "net", "spoke2", "10.0.0.11/24", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	connections, err := repo.ConnectionsByNetworkID(ctx, "net")
+	if err != nil {
+		t.Fatalf("repo.ConnectionsByNetworkID() failed: %v", err)
+	}
+	// One connection between the hubs, and one between each hub and each spoke
+	if len(connections) != 5 {
+		t.Fatalf("expected 5 connections, got %d", len(connections))
+	}
+
+	if _, err := repo.ConnectionByInterfaceIDs(ctx, spoke1.ID, spoke2.ID); err == nil {
+		t.Fatalf("expected spokes not to be connected to each other")
+	}
+
+	tests := []struct {
+		from, to *structs.Interface
+		want     []string
+	}{
+		{hub1, hub2, []string{"10.0.0.2/32"}},
+		{hub2, hub1, []string{"10.0.0.1/32"}},
+		{hub1, spoke1, []string{"10.0.0.10/32"}},
+		{hub2, spoke2, []string{"10.0.0.11/32"}},
+		{spoke1, hub1, []string{"10.0.0.0/24"}},
+		{spoke1, hub2, []string{"10.0.0.2/32"}},
+		{spoke2, hub1, []string{"10.0.0.0/24"}},
+		{spoke2, hub2, []string{"10.0.0.2/32"}},
+	}
+	for _, tt := range tests {
+		if got := allowedIPs(t, repo, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllowedIPs of %s through %s = %v, want %v", tt.from.ID, tt.to.ID, got, tt.want)
+		}
+	}
+
+	c, _ := repo.ConnectionByInterfaceIDs(ctx, hub1.ID, spoke1.ID)
+	if c.PersistentKeepalive == nil || *c.PersistentKeepalive != defaultHubKeepalive {
+		t.Errorf("expected keepalive of %d on hub connections, got %v", defaultHubKeepalive, c.PersistentKeepalive)
+	}
+
+	// The overlap between the routes to both hubs is created on
+	// purpose, and must not be reported as a conflict.
+	s := NewNetworkService(testConfig(), testLogger(t), repo, nil, nil)
+	out := &structs.NetworkValidateResponse{}
+	if err := s.ValidateNetwork(&structs.NetworkSpecificRequest{NetworkID: "net"}, out); err != nil {
+		t.Fatalf("s.ValidateNetwork() failed: %v", err)
+	}
+	if len(out.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %d: %v", len(out.Conflicts), out.Conflicts[0])
+	}
+}
+
+func TestReconcileTopologyChange(t *testing.T) {
+
+	repo := testState()
+	ctx := context.Background()
+
+	network := testNetwork(t, repo, "net", "10.0.0.0/24", structs.NetworkTopologyMesh)
+	hub := testNode(t, repo, "net", "hub", "10.0.0.1/24", true)
+	spoke1 := testNode(t, repo, "net", "spoke1", "10.0.0.10/24", false)
+	spoke2 := testNode(t, repo, "net", "spoke2", "10.0.0.11/24", false)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	network, _ = repo.NetworkByID(ctx, network.ID)
+	network.Topology = structs.NetworkTopologyHubAndSpoke
+	repo.UpsertNetwork(ctx, network)
+
+	if err := reconcileNetworkTopology(ctx, repo, "net"); err != nil {
+		t.Fatalf("reconcileNetworkTopology() failed: %v", err)
+	}
+
+	if _, err := repo.ConnectionByInterfaceIDs(ctx, spoke1.ID, spoke2.ID); err == nil {
+		t.Fatalf("expected connection between spokes to be removed")
+	}
+	if got := allowedIPs(t, repo, spoke1, hub); !reflect.DeepEqual(got, []string{"10.0.0.0/24"}) {
+		t.Fatalf("expected connection to hub to be updated, got AllowedIPs %v", got)
+	}
+}
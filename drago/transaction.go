@@ -0,0 +1,63 @@
+package drago
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	state "github.com/seashell/drago/drago/state"
+	structs "github.com/seashell/drago/drago/structs"
+)
+
+const (
+	// maxConflictRetries is the number of times operations
+	// are retried when failing due to concurrent modifications.
+	maxConflictRetries = 5
+)
+
+// withTransaction calls fn with a context carrying a new transaction, so that all
+// writes made by fn to the repository are committed atomically once it returns. If
+// the context already carries a transaction, fn simply joins it, and the writes are
+// committed along with the ones made by the caller.
+func withTransaction(ctx context.Context, repo state.Repository, fn func(ctx context.Context) error) error {
+
+	if _, ok := state.TransactionFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	txn := repo.Transaction(ctx)
+
+	if err := fn(state.WithTransaction(ctx, txn)); err != nil {
+		return err
+	}
+
+	if _, err := txn.Commit(); err != nil {
+		if errors.Is(err, state.ErrConflict) {
+			return structs.NewConflictError("Resource modified concurrently, please retry")
+		}
+		return structs.NewInternalError(err.Error())
+	}
+
+	return nil
+}
+
+// retryOnConflict calls fn until it does not fail due to a concurrent modification
+// of the resources it writes, up to maxConflictRetries times. This is meant for
+// operations which are not directly requested by users, and thus can be safely
+// retried with a fresh view of the state.
+func retryOnConflict(fn func() error) error {
+	var err error
+	for i := 0; i < maxConflictRetries; i++ {
+		if err = fn(); !isConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func isConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, state.ErrConflict) || strings.HasPrefix(err.Error(), structs.ErrConflict.Error())
+}
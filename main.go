@@ -98,44 +98,73 @@ func setupCLI() *cli.CLI {
 	cli := cli.New(&cli.Config{
 		Name: "drago",
 		Commands: map[string]cli.Command{
-			"agent":                   &command.AgentCommand{UI: ui, StaticFS: uifs},
-			"agent-info":              &command.AgentInfoCommand{UI: ui},
-			"acl":                     &command.ACLCommand{UI: ui},
-			"acl bootstrap":           &command.ACLBootstrapCommand{UI: ui},
-			"acl token":               &command.ACLTokenCommand{UI: ui},
-			"acl token create":        &command.ACLTokenCreateCommand{UI: ui},
-			"acl token delete":        &command.ACLTokenDeleteCommand{UI: ui},
-			"acl token info":          &command.ACLTokenInfoCommand{UI: ui},
-			"acl token list":          &command.ACLTokenListCommand{UI: ui},
-			"acl token self":          &command.ACLTokenSelfCommand{UI: ui},
-			"acl token update":        &command.ACLTokenUpdateCommand{UI: ui},
-			"acl policy":              &command.ACLPolicyCommand{UI: ui},
-			"acl policy apply":        &command.ACLPolicyApplyCommand{UI: ui},
-			"acl policy delete":       &command.ACLPolicyDeleteCommand{UI: ui},
-			"acl policy info":         &command.ACLPolicyInfoCommand{UI: ui},
-			"acl policy list":         &command.ACLPolicyListCommand{UI: ui},
-			"network":                 &command.NetworkCommand{UI: ui},
-			"network create":          &command.NetworkCreateCommand{UI: ui},
-			"network delete":          &command.NetworkDeleteCommand{UI: ui},
-			"network info":            &command.NetworkInfoCommand{UI: ui},
-			"network list":            &command.NetworkListCommand{UI: ui},
-			"node":                    &command.NodeCommand{UI: ui},
-			"node status":             &command.NodeStatusCommand{UI: ui},
-			"node join":               &command.NodeJoinCommand{UI: ui},
-			"node leave":              &command.NodeLeaveCommand{UI: ui},
-			"interface":               &command.InterfaceCommand{UI: ui},
-			"interface list":          &command.InterfaceListCommand{UI: ui},
-			"interface update":        &command.InterfaceUpdateCommand{UI: ui},
-			"connection":              &command.ConnectionCommand{UI: ui},
-			"connection list":         &command.ConnectionListCommand{UI: ui},
-			"connection create":       &command.ConnectionCreateCommand{UI: ui},
-			"connection delete":       &command.ConnectionDeleteCommand{UI: ui},
-			"connection update":       &command.ConnectionUpdateCommand{UI: ui},
-			"connection update-rules": &command.ConnectionUpdateRulesCommand{UI: ui},
-			// "system":                  &command.SystemCommand{UI: ui},
-			// "system gc":               &command.SystemGCCommand{UI: ui},
-			"ui":      &command.UICommand{UI: ui},
-			"version": &command.VersionCommand{UI: ui},
+			"agent":                     &command.AgentCommand{UI: ui, StaticFS: uifs},
+			"agent-info":                &command.AgentInfoCommand{UI: ui},
+			"acl":                       &command.ACLCommand{UI: ui},
+			"acl bootstrap":             &command.ACLBootstrapCommand{UI: ui},
+			"acl token":                 &command.ACLTokenCommand{UI: ui},
+			"acl token create":          &command.ACLTokenCreateCommand{UI: ui},
+			"acl token delete":          &command.ACLTokenDeleteCommand{UI: ui},
+			"acl token info":            &command.ACLTokenInfoCommand{UI: ui},
+			"acl token list":            &command.ACLTokenListCommand{UI: ui},
+			"acl token self":            &command.ACLTokenSelfCommand{UI: ui},
+			"acl token update":          &command.ACLTokenUpdateCommand{UI: ui},
+			"acl policy":                &command.ACLPolicyCommand{UI: ui},
+			"acl policy apply":          &command.ACLPolicyApplyCommand{UI: ui},
+			"acl policy delete":         &command.ACLPolicyDeleteCommand{UI: ui},
+			"acl policy info":           &command.ACLPolicyInfoCommand{UI: ui},
+			"acl policy list":           &command.ACLPolicyListCommand{UI: ui},
+			"namespace":                 &command.NamespaceCommand{UI: ui},
+			"namespace apply":           &command.NamespaceApplyCommand{UI: ui},
+			"namespace delete":          &command.NamespaceDeleteCommand{UI: ui},
+			"namespace info":            &command.NamespaceInfoCommand{UI: ui},
+			"namespace list":            &command.NamespaceListCommand{UI: ui},
+			"network":                   &command.NetworkCommand{UI: ui},
+			"network create":            &command.NetworkCreateCommand{UI: ui},
+			"network delete":            &command.NetworkDeleteCommand{UI: ui},
+			"network import":            &command.NetworkImportCommand{UI: ui},
+			"network info":              &command.NetworkInfoCommand{UI: ui},
+			"network list":              &command.NetworkListCommand{UI: ui},
+			"network rotate-keys":       &command.NetworkRotateKeysCommand{UI: ui},
+			"network update":            &command.NetworkUpdateCommand{UI: ui},
+			"network validate":          &command.NetworkValidateCommand{UI: ui},
+			"node":                      &command.NodeCommand{UI: ui},
+			"node status":               &command.NodeStatusCommand{UI: ui},
+			"node list":                 &command.NodeListCommand{UI: ui},
+			"node eligibility":          &command.NodeEligibilityCommand{UI: ui},
+			"node drain":                &command.NodeDrainCommand{UI: ui},
+			"node rotate-keys":          &command.NodeRotateKeysCommand{UI: ui},
+			"node token":                &command.NodeTokenCommand{UI: ui},
+			"node token create":         &command.NodeTokenCreateCommand{UI: ui},
+			"node token delete":         &command.NodeTokenDeleteCommand{UI: ui},
+			"node token list":           &command.NodeTokenListCommand{UI: ui},
+			"node join":                 &command.NodeJoinCommand{UI: ui},
+			"node leave":                &command.NodeLeaveCommand{UI: ui},
+			"interface":                 &command.InterfaceCommand{UI: ui},
+			"interface list":            &command.InterfaceListCommand{UI: ui},
+			"interface update":          &command.InterfaceUpdateCommand{UI: ui},
+			"connection":                &command.ConnectionCommand{UI: ui},
+			"connection list":           &command.ConnectionListCommand{UI: ui},
+			"connection create":         &command.ConnectionCreateCommand{UI: ui},
+			"connection delete":         &command.ConnectionDeleteCommand{UI: ui},
+			"connection rotate-psk":     &command.ConnectionRotatePresharedKeyCommand{UI: ui},
+			"connection update":         &command.ConnectionUpdateCommand{UI: ui},
+			"connection update-rules":   &command.ConnectionUpdateRulesCommand{UI: ui},
+			"peer":                      &command.PeerCommand{UI: ui},
+			"peer create":               &command.PeerCreateCommand{UI: ui},
+			"peer config":               &command.PeerConfigCommand{UI: ui},
+			"peer delete":               &command.PeerDeleteCommand{UI: ui},
+			"peer list":                 &command.PeerListCommand{UI: ui},
+			"system":                    &command.SystemCommand{UI: ui},
+			"system gc":                 &command.SystemGCCommand{UI: ui},
+			"operator":                  &command.OperatorCommand{UI: ui},
+			"operator snapshot":         &command.OperatorSnapshotCommand{UI: ui},
+			"operator snapshot save":    &command.OperatorSnapshotSaveCommand{UI: ui},
+			"operator snapshot restore": &command.OperatorSnapshotRestoreCommand{UI: ui},
+			"audit":                     &command.AuditCommand{UI: ui},
+			"audit list":                &command.AuditListCommand{UI: ui},
+			"ui":                        &command.UICommand{UI: ui},
+			"version":                   &command.VersionCommand{UI: ui},
 		},
 		Version: version.GetVersion().VersionNumber(),
 	})
@@ -0,0 +1,125 @@
+package cidr
+
+import (
+	"fmt"
+	"net"
+)
+
+// Entry is a prefix stored in a Trie, along with its value.
+type Entry struct {
+	Prefix *net.IPNet
+	Value  interface{}
+}
+
+// Trie is a binary trie of IP prefixes, which allows efficiently finding
+// the prefixes overlapping with a given one, i.e. those containing it or
+// contained by it. IPv4 and IPv6 prefixes are kept in separate tries.
+type Trie struct {
+	v4 *node
+	v6 *node
+}
+
+type node struct {
+	children [2]*node
+	entries  []*Entry
+}
+
+// NewTrie creates a new empty Trie.
+func NewTrie() *Trie {
+	return &Trie{
+		v4: &node{},
+		v6: &node{},
+	}
+}
+
+// Parse parses a prefix in CIDR notation, also accepting plain IP
+// addresses, which are treated as host prefixes (i.e. /32 or /128).
+// Host bits are masked, so that 10.0.0.1/24 is parsed as 10.0.0.0/24.
+func Parse(s string) (*net.IPNet, error) {
+
+	if _, prefix, err := net.ParseCIDR(s); err == nil {
+		return prefix, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid prefix %q", s)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// Insert adds a prefix to the trie, along with a value. The same
+// prefix can be inserted multiple times, with different values.
+func (t *Trie) Insert(prefix *net.IPNet, value interface{}) {
+
+	n, ip, ones := t.root(prefix)
+
+	for i := 0; i < ones; i++ {
+		b := bit(ip, i)
+		if n.children[b] == nil {
+			n.children[b] = &node{}
+		}
+		n = n.children[b]
+	}
+
+	n.entries = append(n.entries, &Entry{Prefix: prefix, Value: value})
+}
+
+// Overlapping returns the entries whose prefixes overlap with the one
+// passed as argument, from the shortest to the longest prefix.
+func (t *Trie) Overlapping(prefix *net.IPNet) []*Entry {
+
+	out := []*Entry{}
+
+	n, ip, ones := t.root(prefix)
+
+	// Entries containing the prefix are found along its path
+	for i := 0; i < ones; i++ {
+		out = append(out, n.entries...)
+		if n = n.children[bit(ip, i)]; n == nil {
+			return out
+		}
+	}
+
+	// Entries contained by the prefix are found below it
+	return append(out, n.all()...)
+}
+
+// root returns the root of the trie corresponding to the address family
+// of a prefix, along with its normalized address and length. The family is
+// given by the size of the mask rather than by the address, so that IPv4-mapped
+// IPv6 prefixes (e.g. ::ffff:10.0.0.0/120) are kept in the IPv6 trie.
+func (t *Trie) root(prefix *net.IPNet) (*node, net.IP, int) {
+
+	ones, bits := prefix.Mask.Size()
+
+	if ip4 := prefix.IP.To4(); ip4 != nil && bits == 8*net.IPv4len {
+		return t.v4, ip4, ones
+	}
+
+	return t.v6, prefix.IP.To16(), ones
+}
+
+// all returns the entries of a node and of all its descendants.
+func (n *node) all() []*Entry {
+
+	out := append([]*Entry{}, n.entries...)
+
+	for _, c := range n.children {
+		if c != nil {
+			out = append(out, c.all()...)
+		}
+	}
+
+	return out
+}
+
+// bit returns the i-th most significant bit of an IP address.
+func bit(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
@@ -0,0 +1,72 @@
+package cidr
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := map[string]string{
+		"10.0.0.1/24": "10.0.0.0/24",
+		"10.0.0.1":    "10.0.0.1/32",
+		"fd00::1":     "fd00::1/128",
+		"fd00::/64":   "fd00::/64",
+	}
+
+	for in, expected := range tests {
+		prefix, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse() failed: %v", err)
+		}
+		if prefix.String() != expected {
+			t.Fatalf("Parse() failed, expected %s, have %s", expected, prefix.String())
+		}
+	}
+
+	if _, err := Parse("invalid"); err == nil {
+		t.Fatalf("Parse() failed, expected error for invalid prefix")
+	}
+}
+
+func TestOverlapping(t *testing.T) {
+
+	trie := NewTrie()
+
+	for _, s := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32", "192.168.0.0/24", "fd00::/64", "::ffff:10.0.0.0/120"} {
+		prefix, _ := Parse(s)
+		trie.Insert(prefix, s)
+	}
+
+	tests := map[string][]string{
+		"10.1.0.0/16":    {"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32"},
+		"10.1.2.0/24":    {"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32"},
+		"10.2.0.1":       {"10.0.0.0/8"},
+		"0.0.0.0/0":      {"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32", "192.168.0.0/24"},
+		"192.168.1.0/24": {},
+		"fd00::1":        {"fd00::/64"},
+		"fd01::/64":      {},
+		// IPv4-mapped IPv6 prefixes are kept apart from IPv4 prefixes
+		"::ffff:10.0.0.1/128": {"::ffff:10.0.0.0/120"},
+		"::/0":                {"fd00::/64", "::ffff:10.0.0.0/120"},
+	}
+
+	for in, expected := range tests {
+
+		prefix, _ := Parse(in)
+
+		entries := trie.Overlapping(prefix)
+		if len(entries) != len(expected) {
+			t.Fatalf("trie.Overlapping(%s) failed, expected %d entries, have %d", in, len(expected), len(entries))
+		}
+
+		found := map[string]bool{}
+		for _, e := range entries {
+			found[e.Value.(string)] = true
+		}
+		for _, s := range expected {
+			if !found[s] {
+				t.Fatalf("trie.Overlapping(%s) failed, expected %s to overlap", in, s)
+			}
+		}
+	}
+}
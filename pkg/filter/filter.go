@@ -0,0 +1,749 @@
+// Package filter implements a small expression language for filtering lists of
+// structs, such as the stubs returned by list endpoints. For example:
+//
+//	Status == "ready" and Meta.region matches "eu-*" and ConnectionsCount > 3
+//
+// Expressions compare the fields of each item, selected by name, to literal values,
+// and can be combined with "and", "or" and "not", as well as grouped in parentheses.
+// Field names are matched case-insensitively, ignoring underscores, and the values of
+// maps are selected by key, as in Meta.region, or Meta."region-name" if the key is
+// not a valid name. The following operators are supported:
+//
+//	==, !=, <, <=, >, >=   compare strings, numbers, booleans and times (RFC 3339)
+//	matches                matches a string against a glob pattern, e.g. "eu-*"
+//	contains               checks whether a string contains a substring, a list
+//	                       contains a string, or a map contains a key
+//	is empty, is not empty checks whether a field is missing, or an empty
+//	                       string, list, map or time
+//
+// Fields which are missing, such as unset pointers or map keys which do not exist,
+// are only matched by != and "is empty".
+package filter
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	opEqual          = "=="
+	opNotEqual       = "!="
+	opLess           = "<"
+	opLessOrEqual    = "<="
+	opGreater        = ">"
+	opGreaterOrEqual = ">="
+	opMatches        = "matches"
+	opContains       = "contains"
+	opEmpty          = "is empty"
+	opNotEmpty       = "is not empty"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ParseError is returned for expressions which are not valid, either
+// syntactically, or because of the fields and operators they use.
+type ParseError struct {
+	// Pos is the position in the expression at which the error occurred.
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Expression is a parsed filter expression.
+type Expression struct {
+	root node
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (*Expression, error) {
+
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return nil, &ParseError{Pos: 0, Msg: "empty expression"}
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+
+	return &Expression{root: root}, nil
+}
+
+// Filter removes the items not matching the expression from the slice pointed to by
+// items, which must be a pointer to a slice of structs or of pointers to structs. The
+// fields used in the expression are checked against the type of the items, so that
+// errors are returned even if the slice is empty.
+func (e *Expression) Filter(items interface{}) error {
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to a slice, got %T", items)
+	}
+
+	slice := v.Elem()
+
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a slice of structs, got %T", items)
+	}
+
+	match, err := e.root.bind(elemType)
+	if err != nil {
+		return err
+	}
+
+	out := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		el := reflect.Indirect(slice.Index(i))
+		if el.IsValid() && match(el) {
+			out = reflect.Append(out, slice.Index(i))
+		}
+	}
+	slice.Set(out)
+
+	return nil
+}
+
+// Filter parses a filter expression and applies it to the slice pointed to by items.
+func Filter(items interface{}, expr string) error {
+	e, err := Parse(expr)
+	if err != nil {
+		return err
+	}
+	return e.Filter(items)
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenDot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lex(s string) ([]token, error) {
+
+	tokens := []token{}
+
+	for i := 0; i < len(s); {
+
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, &ParseError{Pos: i, Msg: "unterminated string"}
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, &ParseError{Pos: i, Msg: fmt.Sprintf("invalid string %s", s[i:j+1])}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = j + 1
+
+		case isDigit(c) || (c == '-' && i+1 < len(s) && isDigit(s[i+1])):
+			j := i + 1
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: s[i:j], pos: i})
+			i = j
+
+		case isLetter(c):
+			j := i + 1
+			for j < len(s) && (isLetter(s[j]) || isDigit(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: s[i:j], pos: i})
+			i = j
+
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, &ParseError{Pos: i, Msg: fmt.Sprintf("unexpected %q, did you mean \"%s=\"?", op, op)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+
+		default:
+			return nil, &ParseError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(t token, keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func isReserved(t token) bool {
+	switch strings.ToLower(t.text) {
+	case "and", "or", "not", "matches", "contains", "is", "empty", "true", "false":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(p.peek(), "and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+
+	if p.isKeyword(p.peek(), "not") {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{expr: expr}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected \")\", found %s", t)}
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+
+	t := p.next()
+	if t.kind != tokenIdent || isReserved(t) {
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected field name, found %s", t)}
+	}
+
+	c := &comparisonNode{pos: t.pos, selector: []string{t.text}}
+
+	for p.peek().kind == tokenDot {
+		p.next()
+		t := p.next()
+		if t.kind != tokenIdent && t.kind != tokenString {
+			return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected field name after \".\", found %s", t)}
+		}
+		c.selector = append(c.selector, t.text)
+	}
+
+	t = p.next()
+
+	switch {
+	case t.kind == tokenOperator:
+		c.op = t.text
+	case p.isKeyword(t, opMatches), p.isKeyword(t, opContains):
+		c.op = strings.ToLower(t.text)
+	case p.isKeyword(t, "is"):
+		c.op = opEmpty
+		if p.isKeyword(p.peek(), "not") {
+			p.next()
+			c.op = opNotEmpty
+		}
+		if t := p.next(); !p.isKeyword(t, "empty") {
+			return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected \"empty\", found %s", t)}
+		}
+		return c, nil
+	default:
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected operator after %q, found %s", c.field(), t)}
+	}
+
+	t = p.next()
+
+	switch {
+	case t.kind == tokenString:
+		c.value = &literal{pos: t.pos, s: t.text, kind: reflect.String}
+	case t.kind == tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.text)}
+		}
+		c.value = &literal{pos: t.pos, n: n, kind: reflect.Float64}
+	case p.isKeyword(t, "true"), p.isKeyword(t, "false"):
+		c.value = &literal{pos: t.pos, b: strings.EqualFold(t.text, "true"), kind: reflect.Bool}
+	default:
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("expected value after %q, found %s", c.op, t)}
+	}
+
+	return c, nil
+}
+
+// Evaluation
+
+// matcher reports whether an item, passed as a struct value, matches an expression.
+type matcher func(v reflect.Value) bool
+
+// node is a node of a parsed expression, which is bound to the type of
+// the items being filtered, checking the fields and operators it uses.
+type node interface {
+	bind(t reflect.Type) (matcher, error)
+}
+
+type logicalNode struct {
+	or          bool
+	left, right node
+}
+
+func (n *logicalNode) bind(t reflect.Type) (matcher, error) {
+	left, err := n.left.bind(t)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.bind(t)
+	if err != nil {
+		return nil, err
+	}
+	if n.or {
+		return func(v reflect.Value) bool { return left(v) || right(v) }, nil
+	}
+	return func(v reflect.Value) bool { return left(v) && right(v) }, nil
+}
+
+type notNode struct {
+	expr node
+}
+
+func (n *notNode) bind(t reflect.Type) (matcher, error) {
+	expr, err := n.expr.bind(t)
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value) bool { return !expr(v) }, nil
+}
+
+type literal struct {
+	pos  int
+	kind reflect.Kind
+	s    string
+	n    float64
+	b    bool
+}
+
+func (l *literal) String() string {
+	switch l.kind {
+	case reflect.String:
+		return fmt.Sprintf("string %q", l.s)
+	case reflect.Bool:
+		return fmt.Sprintf("boolean %t", l.b)
+	}
+	return fmt.Sprintf("number %v", l.n)
+}
+
+type comparisonNode struct {
+	pos      int
+	selector []string
+	op       string
+	value    *literal
+}
+
+func (n *comparisonNode) field() string {
+	return strings.Join(n.selector, ".")
+}
+
+func (n *comparisonNode) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: n.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// step selects either a struct field, by index, or a map value, by key.
+type step struct {
+	index []int
+	key   *reflect.Value
+}
+
+func (n *comparisonNode) bind(t reflect.Type) (matcher, error) {
+
+	steps := []step{}
+
+	for i, name := range n.selector {
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case t.Kind() == reflect.Struct && t != timeType:
+			f, ok := findField(t, name)
+			if !ok {
+				return nil, n.errorf("unknown field %q", strings.Join(n.selector[:i+1], "."))
+			}
+			steps = append(steps, step{index: f.Index})
+			t = f.Type
+		case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			key := reflect.ValueOf(name).Convert(t.Key())
+			steps = append(steps, step{key: &key})
+			t = t.Elem()
+		default:
+			return nil, n.errorf("field %q of type %s has no field %q", strings.Join(n.selector[:i], "."), t, name)
+		}
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	cmp, err := n.compare(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(v reflect.Value) bool {
+		v, ok := selectValue(v, steps)
+		if !ok {
+			// Only != and "is empty" match missing fields
+			return n.op == opNotEqual || n.op == opEmpty
+		}
+		return cmp(v)
+	}, nil
+}
+
+// compare returns a function comparing values of type t to the literal value of the node.
+func (n *comparisonNode) compare(t reflect.Type) (matcher, error) {
+
+	unsupported := n.errorf("operator %q is not supported on field %q of type %s", n.op, n.field(), t)
+
+	switch n.op {
+	case opEmpty, opNotEmpty:
+		var empty matcher
+		switch {
+		case t == timeType:
+			empty = func(v reflect.Value) bool { return v.Interface().(time.Time).IsZero() }
+		case t.Kind() == reflect.String, t.Kind() == reflect.Slice, t.Kind() == reflect.Map, t.Kind() == reflect.Array:
+			empty = func(v reflect.Value) bool { return v.Len() == 0 }
+		default:
+			// Other values are only empty if missing
+			empty = func(v reflect.Value) bool { return false }
+		}
+		if n.op == opNotEmpty {
+			return func(v reflect.Value) bool { return !empty(v) }, nil
+		}
+		return empty, nil
+
+	case opMatches:
+		if t.Kind() != reflect.String {
+			return nil, unsupported
+		}
+		if err := n.expect(reflect.String); err != nil {
+			return nil, err
+		}
+		pattern := n.value.s
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, &ParseError{Pos: n.value.pos, Msg: fmt.Sprintf("invalid pattern %q", pattern)}
+		}
+		return func(v reflect.Value) bool {
+			matched, _ := path.Match(pattern, v.String())
+			return matched
+		}, nil
+
+	case opContains:
+		if err := n.expect(reflect.String); err != nil {
+			return nil, err
+		}
+		s := n.value.s
+		switch {
+		case t.Kind() == reflect.String:
+			return func(v reflect.Value) bool { return strings.Contains(v.String(), s) }, nil
+		case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.String:
+			return func(v reflect.Value) bool {
+				for i := 0; i < v.Len(); i++ {
+					if v.Index(i).String() == s {
+						return true
+					}
+				}
+				return false
+			}, nil
+		case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			key := reflect.ValueOf(s).Convert(t.Key())
+			return func(v reflect.Value) bool { return v.MapIndex(key).IsValid() }, nil
+		}
+		return nil, unsupported
+	}
+
+	// Comparison operators
+	var result func(c int) bool
+	switch n.op {
+	case opEqual:
+		result = func(c int) bool { return c == 0 }
+	case opNotEqual:
+		result = func(c int) bool { return c != 0 }
+	case opLess:
+		result = func(c int) bool { return c < 0 }
+	case opLessOrEqual:
+		result = func(c int) bool { return c <= 0 }
+	case opGreater:
+		result = func(c int) bool { return c > 0 }
+	case opGreaterOrEqual:
+		result = func(c int) bool { return c >= 0 }
+	default:
+		return nil, n.errorf("unknown operator %q", n.op)
+	}
+
+	switch {
+	case t == timeType:
+		if err := n.expect(reflect.String); err != nil {
+			return nil, err
+		}
+		ts, err := time.Parse(time.RFC3339, n.value.s)
+		if err != nil {
+			return nil, &ParseError{Pos: n.value.pos, Msg: fmt.Sprintf("invalid time %q, expected RFC 3339 format", n.value.s)}
+		}
+		return func(v reflect.Value) bool {
+			t := v.Interface().(time.Time)
+			return result(compareOrdered(t.Before(ts), t.After(ts)))
+		}, nil
+
+	case t.Kind() == reflect.String:
+		if err := n.expect(reflect.String); err != nil {
+			return nil, err
+		}
+		s := n.value.s
+		return func(v reflect.Value) bool { return result(strings.Compare(v.String(), s)) }, nil
+
+	case t.Kind() == reflect.Bool:
+		if n.op != opEqual && n.op != opNotEqual {
+			return nil, unsupported
+		}
+		if err := n.expect(reflect.Bool); err != nil {
+			return nil, err
+		}
+		b := n.value.b
+		return func(v reflect.Value) bool { return result(compareOrdered(false, v.Bool() != b)) }, nil
+
+	case isNumber(t.Kind()):
+		if err := n.expect(reflect.Float64); err != nil {
+			return nil, err
+		}
+		x := n.value.n
+		return func(v reflect.Value) bool {
+			f := toFloat(v)
+			return result(compareOrdered(f < x, f > x))
+		}, nil
+	}
+
+	return nil, unsupported
+}
+
+// expect checks that the literal value of the node is of the kind passed.
+func (n *comparisonNode) expect(kind reflect.Kind) error {
+
+	if n.value.kind == kind {
+		return nil
+	}
+
+	expected := map[reflect.Kind]string{
+		reflect.String:  "a string",
+		reflect.Float64: "a number",
+		reflect.Bool:    "a boolean",
+	}[kind]
+
+	return &ParseError{Pos: n.value.pos, Msg: fmt.Sprintf("expected %s to compare with field %q, found %s", expected, n.field(), n.value)}
+}
+
+// selectValue follows the steps of a selector from the struct value v. It
+// returns false if a pointer along the way is nil, or a map key does not exist.
+func selectValue(v reflect.Value, steps []step) (reflect.Value, bool) {
+
+	for _, s := range steps {
+		if v = indirect(v); !v.IsValid() {
+			return v, false
+		}
+		if s.key != nil {
+			if v = v.MapIndex(*s.key); !v.IsValid() {
+				return v, false
+			}
+		} else {
+			v = v.FieldByIndex(s.index)
+		}
+	}
+
+	v = indirect(v)
+
+	return v, v.IsValid()
+}
+
+// indirect dereferences pointers, returning an invalid value if any is nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	name = normalizeName(name)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" && normalizeName(f.Name) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func compareOrdered(lt, gt bool) int {
+	switch {
+	case lt:
+		return -1
+	case gt:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID               string
+	Status           string
+	Name             *string
+	ConnectionsCount int
+	Online           bool
+	Tags             []string
+	Meta             map[string]string
+	CreatedAt        time.Time
+}
+
+func ids(items []*item) []string {
+	out := []string{}
+	for _, i := range items {
+		out = append(out, i.ID)
+	}
+	return out
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func testItems() []*item {
+	t := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*item{
+		{ID: "a", Status: "ready", Name: strPtr("alpha"), ConnectionsCount: 5, Online: true, Tags: []string{"db"}, Meta: map[string]string{"region": "eu-west"}, CreatedAt: t},
+		{ID: "b", Status: "ready", Name: strPtr("bravo"), ConnectionsCount: 2, Meta: map[string]string{"region": "us-east"}, CreatedAt: t.Add(time.Hour)},
+		{ID: "c", Status: "down", Name: nil, ConnectionsCount: 4, Tags: []string{"web", "db"}, Meta: map[string]string{"region": "eu-north"}, CreatedAt: t.Add(2 * time.Hour)},
+		{ID: "d", Status: "ready", Name: strPtr("delta"), ConnectionsCount: 8},
+	}
+}
+
+func TestFilter(t *testing.T) {
+
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{`Status == "ready" and Meta.region matches "eu-*" and ConnectionsCount > 3`, []string{"a"}},
+		{`status != "ready"`, []string{"c"}},
+		{`connections_count >= 4 and not (Status == "down")`, []string{"a", "d"}},
+		{`Status == "down" or ConnectionsCount < 3`, []string{"b", "c"}},
+		{`Status == "down" or Status == "ready" and ConnectionsCount <= 2`, []string{"b", "c"}},
+		{`Online == true`, []string{"a"}},
+		{`Name contains "a"`, []string{"a", "b", "d"}},
+		{`Name is empty`, []string{"c"}},
+		{`Name != "alpha"`, []string{"b", "c", "d"}},
+		{`Tags contains "db"`, []string{"a", "c"}},
+		{`Tags is not empty`, []string{"a", "c"}},
+		{`Meta contains "region"`, []string{"a", "b", "c"}},
+		{`Meta.region is empty`, []string{"d"}},
+		{`Meta."region" == "us-east"`, []string{"b"}},
+		{`CreatedAt > "2021-01-01T00:30:00Z"`, []string{"b", "c"}},
+		{`CreatedAt is empty`, []string{"d"}},
+		{`ConnectionsCount > -1.5 AND NOT Status matches "d*"`, []string{"a", "b", "d"}},
+	}
+
+	for _, c := range cases {
+		items := testItems()
+		if err := Filter(&items, c.expr); err != nil {
+			t.Fatalf("Filter(%s) failed: %v", c.expr, err)
+		}
+		if !reflect.DeepEqual(ids(items), c.expected) {
+			t.Fatalf("Filter(%s) failed, expected %v, have %v", c.expr, c.expected, ids(items))
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+
+	cases := []struct {
+		expr string
+		pos  int
+	}{
+		{``, 0},
+		{`Status = "ready"`, 7},
+		{`Status == "ready`, 10},
+		{`Status == "ready" and`, 21},
+		{`(Status == "ready"`, 18},
+		{`Status "ready"`, 7},
+		{`Status == ready`, 10},
+		{`Status == "ready" Online == true`, 18},
+		{`Unknown == "x"`, 0},
+		{`Status.Foo == "x"`, 0},
+		{`ConnectionsCount == "3"`, 20},
+		{`ConnectionsCount matches "3*"`, 0},
+		{`Online > false`, 0},
+		{`Status matches "[a"`, 15},
+		{`CreatedAt > "yesterday"`, 12},
+		{`ConnectionsCount is empty and Status ~ "x"`, 37},
+	}
+
+	for _, c := range cases {
+		items := testItems()
+		err := Filter(&items, c.expr)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("Filter(%s) failed, expected a parse error, have %v", c.expr, err)
+		}
+		if perr.Pos != c.pos {
+			t.Fatalf("Filter(%s) failed, expected error at position %d, have %d (%v)", c.expr, c.pos, perr.Pos, err)
+		}
+	}
+
+	// Fields are checked even if there are no items
+	items := []*item{}
+	if err := Filter(&items, `Unknown == "x"`); err == nil {
+		t.Fatalf("Filter() failed, expected an error for an unknown field in an empty list")
+	}
+}
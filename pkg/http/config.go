@@ -1,6 +1,8 @@
 package http
 
 import (
+	"crypto/tls"
+
 	log "github.com/seashell/drago/pkg/log"
 )
 
@@ -17,6 +19,13 @@ type Config struct {
 
 	// Logger
 	Logger log.Logger
+
+	// TLSConfig, if set, is used for serving HTTPS.
+	TLSConfig *tls.Config
+
+	// RedirectBindAddress, if set, is the address in the form host:port
+	// on which plain HTTP requests are redirected to HTTPS.
+	RedirectBindAddress string
 }
 
 // DefaultConfig :
@@ -43,5 +52,11 @@ func (s *Config) Merge(b *Config) *Config {
 	if b.Middleware != nil {
 		result.Middleware = b.Middleware
 	}
+	if b.TLSConfig != nil {
+		result.TLSConfig = b.TLSConfig
+	}
+	if b.RedirectBindAddress != "" {
+		result.RedirectBindAddress = b.RedirectBindAddress
+	}
 	return &result
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -23,6 +24,10 @@ type Server struct {
 	listener   net.Listener
 	listenerCh chan struct{}
 	mux        *http.ServeMux
+
+	// redirectListener is the listener of the plain HTTP server
+	// redirecting to the HTTPS server, if any.
+	redirectListener net.Listener
 }
 
 // NewServer :
@@ -35,6 +40,10 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, fmt.Errorf("error starting HTTP listener: %v", err)
 	}
 
+	if config.TLSConfig != nil {
+		listener = tls.NewListener(listener, config.TLSConfig)
+	}
+
 	server := &Server{
 		config:     config,
 		listener:   listener,
@@ -69,19 +78,84 @@ func NewServer(config *Config) (*Server, error) {
 
 	server.logger.Debugf("http server started at %s", httpServer.Addr)
 
+	if config.TLSConfig != nil && config.RedirectBindAddress != "" {
+		if err := server.serveRedirect(); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+
 	return server, nil
 }
 
+// serveRedirect starts a plain HTTP server redirecting all requests
+// to the same path on the HTTPS server.
+func (s *Server) serveRedirect() error {
+
+	listener, err := net.Listen("tcp", s.config.RedirectBindAddress)
+	if err != nil {
+		return fmt.Errorf("error starting HTTP redirect listener: %v", err)
+	}
+
+	_, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	redirect := func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		u := *req.URL
+		u.Scheme = "https"
+		u.Host = net.JoinHostPort(host, port)
+		http.Redirect(w, req, u.String(), http.StatusPermanentRedirect)
+	}
+
+	s.redirectListener = listener
+
+	go http.Serve(listener, http.HandlerFunc(redirect))
+
+	s.logger.Debugf("http redirect server started at %s", listener.Addr().String())
+
+	return nil
+}
+
+// Shutdown closes the listeners of the server, including the one of the
+// redirect server, if any, and waits for the server to stop serving.
+func (s *Server) Shutdown() error {
+
+	if s.redirectListener != nil {
+		s.redirectListener.Close()
+	}
+
+	err := s.listener.Close()
+
+	<-s.listenerCh
+
+	return err
+}
+
 // httpHandlerFunc converts a custom handler func to http.HandlerFunc
 func httpHandlerFunc(handler Handler) http.HandlerFunc {
 
-	f := func(rw http.ResponseWriter, req *http.Request) {
+	f := func(w http.ResponseWriter, req *http.Request) {
 
 		fcn := handler.Handle
 
+		rw := &responseWriter{ResponseWriter: w}
+
 		// Invoke custom handler
 		out, err := fcn(rw, req)
 
+		// Handlers that stream or serve content directly have
+		// already written the response, so there's nothing left to do.
+		if rw.written {
+			return
+		}
+
 		if err != nil {
 			code := http.StatusInternalServerError
 			if err, ok := err.(Error); ok {
@@ -108,6 +182,33 @@ func httpHandlerFunc(handler Handler) http.HandlerFunc {
 	return f
 }
 
+// responseWriter keeps track of whether a handler
+// has already written a response on its own.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+// WriteHeader :
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.written = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write :
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.written = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, which
+// is required by handlers streaming their responses.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func encode(in interface{}) []byte {
 	encoded, err := json.Marshal(in)
 	if err != nil {
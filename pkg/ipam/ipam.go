@@ -0,0 +1,154 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	// ErrAddressOutOfRange is returned when an address does not belong
+	// to the prefix managed by the allocator.
+	ErrAddressOutOfRange = errors.New("address not within range")
+
+	// ErrAddressInUse is returned when trying to reserve an address
+	// which has already been allocated.
+	ErrAddressInUse = errors.New("address already allocated")
+
+	// ErrRangeExhausted is returned when there are no more free host
+	// addresses left in the prefix.
+	ErrRangeExhausted = errors.New("no free addresses left in range")
+)
+
+// Allocator hands out host addresses from an IP prefix. Addresses are
+// represented in CIDR notation, carrying the length of the managed
+// prefix (e.g. 192.168.0.2/24), which is the format expected by the
+// interfaces of a network.
+type Allocator struct {
+	prefix *net.IPNet
+	used   map[string]struct{}
+}
+
+// NewAllocator creates a new Allocator for the prefix passed as
+// argument in CIDR notation (e.g. 192.168.0.0/24).
+func NewAllocator(cidr string) (*Allocator, error) {
+
+	_, prefix, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address range %q: %v", cidr, err)
+	}
+
+	return &Allocator{
+		prefix: prefix,
+		used:   map[string]struct{}{},
+	}, nil
+}
+
+// Reserve marks an address as allocated, returning an error in case it
+// is not within the managed prefix, or if it has already been allocated.
+// The address can be passed either in CIDR notation or as a plain IP.
+func (a *Allocator) Reserve(addr string) error {
+
+	ip, err := parseIP(addr)
+	if err != nil {
+		return err
+	}
+
+	if !a.prefix.Contains(ip) {
+		return ErrAddressOutOfRange
+	}
+
+	if _, found := a.used[ip.String()]; found {
+		return ErrAddressInUse
+	}
+
+	a.used[ip.String()] = struct{}{}
+
+	return nil
+}
+
+// Allocate reserves and returns the lowest free host address within the
+// managed prefix. The network address, and for IPv4 prefixes shorter than
+// /31 also the broadcast address, are never allocated.
+func (a *Allocator) Allocate() (string, error) {
+
+	ones, bits := a.prefix.Mask.Size()
+
+	first := a.prefix.IP.Mask(a.prefix.Mask)
+	last := lastIP(a.prefix)
+
+	// Prefixes with less than two host bits have no network
+	// or broadcast addresses to be skipped (RFC 3021).
+	if bits-ones > 1 {
+		first = nextIP(first)
+		if first.To4() != nil {
+			last = prevIP(last)
+		}
+	}
+
+	for ip := first; a.prefix.Contains(ip); ip = nextIP(ip) {
+		if _, found := a.used[ip.String()]; !found {
+			a.used[ip.String()] = struct{}{}
+			return fmt.Sprintf("%s/%d", ip.String(), ones), nil
+		}
+		if ip.Equal(last) {
+			break
+		}
+	}
+
+	return "", ErrRangeExhausted
+}
+
+func parseIP(addr string) (net.IP, error) {
+
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return normalize(ip), nil
+	}
+
+	if ip := net.ParseIP(addr); ip != nil {
+		return normalize(ip), nil
+	}
+
+	return nil, fmt.Errorf("invalid address %q", addr)
+}
+
+// normalize returns IPv4 addresses in their 4-byte representation,
+// so that they can be compared with the ones derived from prefixes.
+func normalize(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
+
+func lastIP(prefix *net.IPNet) net.IP {
+	ip := make(net.IP, len(prefix.IP))
+	for i := range prefix.IP {
+		ip[i] = prefix.IP[i] | ^prefix.Mask[i]
+	}
+	return ip
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func prevIP(ip net.IP) net.IP {
+	prev := make(net.IP, len(ip))
+	copy(prev, ip)
+	for i := len(prev) - 1; i >= 0; i-- {
+		prev[i]--
+		if prev[i] != 0xff {
+			break
+		}
+	}
+	return prev
+}
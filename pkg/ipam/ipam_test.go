@@ -0,0 +1,82 @@
+package ipam
+
+import (
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+
+	a, err := NewAllocator("192.168.0.0/24")
+	if err != nil {
+		t.Fatalf("NewAllocator() failed: %v", err)
+	}
+
+	for _, expected := range []string{"192.168.0.1/24", "192.168.0.2/24", "192.168.0.3/24"} {
+		addr, err := a.Allocate()
+		if err != nil {
+			t.Fatalf("a.Allocate() failed: %v", err)
+		}
+		if addr != expected {
+			t.Fatalf("a.Allocate() failed, expected %s, have %s", expected, addr)
+		}
+	}
+}
+
+func TestAllocateSkipsReserved(t *testing.T) {
+
+	a, _ := NewAllocator("10.0.0.0/24")
+
+	a.Reserve("10.0.0.1/24")
+	a.Reserve("10.0.0.2")
+
+	addr, err := a.Allocate()
+	if err != nil {
+		t.Fatalf("a.Allocate() failed: %v", err)
+	}
+	if addr != "10.0.0.3/24" {
+		t.Fatalf("a.Allocate() failed, expected %s, have %s", "10.0.0.3/24", addr)
+	}
+}
+
+func TestAllocateExhausted(t *testing.T) {
+
+	a, _ := NewAllocator("10.0.0.0/30")
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Allocate(); err != nil {
+			t.Fatalf("a.Allocate() failed: %v", err)
+		}
+	}
+
+	if _, err := a.Allocate(); err != ErrRangeExhausted {
+		t.Fatalf("a.Allocate() failed, expected %v, have %v", ErrRangeExhausted, err)
+	}
+}
+
+func TestReserve(t *testing.T) {
+
+	a, _ := NewAllocator("10.0.0.0/24")
+
+	if err := a.Reserve("10.0.0.10/24"); err != nil {
+		t.Fatalf("a.Reserve() failed: %v", err)
+	}
+	if err := a.Reserve("10.0.0.10/24"); err != ErrAddressInUse {
+		t.Fatalf("a.Reserve() failed, expected %v, have %v", ErrAddressInUse, err)
+	}
+	if err := a.Reserve("10.0.1.10/24"); err != ErrAddressOutOfRange {
+		t.Fatalf("a.Reserve() failed, expected %v, have %v", ErrAddressOutOfRange, err)
+	}
+}
+
+func TestAllocateIPv6(t *testing.T) {
+
+	a, _ := NewAllocator("fd00::/64")
+
+	addr, err := a.Allocate()
+	if err != nil {
+		t.Fatalf("a.Allocate() failed: %v", err)
+	}
+	if addr != "fd00::1/64" {
+		t.Fatalf("a.Allocate() failed, expected %s, have %s", "fd00::1/64", addr)
+	}
+}
@@ -0,0 +1,367 @@
+// Package paginator sorts lists of structs, such as the stubs returned by
+// list endpoints, by one of their fields, and splits them into pages.
+package paginator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// OrderAsc sorts items in ascending order.
+	OrderAsc = "asc"
+	// OrderDesc sorts items in descending order.
+	OrderDesc = "desc"
+)
+
+var (
+	// ErrInvalidSort is returned when sorting by a field which does not exist,
+	// or whose values can not be compared, such as slices and maps.
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrInvalidOrder is returned when the order is neither OrderAsc nor OrderDesc.
+	ErrInvalidOrder = errors.New("invalid order")
+	// ErrInvalidToken is returned when the token passed was not returned
+	// by a previous call with the same sort field.
+	ErrInvalidToken = errors.New("invalid pagination token")
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Options controls how items are sorted and paginated.
+type Options struct {
+	// PerPage is the maximum number of items in a page. If zero, all
+	// items starting at NextToken are returned in a single page.
+	PerPage int
+
+	// NextToken is the token returned along with the previous page, if any.
+	NextToken string
+
+	// Sort is the name of the field by which items are sorted. Names are
+	// matched case-insensitively, ignoring underscores and dashes, so that
+	// "created_at" matches CreatedAt. Defaults to the ID field.
+	Sort string
+
+	// Order is either OrderAsc or OrderDesc. Defaults to OrderAsc.
+	Order string
+}
+
+// Paginate sorts the slice pointed to by items, which must be a pointer to a slice
+// of structs or of pointers to structs, and replaces it with the page requested. It
+// returns the token of the next page, or an empty string if there are no more items.
+//
+// Items with equal values are sorted by their ID field, or by their Name field if
+// they have no ID, so that the order is stable across calls. The token identifies
+// the first item of the next page by its values, rather than by its position, so
+// that items are neither skipped nor repeated if others are added or removed
+// between calls.
+func Paginate(items interface{}, opts Options) (string, error) {
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return "", fmt.Errorf("expected a pointer to a slice, got %T", items)
+	}
+
+	slice := v.Elem()
+
+	elemType := slice.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return "", fmt.Errorf("expected a slice of structs, got %T", items)
+	}
+
+	idField, ok := findField(elemType, "ID")
+	if !ok || idField.Type.Kind() != reflect.String {
+		if idField, ok = findField(elemType, "Name"); !ok || idField.Type.Kind() != reflect.String {
+			return "", fmt.Errorf("%s has no ID field", elemType.Name())
+		}
+	}
+
+	sortField := idField
+	if opts.Sort != "" {
+		if sortField, ok = findField(elemType, opts.Sort); !ok || !sortable(sortField.Type) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSort, opts.Sort)
+		}
+	}
+
+	desc := false
+	switch strings.ToLower(opts.Order) {
+	case "", OrderAsc:
+	case OrderDesc:
+		desc = true
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidOrder, opts.Order)
+	}
+
+	keys := make([]key, slice.Len())
+	for i := range keys {
+		el := reflect.Indirect(slice.Index(i))
+		keys[i] = key{
+			value: normalize(el.FieldByIndex(sortField.Index)),
+			id:    el.FieldByIndex(idField.Index).String(),
+		}
+	}
+
+	less := func(a, b key) bool {
+		if desc {
+			return compareKeys(b, a) < 0
+		}
+		return compareKeys(a, b) < 0
+	}
+
+	perm := make([]int, len(keys))
+	for i := range perm {
+		perm[i] = i
+	}
+
+	// Items are often sorted already, such as when listed from
+	// a repository sorted by ID, in which case sorting is skipped.
+	byKey := func(i, j int) bool { return less(keys[perm[i]], keys[perm[j]]) }
+	if !sort.SliceIsSorted(perm, byKey) {
+		sort.SliceStable(perm, byKey)
+	}
+
+	start := 0
+	if opts.NextToken != "" {
+		token, err := decodeToken(opts.NextToken, sortField.Type)
+		if err != nil {
+			return "", err
+		}
+		start = sort.Search(len(perm), func(i int) bool { return !less(keys[perm[i]], token) })
+	}
+
+	end := len(perm)
+	if opts.PerPage > 0 && start+opts.PerPage < end {
+		end = start + opts.PerPage
+	}
+
+	page := reflect.MakeSlice(slice.Type(), 0, end-start)
+	for _, i := range perm[start:end] {
+		page = reflect.Append(page, slice.Index(i))
+	}
+	slice.Set(page)
+
+	if end < len(perm) {
+		return encodeToken(keys[perm[end]]), nil
+	}
+
+	return "", nil
+}
+
+// SortedByID checks whether the options sort items by ID in ascending order, which is
+// the order in which items are often stored already, so that pages can be read directly.
+func SortedByID(opts Options) bool {
+	order := strings.ToLower(opts.Order)
+	return (opts.Sort == "" || normalizeName(opts.Sort) == "id") && (order == "" || order == OrderAsc)
+}
+
+// TokenID returns the ID of the first item of the page identified by a
+// token, which was returned by Paginate for items sorted by ID.
+func TokenID(token string) (string, error) {
+	k, err := decodeToken(token, reflect.TypeOf(""))
+	if err != nil {
+		return "", err
+	}
+	return k.id, nil
+}
+
+// key holds the values by which an item is sorted.
+type key struct {
+	value interface{}
+	id    string
+}
+
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	name = normalizeName(name)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" && normalizeName(f.Name) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+}
+
+func sortable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// normalize converts the value of a field into a string, bool, int64, uint64,
+// float64 or time.Time, or nil if it is a nil pointer.
+func normalize(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return nil
+}
+
+// compareKeys compares two keys by value, and then by ID. Nil values come first.
+func compareKeys(a, b key) int {
+	if c := compareValues(a.value, b.value); c != 0 {
+		return c
+	}
+	return strings.Compare(a.id, b.id)
+}
+
+func compareValues(a, b interface{}) int {
+
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		return compareBools(a, b.(bool))
+	case int64:
+		return compareOrdered(a < b.(int64), a > b.(int64))
+	case uint64:
+		return compareOrdered(a < b.(uint64), a > b.(uint64))
+	case float64:
+		return compareOrdered(a < b.(float64), a > b.(float64))
+	case time.Time:
+		return compareOrdered(a.Before(b.(time.Time)), a.After(b.(time.Time)))
+	}
+
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	return compareOrdered(!a && b, a && !b)
+}
+
+func compareOrdered(lt, gt bool) int {
+	switch {
+	case lt:
+		return -1
+	case gt:
+		return 1
+	}
+	return 0
+}
+
+// encodeToken encodes a key as an opaque token. Nil values are encoded
+// as an empty string, and other values are prefixed with a dot.
+func encodeToken(k key) string {
+
+	value := ""
+
+	switch v := k.value.(type) {
+	case nil:
+	case string:
+		value = "." + v
+	case bool:
+		value = "." + strconv.FormatBool(v)
+	case int64:
+		value = "." + strconv.FormatInt(v, 10)
+	case uint64:
+		value = "." + strconv.FormatUint(v, 10)
+	case float64:
+		value = "." + strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		value = "." + v.Format(time.RFC3339Nano)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(k.id + "\x00" + value))
+}
+
+// decodeToken decodes a token into a key, parsing its value according to the type of the sort field.
+func decodeToken(token string, t reflect.Type) (key, error) {
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return key{}, ErrInvalidToken
+	}
+
+	parts := strings.SplitN(string(b), "\x00", 2)
+	if len(parts) != 2 {
+		return key{}, ErrInvalidToken
+	}
+
+	k := key{id: parts[0]}
+
+	if parts[1] == "" {
+		return k, nil
+	}
+	if !strings.HasPrefix(parts[1], ".") {
+		return key{}, ErrInvalidToken
+	}
+
+	s := parts[1][1:]
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		k.value, err = time.Parse(time.RFC3339Nano, s)
+	} else {
+		switch t.Kind() {
+		case reflect.String:
+			k.value = s
+		case reflect.Bool:
+			k.value, err = strconv.ParseBool(s)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			k.value, err = strconv.ParseInt(s, 10, 64)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			k.value, err = strconv.ParseUint(s, 10, 64)
+		case reflect.Float32, reflect.Float64:
+			k.value, err = strconv.ParseFloat(s, 64)
+		}
+	}
+	if err != nil {
+		return key{}, ErrInvalidToken
+	}
+
+	return k, nil
+}
@@ -0,0 +1,172 @@
+package paginator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID        string
+	Name      *string
+	Count     int
+	Tags      []string
+	CreatedAt time.Time
+}
+
+func ids(items []*item) []string {
+	out := []string{}
+	for _, i := range items {
+		out = append(out, i.ID)
+	}
+	return out
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func testItems() []*item {
+	t := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*item{
+		{ID: "c", Name: strPtr("charlie"), Count: 2, CreatedAt: t.Add(2 * time.Second)},
+		{ID: "a", Name: strPtr("alpha"), Count: 1, CreatedAt: t.Add(3 * time.Second)},
+		{ID: "e", Name: nil, Count: 2, CreatedAt: t.Add(1 * time.Second)},
+		{ID: "b", Name: strPtr("bravo"), Count: 3, CreatedAt: t.Add(5 * time.Second)},
+		{ID: "d", Name: strPtr("delta"), Count: 1, CreatedAt: t.Add(4 * time.Second)},
+	}
+}
+
+func TestPaginate(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{"default", Options{}, []string{"a", "b", "c", "d", "e"}},
+		{"desc", Options{Order: "desc"}, []string{"e", "d", "c", "b", "a"}},
+		{"pointer", Options{Sort: "name"}, []string{"e", "a", "b", "c", "d"}},
+		{"ties", Options{Sort: "count"}, []string{"a", "d", "c", "e", "b"}},
+		{"time", Options{Sort: "created_at", Order: "DESC"}, []string{"b", "d", "a", "c", "e"}},
+	}
+
+	for _, c := range cases {
+		items := testItems()
+		next, err := Paginate(&items, c.opts)
+		if err != nil {
+			t.Fatalf("%s: Paginate() failed: %v", c.name, err)
+		}
+		if next != "" {
+			t.Fatalf("%s: Paginate() failed, expected no next token, have %s", c.name, next)
+		}
+		if !reflect.DeepEqual(ids(items), c.expected) {
+			t.Fatalf("%s: Paginate() failed, expected %v, have %v", c.name, c.expected, ids(items))
+		}
+	}
+}
+
+func TestPaginatePages(t *testing.T) {
+
+	for _, sort := range []string{"", "name", "count", "created_at"} {
+
+		all := testItems()
+		if _, err := Paginate(&all, Options{Sort: sort}); err != nil {
+			t.Fatalf("Paginate() failed: %v", err)
+		}
+
+		pages := []string{}
+		opts := Options{Sort: sort, PerPage: 2}
+
+		for {
+			items := testItems()
+			next, err := Paginate(&items, opts)
+			if err != nil {
+				t.Fatalf("Paginate() failed: %v", err)
+			}
+			if len(items) > 2 {
+				t.Fatalf("Paginate() failed, expected at most 2 items, have %d", len(items))
+			}
+			pages = append(pages, ids(items)...)
+			if next == "" {
+				break
+			}
+			opts.NextToken = next
+		}
+
+		if !reflect.DeepEqual(pages, ids(all)) {
+			t.Fatalf("Paginate() failed, expected %v, have %v", ids(all), pages)
+		}
+	}
+}
+
+func TestPaginateRemovedItem(t *testing.T) {
+
+	items := testItems()
+	next, err := Paginate(&items, Options{PerPage: 2})
+	if err != nil {
+		t.Fatalf("Paginate() failed: %v", err)
+	}
+
+	// Remove the first item of the next page before requesting it
+	items = []*item{}
+	for _, i := range testItems() {
+		if i.ID != "c" {
+			items = append(items, i)
+		}
+	}
+
+	if _, err := Paginate(&items, Options{PerPage: 2, NextToken: next}); err != nil {
+		t.Fatalf("Paginate() failed: %v", err)
+	}
+	if expected := []string{"d", "e"}; !reflect.DeepEqual(ids(items), expected) {
+		t.Fatalf("Paginate() failed, expected %v, have %v", expected, ids(items))
+	}
+}
+
+func TestTokenID(t *testing.T) {
+
+	items := testItems()
+	next, err := Paginate(&items, Options{PerPage: 2})
+	if err != nil {
+		t.Fatalf("Paginate() failed: %v", err)
+	}
+
+	id, err := TokenID(next)
+	if err != nil {
+		t.Fatalf("TokenID() failed: %v", err)
+	}
+	if id != "c" {
+		t.Fatalf("TokenID() failed, expected c, have %s", id)
+	}
+
+	if _, err := TokenID("!"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("TokenID() failed, expected %v, have %v", ErrInvalidToken, err)
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+
+	cases := []struct {
+		opts     Options
+		expected error
+	}{
+		{Options{Sort: "unknown"}, ErrInvalidSort},
+		{Options{Sort: "tags"}, ErrInvalidSort},
+		{Options{Order: "random"}, ErrInvalidOrder},
+		{Options{NextToken: "!"}, ErrInvalidToken},
+	}
+
+	for _, c := range cases {
+		items := testItems()
+		if _, err := Paginate(&items, c.opts); !errors.Is(err, c.expected) {
+			t.Fatalf("Paginate(%+v) failed, expected %v, have %v", c.opts, c.expected, err)
+		}
+	}
+
+	items := testItems()
+	if _, err := Paginate(items, Options{}); err == nil {
+		t.Fatalf("Paginate() failed, expected an error when not passing a pointer")
+	}
+}
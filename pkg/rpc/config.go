@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"time"
 
 	log "github.com/seashell/drago/pkg/log"
@@ -15,6 +16,12 @@ type ServerConfig struct {
 
 	// Receivers
 	Receivers map[string]interface{}
+
+	// TLSConfig, if set, is used for serving RPCs over TLS.
+	TLSConfig *tls.Config
+
+	// Observer, if set, is called after each request is served.
+	Observer ObserverFunc
 }
 
 func DefaultConfig() *ServerConfig {
@@ -35,6 +42,12 @@ func (s *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.Receivers != nil {
 		result.Receivers = b.Receivers
 	}
+	if b.TLSConfig != nil {
+		result.TLSConfig = b.TLSConfig
+	}
+	if b.Observer != nil {
+		result.Observer = b.Observer
+	}
 	return &result
 }
 
@@ -47,6 +60,9 @@ type ClientConfig struct {
 
 	// Timeout when dialing.
 	DialTimeout time.Duration
+
+	// TLSConfig, if set, is used for dialing the server over TLS.
+	TLSConfig *tls.Config
 }
 
 func DefaultClientConfig() *ClientConfig {
@@ -67,5 +83,8 @@ func (c *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.DialTimeout != 0 {
 		result.DialTimeout = b.DialTimeout
 	}
+	if b.TLSConfig != nil {
+		result.TLSConfig = b.TLSConfig
+	}
 	return &result
 }
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"errors"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// ObserverFunc is called after a request is served, with the name of the
+// method, the time taken to serve it, and the error returned, if any.
+type ObserverFunc func(method string, duration time.Duration, err error)
+
+type pendingRequest struct {
+	method string
+	start  time.Time
+}
+
+// observedServerCodec wraps a server codec, calling an
+// observer whenever a response is written.
+type observedServerCodec struct {
+	rpc.ServerCodec
+	observer ObserverFunc
+
+	pending map[uint64]pendingRequest
+	mu      sync.Mutex
+}
+
+func newObservedServerCodec(codec rpc.ServerCodec, observer ObserverFunc) rpc.ServerCodec {
+	return &observedServerCodec{
+		ServerCodec: codec,
+		observer:    observer,
+		pending:     map[uint64]pendingRequest{},
+	}
+}
+
+func (c *observedServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	if err := c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.pending[r.Seq] = pendingRequest{method: r.ServiceMethod, start: time.Now()}
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *observedServerCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+
+	c.mu.Lock()
+	req, ok := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+
+	if ok {
+		var err error
+		if r.Error != "" {
+			err = errors.New(r.Error)
+		}
+		c.observer(req.method, time.Since(req.start), err)
+	}
+
+	return c.ServerCodec.WriteResponse(r, body)
+}
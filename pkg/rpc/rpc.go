@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/rpc"
@@ -22,12 +23,15 @@ func NewServer(config *ServerConfig) (*Server, error) {
 
 	config = DefaultConfig().Merge(config)
 
-	// Use tls.Listen for serving with TLS
 	listener, err := net.Listen("tcp", config.BindAddress)
 	if err != nil {
 		return nil, fmt.Errorf("error starting rpc listener: %v", err)
 	}
 
+	if config.TLSConfig != nil {
+		listener = tls.NewListener(listener, config.TLSConfig)
+	}
+
 	server := &Server{
 		rpcServer:  rpc.NewServer(),
 		config:     config,
@@ -48,6 +52,9 @@ func NewServer(config *ServerConfig) (*Server, error) {
 			}
 			conn, _ := listener.Accept()
 			cdc := NewMsgpackServerCodec(conn)
+			if config.Observer != nil {
+				cdc = newObservedServerCodec(cdc, config.Observer)
+			}
 			go func() {
 				server.rpcServer.ServeCodec(cdc)
 			}()
@@ -75,8 +82,21 @@ func NewClient(config *ClientConfig) (*Client, error) {
 		logger: config.Logger,
 	}
 
-	// Use tls.Dial for connection with TLS
-	conn, err := net.DialTimeout("tcp", config.Address, config.DialTimeout)
+	var conn net.Conn
+	var err error
+
+	if config.TLSConfig != nil {
+		tlsConfig := config.TLSConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			if host, _, err := net.SplitHostPort(config.Address); err == nil {
+				tlsConfig.ServerName = host
+			}
+		}
+		dialer := &net.Dialer{Timeout: config.DialTimeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", config.Address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", config.Address, config.DialTimeout)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -91,3 +111,15 @@ func NewClient(config *ClientConfig) (*Client, error) {
 func (c *Client) Call(method string, args interface{}, reply interface{}) error {
 	return c.client.Call(method, args, reply)
 }
+
+// Close closes the underlying connection to the server.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// IsServerError returns true if the error was returned by the remote
+// method itself, meaning the connection to the server is still healthy.
+func IsServerError(err error) bool {
+	_, ok := err.(rpc.ServerError)
+	return ok
+}
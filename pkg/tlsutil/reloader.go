@@ -0,0 +1,55 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+)
+
+// KeyPairReloader serves a certificate which can be reloaded
+// from disk without recreating the listeners using it.
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	cert *tls.Certificate
+	mu   sync.RWMutex
+}
+
+// NewKeyPairReloader loads the given key pair, returning
+// a reloader which serves it until Reload is called.
+func NewKeyPairReloader(certFile, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the key pair from disk again. If it cannot be
+// loaded, the previous key pair keeps being served.
+func (r *KeyPairReloader) Reload() error {
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+
+	return nil
+}
+
+// GetCertificate returns the current certificate, and
+// is meant to be used as tls.Config.GetCertificate.
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
@@ -0,0 +1,210 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	cpem []byte
+	kpem []byte
+}
+
+func generate(t *testing.T, name string, parent *keyPair, isCA bool) *keyPair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	kder, _ := x509.MarshalECPrivateKey(key)
+
+	return &keyPair{
+		cert: cert,
+		key:  key,
+		cpem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		kpem: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}),
+	}
+}
+
+func write(t *testing.T, dir, name string, b []byte) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func handshake(t *testing.T, server, client *Config, serverName string) error {
+	t.Helper()
+
+	in, err := server.IncomingTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := client.OutgoingTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.ServerName = serverName
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- tls.Server(a, in).Handshake()
+		a.Close()
+	}()
+
+	err = tls.Client(b, out).Handshake()
+	b.Close()
+	if serr := <-errCh; err == nil {
+		err = serr
+	}
+	return err
+}
+
+func TestHandshake(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tlsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := generate(t, "ca", nil, true)
+	other := generate(t, "other", nil, true)
+	srv := generate(t, "server.drago", ca, false)
+	cli := generate(t, "client.drago", ca, false)
+	rogue := generate(t, "client.drago", other, false)
+
+	caFile := write(t, dir, "ca.pem", ca.cpem)
+
+	server := &Config{
+		CAFile:   caFile,
+		CertFile: write(t, dir, "server.pem", srv.cpem),
+		KeyFile:  write(t, dir, "server-key.pem", srv.kpem),
+	}
+	client := &Config{
+		CAFile:   caFile,
+		CertFile: write(t, dir, "client.pem", cli.cpem),
+		KeyFile:  write(t, dir, "client-key.pem", cli.kpem),
+	}
+	anonymous := &Config{CAFile: caFile}
+	impostor := &Config{
+		CAFile:   caFile,
+		CertFile: write(t, dir, "rogue.pem", rogue.cpem),
+		KeyFile:  write(t, dir, "rogue-key.pem", rogue.kpem),
+	}
+
+	if err := handshake(t, server, anonymous, "127.0.0.1"); err != nil {
+		t.Fatalf("expected handshake without hostname verification to succeed: %v", err)
+	}
+
+	strict := *anonymous
+	strict.VerifyServerHostname = true
+	if err := handshake(t, server, &strict, "127.0.0.1"); err == nil {
+		t.Fatal("expected handshake with mismatching hostname to fail")
+	}
+	if err := handshake(t, server, &strict, "server.drago"); err != nil {
+		t.Fatalf("expected handshake with matching hostname to succeed: %v", err)
+	}
+
+	server.VerifyIncoming = true
+	if err := handshake(t, server, client, "server.drago"); err != nil {
+		t.Fatalf("expected mutual TLS handshake to succeed: %v", err)
+	}
+	if err := handshake(t, server, anonymous, "server.drago"); err == nil {
+		t.Fatal("expected handshake without client certificate to fail")
+	}
+	if err := handshake(t, server, impostor, "server.drago"); err == nil {
+		t.Fatal("expected handshake with untrusted client certificate to fail")
+	}
+
+	// Certificates signed by another CA are rejected even without hostname verification.
+	rogueServer := &Config{CertFile: impostor.CertFile, KeyFile: impostor.KeyFile}
+	if err := handshake(t, rogueServer, anonymous, "client.drago"); err == nil {
+		t.Fatal("expected handshake with untrusted server certificate to fail")
+	}
+}
+
+func TestKeyPairReloader(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tlsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := generate(t, "ca", nil, true)
+	first := generate(t, "first.drago", ca, false)
+	second := generate(t, "second.drago", ca, false)
+
+	certFile := write(t, dir, "cert.pem", first.cpem)
+	keyFile := write(t, dir, "key.pem", first.kpem)
+
+	r, err := NewKeyPairReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write(t, dir, "cert.pem", second.cpem)
+	write(t, dir, "key.pem", second.kpem)
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _ := r.GetCertificate(nil)
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "second.drago" {
+		t.Fatalf("expected reloaded certificate, got %s", leaf.Subject.CommonName)
+	}
+
+	// A broken key pair keeps the previous one in use.
+	write(t, dir, "key.pem", first.kpem)
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected reloading a mismatching key pair to fail")
+	}
+	if c, _ := r.GetCertificate(nil); c != cert {
+		t.Fatal("expected previous certificate to be kept")
+	}
+}
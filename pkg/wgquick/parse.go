@@ -0,0 +1,183 @@
+package wgquick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseError is returned when a configuration can't be parsed,
+// and contains the number of the line at which parsing failed.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse parses a configuration in the wg-quick format. Comments and settings
+// which are only interpreted by wg-quick itself, such as PostUp or Table, are
+// ignored, whereas unknown settings and sections result in an error.
+func Parse(r io.Reader) (*Config, error) {
+
+	c := &Config{}
+
+	var section string
+	var peer *Peer
+	seenInterface := false
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, &ParseError{line, fmt.Sprintf("invalid section header %q", text)}
+			}
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			switch section {
+			case "interface":
+				if seenInterface {
+					return nil, &ParseError{line, "duplicate [Interface] section"}
+				}
+				seenInterface = true
+			case "peer":
+				peer = &Peer{}
+				c.Peers = append(c.Peers, peer)
+			default:
+				return nil, &ParseError{line, fmt.Sprintf("unknown section %q", text)}
+			}
+			continue
+		}
+
+		i := strings.IndexByte(text, '=')
+		if i < 0 {
+			return nil, &ParseError{line, fmt.Sprintf("expected 'Key = Value', got %q", text)}
+		}
+
+		key := strings.ToLower(strings.TrimSpace(text[:i]))
+		value := strings.TrimSpace(text[i+1:])
+
+		var err error
+
+		switch section {
+		case "interface":
+			err = c.Interface.set(key, value)
+		case "peer":
+			err = peer.set(key, value)
+		default:
+			err = fmt.Errorf("setting outside of a section")
+		}
+		if err != nil {
+			return nil, &ParseError{line, err.Error()}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !seenInterface {
+		return nil, &ParseError{line, "missing [Interface] section"}
+	}
+
+	for i, p := range c.Peers {
+		if p.PublicKey == "" {
+			return nil, fmt.Errorf("peer %d has no PublicKey", i+1)
+		}
+	}
+
+	return c, nil
+}
+
+func (i *Interface) set(key, value string) error {
+
+	var err error
+
+	switch key {
+	case "privatekey":
+		i.PrivateKey = value
+	case "address":
+		i.Address = append(i.Address, splitList(value)...)
+	case "listenport":
+		i.ListenPort, err = parsePort(value)
+	case "dns":
+		i.DNS = append(i.DNS, splitList(value)...)
+	case "mtu":
+		i.MTU, err = parseInt(value)
+	case "table", "preup", "postup", "predown", "postdown", "saveconfig", "fwmark":
+		// Interpreted by wg-quick only
+	default:
+		err = fmt.Errorf("unknown setting %q in [Interface] section", key)
+	}
+
+	return err
+}
+
+func (p *Peer) set(key, value string) error {
+
+	var err error
+
+	switch key {
+	case "publickey":
+		p.PublicKey = value
+	case "presharedkey":
+		p.PresharedKey = value
+	case "allowedips":
+		p.AllowedIPs = append(p.AllowedIPs, splitList(value)...)
+	case "endpoint":
+		p.Endpoint = value
+	case "persistentkeepalive":
+		if value == "off" {
+			p.PersistentKeepalive = 0
+			break
+		}
+		p.PersistentKeepalive, err = parseInt(value)
+	default:
+		err = fmt.Errorf("unknown setting %q in [Peer] section", key)
+	}
+
+	return err
+}
+
+func splitList(s string) []string {
+	out := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func parseInt(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
+
+func parsePort(s string) (int, error) {
+	v, err := parseInt(s)
+	if err != nil || v > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,71 @@
+// Package wgquick implements the configuration file format of wg-quick,
+// which is understood by most WireGuard clients, including the mobile apps.
+package wgquick
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Config is a wg-quick configuration, containing the settings
+// of a single interface, along with those of its peers.
+type Config struct {
+	Interface Interface
+	Peers     []*Peer
+}
+
+// Interface contains the settings in the [Interface] section.
+type Interface struct {
+	PrivateKey string
+	Address    []string
+	ListenPort int
+	DNS        []string
+	MTU        int
+}
+
+// Peer contains the settings in a [Peer] section.
+type Peer struct {
+	PublicKey           string
+	PresharedKey        string
+	AllowedIPs          []string
+	Endpoint            string
+	PersistentKeepalive int
+}
+
+// Marshal returns the configuration in the wg-quick format. Optional
+// settings which are not set are omitted.
+func (c *Config) Marshal() []byte {
+
+	b := &bytes.Buffer{}
+
+	b.WriteString("[Interface]\n")
+	writeValue(b, "PrivateKey", c.Interface.PrivateKey)
+	writeValue(b, "Address", strings.Join(c.Interface.Address, ", "))
+	writeInt(b, "ListenPort", c.Interface.ListenPort)
+	writeValue(b, "DNS", strings.Join(c.Interface.DNS, ", "))
+	writeInt(b, "MTU", c.Interface.MTU)
+
+	for _, p := range c.Peers {
+		b.WriteString("\n[Peer]\n")
+		writeValue(b, "PublicKey", p.PublicKey)
+		writeValue(b, "PresharedKey", p.PresharedKey)
+		writeValue(b, "AllowedIPs", strings.Join(p.AllowedIPs, ", "))
+		writeValue(b, "Endpoint", p.Endpoint)
+		writeInt(b, "PersistentKeepalive", p.PersistentKeepalive)
+	}
+
+	return b.Bytes()
+}
+
+func writeValue(b *bytes.Buffer, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s = %s\n", key, value)
+	}
+}
+
+func writeInt(b *bytes.Buffer, key string, value int) {
+	if value != 0 {
+		fmt.Fprintf(b, "%s = %d\n", key, value)
+	}
+}
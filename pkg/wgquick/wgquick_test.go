@@ -0,0 +1,137 @@
+package wgquick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+
+	c := &Config{
+		Interface: Interface{
+			PrivateKey: "cHJpdmF0ZQ==",
+			Address:    []string{"10.0.0.2/24"},
+			DNS:        []string{"1.1.1.1", "8.8.8.8"},
+		},
+		Peers: []*Peer{
+			{
+				PublicKey:           "cHVibGlj",
+				PresharedKey:        "cHNr",
+				AllowedIPs:          []string{"10.0.0.1/32", "192.168.1.0/24"},
+				Endpoint:            "203.0.113.1:51820",
+				PersistentKeepalive: 25,
+			},
+			{
+				PublicKey:  "b3RoZXI=",
+				AllowedIPs: []string{"10.0.0.3/32"},
+			},
+		},
+	}
+
+	expected := `[Interface]
+PrivateKey = cHJpdmF0ZQ==
+Address = 10.0.0.2/24
+DNS = 1.1.1.1, 8.8.8.8
+
+[Peer]
+PublicKey = cHVibGlj
+PresharedKey = cHNr
+AllowedIPs = 10.0.0.1/32, 192.168.1.0/24
+Endpoint = 203.0.113.1:51820
+PersistentKeepalive = 25
+
+[Peer]
+PublicKey = b3RoZXI=
+AllowedIPs = 10.0.0.3/32
+`
+
+	if got := string(c.Marshal()); got != expected {
+		t.Fatalf("c.Marshal() returned:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	in := `
+# Hand-rolled configuration
+[Interface]
+PrivateKey = cHJpdmF0ZQ==
+Address = 10.0.0.2/24, fd00::2/64
+ListenPort = 51820
+PostUp = iptables -A FORWARD -i %i -j ACCEPT
+
+[Peer]
+PublicKey = cHVibGlj
+AllowedIPs = 10.0.0.1/32
+AllowedIPs = 192.168.1.0/24
+Endpoint = 203.0.113.1:51820 # gateway
+PersistentKeepalive = 25
+
+[peer]
+publickey = b3RoZXI=
+AllowedIPs = 10.0.0.3/32
+`
+
+	c, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if c.Interface.PrivateKey != "cHJpdmF0ZQ==" || c.Interface.ListenPort != 51820 {
+		t.Fatalf("unexpected interface settings: %+v", c.Interface)
+	}
+	if !reflect.DeepEqual(c.Interface.Address, []string{"10.0.0.2/24", "fd00::2/64"}) {
+		t.Fatalf("unexpected addresses: %v", c.Interface.Address)
+	}
+	if len(c.Peers) != 2 {
+		t.Fatalf("expected 2 peers, got %d", len(c.Peers))
+	}
+
+	p := c.Peers[0]
+	if p.PublicKey != "cHVibGlj" || p.Endpoint != "203.0.113.1:51820" || p.PersistentKeepalive != 25 {
+		t.Fatalf("unexpected peer settings: %+v", p)
+	}
+	if !reflect.DeepEqual(p.AllowedIPs, []string{"10.0.0.1/32", "192.168.1.0/24"}) {
+		t.Fatalf("unexpected allowed IPs: %v", p.AllowedIPs)
+	}
+	if c.Peers[1].PublicKey != "b3RoZXI=" {
+		t.Fatalf("expected keys to be case-insensitive")
+	}
+
+	// Round-trip through Marshal
+	c2, err := Parse(strings.NewReader(string(c.Marshal())))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if !reflect.DeepEqual(c, c2) {
+		t.Fatalf("round-trip mismatch:\n%+v\n%+v", c, c2)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+
+	tests := []struct {
+		in   string
+		line int
+	}{
+		{"[Peer]\nPublicKey = a\n", 2},
+		{"[Interface]\nListenPort = abc\n", 2},
+		{"[Interface]\n[Interface]\n", 2},
+		{"[Interface]\nFoo = bar\n", 2},
+		{"PrivateKey = a\n", 1},
+		{"[Interface]\n[Bogus]\n", 2},
+		{"[Interface]\nPrivateKey\n", 2},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.in))
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("Parse(%q) returned %v, expected a ParseError", test.in, err)
+		}
+		if perr.Line != test.line {
+			t.Fatalf("Parse(%q) failed at line %d, expected %d", test.in, perr.Line, test.line)
+		}
+	}
+}